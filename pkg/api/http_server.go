@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/middleware/csrf"
 
@@ -575,8 +576,8 @@ func (hs *HTTPServer) healthzHandler(ctx *web.Context) {
 }
 
 // apiHealthHandler will return ok if Grafana's web server is running and it
-// can access the database. If the database cannot be accessed it will return
-// http status code 503.
+// can access the database. If the database cannot be accessed, or if the
+// delivery of the alerts is stuck, it will return http status code 503.
 func (hs *HTTPServer) apiHealthHandler(ctx *web.Context) {
 	notHeadOrGet := ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead
 	if notHeadOrGet || ctx.Req.URL.Path != "/api/health" {
@@ -590,10 +591,15 @@ func (hs *HTTPServer) apiHealthHandler(ctx *web.Context) {
 		data.Set("commit", hs.Cfg.BuildCommit)
 	}
 
+	alertingHealthy := hs.setAlertingHealth(data)
+
 	if !hs.databaseHealthy(ctx.Req.Context()) {
 		data.Set("database", "failing")
 		ctx.Resp.Header().Set("Content-Type", "application/json; charset=UTF-8")
 		ctx.Resp.WriteHeader(503)
+	} else if !alertingHealthy {
+		ctx.Resp.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		ctx.Resp.WriteHeader(503)
 	} else {
 		ctx.Resp.Header().Set("Content-Type", "application/json; charset=UTF-8")
 		ctx.Resp.WriteHeader(200)
@@ -610,6 +616,34 @@ func (hs *HTTPServer) apiHealthHandler(ctx *web.Context) {
 	}
 }
 
+// setAlertingHealth adds the health of the delivery of the alerts to the health data, if this instance delivers
+// alerts. It returns false if the admin configuration of the alerting was not synced for too long, which means
+// that the notification pipeline is stuck. Failing Alertmanagers are reported but do not make it unhealthy, as
+// they are not an issue of this instance.
+func (hs *HTTPServer) setAlertingHealth(data *simplejson.Json) bool {
+	if hs.AlertNG == nil {
+		return true
+	}
+	h, ok := hs.AlertNG.DeliveryHealth()
+	if !ok {
+		return true
+	}
+
+	alerting := map[string]interface{}{
+		"status":               "ok",
+		"failingAlertmanagers": h.FailingAlertmanagers,
+		"unhealthyOrgs":        h.UnhealthyOrgs,
+	}
+	if !h.LastConfigSync.IsZero() {
+		alerting["secondsSinceConfigSync"] = int64(time.Since(h.LastConfigSync).Seconds())
+	}
+	if h.ConfigSyncStale {
+		alerting["status"] = "failing"
+	}
+	data.Set("alerting", alerting)
+	return !h.ConfigSyncStale
+}
+
 func (hs *HTTPServer) mapStatic(m *web.Mux, rootDir string, dir string, prefix string, exclude ...string) {
 	headers := func(c *web.Context) {
 		c.Resp.Header().Set("Cache-Control", "public, max-age=3600")
@@ -272,6 +272,129 @@ func (gr *ResampleCommand) Execute(ctx context.Context, vars mathexp.Vars) (math
 	return newRes, nil
 }
 
+// JoinCommand is an expression command that looks up, for each value of a variable, the value of another
+// variable with the same values for some labels, e.g. the request count of an instance queried from one
+// datasource for the error rate of each endpoint of the instance queried from another. The values looked up
+// get the labels of the values they are looked up for, so that a math expression can combine both.
+type JoinCommand struct {
+	VarToJoin   string
+	VarToLookup string
+	On          []string
+	refID       string
+}
+
+// NewJoinCommand creates a new JoinCommand. It will return an error if there is no label to join on.
+func NewJoinCommand(refID, varToJoin, varToLookup string, on []string) (*JoinCommand, error) {
+	if len(on) == 0 {
+		return nil, fmt.Errorf("no labels to join on for refId %v", refID)
+	}
+	return &JoinCommand{
+		VarToJoin:   varToJoin,
+		VarToLookup: varToLookup,
+		On:          on,
+		refID:       refID,
+	}, nil
+}
+
+// UnmarshalJoinCommand creates a JoinCommand from Grafana's frontend query.
+func UnmarshalJoinCommand(rn *rawNode) (*JoinCommand, error) {
+	vars := make([]string, 0, 2)
+	for _, key := range []string{"expression", "lookup"} {
+		rawVar, ok := rn.Query[key]
+		if !ok {
+			return nil, fmt.Errorf("no %s variable specified in join command for refId %v", key, rn.RefID)
+		}
+		v, ok := rawVar.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected join %s variable to be a string, got %T for refId %v", key, rawVar, rn.RefID)
+		}
+		vars = append(vars, strings.TrimPrefix(v, "$"))
+	}
+
+	rawOn, ok := rn.Query["on"]
+	if !ok {
+		return nil, fmt.Errorf("no labels to join on specified for refId %v", rn.RefID)
+	}
+	rawLabels, ok := rawOn.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected join labels to be an array, got %T for refId %v", rawOn, rn.RefID)
+	}
+	on := make([]string, 0, len(rawLabels))
+	for _, rawLabel := range rawLabels {
+		label, ok := rawLabel.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected join label to be a string, got %T for refId %v", rawLabel, rn.RefID)
+		}
+		on = append(on, label)
+	}
+
+	return NewJoinCommand(rn.RefID, vars[0], vars[1], on)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gj *JoinCommand) NeedsVars() []string {
+	return []string{gj.VarToJoin, gj.VarToLookup}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute. Values without a value to look up, or without some of the labels
+// to join on, are dropped. Several values to look up with the same labels are an error.
+func (gj *JoinCommand) Execute(_ context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	lookup := make(map[string]mathexp.Value, len(vars[gj.VarToLookup].Values))
+	for _, val := range vars[gj.VarToLookup].Values {
+		key, ok := gj.joinKey(val.GetLabels())
+		if !ok {
+			continue
+		}
+		if _, ok := lookup[key]; ok {
+			return newRes, fmt.Errorf("several values of %s have the labels %s to join on", gj.VarToLookup, key)
+		}
+		lookup[key] = val
+	}
+
+	for _, val := range vars[gj.VarToJoin].Values {
+		key, ok := gj.joinKey(val.GetLabels())
+		if !ok {
+			continue
+		}
+		found, ok := lookup[key]
+		if !ok {
+			continue
+		}
+		switch v := found.(type) {
+		case mathexp.Number:
+			num := mathexp.NewNumber(gj.refID, val.GetLabels().Copy())
+			num.SetValue(v.GetFloat64Value())
+			newRes.Values = append(newRes.Values, num)
+		case mathexp.Series:
+			series := mathexp.NewSeries(gj.refID, val.GetLabels().Copy(), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				series.SetPoint(i, t, f)
+			}
+			newRes.Values = append(newRes.Values, series)
+		default:
+			return newRes, fmt.Errorf("can only look up type number or series, got type %v", found.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// joinKey returns the values of the labels to join on, false if some of them are missing.
+func (gj *JoinCommand) joinKey(labels data.Labels) (string, bool) {
+	on := make(data.Labels, len(gj.On))
+	for _, name := range gj.On {
+		v, ok := labels[name]
+		if !ok {
+			return "", false
+		}
+		on[name] = v
+	}
+	return on.String(), true
+}
+
 // CommandType is the type of the expression command.
 type CommandType int
 
@@ -286,6 +409,8 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeJoin is the CMDType for a join expression.
+	TypeJoin
 )
 
 func (gt CommandType) String() string {
@@ -298,6 +423,8 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeJoin:
+		return "join"
 	default:
 		return "unknown"
 	}
@@ -314,6 +441,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "join":
+		return TypeJoin, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
@@ -142,3 +143,122 @@ func randomReduceFunc() string {
 	res := mathexp.GetSupportedReduceFuncs()
 	return res[rand.Intn(len(res)-1)]
 }
+
+func Test_UnmarshalJoinCommand(t *testing.T) {
+	var tests = []struct {
+		name    string
+		query   string
+		isError bool
+	}{
+		{
+			name:  "join command",
+			query: `{ "expression" : "$A", "lookup": "$B", "on": ["instance"] }`,
+		},
+		{
+			name:    "error when the lookup variable is not specified",
+			query:   `{ "expression" : "$A", "on": ["instance"] }`,
+			isError: true,
+		},
+		{
+			name:    "error when on is not an array",
+			query:   `{ "expression" : "$A", "lookup": "$B", "on": "instance" }`,
+			isError: true,
+		},
+		{
+			name:    "error when on is empty",
+			query:   `{ "expression" : "$A", "lookup": "$B", "on": [] }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalJoinCommand(&rawNode{RefID: "C", Query: qmap})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.Equal(t, []string{"A", "B"}, cmd.NeedsVars())
+			require.Equal(t, []string{"instance"}, cmd.On)
+		})
+	}
+}
+
+func TestJoinExecute(t *testing.T) {
+	cmd, err := NewJoinCommand("C", "A", "B", []string{"instance"})
+	require.NoError(t, err)
+
+	number := func(labels data.Labels, value float64) mathexp.Value {
+		n := mathexp.NewNumber("", labels)
+		n.SetValue(&value)
+		return n
+	}
+
+	t.Run("should look up the values with the same labels to join on", func(t *testing.T) {
+		vars := mathexp.Vars{
+			"A": {Values: mathexp.Values{
+				number(data.Labels{"instance": "a", "path": "/api"}, 1),
+				number(data.Labels{"instance": "a", "path": "/login"}, 2),
+				number(data.Labels{"instance": "b", "path": "/api"}, 3),
+				number(data.Labels{"instance": "c", "path": "/api"}, 4),
+				number(data.Labels{"path": "/api"}, 5),
+			}},
+			"B": {Values: mathexp.Values{
+				number(data.Labels{"instance": "a", "host": "x"}, 10),
+				number(data.Labels{"instance": "b", "host": "y"}, 20),
+			}},
+		}
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 3)
+		expected := []struct {
+			labels data.Labels
+			value  float64
+		}{
+			{data.Labels{"instance": "a", "path": "/api"}, 10},
+			{data.Labels{"instance": "a", "path": "/login"}, 10},
+			{data.Labels{"instance": "b", "path": "/api"}, 20},
+		}
+		for i, e := range expected {
+			require.Equal(t, e.labels, res.Values[i].GetLabels())
+			require.Equal(t, e.value, *res.Values[i].(mathexp.Number).GetFloat64Value())
+		}
+	})
+
+	t.Run("should look up series", func(t *testing.T) {
+		series := mathexp.NewSeries("", data.Labels{"instance": "a"}, 2)
+		series.SetPoint(0, time.Unix(0, 0), ptr.Float64(1))
+		series.SetPoint(1, time.Unix(60, 0), ptr.Float64(2))
+		vars := mathexp.Vars{
+			"A": {Values: mathexp.Values{number(data.Labels{"instance": "a", "path": "/api"}, 1)}},
+			"B": {Values: mathexp.Values{series}},
+		}
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		joined := res.Values[0].(mathexp.Series)
+		require.Equal(t, data.Labels{"instance": "a", "path": "/api"}, joined.GetLabels())
+		require.Equal(t, 2, joined.Len())
+		require.Equal(t, 2.0, *joined.GetValue(1))
+	})
+
+	t.Run("should fail if several values have the same labels to join on", func(t *testing.T) {
+		vars := mathexp.Vars{
+			"A": {Values: mathexp.Values{number(data.Labels{"instance": "a"}, 1)}},
+			"B": {Values: mathexp.Values{
+				number(data.Labels{"instance": "a", "host": "x"}, 10),
+				number(data.Labels{"instance": "a", "host": "y"}, 20),
+			}},
+		}
+
+		_, err := cmd.Execute(context.Background(), vars)
+		require.Error(t, err)
+	})
+}
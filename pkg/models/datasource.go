@@ -81,6 +81,24 @@ func (ds DataSource) AllowedCookies() []string {
 	return []string{}
 }
 
+// AlertingMaxConcurrentEvaluations returns the jsondata.alertingMaxConcurrentEvaluations, the most alert rules
+// querying the datasource evaluated at the same time, 0 if there is no limit.
+func (ds DataSource) AlertingMaxConcurrentEvaluations() int {
+	if ds.JsonData != nil {
+		return ds.JsonData.Get("alertingMaxConcurrentEvaluations").MustInt(0)
+	}
+	return 0
+}
+
+// AlertingRemoteWritePath returns the jsondata.alertingRemoteWritePath, the path of the remote write API the
+// recording rules write their samples to, /api/v1/write if it is not set.
+func (ds DataSource) AlertingRemoteWritePath() string {
+	if ds.JsonData != nil {
+		return ds.JsonData.Get("alertingRemoteWritePath").MustString("/api/v1/write")
+	}
+	return "/api/v1/write"
+}
+
 // ----------------------
 // COMMANDS
 
@@ -385,6 +385,10 @@ const (
 	// External alerting notifications actions. We can only narrow it down to writes or reads, as we don't control the atomicity in the external system.
 	ActionAlertingNotificationsExternalWrite = "alert.notifications.external:write"
 	ActionAlertingNotificationsExternalRead  = "alert.notifications.external:read"
+
+	// External Alertmanagers scope. The external notifications actions in this scope allow to manage the external
+	// Alertmanagers Grafana sends the alerts of the organization to, rather than the ones of data sources.
+	ScopeAlertingExternalAlertmanagersAll = "alertmanagers:*"
 )
 
 var (
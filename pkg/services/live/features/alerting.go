@@ -0,0 +1,35 @@
+package features
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// AlertingHandler manages the `grafana/alerting/events` channel, that unified alerting broadcasts the state
+// transitions of the alert instances and the alerts dispatched to the notifiers of an organization to.
+type AlertingHandler struct{}
+
+// GetHandlerForPath called on init.
+func (h *AlertingHandler) GetHandlerForPath(_ string) (models.ChannelHandler, error) {
+	return h, nil
+}
+
+// OnSubscribe only allows the admins of the organization to subscribe to the events, as they carry the labels
+// and annotations of the alerts of all the folders.
+func (h *AlertingHandler) OnSubscribe(_ context.Context, user *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	if e.Path != "events" {
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, nil
+	}
+	if !user.HasRole(models.ROLE_ADMIN) {
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, nil
+	}
+	return models.SubscribeReply{}, backend.SubscribeStreamStatusOK, nil
+}
+
+// OnPublish is not used for alerting, the events are published by the server only.
+func (h *AlertingHandler) OnPublish(_ context.Context, _ *models.SignedInUser, _ models.PublishEvent) (models.PublishReply, backend.PublishStreamStatus, error) {
+	return models.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
+}
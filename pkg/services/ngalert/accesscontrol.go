@@ -138,6 +138,39 @@ var (
 		},
 	}
 
+	alertmanagersReaderRole = accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        accesscontrol.FixedRolePrefix + "alerting.alertmanagers:reader",
+			DisplayName: "External Alertmanagers Reader",
+			Description: "Can read the configuration and the status of the external Alertmanagers Grafana sends alerts to",
+			Group:       AlertRolesGroup,
+			Version:     1,
+			Permissions: []accesscontrol.Permission{
+				{
+					Action: accesscontrol.ActionAlertingNotificationsExternalRead,
+					Scope:  accesscontrol.ScopeAlertingExternalAlertmanagersAll,
+				},
+			},
+		},
+	}
+
+	alertmanagersEditorRole = accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        accesscontrol.FixedRolePrefix + "alerting.alertmanagers:editor",
+			DisplayName: "External Alertmanagers Editor",
+			Description: "Can configure the external Alertmanagers Grafana sends alerts to, and pause or redo the deliveries to them",
+			Group:       AlertRolesGroup,
+			Version:     1,
+			Permissions: accesscontrol.ConcatPermissions(alertmanagersReaderRole.Role.Permissions, []accesscontrol.Permission{
+				{
+					Action: accesscontrol.ActionAlertingNotificationsExternalWrite,
+					Scope:  accesscontrol.ScopeAlertingExternalAlertmanagersAll,
+				},
+			}),
+		},
+		Grants: []string{string(models.ROLE_ADMIN), accesscontrol.RoleGrafanaAdmin},
+	}
+
 	alertingReaderRole = accesscontrol.RoleRegistration{
 		Role: accesscontrol.RoleDTO{
 			Name:        accesscontrol.FixedRolePrefix + "alerting:reader",
@@ -168,6 +201,7 @@ func DeclareFixedRoles(ac accesscontrol.AccessControl) error {
 		rulesReaderRole, rulesEditorRole,
 		instancesReaderRole, instancesEditorRole,
 		notificationsReaderRole, notificationsEditorRole,
+		alertmanagersReaderRole, alertmanagersEditorRole,
 		alertingReaderRole, alertingWriterRole,
 	)
 }
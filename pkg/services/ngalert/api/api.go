@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -31,6 +32,18 @@ var timeNow = time.Now
 type Scheduler interface {
 	AlertmanagersFor(orgID int64) []*url.URL
 	DroppedAlertmanagersFor(orgID int64) []*url.URL
+	IsUnhealthy(orgID int64) bool
+	PendingRoutingModeChangeFor(orgID int64) (schedule.PendingRoutingModeChange, bool)
+	InconsistenciesFor(orgID int64) []string
+	SenderStatuses() []schedule.SenderStatus
+	AdminConfigurationChanged()
+	SendTestAlert(ctx context.Context, cfg *models.AdminConfiguration) []sender.AMTestResult
+	DeadLetterAlerts(ctx context.Context, orgID int64) ([]*models.DeadLetterAlert, error)
+	RedispatchDeadLetterAlerts(ctx context.Context, orgID int64) (int, error)
+	PurgeDeadLetterAlerts(ctx context.Context, orgID int64) error
+	PauseExternalDeliveryFor(orgID int64, duration time.Duration)
+	ResumeExternalDeliveryFor(orgID int64)
+	ExternalDeliveryPausedFor(orgID int64) (bool, time.Time)
 }
 
 type Alertmanager interface {
@@ -51,6 +64,7 @@ type Alertmanager interface {
 
 	// Testing
 	TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*notifier.TestReceiversResult, error)
+	TestTemplates(ctx context.Context, c apimodels.TestTemplatesConfigBodyParams) ([]notifier.TestTemplatesResult, error)
 }
 
 type AlertingStore interface {
@@ -71,6 +85,8 @@ type API struct {
 	InstanceStore        store.InstanceStore
 	AlertingStore        AlertingStore
 	AdminConfigStore     store.AdminConfigurationStore
+	StateHistoryStore    store.StateHistoryStore
+	DeliveryReceiptStore store.DeliveryReceiptStore
 	DataProxy            *datasourceproxy.DataSourceProxyService
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	StateManager         *state.Manager
@@ -81,6 +97,7 @@ type API struct {
 	Templates            *provisioning.TemplateService
 	MuteTimings          *provisioning.MuteTimingService
 	AlertRules           *provisioning.AlertRuleService
+	MaintenanceWindows   *provisioning.MaintenanceWindowService
 }
 
 // RegisterAPIEndpoints registers API handlers
@@ -116,6 +133,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			log:             logger,
 			cfg:             &api.Cfg.UnifiedAlerting,
 			ac:              api.AccessControl,
+			amConfigStore:   api.AlertingStore,
 		},
 	), m)
 	api.RegisterTestingApiEndpoints(NewForkedTestingApi(
@@ -126,12 +144,18 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			log:               logger,
 			accessControl:     api.AccessControl,
 			evaluator:         eval.NewEvaluator(api.Cfg, log.New("ngalert.eval"), api.DatasourceCache, api.SecretsService),
+			cfg:               api.Cfg,
 		}), m)
 	api.RegisterConfigurationApiEndpoints(NewForkedConfiguration(
 		&AdminSrv{
-			store:     api.AdminConfigStore,
-			log:       logger,
-			scheduler: api.Schedule,
+			store:           api.AdminConfigStore,
+			ruleStore:       api.RuleStore,
+			historyStore:    api.StateHistoryStore,
+			receiptStore:    api.DeliveryReceiptStore,
+			log:             logger,
+			scheduler:       api.Schedule,
+			secretsService:  api.SecretsService,
+			provenanceStore: api.ProvenanceStore,
 		},
 	), m)
 
@@ -142,5 +166,6 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		templates:           api.Templates,
 		muteTimings:         api.MuteTimings,
 		alertRules:          api.AlertRules,
+		maintenanceWindows:  api.MaintenanceWindows,
 	}), m)
 }
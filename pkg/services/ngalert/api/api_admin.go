@@ -1,24 +1,48 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/util"
 
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// defaultAlertStateHistoryLimit is the most state transitions returned if the request does not set a limit.
+	defaultAlertStateHistoryLimit = 100
+	// maxAlertStateHistoryLimit is the most state transitions returned whatever the limit of the request.
+	maxAlertStateHistoryLimit = 1000
+	// defaultDeliveryReceiptsLimit is the most delivery receipts returned if the request does not set a limit.
+	defaultDeliveryReceiptsLimit = 100
+	// maxDeliveryReceiptsLimit is the most delivery receipts returned whatever the limit of the request.
+	maxDeliveryReceiptsLimit = 1000
 )
 
 type AdminSrv struct {
-	scheduler Scheduler
-	store     store.AdminConfigurationStore
-	log       log.Logger
+	scheduler       Scheduler
+	store           store.AdminConfigurationStore
+	ruleStore       store.RuleStore
+	historyStore    store.StateHistoryStore
+	receiptStore    store.DeliveryReceiptStore
+	log             log.Logger
+	secretsService  secrets.Service
+	provenanceStore provisioning.ProvisioningStore
 }
 
 func (srv AdminSrv) RouteGetAlertmanagers(c *models.ReqContext) response.Response {
@@ -32,17 +56,254 @@ func (srv AdminSrv) RouteGetAlertmanagers(c *models.ReqContext) response.Respons
 		ams.Dropped[i].URL = url.String()
 	}
 
-	return response.JSON(http.StatusOK, apimodels.GettableAlertmanagers{
-		Status: "success",
-		Data:   ams,
-	})
+	resp := apimodels.GettableAlertmanagers{
+		Status:          "success",
+		Data:            ams,
+		Unhealthy:       srv.scheduler.IsUnhealthy(c.OrgId),
+		Inconsistencies: srv.scheduler.InconsistenciesFor(c.OrgId),
+	}
+	if pending, ok := srv.scheduler.PendingRoutingModeChangeFor(c.OrgId); ok {
+		resp.PendingAlertmanagersChoice = &apimodels.PendingAlertmanagersChoice{
+			AlertmanagersChoice: apimodels.AlertmanagersChoice(pending.SendAlertsTo.String()),
+			Since:               pending.Since,
+		}
+	}
+
+	return response.JSON(http.StatusOK, resp)
 }
 
-func (srv AdminSrv) RouteGetNGalertConfig(c *models.ReqContext) response.Response {
-	if c.OrgRole != models.ROLE_ADMIN {
-		return accessForbiddenResp()
+// RouteGetSenders returns the status of the senders of all the organizations to Grafana admins, and of the
+// sender of their organization to the other users.
+func (srv AdminSrv) RouteGetSenders(c *models.ReqContext) response.Response {
+	statuses := srv.scheduler.SenderStatuses()
+	resp := apimodels.GettableSenders{Senders: make([]apimodels.GettableSender, 0, len(statuses))}
+	for _, status := range statuses {
+		if !c.IsGrafanaAdmin && status.OrgID != c.OrgId {
+			continue
+		}
+		s := apimodels.GettableSender{
+			OrgID:                status.OrgID,
+			AlertmanagersChoice:  apimodels.AlertmanagersChoice(status.SendAlertsTo.String()),
+			ActiveAlertmanagers:  make([]string, 0, len(status.Alertmanagers)),
+			DroppedAlertmanagers: make([]string, 0, len(status.DroppedAlertmanagers)),
+			ConfigHash:           status.ConfigHash,
+		}
+		for _, u := range status.Alertmanagers {
+			s.ActiveAlertmanagers = append(s.ActiveAlertmanagers, u.String())
+		}
+		for _, u := range status.DroppedAlertmanagers {
+			s.DroppedAlertmanagers = append(s.DroppedAlertmanagers, u.String())
+		}
+		if !status.LastSuccess.IsZero() {
+			lastSuccess := status.LastSuccess
+			s.LastSuccessfulDelivery = &lastSuccess
+		}
+		resp.Senders = append(resp.Senders, s)
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+// RouteGetAlertStateHistory returns the state transitions of the alert instances of the rules of the organization
+// in the folders the user can read, most recent first.
+func (srv AdminSrv) RouteGetAlertStateHistory(c *models.ReqContext) response.Response {
+	query := ngmodels.GetAlertStateHistoryQuery{
+		OrgID:   c.OrgId,
+		RuleUID: c.Query("ruleUID"),
+		Limit:   c.QueryInt("limit"),
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultAlertStateHistoryLimit
+	}
+	if query.Limit > maxAlertStateHistoryLimit {
+		query.Limit = maxAlertStateHistoryLimit
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.UnixMilli(to)
+	}
+	labels, err := parseInstanceLabels(c.QueryStrings("labels"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	query.Labels = labels
+
+	namespaceMap, err := srv.ruleStore.GetUserVisibleNamespaces(c.Req.Context(), c.OrgId, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	resp := apimodels.GettableAlertStateHistory{Transitions: []apimodels.GettableAlertStateTransition{}}
+	if len(namespaceMap) == 0 {
+		srv.log.Debug("User does not have access to any namespaces")
+		return response.JSON(http.StatusOK, resp)
+	}
+	if query.RuleUID != "" {
+		ruleQuery := ngmodels.GetAlertRuleByUIDQuery{UID: query.RuleUID, OrgID: c.OrgId}
+		if err := srv.ruleStore.GetAlertRuleByUID(c.Req.Context(), &ruleQuery); err != nil {
+			if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+				return ErrResp(http.StatusNotFound, err, "")
+			}
+			return ErrResp(http.StatusInternalServerError, err, "failed to get the alert rule")
+		}
+		if _, ok := namespaceMap[ruleQuery.Result.NamespaceUID]; !ok {
+			return ErrResp(http.StatusForbidden, errors.New("the user cannot read the folder of the alert rule"), "")
+		}
+	}
+	query.NamespaceUIDs = make([]string, 0, len(namespaceMap))
+	for uid := range namespaceMap {
+		query.NamespaceUIDs = append(query.NamespaceUIDs, uid)
+	}
+
+	if err := srv.historyStore.GetAlertStateHistory(c.Req.Context(), &query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the state history")
+	}
+	for _, t := range query.Result {
+		resp.Transitions = append(resp.Transitions, apimodels.GettableAlertStateTransition{
+			RuleUID:        t.RuleUID,
+			RuleVersion:    t.RuleVersion,
+			Labels:         t.Labels,
+			PreviousState:  string(t.PreviousState),
+			PreviousReason: t.PreviousReason,
+			State:          string(t.State),
+			Reason:         t.Reason,
+			Timestamp:      t.TransitionedAt,
+		})
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+// RouteGetDeliveryReceipts returns the outcomes of sending the alerts of the organization to its external
+// Alertmanager(s), most recent first.
+func (srv AdminSrv) RouteGetDeliveryReceipts(c *models.ReqContext) response.Response {
+	query := ngmodels.GetDeliveryReceiptsQuery{
+		OrgID:   c.OrgId,
+		RuleUID: c.Query("ruleUID"),
+		BatchID: c.Query("batchID"),
+		Limit:   c.QueryInt("limit"),
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultDeliveryReceiptsLimit
+	}
+	if query.Limit > maxDeliveryReceiptsLimit {
+		query.Limit = maxDeliveryReceiptsLimit
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.UnixMilli(to)
+	}
+	labels, err := parseInstanceLabels(c.QueryStrings("labels"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	query.Labels = labels
+
+	if err := srv.receiptStore.GetDeliveryReceipts(c.Req.Context(), &query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the delivery receipts")
+	}
+	resp := apimodels.GettableDeliveryReceipts{Receipts: make([]apimodels.GettableDeliveryReceipt, 0, len(query.Result))}
+	for _, r := range query.Result {
+		resp.Receipts = append(resp.Receipts, apimodels.GettableDeliveryReceipt{
+			BatchID:    r.BatchID,
+			Target:     r.Target,
+			RuleUID:    r.RuleUID,
+			Labels:     r.Labels,
+			Delivered:  r.Delivered(),
+			StatusCode: r.StatusCode,
+			Error:      r.Error,
+			Attempts:   r.Attempts,
+			Timestamp:  r.SentAt,
+		})
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+// parseInstanceLabels parses the labels of an alert instance given as name=value pairs, nil if there are none.
+func parseInstanceLabels(pairs []string) (ngmodels.InstanceLabels, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	labels := make(ngmodels.InstanceLabels, len(pairs))
+	for _, l := range pairs {
+		parts := strings.SplitN(l, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid label %q, expected name=value", l)
+		}
+		labels[parts[0]] = parts[1]
+	}
+	return labels, nil
+}
+
+// RouteGetDeadLetterAlerts returns the alerts of the organization that could not be delivered, oldest first.
+func (srv AdminSrv) RouteGetDeadLetterAlerts(c *models.ReqContext) response.Response {
+	deadLetters, err := srv.scheduler.DeadLetterAlerts(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the alerts that could not be delivered")
 	}
+	resp := apimodels.GettableDeadLetterAlerts{Alerts: make([]apimodels.GettableDeadLetterAlert, 0, len(deadLetters))}
+	for _, d := range deadLetters {
+		a := apimodels.GettableDeadLetterAlert{ID: d.ID, RuleUID: d.RuleUID, CreatedAt: d.CreatedAt}
+		if err := json.Unmarshal([]byte(d.Alert), &a.Alert); err != nil {
+			srv.log.Error("unable to unmarshal alert that could not be delivered", "id", d.ID, "err", err)
+			continue
+		}
+		resp.Alerts = append(resp.Alerts, a)
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+// RouteRedispatchDeadLetterAlerts delivers again the alerts of the organization that could not be delivered.
+func (srv AdminSrv) RouteRedispatchDeadLetterAlerts(c *models.ReqContext) response.Response {
+	n, err := srv.scheduler.RedispatchDeadLetterAlerts(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to deliver again the alerts that could not be delivered, %d were delivered", n)
+	}
+	return response.JSON(http.StatusOK, apimodels.RedispatchedDeadLetterAlerts{Redispatched: n})
+}
 
+// RoutePurgeDeadLetterAlerts deletes the alerts of the organization that could not be delivered.
+func (srv AdminSrv) RoutePurgeDeadLetterAlerts(c *models.ReqContext) response.Response {
+	if err := srv.scheduler.PurgeDeadLetterAlerts(c.Req.Context(), c.OrgId); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete the alerts that could not be delivered")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "alerts that could not be delivered deleted"})
+}
+
+// RouteGetExternalDeliveryPause returns whether the delivery of the alerts of the organization to its external
+// Alertmanager(s) is paused.
+func (srv AdminSrv) RouteGetExternalDeliveryPause(c *models.ReqContext) response.Response {
+	paused, until := srv.scheduler.ExternalDeliveryPausedFor(c.OrgId)
+	resp := apimodels.GettableExternalDeliveryPause{Paused: paused}
+	if paused && !until.IsZero() {
+		resp.Until = &until
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+// RoutePauseExternalDelivery sends the alerts of the organization to the local notifier only, for the duration
+// of the body or until it is resumed.
+func (srv AdminSrv) RoutePauseExternalDelivery(c *models.ReqContext, body apimodels.PostableExternalDeliveryPause) response.Response {
+	var duration time.Duration
+	if body.Duration != "" {
+		d, err := model.ParseDuration(body.Duration)
+		if err != nil || d <= 0 {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid duration %q", body.Duration), "")
+		}
+		duration = time.Duration(d)
+	}
+	srv.scheduler.PauseExternalDeliveryFor(c.OrgId, duration)
+	return response.JSON(http.StatusOK, util.DynMap{"message": "external delivery paused"})
+}
+
+// RouteResumeExternalDelivery resumes sending the alerts of the organization to its external Alertmanager(s).
+func (srv AdminSrv) RouteResumeExternalDelivery(c *models.ReqContext) response.Response {
+	srv.scheduler.ResumeExternalDeliveryFor(c.OrgId)
+	return response.JSON(http.StatusOK, util.DynMap{"message": "external delivery resumed"})
+}
+
+func (srv AdminSrv) RouteGetNGalertConfig(c *models.ReqContext) response.Response {
 	cfg, err := srv.store.GetAdminConfiguration(c.OrgId)
 	if err != nil {
 		if errors.Is(err, store.ErrNoAdminConfiguration) {
@@ -55,58 +316,363 @@ func (srv AdminSrv) RouteGetNGalertConfig(c *models.ReqContext) response.Respons
 	}
 
 	resp := apimodels.GettableNGalertConfig{
-		Alertmanagers:       cfg.Alertmanagers,
-		AlertmanagersChoice: apimodels.AlertmanagersChoice(cfg.SendAlertsTo.String()),
+		Alertmanagers:             cfg.Alertmanagers,
+		AlertmanagersChoice:       apimodels.AlertmanagersChoice(cfg.SendAlertsTo.String()),
+		Disabled:                  cfg.Disabled,
+		ExternalRuleUIDs:          cfg.ExternalRuleUIDs,
+		AlertmanagersProxyURL:     cfg.ProxyURL,
+		AlertmanagersNoProxy:      cfg.NoProxy,
+		AlertmanagersProxyURLs:    cfg.ProxyURLs,
+		AlertmanagersAPIVersions:  cfg.APIVersions,
+		AlertmanagersHeaders:      cfg.Headers,
+		AlertmanagersTimeout:      cfg.Timeout,
+		AlertmanagersTimeouts:     cfg.Timeouts,
+		AlertmanagersCompressions: cfg.Compressions,
+		SilenceSync:               string(cfg.SilenceSync),
+	}
+	if len(cfg.TLSConfigs) > 0 {
+		resp.AlertmanagersTLS = make(map[string]apimodels.AlertmanagerTLSConfig, len(cfg.TLSConfigs))
+		for u, tlsCfg := range cfg.TLSConfigs {
+			resp.AlertmanagersTLS[u] = apimodels.AlertmanagerTLSConfig(tlsCfg)
+		}
+	}
+	if len(cfg.Credentials) > 0 {
+		resp.AlertmanagersCredentials = make(map[string]apimodels.GettableAlertmanagerCredentials, len(cfg.Credentials))
+		for u, creds := range cfg.Credentials {
+			secureFields := make(map[string]bool, len(creds.SecureSettings))
+			for k := range creds.SecureSettings {
+				secureFields[k] = true
+			}
+			resp.AlertmanagersCredentials[u] = apimodels.GettableAlertmanagerCredentials{
+				BasicAuthUser: creds.BasicAuthUser,
+				SecureFields:  secureFields,
+			}
+		}
+	}
+	for _, wh := range cfg.Webhooks {
+		resp.Webhooks = append(resp.Webhooks, apimodels.AlertWebhookConfig(wh))
+	}
+	if cfg.Relabel != nil {
+		relabel := fromAlertRelabelConfigs(*cfg.Relabel)
+		resp.Relabel = &relabel
+	}
+	if len(cfg.TargetRelabels) > 0 {
+		resp.TargetRelabels = make(map[string]apimodels.AlertRelabelConfigs, len(cfg.TargetRelabels))
+		for u, rc := range cfg.TargetRelabels {
+			resp.TargetRelabels[u] = fromAlertRelabelConfigs(rc)
+		}
+	}
+	if cfg.ResolvedAlerts != nil {
+		resolved := apimodels.ResolvedAlertsConfig(*cfg.ResolvedAlerts)
+		resp.ResolvedAlerts = &resolved
+	}
+	if len(cfg.RuleResolvedAlerts) > 0 {
+		resp.RuleResolvedAlerts = make(map[string]apimodels.ResolvedAlertsConfig, len(cfg.RuleResolvedAlerts))
+		for uid, rc := range cfg.RuleResolvedAlerts {
+			resp.RuleResolvedAlerts[uid] = apimodels.ResolvedAlertsConfig(rc)
+		}
+	}
+	if cfg.MuteTimings != nil {
+		mt := apimodels.MuteTimingsConfig(*cfg.MuteTimings)
+		resp.MuteTimings = &mt
+	}
+	if len(cfg.RuleMuteTimings) > 0 {
+		resp.RuleMuteTimings = make(map[string]apimodels.MuteTimingsConfig, len(cfg.RuleMuteTimings))
+		for uid, mt := range cfg.RuleMuteTimings {
+			resp.RuleMuteTimings[uid] = apimodels.MuteTimingsConfig(mt)
+		}
+	}
+	if cfg.Grouping != nil {
+		g := apimodels.AlertGroupingConfig(*cfg.Grouping)
+		resp.Grouping = &g
+	}
+	if cfg.PagerDuty != nil {
+		secureFields := make(map[string]bool, len(cfg.PagerDuty.SecureSettings))
+		for k := range cfg.PagerDuty.SecureSettings {
+			secureFields[k] = true
+		}
+		resp.PagerDuty = &apimodels.GettablePagerDutyConfig{
+			URL:          cfg.PagerDuty.URL,
+			Severity:     cfg.PagerDuty.Severity,
+			SecureFields: secureFields,
+		}
+	}
+	if cfg.AWS != nil {
+		secureFields := make(map[string]bool, len(cfg.AWS.SecureSettings))
+		for k := range cfg.AWS.SecureSettings {
+			secureFields[k] = true
+		}
+		resp.AWS = &apimodels.GettableAWSConfig{
+			Region:        cfg.AWS.Region,
+			TopicARN:      cfg.AWS.TopicARN,
+			QueueURL:      cfg.AWS.QueueURL,
+			AssumeRoleARN: cfg.AWS.AssumeRoleARN,
+			ExternalID:    cfg.AWS.ExternalID,
+			Endpoint:      cfg.AWS.Endpoint,
+			SecureFields:  secureFields,
+		}
 	}
 	return response.JSON(http.StatusOK, resp)
 }
 
 func (srv AdminSrv) RoutePostNGalertConfig(c *models.ReqContext, body apimodels.PostableNGalertConfig) response.Response {
-	if c.OrgRole != models.ROLE_ADMIN {
-		return accessForbiddenResp()
+	if errResp := srv.checkNotProvisioned(c); errResp != nil {
+		return errResp
+	}
+
+	cfg, errResp := srv.toAdminConfiguration(c, body)
+	if errResp != nil {
+		return errResp
+	}
+
+	cmd := store.UpdateAdminConfigurationCmd{AdminConfiguration: cfg, UserID: c.UserId, Login: c.Login}
+	if err := srv.store.UpdateAdminConfiguration(cmd); err != nil {
+		msg := "failed to save the admin configuration to the database"
+		srv.log.Error(msg, "err", err)
+		return ErrResp(http.StatusBadRequest, err, msg)
 	}
+	srv.scheduler.AdminConfigurationChanged()
 
+	return response.JSON(http.StatusCreated, util.DynMap{"message": "admin configuration updated"})
+}
+
+// RouteTestNGalertConfig sends a test alert to the external Alertmanagers of an admin configuration, which is
+// validated like it would be saved but is not saved.
+func (srv AdminSrv) RouteTestNGalertConfig(c *models.ReqContext, body apimodels.PostableNGalertConfig) response.Response {
+	if len(body.Alertmanagers) == 0 {
+		return response.Error(400, "At least one Alertmanager must be provided to be tested", nil)
+	}
+
+	cfg, errResp := srv.toAdminConfiguration(c, body)
+	if errResp != nil {
+		return errResp
+	}
+
+	results := srv.scheduler.SendTestAlert(c.Req.Context(), cfg)
+	resp := apimodels.TestNGalertConfigResult{Results: make([]apimodels.AlertmanagerTestResult, 0, len(results))}
+	for _, res := range results {
+		r := apimodels.AlertmanagerTestResult{
+			URL:        res.URL,
+			Success:    res.Reachable,
+			StatusCode: res.StatusCode,
+			Latency:    res.Latency,
+		}
+		if res.Err != nil {
+			r.Error = res.Err.Error()
+		}
+		resp.Results = append(resp.Results, r)
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+// checkNotProvisioned returns the error response to send if the admin configuration of the organization is
+// provisioned from a file, it cannot be changed through the API.
+func (srv AdminSrv) checkNotProvisioned(c *models.ReqContext) response.Response {
+	provenance, err := srv.provenanceStore.GetProvenance(c.Req.Context(), &ngmodels.AdminConfiguration{OrgID: c.OrgId}, c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the provenance of the admin configuration")
+	}
+	if provenance != ngmodels.ProvenanceNone {
+		return ErrResp(http.StatusBadRequest, errors.New("the admin configuration was provisioned and cannot be changed through the API"), "")
+	}
+	return nil
+}
+
+// toAdminConfiguration validates an admin configuration of the API and converts it to the one that is stored,
+// with its credentials encrypted. It returns the error response to send if the configuration is not valid.
+func (srv AdminSrv) toAdminConfiguration(c *models.ReqContext, body apimodels.PostableNGalertConfig) (*ngmodels.AdminConfiguration, response.Response) {
 	sendAlertsTo, err := ngmodels.StringToAlertmanagersChoice(string(body.AlertmanagersChoice))
 	if err != nil {
-		return response.Error(400, "Invalid alertmanager choice specified", nil)
+		return nil, response.Error(400, "Invalid alertmanager choice specified", nil)
 	}
 
-	if sendAlertsTo == ngmodels.ExternalAlertmanagers && len(body.Alertmanagers) == 0 {
-		return response.Error(400, "At least one Alertmanager must be provided to choose this option", nil)
+	directTargets := body.PagerDuty != nil || len(body.Webhooks) > 0 || body.AWS != nil
+	if sendAlertsTo == ngmodels.ExternalAlertmanagers && len(body.Alertmanagers) == 0 && !directTargets {
+		return nil, response.Error(400, "At least one Alertmanager, PagerDuty, webhook, SNS topic or SQS queue must be provided to choose this option", nil)
+	}
+
+	if len(body.ExternalRuleUIDs) > 0 && len(body.Alertmanagers) == 0 && !directTargets {
+		return nil, response.Error(400, "At least one Alertmanager must be provided to send the alerts of rules to external Alertmanagers", nil)
 	}
 
 	cfg := &ngmodels.AdminConfiguration{
-		Alertmanagers: body.Alertmanagers,
-		SendAlertsTo:  sendAlertsTo,
-		OrgID:         c.OrgId,
+		Alertmanagers:    body.Alertmanagers,
+		SendAlertsTo:     sendAlertsTo,
+		Disabled:         body.Disabled,
+		ExternalRuleUIDs: body.ExternalRuleUIDs,
+		ProxyURL:         body.AlertmanagersProxyURL,
+		NoProxy:          body.AlertmanagersNoProxy,
+		ProxyURLs:        body.AlertmanagersProxyURLs,
+		APIVersions:      body.AlertmanagersAPIVersions,
+		Headers:          body.AlertmanagersHeaders,
+		Timeout:          body.AlertmanagersTimeout,
+		Timeouts:         body.AlertmanagersTimeouts,
+		Compressions:     body.AlertmanagersCompressions,
+		SilenceSync:      ngmodels.SilenceSyncMode(body.SilenceSync),
+		OrgID:            c.OrgId,
+	}
+	if len(body.AlertmanagersTLS) > 0 {
+		cfg.TLSConfigs = make(map[string]ngmodels.AlertmanagerTLSConfig, len(body.AlertmanagersTLS))
+		for u, tlsCfg := range body.AlertmanagersTLS {
+			cfg.TLSConfigs[u] = ngmodels.AlertmanagerTLSConfig(tlsCfg)
+		}
+	}
+	if len(body.AlertmanagersCredentials) > 0 {
+		cfg.Credentials = make(map[string]ngmodels.AlertmanagerCredentials, len(body.AlertmanagersCredentials))
+		for u, creds := range body.AlertmanagersCredentials {
+			encrypted, err := srv.encryptCredentials(c.Req.Context(), creds)
+			if err != nil {
+				msg := "failed to encrypt the credentials of the Alertmanagers"
+				srv.log.Error(msg, "err", err)
+				return nil, ErrResp(http.StatusInternalServerError, err, msg)
+			}
+			cfg.Credentials[u] = encrypted
+		}
+	}
+	for _, wh := range body.Webhooks {
+		cfg.Webhooks = append(cfg.Webhooks, ngmodels.AlertWebhookConfig(wh))
+	}
+	if body.Relabel != nil {
+		relabel := toAlertRelabelConfigs(*body.Relabel)
+		cfg.Relabel = &relabel
+	}
+	if len(body.TargetRelabels) > 0 {
+		cfg.TargetRelabels = make(map[string]ngmodels.AlertRelabelConfigs, len(body.TargetRelabels))
+		for u, rc := range body.TargetRelabels {
+			cfg.TargetRelabels[u] = toAlertRelabelConfigs(rc)
+		}
+	}
+	if body.ResolvedAlerts != nil {
+		resolved := ngmodels.ResolvedAlertsConfig(*body.ResolvedAlerts)
+		cfg.ResolvedAlerts = &resolved
+	}
+	if len(body.RuleResolvedAlerts) > 0 {
+		cfg.RuleResolvedAlerts = make(map[string]ngmodels.ResolvedAlertsConfig, len(body.RuleResolvedAlerts))
+		for uid, rc := range body.RuleResolvedAlerts {
+			cfg.RuleResolvedAlerts[uid] = ngmodels.ResolvedAlertsConfig(rc)
+		}
+	}
+	if body.MuteTimings != nil {
+		mt := ngmodels.MuteTimingsConfig(*body.MuteTimings)
+		cfg.MuteTimings = &mt
+	}
+	if len(body.RuleMuteTimings) > 0 {
+		cfg.RuleMuteTimings = make(map[string]ngmodels.MuteTimingsConfig, len(body.RuleMuteTimings))
+		for uid, mt := range body.RuleMuteTimings {
+			cfg.RuleMuteTimings[uid] = ngmodels.MuteTimingsConfig(mt)
+		}
+	}
+	if body.Grouping != nil {
+		g := ngmodels.AlertGroupingConfig(*body.Grouping)
+		cfg.Grouping = &g
+	}
+	if body.PagerDuty != nil {
+		cfg.PagerDuty = &ngmodels.PagerDutyConfig{URL: body.PagerDuty.URL, Severity: body.PagerDuty.Severity}
+		if body.PagerDuty.RoutingKey != "" {
+			settings := map[string]string{ngmodels.PagerDutyRoutingKeyKey: body.PagerDuty.RoutingKey}
+			secureSettings, err := srv.secretsService.EncryptJsonData(c.Req.Context(), settings, secrets.WithoutScope())
+			if err != nil {
+				msg := "failed to encrypt the routing key of PagerDuty"
+				srv.log.Error(msg, "err", err)
+				return nil, ErrResp(http.StatusInternalServerError, err, msg)
+			}
+			cfg.PagerDuty.SecureSettings = secureSettings
+		}
+	}
+	if body.AWS != nil {
+		cfg.AWS = &ngmodels.AWSConfig{
+			Region:        body.AWS.Region,
+			TopicARN:      body.AWS.TopicARN,
+			QueueURL:      body.AWS.QueueURL,
+			AssumeRoleARN: body.AWS.AssumeRoleARN,
+			ExternalID:    body.AWS.ExternalID,
+			Endpoint:      body.AWS.Endpoint,
+		}
+		settings := map[string]string{}
+		if body.AWS.AccessKey != "" {
+			settings[ngmodels.AWSAccessKeyKey] = body.AWS.AccessKey
+		}
+		if body.AWS.SecretKey != "" {
+			settings[ngmodels.AWSSecretKeyKey] = body.AWS.SecretKey
+		}
+		if len(settings) > 0 {
+			secureSettings, err := srv.secretsService.EncryptJsonData(c.Req.Context(), settings, secrets.WithoutScope())
+			if err != nil {
+				msg := "failed to encrypt the AWS credentials"
+				srv.log.Error(msg, "err", err)
+				return nil, ErrResp(http.StatusInternalServerError, err, msg)
+			}
+			cfg.AWS.SecureSettings = secureSettings
+		}
 	}
 
 	if err := cfg.Validate(); err != nil {
 		msg := "failed to validate admin configuration"
 		srv.log.Error(msg, "err", err)
-		return ErrResp(http.StatusBadRequest, err, msg)
+		return nil, ErrResp(http.StatusBadRequest, err, msg)
 	}
 
-	cmd := store.UpdateAdminConfigurationCmd{AdminConfiguration: cfg}
-	if err := srv.store.UpdateAdminConfiguration(cmd); err != nil {
-		msg := "failed to save the admin configuration to the database"
-		srv.log.Error(msg, "err", err)
-		return ErrResp(http.StatusBadRequest, err, msg)
+	return cfg, nil
+}
+
+// encryptCredentials encrypts the secrets of the credentials of an Alertmanager to store them.
+func (srv AdminSrv) encryptCredentials(ctx context.Context, creds apimodels.PostableAlertmanagerCredentials) (ngmodels.AlertmanagerCredentials, error) {
+	settings := map[string]string{}
+	if creds.BasicAuthPassword != "" {
+		settings[ngmodels.BasicAuthPasswordKey] = creds.BasicAuthPassword
+	}
+	if creds.BearerToken != "" {
+		settings[ngmodels.BearerTokenKey] = creds.BearerToken
+	}
+	for k, v := range creds.Headers {
+		settings[ngmodels.HeaderKeyPrefix+k] = v
 	}
 
-	return response.JSON(http.StatusCreated, util.DynMap{"message": "admin configuration updated"})
+	res := ngmodels.AlertmanagerCredentials{BasicAuthUser: creds.BasicAuthUser}
+	if len(settings) == 0 {
+		return res, nil
+	}
+	secureSettings, err := srv.secretsService.EncryptJsonData(ctx, settings, secrets.WithoutScope())
+	if err != nil {
+		return res, err
+	}
+	res.SecureSettings = secureSettings
+	return res, nil
+}
+
+func toAlertRelabelConfigs(rc apimodels.AlertRelabelConfigs) ngmodels.AlertRelabelConfigs {
+	var res ngmodels.AlertRelabelConfigs
+	for _, r := range rc.Labels {
+		res.Labels = append(res.Labels, ngmodels.RelabelConfig(r))
+	}
+	for _, r := range rc.Annotations {
+		res.Annotations = append(res.Annotations, ngmodels.RelabelConfig(r))
+	}
+	return res
+}
+
+func fromAlertRelabelConfigs(rc ngmodels.AlertRelabelConfigs) apimodels.AlertRelabelConfigs {
+	var res apimodels.AlertRelabelConfigs
+	for _, r := range rc.Labels {
+		res.Labels = append(res.Labels, apimodels.RelabelConfig(r))
+	}
+	for _, r := range rc.Annotations {
+		res.Annotations = append(res.Annotations, apimodels.RelabelConfig(r))
+	}
+	return res
 }
 
 func (srv AdminSrv) RouteDeleteNGalertConfig(c *models.ReqContext) response.Response {
-	if c.OrgRole != models.ROLE_ADMIN {
-		return accessForbiddenResp()
+	if errResp := srv.checkNotProvisioned(c); errResp != nil {
+		return errResp
 	}
 
-	err := srv.store.DeleteAdminConfiguration(c.OrgId)
+	err := srv.store.DeleteAdminConfiguration(store.DeleteAdminConfigurationCmd{OrgID: c.OrgId, UserID: c.UserId, Login: c.Login})
 	if err != nil {
 		srv.log.Error("unable to delete configuration", "err", err)
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
+	srv.scheduler.AdminConfigurationChanged()
 
 	return response.JSON(http.StatusOK, util.DynMap{"message": "admin configuration deleted"})
 }
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestRouteGetSenders(t *testing.T) {
+	scheduler := &schedule.FakeScheduleService{}
+	scheduler.On("SenderStatuses").Return([]schedule.SenderStatus{
+		{OrgID: 1, SendAlertsTo: ngmodels.AllAlertmanagers},
+		{OrgID: 2, SendAlertsTo: ngmodels.ExternalAlertmanagers},
+	})
+	srv := AdminSrv{scheduler: scheduler}
+
+	orgsOf := func(isGrafanaAdmin bool) []int64 {
+		rc := createTestRequestCtx()
+		rc.SignedInUser.IsGrafanaAdmin = isGrafanaAdmin
+		resp := srv.RouteGetSenders(&rc)
+		require.Equal(t, http.StatusOK, resp.Status())
+		var senders apimodels.GettableSenders
+		require.NoError(t, json.Unmarshal(resp.Body(), &senders))
+		orgs := make([]int64, 0, len(senders.Senders))
+		for _, s := range senders.Senders {
+			orgs = append(orgs, s.OrgID)
+		}
+		return orgs
+	}
+
+	t.Run("Grafana admins get the senders of all the organizations", func(t *testing.T) {
+		require.Equal(t, []int64{1, 2}, orgsOf(true))
+	})
+
+	t.Run("other users get the sender of their organization only", func(t *testing.T) {
+		require.Equal(t, []int64{1}, orgsOf(false))
+	})
+}
+
+// fakeStateHistoryStore records the last query for the state history and returns no transitions.
+type fakeStateHistoryStore struct {
+	query *ngmodels.GetAlertStateHistoryQuery
+}
+
+func (f *fakeStateHistoryStore) SaveAlertStateTransitions(context.Context, []*ngmodels.AlertStateTransition) error {
+	return nil
+}
+
+func (f *fakeStateHistoryStore) GetAlertStateHistory(_ context.Context, query *ngmodels.GetAlertStateHistoryQuery) error {
+	f.query = query
+	return nil
+}
+
+func TestRouteGetAlertStateHistory(t *testing.T) {
+	ruleStore := store.NewFakeRuleStore(t)
+	visible := ngmodels.AlertRuleGen(withOrgID(1))()
+	hidden := ngmodels.AlertRuleGen(withOrgID(1))()
+	ruleStore.PutRule(context.Background(), visible, hidden)
+	// the user cannot read the folder of the hidden rule
+	for i, f := range ruleStore.Folders[1] {
+		if f.Uid == hidden.NamespaceUID {
+			ruleStore.Folders[1] = append(ruleStore.Folders[1][:i], ruleStore.Folders[1][i+1:]...)
+			break
+		}
+	}
+	historyStore := &fakeStateHistoryStore{}
+	srv := AdminSrv{ruleStore: ruleStore, historyStore: historyStore, log: log.NewNopLogger()}
+
+	get := func(target string) response.Response {
+		historyStore.query = nil
+		rc := createTestRequestCtx()
+		rc.Req = httptest.NewRequest(http.MethodGet, target, nil)
+		return srv.RouteGetAlertStateHistory(&rc)
+	}
+
+	t.Run("transitions are restricted to the folders the user can read", func(t *testing.T) {
+		resp := get("/api/v1/ngalert/state_history")
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Equal(t, []string{visible.NamespaceUID}, historyStore.query.NamespaceUIDs)
+		require.Equal(t, defaultAlertStateHistoryLimit, historyStore.query.Limit)
+	})
+
+	t.Run("the limit is capped", func(t *testing.T) {
+		resp := get("/api/v1/ngalert/state_history?limit=100000")
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Equal(t, maxAlertStateHistoryLimit, historyStore.query.Limit)
+	})
+
+	t.Run("the transitions of a rule in a folder the user can read are returned", func(t *testing.T) {
+		resp := get("/api/v1/ngalert/state_history?ruleUID=" + visible.UID)
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Equal(t, visible.UID, historyStore.query.RuleUID)
+	})
+
+	t.Run("the transitions of a rule in a folder the user cannot read are rejected", func(t *testing.T) {
+		resp := get("/api/v1/ngalert/state_history?ruleUID=" + hidden.UID)
+		require.Equal(t, http.StatusForbidden, resp.Status())
+		require.Nil(t, historyStore.query)
+	})
+
+	t.Run("no transitions are returned if the user cannot read any folder", func(t *testing.T) {
+		srv := AdminSrv{ruleStore: store.NewFakeRuleStore(t), historyStore: historyStore, log: log.NewNopLogger()}
+		rc := createTestRequestCtx()
+		rc.Req = httptest.NewRequest(http.MethodGet, "/api/v1/ngalert/state_history", nil)
+		resp := srv.RouteGetAlertStateHistory(&rc)
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.JSONEq(t, `{"transitions":[]}`, string(resp.Body()))
+		require.Nil(t, historyStore.query)
+	})
+}
+
+func TestRouteGetDeliveryReceipts(t *testing.T) {
+	receiptStore := store.NewFakeDeliveryReceiptStore(t)
+	for i := 0; i < 2*maxDeliveryReceiptsLimit; i++ {
+		receiptStore.Receipts = append(receiptStore.Receipts, &ngmodels.DeliveryReceipt{OrgID: 1})
+	}
+	srv := AdminSrv{receiptStore: receiptStore}
+
+	countOf := func(target string) int {
+		rc := createTestRequestCtx()
+		rc.Req = httptest.NewRequest(http.MethodGet, target, nil)
+		resp := srv.RouteGetDeliveryReceipts(&rc)
+		require.Equal(t, http.StatusOK, resp.Status())
+		var receipts apimodels.GettableDeliveryReceipts
+		require.NoError(t, json.Unmarshal(resp.Body(), &receipts))
+		return len(receipts.Receipts)
+	}
+
+	t.Run("the default limit applies if the request does not set one", func(t *testing.T) {
+		require.Equal(t, defaultDeliveryReceiptsLimit, countOf("/api/v1/ngalert/delivery_receipts"))
+	})
+
+	t.Run("the limit of the request is capped", func(t *testing.T) {
+		require.Equal(t, 10, countOf("/api/v1/ngalert/delivery_receipts?limit=10"))
+		require.Equal(t, maxDeliveryReceiptsLimit, countOf("/api/v1/ngalert/delivery_receipts?limit=100000"))
+	})
+}
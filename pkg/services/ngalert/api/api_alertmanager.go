@@ -290,6 +290,35 @@ func (srv AlertmanagerSrv) RoutePostTestReceivers(c *models.ReqContext, body api
 	return response.JSON(statusForTestReceivers(result.Receivers), newTestReceiversResult(result))
 }
 
+func (srv AlertmanagerSrv) RoutePostTestTemplates(c *models.ReqContext, body apimodels.TestTemplatesConfigBodyParams) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	results, err := am.TestTemplates(c.Req.Context(), body)
+	if err != nil {
+		var invalidTemplateErr notifier.InvalidTemplateError
+		if errors.As(err, &invalidTemplateErr) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to render the templates")
+	}
+	return response.JSON(http.StatusOK, newTestTemplatesResults(results))
+}
+
+func newTestTemplatesResults(results []notifier.TestTemplatesResult) apimodels.TestTemplatesResults {
+	v := apimodels.TestTemplatesResults{Results: make([]apimodels.TestTemplatesResult, 0, len(results))}
+	for _, r := range results {
+		next := apimodels.TestTemplatesResult{Type: r.Type, Title: r.Title, Message: r.Message}
+		if r.Error != nil {
+			next.Error = r.Error.Error()
+		}
+		v.Results = append(v.Results, next)
+	}
+	return v
+}
+
 // contextWithTimeoutFromRequest returns a context with a deadline set from the
 // Request-Timeout header in the HTTP request. If the header is absent then the
 // context will use the default timeout. The timeout in the Request-Timeout
@@ -208,6 +208,18 @@ func (srv PrometheusSrv) toRuleGroup(groupName string, folder *models.Folder, ru
 			Type:           apiv1.RuleTypeAlerting,
 			LastEvaluation: time.Time{},
 		}
+		if rule.IsRecording() {
+			// recording rules have no alerts, only the fields of the rule are exposed
+			newRule.Type = apiv1.RuleTypeRecording
+			newRule.Query = alertingRule.Query
+			newGroup.Rules = append(newGroup.Rules, apimodels.AlertingRule{
+				Name:  alertingRule.Name,
+				Query: alertingRule.Query,
+				Rule:  newRule,
+			})
+			newGroup.Interval = float64(rule.IntervalSeconds)
+			continue
+		}
 
 		for _, alertState := range srv.manager.GetStatesForRuleUID(rule.OrgID, rule.UID) {
 			activeAt := alertState.StartsAt
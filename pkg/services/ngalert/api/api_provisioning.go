@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -13,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/cmputil"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -21,6 +23,7 @@ const (
 	uidPathParam       = ":UID"
 	groupPathParam     = ":Group"
 	folderUIDPathParam = ":FolderUID"
+	versionPathParam   = ":Version"
 )
 
 type ProvisioningSrv struct {
@@ -30,6 +33,7 @@ type ProvisioningSrv struct {
 	templates           TemplateService
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
+	maintenanceWindows  MaintenanceWindowService
 }
 
 type ContactPointService interface {
@@ -57,12 +61,27 @@ type MuteTimingService interface {
 	DeleteMuteTiming(ctx context.Context, name string, orgID int64) error
 }
 
+type MaintenanceWindowService interface {
+	GetMaintenanceWindows(ctx context.Context, orgID int64) ([]*alerting_models.MaintenanceWindow, error)
+	GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*alerting_models.MaintenanceWindow, error)
+	CreateMaintenanceWindow(ctx context.Context, w alerting_models.MaintenanceWindow) (*alerting_models.MaintenanceWindow, error)
+	UpdateMaintenanceWindow(ctx context.Context, w alerting_models.MaintenanceWindow) (*alerting_models.MaintenanceWindow, error)
+	DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string) error
+	GetSuppressedAlerts(ctx context.Context, orgID int64, uid string) ([]*alerting_models.MaintenanceSuppression, error)
+}
+
 type AlertRuleService interface {
 	GetAlertRule(ctx context.Context, orgID int64, ruleUID string) (alerting_models.AlertRule, alerting_models.Provenance, error)
 	CreateAlertRule(ctx context.Context, rule alerting_models.AlertRule, provenance alerting_models.Provenance) (alerting_models.AlertRule, error)
 	UpdateAlertRule(ctx context.Context, rule alerting_models.AlertRule, provenance alerting_models.Provenance) (alerting_models.AlertRule, error)
 	DeleteAlertRule(ctx context.Context, orgID int64, ruleUID string, provenance alerting_models.Provenance) error
 	UpdateAlertGroup(ctx context.Context, orgID int64, folderUID, rulegroup string, interval int64) error
+	SetAlertRulePaused(ctx context.Context, orgID int64, ruleUID string, paused bool, provenance alerting_models.Provenance) error
+	SetAlertGroupPaused(ctx context.Context, orgID int64, folderUID, rulegroup string, paused bool, provenance alerting_models.Provenance) error
+	ApplyAlertRulesBatch(ctx context.Context, orgID int64, batch provisioning.AlertRulesBatch, provenance alerting_models.Provenance) ([]provisioning.AlertRuleBatchResult, error)
+	GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) ([]*alerting_models.AlertRuleVersion, error)
+	DiffAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string, from, to int64) (cmputil.DiffReport, error)
+	RestoreAlertRuleVersion(ctx context.Context, orgID int64, ruleUID string, version int64, provenance alerting_models.Provenance) (alerting_models.AlertRule, error)
 }
 
 func (srv *ProvisioningSrv) RouteGetPolicyTree(c *models.ReqContext) response.Response {
@@ -273,6 +292,131 @@ func (srv *ProvisioningSrv) RouteDeleteAlertRule(c *models.ReqContext) response.
 	return response.JSON(http.StatusNoContent, "")
 }
 
+func (srv *ProvisioningSrv) RoutePostAlertRulePause(c *models.ReqContext) response.Response {
+	return srv.setAlertRulePaused(c, true)
+}
+
+func (srv *ProvisioningSrv) RoutePostAlertRuleResume(c *models.ReqContext) response.Response {
+	return srv.setAlertRulePaused(c, false)
+}
+
+func (srv *ProvisioningSrv) setAlertRulePaused(c *models.ReqContext, paused bool) response.Response {
+	uid := pathParam(c, uidPathParam)
+	err := srv.alertRules.SetAlertRulePaused(c.Req.Context(), c.OrgId, uid, paused, alerting_models.ProvenanceAPI)
+	if errors.Is(err, alerting_models.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, "")
+}
+
+func (srv *ProvisioningSrv) RouteGetAlertRuleVersions(c *models.ReqContext) response.Response {
+	uid := pathParam(c, uidPathParam)
+	versions, err := srv.alertRules.GetAlertRuleVersions(c.Req.Context(), c.OrgId, uid)
+	if errors.Is(err, alerting_models.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	result := make(apimodels.AlertRuleVersions, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, apimodels.NewAlertRuleVersion(v))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RouteGetAlertRuleVersionsDiff(c *models.ReqContext) response.Response {
+	uid := pathParam(c, uidPathParam)
+	from, to := c.QueryInt64("from"), c.QueryInt64("to")
+	if from <= 0 || to <= 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("the versions to compare are required"), "")
+	}
+	diff, err := srv.alertRules.DiffAlertRuleVersions(c.Req.Context(), c.OrgId, uid, from, to)
+	if errors.Is(err, alerting_models.ErrAlertRuleNotFound) || errors.Is(err, alerting_models.ErrAlertRuleVersionNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, apimodels.NewAlertRuleVersionDiff(from, to, diff))
+}
+
+func (srv *ProvisioningSrv) RoutePostAlertRuleVersionRestore(c *models.ReqContext) response.Response {
+	uid := pathParam(c, uidPathParam)
+	version, err := strconv.ParseInt(pathParam(c, versionPathParam), 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid version")
+	}
+	rule, err := srv.alertRules.RestoreAlertRuleVersion(c.Req.Context(), c.OrgId, uid, version, alerting_models.ProvenanceAPI)
+	if errors.Is(err, alerting_models.ErrAlertRuleNotFound) || errors.Is(err, alerting_models.ErrAlertRuleVersionNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, apimodels.NewAlertRule(rule, alerting_models.ProvenanceAPI))
+}
+
+func (srv *ProvisioningSrv) RoutePostAlertRuleGroupPause(c *models.ReqContext) response.Response {
+	return srv.setAlertRuleGroupPaused(c, true)
+}
+
+func (srv *ProvisioningSrv) RoutePostAlertRuleGroupResume(c *models.ReqContext) response.Response {
+	return srv.setAlertRuleGroupPaused(c, false)
+}
+
+func (srv *ProvisioningSrv) setAlertRuleGroupPaused(c *models.ReqContext, paused bool) response.Response {
+	rulegroup := pathParam(c, groupPathParam)
+	folderUID := pathParam(c, folderUIDPathParam)
+	err := srv.alertRules.SetAlertGroupPaused(c.Req.Context(), c.OrgId, folderUID, rulegroup, paused, alerting_models.ProvenanceAPI)
+	if errors.Is(err, store.ErrAlertRuleGroupNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, "")
+}
+
+func (srv *ProvisioningSrv) RoutePostAlertRulesBatch(c *models.ReqContext, batch apimodels.AlertRulesBatch) response.Response {
+	b := provisioning.AlertRulesBatch{
+		Create: make([]alerting_models.AlertRule, 0, len(batch.Create)),
+		Update: make([]alerting_models.AlertRule, 0, len(batch.Update)),
+		Delete: batch.Delete,
+		Pause:  batch.Pause,
+		Resume: batch.Resume,
+	}
+	for _, ar := range batch.Create {
+		b.Create = append(b.Create, ar.UpstreamModel())
+	}
+	for _, ar := range batch.Update {
+		b.Update = append(b.Update, ar.UpstreamModel())
+	}
+	results, err := srv.alertRules.ApplyAlertRulesBatch(c.Req.Context(), c.OrgId, b, alerting_models.ProvenanceAPI)
+	if err != nil && !errors.Is(err, provisioning.ErrAlertRulesBatchRejected) {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	resp := apimodels.AlertRulesBatchResults{
+		Applied: err == nil,
+		Results: make([]apimodels.AlertRulesBatchResult, 0, len(results)),
+	}
+	for _, r := range results {
+		res := apimodels.AlertRulesBatchResult{Operation: string(r.Operation), UID: r.UID, Title: r.Title}
+		if r.Error != nil {
+			res.Error = r.Error.Error()
+		}
+		resp.Results = append(resp.Results, res)
+	}
+	if !resp.Applied {
+		return response.JSON(http.StatusBadRequest, resp)
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
 func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *models.ReqContext, ag apimodels.AlertRuleGroup) response.Response {
 	rulegroup := pathParam(c, groupPathParam)
 	folderUID := pathParam(c, folderUIDPathParam)
@@ -283,6 +427,84 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *models.ReqContext, ag apim
 	return response.JSON(http.StatusOK, ag)
 }
 
+func (srv *ProvisioningSrv) RouteGetMaintenanceWindows(c *models.ReqContext) response.Response {
+	windows, err := srv.maintenanceWindows.GetMaintenanceWindows(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	result := make(apimodels.MaintenanceWindows, 0, len(windows))
+	for _, w := range windows {
+		result = append(result, apimodels.NewMaintenanceWindow(w))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RouteGetMaintenanceWindow(c *models.ReqContext) response.Response {
+	uid := pathParam(c, uidPathParam)
+	w, err := srv.maintenanceWindows.GetMaintenanceWindow(c.Req.Context(), c.OrgId, uid)
+	if errors.Is(err, alerting_models.ErrMaintenanceWindowNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, apimodels.NewMaintenanceWindow(w))
+}
+
+func (srv *ProvisioningSrv) RoutePostMaintenanceWindow(c *models.ReqContext, mw apimodels.MaintenanceWindow) response.Response {
+	created, err := srv.maintenanceWindows.CreateMaintenanceWindow(c.Req.Context(), mw.UpstreamModel(c.OrgId))
+	if errors.Is(err, provisioning.ErrValidation) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusCreated, apimodels.NewMaintenanceWindow(created))
+}
+
+func (srv *ProvisioningSrv) RoutePutMaintenanceWindow(c *models.ReqContext, mw apimodels.MaintenanceWindow) response.Response {
+	mw.UID = pathParam(c, uidPathParam)
+	updated, err := srv.maintenanceWindows.UpdateMaintenanceWindow(c.Req.Context(), mw.UpstreamModel(c.OrgId))
+	if errors.Is(err, provisioning.ErrValidation) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if errors.Is(err, alerting_models.ErrMaintenanceWindowNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, apimodels.NewMaintenanceWindow(updated))
+}
+
+func (srv *ProvisioningSrv) RouteDeleteMaintenanceWindow(c *models.ReqContext) response.Response {
+	uid := pathParam(c, uidPathParam)
+	err := srv.maintenanceWindows.DeleteMaintenanceWindow(c.Req.Context(), c.OrgId, uid)
+	if errors.Is(err, alerting_models.ErrMaintenanceWindowNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusNoContent, "")
+}
+
+func (srv *ProvisioningSrv) RouteGetMaintenanceWindowSuppressedAlerts(c *models.ReqContext) response.Response {
+	uid := pathParam(c, uidPathParam)
+	suppressions, err := srv.maintenanceWindows.GetSuppressedAlerts(c.Req.Context(), c.OrgId, uid)
+	if errors.Is(err, alerting_models.ErrMaintenanceWindowNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	result := make(apimodels.MaintenanceSuppressedAlerts, 0, len(suppressions))
+	for _, s := range suppressions {
+		result = append(result, apimodels.NewMaintenanceSuppressedAlert(s))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
 func pathParam(c *models.ReqContext, param string) string {
 	return web.Params(c.Req)[param]
 }
@@ -1,14 +1,18 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -16,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/util/cmputil"
 
 	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/grafana/pkg/api/apierrors"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -38,6 +43,7 @@ type RulerSrv struct {
 	log             log.Logger
 	cfg             *setting.UnifiedAlertingSettings
 	ac              accesscontrol.AccessControl
+	amConfigStore   AlertingStore
 }
 
 var (
@@ -329,6 +335,9 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 	if err != nil {
 		return ErrResp(http.StatusBadRequest, err, "")
 	}
+	if err := srv.validateContactPoints(c.Req.Context(), c.SignedInUser.OrgId, rules); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
 
 	groupKey := ngmodels.AlertRuleGroupKey{
 		OrgID:        c.SignedInUser.OrgId,
@@ -339,6 +348,196 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 	return srv.updateAlertRulesInGroup(c, groupKey, rules)
 }
 
+// RouteGetNamespaceRulesExport exports the rule groups of the namespace as a Prometheus rule file. The rules that
+// cannot be translated are listed in comments at the top of the file.
+func (srv RulerSrv) RouteGetNamespaceRulesExport(c *models.ReqContext) response.Response {
+	namespaceTitle := web.Params(c.Req)[":Namespace"]
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, false)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	q := ngmodels.ListAlertRulesQuery{
+		OrgID:         c.SignedInUser.OrgId,
+		NamespaceUIDs: []string{namespace.Uid},
+	}
+	if err := srv.store.ListAlertRules(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get rules")
+	}
+
+	hasAccess := func(evaluator accesscontrol.Evaluator) bool {
+		return accesscontrol.HasAccess(srv.ac, c)(accesscontrol.ReqViewer, evaluator)
+	}
+
+	groups := make(map[string][]*ngmodels.AlertRule)
+	for _, r := range q.Result {
+		if !authorizeDatasourceAccessForRule(r, hasAccess) {
+			continue
+		}
+		groups[r.RuleGroup] = append(groups[r.RuleGroup], r)
+	}
+	groupNames := make([]string, 0, len(groups))
+	for name := range groups {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+
+	file := apimodels.PrometheusRuleFile{Groups: make([]apimodels.PrometheusRuleGroup, 0, len(groups))}
+	var notExported bytes.Buffer
+	for _, name := range groupNames {
+		rules := groups[name]
+		group := apimodels.PrometheusRuleGroup{
+			Name:     name,
+			Interval: model.Duration(time.Duration(rules[0].IntervalSeconds) * time.Second),
+		}
+		for _, r := range rules {
+			rule, err := toPrometheusRule(r)
+			if err != nil {
+				fmt.Fprintf(&notExported, "# rule %q (%s) of the group %q is not exported: %s\n", r.Title, r.UID, name, err)
+				continue
+			}
+			group.Rules = append(group.Rules, rule)
+		}
+		if len(group.Rules) > 0 {
+			file.Groups = append(file.Groups, group)
+		}
+	}
+
+	body, err := yaml.Marshal(file)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to marshal the rules")
+	}
+	return response.Respond(http.StatusOK, append(notExported.Bytes(), body...)).SetHeader("Content-Type", "application/yaml")
+}
+
+// RoutePostNamespaceRulesImport imports the rule groups of a Prometheus rule file in the namespace. The rules query
+// the data source of the datasourceUid parameter, the recording rules write to the one of the targetDatasourceUid
+// parameter, or to the queried data source. The groups with the name of an imported group are replaced, the rules
+// keep their UID when their title does not change.
+func (srv RulerSrv) RoutePostNamespaceRulesImport(c *models.ReqContext) response.Response {
+	namespaceTitle := web.Params(c.Req)[":Namespace"]
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	datasourceUID := c.Query("datasourceUid")
+	if datasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("the data source queried by the rules is missing"), "")
+	}
+	ds, err := srv.DatasourceCache.GetDatasourceByUID(c.Req.Context(), datasourceUID, c.SignedInUser, c.SkipCache)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceAccessDenied) {
+			return ErrResp(http.StatusForbidden, err, "Access denied to datasource")
+		}
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "Unable to find datasource")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "Unable to load datasource meta data")
+	}
+	if ds.Type != models.DS_PROMETHEUS && ds.Type != models.DS_LOKI {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("the data source %s is not a Prometheus or Loki data source", datasourceUID), "")
+	}
+	targetDatasourceUID := c.Query("targetDatasourceUid")
+	if targetDatasourceUID == "" {
+		targetDatasourceUID = datasourceUID
+	}
+
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read the rule file")
+	}
+	var file apimodels.PrometheusRuleFile
+	if err := yaml.Unmarshal(body, &file); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse the rule file")
+	}
+
+	type importedGroup struct {
+		key   ngmodels.AlertRuleGroupKey
+		rules []*ngmodels.AlertRule
+	}
+	imported := make([]importedGroup, 0, len(file.Groups))
+	titles := make(map[string]struct{})
+	for _, group := range file.Groups {
+		q := ngmodels.ListAlertRulesQuery{
+			OrgID:         c.SignedInUser.OrgId,
+			NamespaceUIDs: []string{namespace.Uid},
+			RuleGroup:     group.Name,
+		}
+		if err := srv.store.ListAlertRules(c.Req.Context(), &q); err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to get rules")
+		}
+		existingUIDs := make(map[string]string, len(q.Result))
+		for _, r := range q.Result {
+			existingUIDs[r.Title] = r.UID
+		}
+
+		ruleGroupConfig := apimodels.PostableRuleGroupConfig{Name: group.Name, Interval: group.Interval}
+		for _, rule := range group.Rules {
+			node, err := fromPrometheusRule(rule, ds, targetDatasourceUID)
+			if err != nil {
+				return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid rule %q of the group %q: %w", rule.Alert+rule.Record, group.Name, err), "")
+			}
+			title := node.GrafanaManagedAlert.Title
+			if _, ok := titles[title]; ok {
+				return ErrResp(http.StatusBadRequest, fmt.Errorf("more than one rule is named %q, the rules of a folder must have different names", title), "")
+			}
+			titles[title] = struct{}{}
+			node.GrafanaManagedAlert.UID = existingUIDs[title]
+			ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, node)
+		}
+
+		rules, err := validateRuleGroup(&ruleGroupConfig, c.SignedInUser.OrgId, namespace, conditionValidator(c, srv.DatasourceCache), srv.cfg)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid group %q: %w", group.Name, err), "")
+		}
+		imported = append(imported, importedGroup{
+			key: ngmodels.AlertRuleGroupKey{
+				OrgID:        c.SignedInUser.OrgId,
+				NamespaceUID: namespace.Uid,
+				RuleGroup:    group.Name,
+			},
+			rules: rules,
+		})
+	}
+
+	for _, group := range imported {
+		if resp := srv.updateAlertRulesInGroup(c, group.key, group.rules); resp.Status() != http.StatusAccepted {
+			return resp
+		}
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule groups imported successfully"})
+}
+
+// validateContactPoints returns an error if the notification settings of a rule refer to a contact point that does
+// not exist in the Alertmanager configuration of the organization.
+func (srv RulerSrv) validateContactPoints(ctx context.Context, orgID int64, rules []*ngmodels.AlertRule) error {
+	var receivers map[string]struct{}
+	for _, r := range rules {
+		if r.NotificationSettings == nil {
+			continue
+		}
+		if receivers == nil {
+			query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: orgID}
+			if err := srv.amConfigStore.GetLatestAlertmanagerConfiguration(ctx, &query); err != nil {
+				return fmt.Errorf("failed to get the Alertmanager configuration: %w", err)
+			}
+			cfg, err := notifier.Load([]byte(query.Result.AlertmanagerConfiguration))
+			if err != nil {
+				return err
+			}
+			receivers = make(map[string]struct{}, len(cfg.AlertmanagerConfig.Receivers))
+			for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+				receivers[receiver.Name] = struct{}{}
+			}
+		}
+		if _, ok := receivers[r.NotificationSettings.Receiver]; !ok {
+			return fmt.Errorf("%w: contact point %s of the alert rule %s does not exist", ngmodels.ErrAlertRuleFailedValidation, r.NotificationSettings.Receiver, r.Title)
+		}
+	}
+	return nil
+}
+
 // updateAlertRulesInGroup calculates changes (rules to add,update,delete), verifies that the user is authorized to do the calculated changes and updates database.
 // All operations are performed in a single transaction
 // nolint: gocyclo
@@ -515,21 +714,26 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provena
 	}
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
-			ID:              r.ID,
-			OrgID:           r.OrgID,
-			Title:           r.Title,
-			Condition:       r.Condition,
-			Data:            r.Data,
-			Updated:         r.Updated,
-			IntervalSeconds: r.IntervalSeconds,
-			Version:         r.Version,
-			UID:             r.UID,
-			NamespaceUID:    r.NamespaceUID,
-			NamespaceID:     namespaceID,
-			RuleGroup:       r.RuleGroup,
-			NoDataState:     apimodels.NoDataState(r.NoDataState),
-			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
-			Provenance:      provenance,
+			ID:                   r.ID,
+			OrgID:                r.OrgID,
+			Title:                r.Title,
+			Condition:            r.Condition,
+			Data:                 r.Data,
+			Updated:              r.Updated,
+			IntervalSeconds:      r.IntervalSeconds,
+			Version:              r.Version,
+			UID:                  r.UID,
+			NamespaceUID:         r.NamespaceUID,
+			NamespaceID:          namespaceID,
+			RuleGroup:            r.RuleGroup,
+			NoDataState:          apimodels.NoDataState(r.NoDataState),
+			ExecErrState:         apimodels.ExecutionErrorState(r.ExecErrState),
+			Provenance:           provenance,
+			Record:               r.Record,
+			NotificationSettings: r.NotificationSettings,
+			AlignEvaluation:      r.AlignEvaluation,
+			EvaluationTimeout:    model.Duration(r.EvaluationTimeout),
+			Dependencies:         r.Dependencies,
 		},
 	}
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
@@ -625,6 +829,8 @@ func calculateChanges(ctx context.Context, ruleStore store.RuleStore, groupKey n
 		}
 
 		ngmodels.PatchPartialAlertRule(existing, r)
+		// the rules cannot be paused with the ruler API, they stay paused or not
+		r.IsPaused = existing.IsPaused
 
 		diff := existing.Diff(r, alertRuleFieldsToIgnoreInDiff...)
 		if len(diff) == 0 {
@@ -20,9 +20,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -749,3 +751,35 @@ func withUIDs(uids map[string]*models.AlertRule) func(rule *models.AlertRule) {
 		unused = unused[1:]
 	}
 }
+
+func TestValidateContactPoints(t *testing.T) {
+	orgID := rand.Int63()
+	configStore := notifier.NewFakeConfigStore(t, map[int64]*models.AlertConfiguration{
+		orgID: {AlertmanagerConfiguration: setting.GetAlertmanagerDefaultConfiguration(), OrgID: orgID},
+	})
+	srv := createService(acMock.New(), store.NewFakeRuleStore(t), nil)
+	srv.amConfigStore = &configStore
+
+	withReceiver := func(receiver string) func(*models.AlertRule) {
+		return func(rule *models.AlertRule) {
+			rule.NotificationSettings = &models.NotificationSettings{Receiver: receiver}
+		}
+	}
+
+	t.Run("accepts rules without notification settings", func(t *testing.T) {
+		rules := models.GenerateAlertRules(3, models.AlertRuleGen())
+		require.NoError(t, srv.validateContactPoints(context.Background(), rand.Int63(), rules))
+	})
+
+	t.Run("accepts existing contact point", func(t *testing.T) {
+		rules := models.GenerateAlertRules(3, models.AlertRuleGen(withReceiver("grafana-default-email")))
+		require.NoError(t, srv.validateContactPoints(context.Background(), orgID, rules))
+	})
+
+	t.Run("fails if contact point does not exist", func(t *testing.T) {
+		rules := models.GenerateAlertRules(3, models.AlertRuleGen(withReceiver("grafana-default-email")))
+		rules = append(rules, models.AlertRuleGen(withReceiver("unknown"))())
+		err := srv.validateContactPoints(context.Background(), orgID, rules)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
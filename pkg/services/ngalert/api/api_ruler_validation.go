@@ -11,6 +11,8 @@ import (
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
+
+	prometheusModel "github.com/prometheus/common/model"
 )
 
 // validateRuleNode validates API model (definitions.PostableExtendedRuleNode) and converts it to models.AlertRule
@@ -86,9 +88,44 @@ func validateRuleNode(
 		}
 	}
 
+	condition := ruleNode.GrafanaManagedAlert.Condition
+	record := ruleNode.GrafanaManagedAlert.Record
+	if record != nil {
+		if err := validateRecord(record); err != nil {
+			return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err)
+		}
+		// the condition of a recording rule is the query or expression it records
+		if condition == "" {
+			condition = record.From
+		}
+		if condition != record.From {
+			return nil, fmt.Errorf("%w: the condition of a recording rule must be the query or expression it records", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+
+	notificationSettings := ruleNode.GrafanaManagedAlert.NotificationSettings
+	if notificationSettings != nil {
+		if record != nil {
+			return nil, fmt.Errorf("%w: a recording rule cannot have notification settings", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if notificationSettings.Receiver == "" {
+			return nil, fmt.Errorf("%w: the notification settings must have a contact point", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+
+	if ruleNode.GrafanaManagedAlert.EvaluationTimeout < 0 {
+		return nil, fmt.Errorf("%w: the evaluation timeout must be 0 or greater", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
+	for _, dep := range ruleNode.GrafanaManagedAlert.Dependencies {
+		if err := validateDependency(dep, ruleNode.GrafanaManagedAlert.UID); err != nil {
+			return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err)
+		}
+	}
+
 	if len(ruleNode.GrafanaManagedAlert.Data) != 0 {
 		cond := ngmodels.Condition{
-			Condition: ruleNode.GrafanaManagedAlert.Condition,
+			Condition: condition,
 			OrgID:     orgId,
 			Data:      ruleNode.GrafanaManagedAlert.Data,
 		}
@@ -98,16 +135,21 @@ func validateRuleNode(
 	}
 
 	newAlertRule := ngmodels.AlertRule{
-		OrgID:           orgId,
-		Title:           ruleNode.GrafanaManagedAlert.Title,
-		Condition:       ruleNode.GrafanaManagedAlert.Condition,
-		Data:            ruleNode.GrafanaManagedAlert.Data,
-		UID:             ruleNode.GrafanaManagedAlert.UID,
-		IntervalSeconds: intervalSeconds,
-		NamespaceUID:    namespace.Uid,
-		RuleGroup:       groupName,
-		NoDataState:     noDataState,
-		ExecErrState:    errorState,
+		OrgID:                orgId,
+		Title:                ruleNode.GrafanaManagedAlert.Title,
+		Condition:            condition,
+		Data:                 ruleNode.GrafanaManagedAlert.Data,
+		UID:                  ruleNode.GrafanaManagedAlert.UID,
+		IntervalSeconds:      intervalSeconds,
+		NamespaceUID:         namespace.Uid,
+		RuleGroup:            groupName,
+		NoDataState:          noDataState,
+		ExecErrState:         errorState,
+		Record:               record,
+		NotificationSettings: notificationSettings,
+		AlignEvaluation:      ruleNode.GrafanaManagedAlert.AlignEvaluation,
+		EvaluationTimeout:    time.Duration(ruleNode.GrafanaManagedAlert.EvaluationTimeout),
+		Dependencies:         ruleNode.GrafanaManagedAlert.Dependencies,
 	}
 
 	if ruleNode.ApiRuleNode != nil {
@@ -135,6 +177,36 @@ func validateRuleNode(
 	return &newAlertRule, nil
 }
 
+// validateRecord validates the definition of a recording rule.
+func validateRecord(record *ngmodels.Record) error {
+	if !prometheusModel.IsValidMetricName(prometheusModel.LabelValue(record.Metric)) {
+		return fmt.Errorf("invalid metric name %q of the recording rule", record.Metric)
+	}
+	if record.From == "" {
+		return errors.New("the recording rule must record a query or expression")
+	}
+	if record.TargetDatasourceUID == "" {
+		return errors.New("the recording rule must have a target data source")
+	}
+	return nil
+}
+
+// validateDependency validates a rule the rule of the given UID depends on.
+func validateDependency(dep ngmodels.RuleDependency, uid string) error {
+	if dep.RuleUID == "" {
+		return errors.New("a dependency must have the UID of the rule depended on")
+	}
+	if dep.RuleUID == uid {
+		return errors.New("a rule cannot depend on itself")
+	}
+	for _, name := range dep.Equal {
+		if !prometheusModel.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid label %q of the dependency on rule %s", name, dep.RuleUID)
+		}
+	}
+	return nil
+}
+
 // validateRuleGroup validates API model (definitions.PostableRuleGroupConfig) and converts it to a collection of models.AlertRule.
 // Returns a slice that contains all rules described by API model or error if either group specification or an alert definition is not valid.
 func validateRuleGroup(
@@ -297,6 +297,68 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				require.Equal(t, int64(panelId), *alert.PanelID)
 			},
 		},
+		{
+			name: "defaults the condition of a recording rule to the recorded query",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Condition = ""
+				r.GrafanaManagedAlert.Record = &models.Record{
+					Metric:              "test_metric",
+					From:                "A",
+					TargetDatasourceUID: "DATASOURCE_TARGET",
+				}
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, "A", alert.Condition)
+				require.Equal(t, api.GrafanaManagedAlert.Record, alert.Record)
+				require.True(t, alert.IsRecording())
+			},
+		},
+		{
+			name: "coverts notification settings",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.NotificationSettings = &models.NotificationSettings{Receiver: "test-receiver"}
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, api.GrafanaManagedAlert.NotificationSettings, alert.NotificationSettings)
+			},
+		},
+		{
+			name: "converts align evaluation",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.AlignEvaluation = true
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.True(t, alert.AlignEvaluation)
+			},
+		},
+		{
+			name: "converts evaluation timeout",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.EvaluationTimeout = model.Duration(5 * time.Second)
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, 5*time.Second, alert.EvaluationTimeout)
+			},
+		},
+		{
+			name: "converts dependencies",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Dependencies = []models.RuleDependency{{RuleUID: "host-down", Equal: []string{"host"}}}
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, api.GrafanaManagedAlert.Dependencies, alert.Dependencies)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -380,6 +442,30 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if the evaluation timeout is negative",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.EvaluationTimeout = model.Duration(-time.Second)
+				return &r
+			},
+		},
+		{
+			name: "fail if a dependency has no rule UID",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Dependencies = []models.RuleDependency{{Equal: []string{"host"}}}
+				return &r
+			},
+		},
+		{
+			name: "fail if a label of a dependency is invalid",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Dependencies = []models.RuleDependency{{RuleUID: "host-down", Equal: []string{"host-name"}}}
+				return &r
+			},
+		},
 		{
 			name: "fail if there are not data (nil)",
 			rule: func() *apimodels.PostableExtendedRuleNode {
@@ -437,6 +523,47 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if the metric of a recording rule is not a valid metric name",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &models.Record{Metric: "test-metric", From: "A", TargetDatasourceUID: "DATASOURCE_TARGET"}
+				return &r
+			},
+		},
+		{
+			name: "fail if a recording rule has no target data source",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &models.Record{Metric: "test_metric", From: "A"}
+				return &r
+			},
+		},
+		{
+			name: "fail if the condition of a recording rule is not the recorded query",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &models.Record{Metric: "test_metric", From: "B", TargetDatasourceUID: "DATASOURCE_TARGET"}
+				return &r
+			},
+		},
+		{
+			name: "fail if the notification settings have no contact point",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.NotificationSettings = &models.NotificationSettings{}
+				return &r
+			},
+		},
+		{
+			name: "fail if a recording rule has notification settings",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &models.Record{Metric: "test_metric", From: "A", TargetDatasourceUID: "DATASOURCE_TARGET"}
+				r.GrafanaManagedAlert.NotificationSettings = &models.NotificationSettings{Receiver: "test-receiver"}
+				return &r
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -19,10 +20,15 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
 
+// backtestMaxEvaluations is the greatest number of evaluations of a rule replayed by a backtest.
+const backtestMaxEvaluations = 1000
+
 type TestingApiSrv struct {
 	*AlertingProxy
 	ExpressionService *expr.Service
@@ -30,6 +36,7 @@ type TestingApiSrv struct {
 	log               log.Logger
 	accessControl     accesscontrol.AccessControl
 	evaluator         eval.Evaluator
+	cfg               *setting.Cfg
 }
 
 func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *models.ReqContext, body apimodels.TestRulePayload) response.Response {
@@ -132,3 +139,122 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 
 	return response.JSONStreaming(http.StatusOK, evalResults)
 }
+
+// RouteBacktestConfig replays the evaluations of the rule from the start to the end of the time range, and returns
+// the states of its alert instances after each evaluation and the alerts that would have been sent to the
+// Alertmanager. The alert instances go through the same states as the ones of the rules of the scheduler, but
+// nothing is persisted, annotated or sent.
+func (srv TestingApiSrv) RouteBacktestConfig(c *models.ReqContext, body apimodels.BacktestConfig) response.Response {
+	if !authorizeDatasourceAccessForRule(&ngmodels.AlertRule{Data: body.Data}, func(evaluator accesscontrol.Evaluator) bool {
+		return accesscontrol.HasAccess(srv.accessControl, c)(accesscontrol.ReqSignedIn, evaluator)
+	}) {
+		return ErrResp(http.StatusUnauthorized, fmt.Errorf("%w to query one or many data sources used by the rule", ErrAuthorization), "")
+	}
+
+	if body.From.IsZero() || body.To.Before(body.From) {
+		return ErrResp(http.StatusBadRequest, errors.New("the end of the time range must not be before its start"), "invalid time range")
+	}
+	if body.To.After(timeNow()) {
+		return ErrResp(http.StatusBadRequest, errors.New("the time range must be in the past"), "invalid time range")
+	}
+
+	baseInterval := srv.cfg.UnifiedAlerting.BaseInterval
+	interval := time.Duration(body.Interval)
+	if interval == 0 {
+		interval = baseInterval
+	}
+	if interval <= 0 || interval%baseInterval != 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("rule evaluation interval must be positive duration that is multiple of the base interval %s", baseInterval), "invalid interval")
+	}
+	if n := body.To.Sub(body.From)/interval + 1; n > backtestMaxEvaluations {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("the time range needs %d evaluations of the rule, the maximum is %d", n, backtestMaxEvaluations), "invalid time range")
+	}
+
+	noDataState := ngmodels.NoData
+	if body.NoDataState != "" {
+		var err error
+		if noDataState, err = ngmodels.NoDataStateFromString(string(body.NoDataState)); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+	}
+	errorState := ngmodels.AlertingErrState
+	if body.ExecErrState != "" {
+		var err error
+		if errorState, err = ngmodels.ErrStateFromString(string(body.ExecErrState)); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+	}
+
+	evalCond := ngmodels.Condition{
+		Condition: body.Condition,
+		OrgID:     c.SignedInUser.OrgId,
+		Data:      body.Data,
+	}
+	if len(evalCond.Data) == 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("%w: no queries or expressions are found", ngmodels.ErrAlertRuleFailedValidation), "")
+	}
+	if err := validateCondition(c.Req.Context(), evalCond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid condition")
+	}
+
+	rule := &ngmodels.AlertRule{
+		OrgID:           c.SignedInUser.OrgId,
+		Title:           body.Title,
+		Condition:       body.Condition,
+		Data:            body.Data,
+		IntervalSeconds: int64(interval.Seconds()),
+		For:             time.Duration(body.For),
+		Labels:          body.Labels,
+		Annotations:     body.Annotations,
+		NoDataState:     noDataState,
+		ExecErrState:    errorState,
+	}
+
+	appURL, err := url.Parse(srv.cfg.AppURL)
+	if err != nil {
+		srv.log.Warn("failed to parse application URL, the templates of the rule are expanded without it", "error", err)
+	}
+	replayer := state.NewReplayer(srv.log, appURL)
+
+	result := apimodels.BacktestResult{
+		Evaluations:   make([]apimodels.BacktestEvaluation, 0, body.To.Sub(body.From)/interval+1),
+		Notifications: []apimodels.BacktestNotification{},
+	}
+	for ts := body.From; !ts.After(body.To); ts = ts.Add(interval) {
+		if err := c.Req.Context().Err(); err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "backtest was canceled")
+		}
+
+		evalResults, err := srv.evaluator.ConditionEval(&evalCond, ts, srv.ExpressionService)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "Failed to evaluate conditions")
+		}
+
+		states := replayer.ProcessEvalResults(c.Req.Context(), rule, evalResults)
+		evaluation := apimodels.BacktestEvaluation{Time: ts, Instances: make([]apimodels.BacktestInstance, 0, len(states))}
+		for _, s := range states {
+			labels := s.GetLabels(ngmodels.WithoutInternalLabels())
+			evaluation.Instances = append(evaluation.Instances, apimodels.BacktestInstance{
+				Labels: labels,
+				State:  s.State.String(),
+				Reason: s.StateReason,
+			})
+			if !s.NeedsSending(state.ResendDelay) {
+				continue
+			}
+			result.Notifications = append(result.Notifications, apimodels.BacktestNotification{
+				Time:        ts,
+				Labels:      labels,
+				Annotations: s.Annotations,
+				State:       s.State.String(),
+				StartsAt:    s.StartsAt,
+				EndsAt:      s.EndsAt,
+				Resolved:    s.Resolved,
+			})
+			s.LastSentAt = ts
+		}
+		result.Evaluations = append(result.Evaluations, evaluation)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
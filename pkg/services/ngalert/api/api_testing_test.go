@@ -1,14 +1,19 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prommodels "github.com/prometheus/common/model"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
 	models2 "github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	acMock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
@@ -17,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -275,5 +281,109 @@ func createTestingApiSrv(ds *fakes.FakeCacheService, ac *acMock.Mock, evaluator
 		DatasourceCache: ds,
 		accessControl:   ac,
 		evaluator:       evaluator,
+		log:             log.NewNopLogger(),
+		cfg:             &setting.Cfg{UnifiedAlerting: setting.UnifiedAlertingSettings{BaseInterval: 10 * time.Second}},
 	}
 }
+
+func TestRouteBacktestConfig(t *testing.T) {
+	rc := &models2.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{},
+		},
+		IsSignedIn: true,
+		SignedInUser: &models2.SignedInUser{
+			OrgId: 1,
+		},
+	}
+	from := timeNow().Add(-time.Hour).Truncate(time.Second)
+
+	t.Run("should return 401 if user cannot query a data source", func(t *testing.T) {
+		data1 := models.GenerateAlertQuery()
+
+		srv := createTestingApiSrv(nil, acMock.New(), nil)
+
+		response := srv.RouteBacktestConfig(rc, definitions.BacktestConfig{
+			From:      from,
+			To:        from.Add(time.Minute),
+			Condition: data1.RefID,
+			Data:      []models.AlertQuery{data1},
+		})
+
+		require.Equal(t, http.StatusUnauthorized, response.Status())
+	})
+
+	t.Run("should return 400 if the time range or interval is invalid", func(t *testing.T) {
+		data1 := models.GenerateAlertQuery()
+		ds := &fakes.FakeCacheService{DataSources: []*models2.DataSource{
+			{Uid: data1.DatasourceUID},
+		}}
+		srv := createTestingApiSrv(ds, nil, &eval.FakeEvaluator{})
+
+		testCases := map[string]definitions.BacktestConfig{
+			"end before start":           {From: from, To: from.Add(-time.Minute)},
+			"end in the future":          {From: from, To: timeNow().Add(time.Hour)},
+			"interval not multiple":      {From: from, To: from.Add(time.Minute), Interval: prommodels.Duration(15 * time.Second)},
+			"too many evaluations":       {From: from.Add(-24 * time.Hour), To: from},
+			"invalid no data state":      {From: from, To: from.Add(time.Minute), NoDataState: "invalid"},
+			"no queries and expressions": {From: from, To: from.Add(time.Minute)},
+		}
+		for name, body := range testCases {
+			t.Run(name, func(t *testing.T) {
+				if name != "no queries and expressions" {
+					body.Condition = data1.RefID
+					body.Data = []models.AlertQuery{data1}
+				}
+				response := srv.RouteBacktestConfig(rc, body)
+				require.Equal(t, http.StatusBadRequest, response.Status())
+			})
+		}
+	})
+
+	t.Run("should return the states and notifications of the evaluations", func(t *testing.T) {
+		data1 := models.GenerateAlertQuery()
+		ds := &fakes.FakeCacheService{DataSources: []*models2.DataSource{
+			{Uid: data1.DatasourceUID},
+		}}
+
+		evaluator := &eval.FakeEvaluator{}
+		evaluator.On("ConditionEval", mock.Anything, mock.Anything, mock.Anything).Return(func(_ *models.Condition, now time.Time, _ *expr.Service) eval.Results {
+			s := eval.Normal
+			if now.Before(from.Add(50 * time.Second)) {
+				s = eval.Alerting
+			}
+			return eval.Results{{Instance: data.Labels{"instance": "a"}, State: s, EvaluatedAt: now}}
+		}, nil)
+
+		srv := createTestingApiSrv(ds, nil, evaluator)
+
+		response := srv.RouteBacktestConfig(rc, definitions.BacktestConfig{
+			From:      from,
+			To:        from.Add(50 * time.Second),
+			Condition: data1.RefID,
+			Data:      []models.AlertQuery{data1},
+			Title:     "test",
+			For:       prommodels.Duration(10 * time.Second),
+		})
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var result definitions.BacktestResult
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+
+		states := make([]string, 0, len(result.Evaluations))
+		for _, e := range result.Evaluations {
+			require.Len(t, e.Instances, 1)
+			require.Equal(t, map[string]string{"alertname": "test", "instance": "a"}, e.Instances[0].Labels)
+			states = append(states, e.Instances[0].State)
+		}
+		require.Equal(t, []string{"Pending", "Alerting", "Alerting", "Alerting", "Alerting", "Normal"}, states)
+
+		// the alert is sent when it starts firing and again after the resend delay
+		require.Len(t, result.Notifications, 2)
+		for i, at := range []time.Time{from.Add(10 * time.Second), from.Add(40 * time.Second)} {
+			require.Equal(t, "Alerting", result.Notifications[i].State)
+			require.True(t, at.Equal(result.Notifications[i].Time))
+			require.True(t, from.Add(10*time.Second).Equal(result.Notifications[i].StartsAt))
+		}
+	})
+}
@@ -46,9 +46,12 @@ func (api *API) authorize(method, path string) web.Handler {
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead, dashboards.ScopeFoldersProvider.GetResourceScopeName(ac.Parameter(":Namespace")))
 	case http.MethodGet + "/api/ruler/grafana/api/v1/rules/{Namespace}":
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead, dashboards.ScopeFoldersProvider.GetResourceScopeName(ac.Parameter(":Namespace")))
+	case http.MethodGet + "/api/ruler/grafana/api/v1/export/{Namespace}":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead, dashboards.ScopeFoldersProvider.GetResourceScopeName(ac.Parameter(":Namespace")))
 	case http.MethodGet + "/api/ruler/grafana/api/v1/rules":
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
-	case http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}":
+	case http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}",
+		http.MethodPost + "/api/ruler/grafana/api/v1/import/{Namespace}":
 		fallback = middleware.ReqSignedIn // if RBAC is disabled then we need to delegate permission check to folder because its permissions can allow editing for Viewer role
 		scope := dashboards.ScopeFoldersProvider.GetResourceScopeName(ac.Parameter(":Namespace"))
 		// more granular permissions are enforced by the handler via "authorizeRuleChanges"
@@ -71,6 +74,10 @@ func (api *API) authorize(method, path string) web.Handler {
 		fallback = middleware.ReqSignedIn
 		// additional authorization is done in the request handler
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodPost + "/api/v1/rule/backtest":
+		fallback = middleware.ReqSignedIn
+		// additional authorization is done in the request handler
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
 
 	// Lotex Paths
 	case http.MethodDelete + "/api/ruler/{DatasourceUID}/api/v1/rules/{Namespace}":
@@ -158,6 +165,9 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/receivers/test":
 		fallback = middleware.ReqEditorRole
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
+	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/templates/test":
+		fallback = middleware.ReqEditorRole
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
 
 	// External Alertmanager Paths
 	case http.MethodDelete + "/api/alertmanager/{DatasourceUID}/config/api/v1/alerts":
@@ -172,11 +182,28 @@ func (api *API) authorize(method, path string) web.Handler {
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsExternalRead, datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":DatasourceUID")))
 
 	// Raw Alertmanager Config Paths
+	case http.MethodGet + "/api/v1/ngalert/admin_config",
+		http.MethodGet + "/api/v1/ngalert/admin_config/pause",
+		http.MethodGet + "/api/v1/ngalert/alertmanagers",
+		http.MethodGet + "/api/v1/ngalert/dead_letter",
+		http.MethodGet + "/api/v1/ngalert/delivery_receipts":
+		fallback = middleware.ReqOrgAdmin
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsExternalRead, ac.ScopeAlertingExternalAlertmanagersAll)
 	case http.MethodDelete + "/api/v1/ngalert/admin_config",
-		http.MethodGet + "/api/v1/ngalert/admin_config",
 		http.MethodPost + "/api/v1/ngalert/admin_config",
-		http.MethodGet + "/api/v1/ngalert/alertmanagers":
-		return middleware.ReqOrgAdmin
+		http.MethodPost + "/api/v1/ngalert/admin_config/test",
+		http.MethodPost + "/api/v1/ngalert/admin_config/pause",
+		http.MethodDelete + "/api/v1/ngalert/admin_config/pause",
+		http.MethodDelete + "/api/v1/ngalert/dead_letter",
+		http.MethodPost + "/api/v1/ngalert/dead_letter/redispatch":
+		fallback = middleware.ReqOrgAdmin
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsExternalWrite, ac.ScopeAlertingExternalAlertmanagersAll)
+	case http.MethodGet + "/api/v1/ngalert/senders":
+		// the senders of the other organizations are returned to Grafana admins only, by the handler
+		fallback = middleware.ReqGrafanaAdmin
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsExternalRead, ac.ScopeAlertingExternalAlertmanagersAll)
+	case http.MethodGet + "/api/v1/ngalert/state_history":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
 
 	// Grafana-only Provisioning Read Paths
 	case http.MethodGet + "/api/v1/provisioning/policies",
@@ -185,7 +212,12 @@ func (api *API) authorize(method, path string) web.Handler {
 		http.MethodGet + "/api/v1/provisioning/templates/{name}",
 		http.MethodGet + "/api/v1/provisioning/mute-timings",
 		http.MethodGet + "/api/v1/provisioning/mute-timings/{name}",
-		http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}":
+		http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}",
+		http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}/versions",
+		http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}/versions/diff",
+		http.MethodGet + "/api/v1/provisioning/maintenance-windows",
+		http.MethodGet + "/api/v1/provisioning/maintenance-windows/{UID}",
+		http.MethodGet + "/api/v1/provisioning/maintenance-windows/{UID}/suppressed-alerts":
 		return middleware.ReqSignedIn
 
 	case http.MethodPut + "/api/v1/provisioning/policies",
@@ -198,9 +230,18 @@ func (api *API) authorize(method, path string) web.Handler {
 		http.MethodPut + "/api/v1/provisioning/mute-timings/{name}",
 		http.MethodDelete + "/api/v1/provisioning/mute-timings/{name}",
 		http.MethodPost + "/api/v1/provisioning/alert-rules",
+		http.MethodPost + "/api/v1/provisioning/alert-rules/batch",
+		http.MethodPost + "/api/v1/provisioning/alert-rules/{UID}/pause",
+		http.MethodPost + "/api/v1/provisioning/alert-rules/{UID}/resume",
+		http.MethodPost + "/api/v1/provisioning/alert-rules/{UID}/versions/{Version}/restore",
+		http.MethodPost + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/pause",
+		http.MethodPost + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/resume",
 		http.MethodPut + "/api/v1/provisioning/alert-rules/{UID}",
 		http.MethodDelete + "/api/v1/provisioning/alert-rules/{UID}",
-		http.MethodPut + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}":
+		http.MethodPut + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}",
+		http.MethodPost + "/api/v1/provisioning/maintenance-windows",
+		http.MethodPut + "/api/v1/provisioning/maintenance-windows/{UID}",
+		http.MethodDelete + "/api/v1/provisioning/maintenance-windows/{UID}":
 		return middleware.ReqEditorRole
 	}
 
@@ -48,7 +48,7 @@ func TestAuthorize(t *testing.T) {
 		}
 		paths[p] = methods
 	}
-	require.Len(t, paths, 39)
+	require.Len(t, paths, 61)
 
 	ac := acmock.New()
 	api := &API{AccessControl: ac}
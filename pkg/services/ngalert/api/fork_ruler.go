@@ -121,6 +121,10 @@ func (f *ForkedRulerApi) forkRouteGetNamespaceGrafanaRulesConfig(ctx *models.Req
 	return f.GrafanaRuler.RouteGetNamespaceRulesConfig(ctx)
 }
 
+func (f *ForkedRulerApi) forkRouteGetNamespaceGrafanaRulesExport(ctx *models.ReqContext) response.Response {
+	return f.GrafanaRuler.RouteGetNamespaceRulesExport(ctx)
+}
+
 func (f *ForkedRulerApi) forkRouteGetGrafanaRuleGroupConfig(ctx *models.ReqContext) response.Response {
 	return f.GrafanaRuler.RouteGetRulesGroupConfig(ctx)
 }
@@ -136,3 +140,7 @@ func (f *ForkedRulerApi) forkRoutePostNameGrafanaRulesConfig(ctx *models.ReqCont
 	}
 	return f.GrafanaRuler.RoutePostNameRulesConfig(ctx, conf)
 }
+
+func (f *ForkedRulerApi) forkRoutePostNamespaceGrafanaRulesImport(ctx *models.ReqContext) response.Response {
+	return f.GrafanaRuler.RoutePostNamespaceRulesImport(ctx)
+}
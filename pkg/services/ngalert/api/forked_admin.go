@@ -26,10 +26,50 @@ func (f *ForkedConfigurationApi) forkRouteGetNGalertConfig(c *models.ReqContext)
 	return f.grafana.RouteGetNGalertConfig(c)
 }
 
+func (f *ForkedConfigurationApi) forkRouteGetAlertStateHistory(c *models.ReqContext) response.Response {
+	return f.grafana.RouteGetAlertStateHistory(c)
+}
+
+func (f *ForkedConfigurationApi) forkRouteGetDeliveryReceipts(c *models.ReqContext) response.Response {
+	return f.grafana.RouteGetDeliveryReceipts(c)
+}
+
+func (f *ForkedConfigurationApi) forkRouteGetDeadLetterAlerts(c *models.ReqContext) response.Response {
+	return f.grafana.RouteGetDeadLetterAlerts(c)
+}
+
+func (f *ForkedConfigurationApi) forkRoutePurgeDeadLetterAlerts(c *models.ReqContext) response.Response {
+	return f.grafana.RoutePurgeDeadLetterAlerts(c)
+}
+
+func (f *ForkedConfigurationApi) forkRouteRedispatchDeadLetterAlerts(c *models.ReqContext) response.Response {
+	return f.grafana.RouteRedispatchDeadLetterAlerts(c)
+}
+
+func (f *ForkedConfigurationApi) forkRouteGetExternalDeliveryPause(c *models.ReqContext) response.Response {
+	return f.grafana.RouteGetExternalDeliveryPause(c)
+}
+
+func (f *ForkedConfigurationApi) forkRoutePauseExternalDelivery(c *models.ReqContext, body apimodels.PostableExternalDeliveryPause) response.Response {
+	return f.grafana.RoutePauseExternalDelivery(c, body)
+}
+
+func (f *ForkedConfigurationApi) forkRouteResumeExternalDelivery(c *models.ReqContext) response.Response {
+	return f.grafana.RouteResumeExternalDelivery(c)
+}
+
+func (f *ForkedConfigurationApi) forkRouteGetSenders(c *models.ReqContext) response.Response {
+	return f.grafana.RouteGetSenders(c)
+}
+
 func (f *ForkedConfigurationApi) forkRoutePostNGalertConfig(c *models.ReqContext, body apimodels.PostableNGalertConfig) response.Response {
 	return f.grafana.RoutePostNGalertConfig(c, body)
 }
 
+func (f *ForkedConfigurationApi) forkRouteTestNGalertConfig(c *models.ReqContext, body apimodels.PostableNGalertConfig) response.Response {
+	return f.grafana.RouteTestNGalertConfig(c, body)
+}
+
 func (f *ForkedConfigurationApi) forkRouteDeleteNGalertConfig(c *models.ReqContext) response.Response {
 	return f.grafana.RouteDeleteNGalertConfig(c)
 }
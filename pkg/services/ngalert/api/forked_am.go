@@ -202,3 +202,7 @@ func (f *ForkedAlertmanagerApi) forkRoutePostGrafanaAlertingConfig(ctx *models.R
 func (f *ForkedAlertmanagerApi) forkRoutePostTestGrafanaReceivers(ctx *models.ReqContext, conf apimodels.TestReceiversConfigBodyParams) response.Response {
 	return f.GrafanaSvc.RoutePostTestReceivers(ctx, conf)
 }
+
+func (f *ForkedAlertmanagerApi) forkRoutePostTestGrafanaTemplates(ctx *models.ReqContext, conf apimodels.TestTemplatesConfigBodyParams) response.Response {
+	return f.GrafanaSvc.RoutePostTestTemplates(ctx, conf)
+}
@@ -87,6 +87,38 @@ func (f *ForkedProvisioningApi) forkRoutePostAlertRule(ctx *models.ReqContext, a
 	return f.svc.RoutePostAlertRule(ctx, ar)
 }
 
+func (f *ForkedProvisioningApi) forkRoutePostAlertRulePause(ctx *models.ReqContext) response.Response {
+	return f.svc.RoutePostAlertRulePause(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostAlertRuleResume(ctx *models.ReqContext) response.Response {
+	return f.svc.RoutePostAlertRuleResume(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetAlertRuleVersions(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetAlertRuleVersions(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetAlertRuleVersionsDiff(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetAlertRuleVersionsDiff(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostAlertRuleVersionRestore(ctx *models.ReqContext) response.Response {
+	return f.svc.RoutePostAlertRuleVersionRestore(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostAlertRuleGroupPause(ctx *models.ReqContext) response.Response {
+	return f.svc.RoutePostAlertRuleGroupPause(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostAlertRuleGroupResume(ctx *models.ReqContext) response.Response {
+	return f.svc.RoutePostAlertRuleGroupResume(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostAlertRulesBatch(ctx *models.ReqContext, batch apimodels.AlertRulesBatch) response.Response {
+	return f.svc.RoutePostAlertRulesBatch(ctx, batch)
+}
+
 func (f *ForkedProvisioningApi) forkRoutePutAlertRule(ctx *models.ReqContext, ar apimodels.AlertRule) response.Response {
 	return f.svc.RoutePutAlertRule(ctx, ar)
 }
@@ -98,3 +130,27 @@ func (f *ForkedProvisioningApi) forkRouteDeleteAlertRule(ctx *models.ReqContext)
 func (f *ForkedProvisioningApi) forkRoutePutAlertRuleGroup(ctx *models.ReqContext, ag apimodels.AlertRuleGroup) response.Response {
 	return f.svc.RoutePutAlertRuleGroup(ctx, ag)
 }
+
+func (f *ForkedProvisioningApi) forkRouteGetMaintenanceWindows(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetMaintenanceWindows(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetMaintenanceWindow(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetMaintenanceWindow(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostMaintenanceWindow(ctx *models.ReqContext, w apimodels.MaintenanceWindow) response.Response {
+	return f.svc.RoutePostMaintenanceWindow(ctx, w)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePutMaintenanceWindow(ctx *models.ReqContext, w apimodels.MaintenanceWindow) response.Response {
+	return f.svc.RoutePutMaintenanceWindow(ctx, w)
+}
+
+func (f *ForkedProvisioningApi) forkRouteDeleteMaintenanceWindow(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteDeleteMaintenanceWindow(ctx)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetMaintenanceWindowSuppressedAlerts(ctx *models.ReqContext) response.Response {
+	return f.svc.RouteGetMaintenanceWindowSuppressedAlerts(ctx)
+}
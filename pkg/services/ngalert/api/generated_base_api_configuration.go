@@ -20,20 +20,52 @@ import (
 
 type ConfigurationApiForkingService interface {
 	RouteDeleteNGalertConfig(*models.ReqContext) response.Response
+	RouteGetAlertStateHistory(*models.ReqContext) response.Response
 	RouteGetAlertmanagers(*models.ReqContext) response.Response
+	RouteGetDeadLetterAlerts(*models.ReqContext) response.Response
+	RouteGetDeliveryReceipts(*models.ReqContext) response.Response
+	RouteGetExternalDeliveryPause(*models.ReqContext) response.Response
 	RouteGetNGalertConfig(*models.ReqContext) response.Response
+	RouteGetSenders(*models.ReqContext) response.Response
+	RoutePauseExternalDelivery(*models.ReqContext) response.Response
 	RoutePostNGalertConfig(*models.ReqContext) response.Response
+	RoutePurgeDeadLetterAlerts(*models.ReqContext) response.Response
+	RouteRedispatchDeadLetterAlerts(*models.ReqContext) response.Response
+	RouteResumeExternalDelivery(*models.ReqContext) response.Response
+	RouteTestNGalertConfig(*models.ReqContext) response.Response
 }
 
 func (f *ForkedConfigurationApi) RouteDeleteNGalertConfig(ctx *models.ReqContext) response.Response {
 	return f.forkRouteDeleteNGalertConfig(ctx)
 }
+func (f *ForkedConfigurationApi) RouteGetAlertStateHistory(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetAlertStateHistory(ctx)
+}
 func (f *ForkedConfigurationApi) RouteGetAlertmanagers(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetAlertmanagers(ctx)
 }
+func (f *ForkedConfigurationApi) RouteGetDeadLetterAlerts(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetDeadLetterAlerts(ctx)
+}
+func (f *ForkedConfigurationApi) RouteGetDeliveryReceipts(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetDeliveryReceipts(ctx)
+}
+func (f *ForkedConfigurationApi) RouteGetExternalDeliveryPause(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetExternalDeliveryPause(ctx)
+}
 func (f *ForkedConfigurationApi) RouteGetNGalertConfig(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetNGalertConfig(ctx)
 }
+func (f *ForkedConfigurationApi) RouteGetSenders(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetSenders(ctx)
+}
+func (f *ForkedConfigurationApi) RoutePauseExternalDelivery(ctx *models.ReqContext) response.Response {
+	conf := apimodels.PostableExternalDeliveryPause{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePauseExternalDelivery(ctx, conf)
+}
 func (f *ForkedConfigurationApi) RoutePostNGalertConfig(ctx *models.ReqContext) response.Response {
 	conf := apimodels.PostableNGalertConfig{}
 	if err := web.Bind(ctx.Req, &conf); err != nil {
@@ -41,6 +73,22 @@ func (f *ForkedConfigurationApi) RoutePostNGalertConfig(ctx *models.ReqContext)
 	}
 	return f.forkRoutePostNGalertConfig(ctx, conf)
 }
+func (f *ForkedConfigurationApi) RoutePurgeDeadLetterAlerts(ctx *models.ReqContext) response.Response {
+	return f.forkRoutePurgeDeadLetterAlerts(ctx)
+}
+func (f *ForkedConfigurationApi) RouteRedispatchDeadLetterAlerts(ctx *models.ReqContext) response.Response {
+	return f.forkRouteRedispatchDeadLetterAlerts(ctx)
+}
+func (f *ForkedConfigurationApi) RouteResumeExternalDelivery(ctx *models.ReqContext) response.Response {
+	return f.forkRouteResumeExternalDelivery(ctx)
+}
+func (f *ForkedConfigurationApi) RouteTestNGalertConfig(ctx *models.ReqContext) response.Response {
+	conf := apimodels.PostableNGalertConfig{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRouteTestNGalertConfig(ctx, conf)
+}
 
 func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApiForkingService, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
@@ -54,6 +102,46 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApiForkingSer
 				m,
 			),
 		)
+		group.Delete(
+			toMacaronPath("/api/v1/ngalert/admin_config/pause"),
+			api.authorize(http.MethodDelete, "/api/v1/ngalert/admin_config/pause"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/ngalert/admin_config/pause",
+				srv.RouteResumeExternalDelivery,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/ngalert/dead_letter"),
+			api.authorize(http.MethodDelete, "/api/v1/ngalert/dead_letter"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/ngalert/dead_letter",
+				srv.RoutePurgeDeadLetterAlerts,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/state_history"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/state_history"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/state_history",
+				srv.RouteGetAlertStateHistory,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/delivery_receipts"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/delivery_receipts"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/delivery_receipts",
+				srv.RouteGetDeliveryReceipts,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/ngalert/alertmanagers"),
 			api.authorize(http.MethodGet, "/api/v1/ngalert/alertmanagers"),
@@ -64,6 +152,26 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApiForkingSer
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/admin_config/pause"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/admin_config/pause"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/admin_config/pause",
+				srv.RouteGetExternalDeliveryPause,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/dead_letter"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/dead_letter"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/dead_letter",
+				srv.RouteGetDeadLetterAlerts,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/ngalert/admin_config"),
 			api.authorize(http.MethodGet, "/api/v1/ngalert/admin_config"),
@@ -74,6 +182,16 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApiForkingSer
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/senders"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/senders"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/senders",
+				srv.RouteGetSenders,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/ngalert/admin_config"),
 			api.authorize(http.MethodPost, "/api/v1/ngalert/admin_config"),
@@ -84,5 +202,35 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApiForkingSer
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/admin_config/test"),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/admin_config/test"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/admin_config/test",
+				srv.RouteTestNGalertConfig,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/admin_config/pause"),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/admin_config/pause"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/admin_config/pause",
+				srv.RoutePauseExternalDelivery,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/dead_letter/redispatch"),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/dead_letter/redispatch"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/dead_letter/redispatch",
+				srv.RouteRedispatchDeadLetterAlerts,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
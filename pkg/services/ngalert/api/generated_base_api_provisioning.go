@@ -21,21 +21,35 @@ import (
 type ProvisioningApiForkingService interface {
 	RouteDeleteAlertRule(*models.ReqContext) response.Response
 	RouteDeleteContactpoints(*models.ReqContext) response.Response
+	RouteDeleteMaintenanceWindow(*models.ReqContext) response.Response
 	RouteDeleteMuteTiming(*models.ReqContext) response.Response
 	RouteDeleteTemplate(*models.ReqContext) response.Response
 	RouteGetAlertRule(*models.ReqContext) response.Response
+	RouteGetAlertRuleVersions(*models.ReqContext) response.Response
+	RouteGetAlertRuleVersionsDiff(*models.ReqContext) response.Response
 	RouteGetContactpoints(*models.ReqContext) response.Response
+	RouteGetMaintenanceWindow(*models.ReqContext) response.Response
+	RouteGetMaintenanceWindowSuppressedAlerts(*models.ReqContext) response.Response
+	RouteGetMaintenanceWindows(*models.ReqContext) response.Response
 	RouteGetMuteTiming(*models.ReqContext) response.Response
 	RouteGetMuteTimings(*models.ReqContext) response.Response
 	RouteGetPolicyTree(*models.ReqContext) response.Response
 	RouteGetTemplate(*models.ReqContext) response.Response
 	RouteGetTemplates(*models.ReqContext) response.Response
 	RoutePostAlertRule(*models.ReqContext) response.Response
+	RoutePostAlertRuleGroupPause(*models.ReqContext) response.Response
+	RoutePostAlertRuleGroupResume(*models.ReqContext) response.Response
+	RoutePostAlertRulePause(*models.ReqContext) response.Response
+	RoutePostAlertRuleResume(*models.ReqContext) response.Response
+	RoutePostAlertRuleVersionRestore(*models.ReqContext) response.Response
+	RoutePostAlertRulesBatch(*models.ReqContext) response.Response
 	RoutePostContactpoints(*models.ReqContext) response.Response
+	RoutePostMaintenanceWindow(*models.ReqContext) response.Response
 	RoutePostMuteTiming(*models.ReqContext) response.Response
 	RoutePutAlertRule(*models.ReqContext) response.Response
 	RoutePutAlertRuleGroup(*models.ReqContext) response.Response
 	RoutePutContactpoint(*models.ReqContext) response.Response
+	RoutePutMaintenanceWindow(*models.ReqContext) response.Response
 	RoutePutMuteTiming(*models.ReqContext) response.Response
 	RoutePutPolicyTree(*models.ReqContext) response.Response
 	RoutePutTemplate(*models.ReqContext) response.Response
@@ -47,6 +61,9 @@ func (f *ForkedProvisioningApi) RouteDeleteAlertRule(ctx *models.ReqContext) res
 func (f *ForkedProvisioningApi) RouteDeleteContactpoints(ctx *models.ReqContext) response.Response {
 	return f.forkRouteDeleteContactpoints(ctx)
 }
+func (f *ForkedProvisioningApi) RouteDeleteMaintenanceWindow(ctx *models.ReqContext) response.Response {
+	return f.forkRouteDeleteMaintenanceWindow(ctx)
+}
 func (f *ForkedProvisioningApi) RouteDeleteMuteTiming(ctx *models.ReqContext) response.Response {
 	return f.forkRouteDeleteMuteTiming(ctx)
 }
@@ -56,9 +73,24 @@ func (f *ForkedProvisioningApi) RouteDeleteTemplate(ctx *models.ReqContext) resp
 func (f *ForkedProvisioningApi) RouteGetAlertRule(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetAlertRule(ctx)
 }
+func (f *ForkedProvisioningApi) RouteGetAlertRuleVersions(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetAlertRuleVersions(ctx)
+}
+func (f *ForkedProvisioningApi) RouteGetAlertRuleVersionsDiff(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetAlertRuleVersionsDiff(ctx)
+}
 func (f *ForkedProvisioningApi) RouteGetContactpoints(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetContactpoints(ctx)
 }
+func (f *ForkedProvisioningApi) RouteGetMaintenanceWindow(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetMaintenanceWindow(ctx)
+}
+func (f *ForkedProvisioningApi) RouteGetMaintenanceWindowSuppressedAlerts(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetMaintenanceWindowSuppressedAlerts(ctx)
+}
+func (f *ForkedProvisioningApi) RouteGetMaintenanceWindows(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetMaintenanceWindows(ctx)
+}
 func (f *ForkedProvisioningApi) RouteGetMuteTiming(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetMuteTiming(ctx)
 }
@@ -81,6 +113,28 @@ func (f *ForkedProvisioningApi) RoutePostAlertRule(ctx *models.ReqContext) respo
 	}
 	return f.forkRoutePostAlertRule(ctx, conf)
 }
+func (f *ForkedProvisioningApi) RoutePostAlertRuleGroupPause(ctx *models.ReqContext) response.Response {
+	return f.forkRoutePostAlertRuleGroupPause(ctx)
+}
+func (f *ForkedProvisioningApi) RoutePostAlertRuleGroupResume(ctx *models.ReqContext) response.Response {
+	return f.forkRoutePostAlertRuleGroupResume(ctx)
+}
+func (f *ForkedProvisioningApi) RoutePostAlertRulePause(ctx *models.ReqContext) response.Response {
+	return f.forkRoutePostAlertRulePause(ctx)
+}
+func (f *ForkedProvisioningApi) RoutePostAlertRuleResume(ctx *models.ReqContext) response.Response {
+	return f.forkRoutePostAlertRuleResume(ctx)
+}
+func (f *ForkedProvisioningApi) RoutePostAlertRuleVersionRestore(ctx *models.ReqContext) response.Response {
+	return f.forkRoutePostAlertRuleVersionRestore(ctx)
+}
+func (f *ForkedProvisioningApi) RoutePostAlertRulesBatch(ctx *models.ReqContext) response.Response {
+	conf := apimodels.AlertRulesBatch{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePostAlertRulesBatch(ctx, conf)
+}
 func (f *ForkedProvisioningApi) RoutePostContactpoints(ctx *models.ReqContext) response.Response {
 	conf := apimodels.EmbeddedContactPoint{}
 	if err := web.Bind(ctx.Req, &conf); err != nil {
@@ -88,6 +142,13 @@ func (f *ForkedProvisioningApi) RoutePostContactpoints(ctx *models.ReqContext) r
 	}
 	return f.forkRoutePostContactpoints(ctx, conf)
 }
+func (f *ForkedProvisioningApi) RoutePostMaintenanceWindow(ctx *models.ReqContext) response.Response {
+	conf := apimodels.MaintenanceWindow{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePostMaintenanceWindow(ctx, conf)
+}
 func (f *ForkedProvisioningApi) RoutePostMuteTiming(ctx *models.ReqContext) response.Response {
 	conf := apimodels.MuteTimeInterval{}
 	if err := web.Bind(ctx.Req, &conf); err != nil {
@@ -116,6 +177,13 @@ func (f *ForkedProvisioningApi) RoutePutContactpoint(ctx *models.ReqContext) res
 	}
 	return f.forkRoutePutContactpoint(ctx, conf)
 }
+func (f *ForkedProvisioningApi) RoutePutMaintenanceWindow(ctx *models.ReqContext) response.Response {
+	conf := apimodels.MaintenanceWindow{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.forkRoutePutMaintenanceWindow(ctx, conf)
+}
 func (f *ForkedProvisioningApi) RoutePutMuteTiming(ctx *models.ReqContext) response.Response {
 	conf := apimodels.MuteTimeInterval{}
 	if err := web.Bind(ctx.Req, &conf); err != nil {
@@ -160,6 +228,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingServi
 				m,
 			),
 		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/maintenance-windows/{UID}"),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/maintenance-windows/{UID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/maintenance-windows/{UID}",
+				srv.RouteDeleteMaintenanceWindow,
+				m,
+			),
+		)
 		group.Delete(
 			toMacaronPath("/api/v1/provisioning/mute-timings/{name}"),
 			api.authorize(http.MethodDelete, "/api/v1/provisioning/mute-timings/{name}"),
@@ -190,6 +268,26 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingServi
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}/versions"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/alert-rules/{UID}/versions"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/alert-rules/{UID}/versions",
+				srv.RouteGetAlertRuleVersions,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}/versions/diff"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/alert-rules/{UID}/versions/diff"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/alert-rules/{UID}/versions/diff",
+				srv.RouteGetAlertRuleVersionsDiff,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/contact-points"),
 			api.authorize(http.MethodGet, "/api/v1/provisioning/contact-points"),
@@ -200,6 +298,36 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingServi
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/maintenance-windows/{UID}"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/maintenance-windows/{UID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/maintenance-windows/{UID}",
+				srv.RouteGetMaintenanceWindow,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/maintenance-windows/{UID}/suppressed-alerts"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/maintenance-windows/{UID}/suppressed-alerts"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/maintenance-windows/{UID}/suppressed-alerts",
+				srv.RouteGetMaintenanceWindowSuppressedAlerts,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/maintenance-windows"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/maintenance-windows"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/maintenance-windows",
+				srv.RouteGetMaintenanceWindows,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/mute-timings/{name}"),
 			api.authorize(http.MethodGet, "/api/v1/provisioning/mute-timings/{name}"),
@@ -260,6 +388,66 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingServi
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/alert-rules/batch"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/alert-rules/batch"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/alert-rules/batch",
+				srv.RoutePostAlertRulesBatch,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}/pause"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/alert-rules/{UID}/pause"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/alert-rules/{UID}/pause",
+				srv.RoutePostAlertRulePause,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}/resume"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/alert-rules/{UID}/resume"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/alert-rules/{UID}/resume",
+				srv.RoutePostAlertRuleResume,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}/versions/{Version}/restore"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/alert-rules/{UID}/versions/{Version}/restore"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/alert-rules/{UID}/versions/{Version}/restore",
+				srv.RoutePostAlertRuleVersionRestore,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/pause"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/pause"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/pause",
+				srv.RoutePostAlertRuleGroupPause,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/resume"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/resume"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/resume",
+				srv.RoutePostAlertRuleGroupResume,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/provisioning/contact-points"),
 			api.authorize(http.MethodPost, "/api/v1/provisioning/contact-points"),
@@ -270,6 +458,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingServi
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/maintenance-windows"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/maintenance-windows"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/maintenance-windows",
+				srv.RoutePostMaintenanceWindow,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/provisioning/mute-timings"),
 			api.authorize(http.MethodPost, "/api/v1/provisioning/mute-timings"),
@@ -310,6 +508,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiForkingServi
 				m,
 			),
 		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/maintenance-windows/{UID}"),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/maintenance-windows/{UID}"),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/maintenance-windows/{UID}",
+				srv.RoutePutMaintenanceWindow,
+				m,
+			),
+		)
 		group.Put(
 			toMacaronPath("/api/v1/provisioning/mute-timings/{name}"),
 			api.authorize(http.MethodPut, "/api/v1/provisioning/mute-timings/{name}"),
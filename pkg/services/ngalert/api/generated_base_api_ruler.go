@@ -26,11 +26,13 @@ type RulerApiForkingService interface {
 	RouteGetGrafanaRuleGroupConfig(*models.ReqContext) response.Response
 	RouteGetGrafanaRulesConfig(*models.ReqContext) response.Response
 	RouteGetNamespaceGrafanaRulesConfig(*models.ReqContext) response.Response
+	RouteGetNamespaceGrafanaRulesExport(*models.ReqContext) response.Response
 	RouteGetNamespaceRulesConfig(*models.ReqContext) response.Response
 	RouteGetRulegGroupConfig(*models.ReqContext) response.Response
 	RouteGetRulesConfig(*models.ReqContext) response.Response
 	RoutePostNameGrafanaRulesConfig(*models.ReqContext) response.Response
 	RoutePostNameRulesConfig(*models.ReqContext) response.Response
+	RoutePostNamespaceGrafanaRulesImport(*models.ReqContext) response.Response
 }
 
 func (f *ForkedRulerApi) RouteDeleteGrafanaRuleGroupConfig(ctx *models.ReqContext) response.Response {
@@ -54,6 +56,9 @@ func (f *ForkedRulerApi) RouteGetGrafanaRulesConfig(ctx *models.ReqContext) resp
 func (f *ForkedRulerApi) RouteGetNamespaceGrafanaRulesConfig(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetNamespaceGrafanaRulesConfig(ctx)
 }
+func (f *ForkedRulerApi) RouteGetNamespaceGrafanaRulesExport(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetNamespaceGrafanaRulesExport(ctx)
+}
 func (f *ForkedRulerApi) RouteGetNamespaceRulesConfig(ctx *models.ReqContext) response.Response {
 	return f.forkRouteGetNamespaceRulesConfig(ctx)
 }
@@ -77,6 +82,9 @@ func (f *ForkedRulerApi) RoutePostNameRulesConfig(ctx *models.ReqContext) respon
 	}
 	return f.forkRoutePostNameRulesConfig(ctx, conf)
 }
+func (f *ForkedRulerApi) RoutePostNamespaceGrafanaRulesImport(ctx *models.ReqContext) response.Response {
+	return f.forkRoutePostNamespaceGrafanaRulesImport(ctx)
+}
 
 func (api *API) RegisterRulerApiEndpoints(srv RulerApiForkingService, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
@@ -150,6 +158,16 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiForkingService, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/export/{Namespace}"),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/export/{Namespace}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/export/{Namespace}",
+				srv.RouteGetNamespaceGrafanaRulesExport,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/ruler/{DatasourceUID}/api/v1/rules/{Namespace}"),
 			api.authorize(http.MethodGet, "/api/ruler/{DatasourceUID}/api/v1/rules/{Namespace}"),
@@ -200,5 +218,15 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiForkingService, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/import/{Namespace}"),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/import/{Namespace}"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/import/{Namespace}",
+				srv.RoutePostNamespaceGrafanaRulesImport,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
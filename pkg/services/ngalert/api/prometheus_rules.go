@@ -0,0 +1,264 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// The RefIDs of the queries and expressions of the imported rules: the query, the reduction of its result to its
+// last values and, for alert rules, the condition alerting on every series of the result like Prometheus does.
+const (
+	importedQueryRefID     = "A"
+	importedReduceRefID    = "B"
+	importedConditionRefID = "C"
+
+	importedCondition = "!is_null($" + importedReduceRefID + ")"
+)
+
+// importedQueryRange is the time range of the queries of the imported rules. The queries are instant, the range
+// only bounds the look-back of the data source.
+const importedQueryRange = 10 * time.Minute
+
+// errNotTranslatable is returned when the queries and expressions of a rule cannot be translated to a PromQL or LogQL
+// expression.
+var errNotTranslatable = errors.New("the queries and expressions cannot be translated")
+
+var (
+	// mathComparison matches the math expressions comparing the values of a query or expression to a number.
+	mathComparison = regexp.MustCompile(`^\$\{?(\w+)\}?\s*(>=|<=|==|!=|>|<)\s*(\S+)$`)
+	// mathNotNull matches the math expressions true for every value of a query or expression.
+	mathNotNull = regexp.MustCompile(`^!\s*is_null\(\s*\$\{?(\w+)\}?\s*\)$`)
+)
+
+// expressionModel is the part of the model of an expression the translation of rules relies on.
+type expressionModel struct {
+	Type       string `json:"type"`
+	Expression string `json:"expression"`
+	Reducer    string `json:"reducer"`
+}
+
+// toPrometheusRule translates an alert rule to a Prometheus rule. Only the rules alerting on, or recording, the
+// last values of a single PromQL or LogQL query, optionally compared to a number, can be translated.
+func toPrometheusRule(r *ngmodels.AlertRule) (apimodels.ApiRuleNode, error) {
+	queries := make(map[string]ngmodels.AlertQuery, len(r.Data))
+	for _, q := range r.Data {
+		queries[q.RefID] = q
+	}
+
+	if r.IsRecording() {
+		e, err := valueExpr(queries, r.Record.From)
+		if err != nil {
+			return apimodels.ApiRuleNode{}, err
+		}
+		return apimodels.ApiRuleNode{
+			Record: r.Record.Metric,
+			Expr:   e,
+			Labels: r.Labels,
+		}, nil
+	}
+
+	e, err := alertExpr(queries, r.Condition)
+	if err != nil {
+		return apimodels.ApiRuleNode{}, err
+	}
+	var annotations map[string]string
+	for name, value := range r.Annotations {
+		if _, ok := ngmodels.InternalAnnotationNameSet[name]; ok {
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]string, len(r.Annotations))
+		}
+		annotations[name] = value
+	}
+	return apimodels.ApiRuleNode{
+		Alert:       r.Title,
+		Expr:        e,
+		For:         model.Duration(r.For),
+		Labels:      r.Labels,
+		Annotations: annotations,
+	}, nil
+}
+
+// alertExpr returns the expression returning the series the condition refID alerts on.
+func alertExpr(queries map[string]ngmodels.AlertQuery, refID string) (string, error) {
+	q, ok := queries[refID]
+	if !ok {
+		return "", fmt.Errorf("%w: no query or expression %s", errNotTranslatable, refID)
+	}
+	if !expr.IsDataSource(q.DatasourceUID) {
+		e, err := queryExpr(q)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s) != 0", e), nil
+	}
+
+	m, err := parseExpressionModel(q)
+	if err != nil {
+		return "", err
+	}
+	switch m.Type {
+	case "reduce":
+		e, err := valueExpr(queries, refID)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s) != 0", e), nil
+	case "math":
+		expression := strings.TrimSpace(m.Expression)
+		if match := mathNotNull.FindStringSubmatch(expression); match != nil {
+			return valueExpr(queries, match[1])
+		}
+		if match := mathComparison.FindStringSubmatch(expression); match != nil {
+			if _, err := strconv.ParseFloat(match[3], 64); err != nil {
+				return "", fmt.Errorf("%w: %s is not compared to a number", errNotTranslatable, refID)
+			}
+			e, err := valueExpr(queries, match[1])
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("(%s) %s %s", e, match[2], match[3]), nil
+		}
+	}
+	return "", fmt.Errorf("%w: the %s expression %s is not supported", errNotTranslatable, m.Type, refID)
+}
+
+// valueExpr returns the expression returning the values of refID, a query or the last values of a query.
+func valueExpr(queries map[string]ngmodels.AlertQuery, refID string) (string, error) {
+	q, ok := queries[refID]
+	if !ok {
+		return "", fmt.Errorf("%w: no query or expression %s", errNotTranslatable, refID)
+	}
+	if !expr.IsDataSource(q.DatasourceUID) {
+		return queryExpr(q)
+	}
+
+	m, err := parseExpressionModel(q)
+	if err != nil {
+		return "", err
+	}
+	if m.Type != "reduce" || m.Reducer != "last" {
+		return "", fmt.Errorf("%w: %s is not the last values of a query", errNotTranslatable, refID)
+	}
+	reduced, ok := queries[strings.TrimPrefix(m.Expression, "$")]
+	if !ok || expr.IsDataSource(reduced.DatasourceUID) {
+		return "", fmt.Errorf("%w: %s is not the last values of a query", errNotTranslatable, refID)
+	}
+	return queryExpr(reduced)
+}
+
+// queryExpr returns the PromQL or LogQL expression of the query.
+func queryExpr(q ngmodels.AlertQuery) (string, error) {
+	e, err := q.GetQuery()
+	if err != nil || e == "" {
+		return "", fmt.Errorf("%w: %s is not a PromQL or LogQL query", errNotTranslatable, q.RefID)
+	}
+	return e, nil
+}
+
+func parseExpressionModel(q ngmodels.AlertQuery) (expressionModel, error) {
+	var m expressionModel
+	if err := json.Unmarshal(q.Model, &m); err != nil {
+		return m, fmt.Errorf("%w: failed to parse the expression %s: %s", errNotTranslatable, q.RefID, err)
+	}
+	return m, nil
+}
+
+// fromPrometheusRule translates a Prometheus rule to a rule querying the data source. The rule evaluates the
+// instant query of the expression of the Prometheus rule and reduces its result to the last values. Alert rules
+// alert on every series of the result, as Prometheus does, and recording rules write the result to the target
+// data source.
+func fromPrometheusRule(rule apimodels.ApiRuleNode, ds *models.DataSource, targetDatasourceUID string) (apimodels.PostableExtendedRuleNode, error) {
+	if rule.Expr == "" {
+		return apimodels.PostableExtendedRuleNode{}, errors.New("the rule has no expression")
+	}
+	if (rule.Alert == "") == (rule.Record == "") {
+		return apimodels.PostableExtendedRuleNode{}, errors.New("the rule must be either an alerting or a recording rule")
+	}
+
+	query := map[string]interface{}{
+		"refId":      importedQueryRefID,
+		"expr":       rule.Expr,
+		"datasource": map[string]string{"type": ds.Type, "uid": ds.Uid},
+	}
+	if ds.Type == models.DS_LOKI {
+		query["queryType"] = "instant"
+	} else {
+		query["instant"] = true
+		query["range"] = false
+	}
+	data := []ngmodels.AlertQuery{
+		importedQuery(importedQueryRefID, ds.Uid, query),
+		importedQuery(importedReduceRefID, expr.DatasourceUID, map[string]interface{}{
+			"refId":      importedReduceRefID,
+			"type":       "reduce",
+			"expression": importedQueryRefID,
+			"reducer":    "last",
+			"datasource": map[string]string{"type": expr.DatasourceType, "uid": expr.DatasourceUID},
+		}),
+	}
+
+	if rule.Record != "" {
+		return apimodels.PostableExtendedRuleNode{
+			ApiRuleNode: &apimodels.ApiRuleNode{
+				Labels: rule.Labels,
+			},
+			GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				Title:     rule.Record,
+				Condition: importedReduceRefID,
+				Data:      data,
+				Record: &ngmodels.Record{
+					Metric:              rule.Record,
+					From:                importedReduceRefID,
+					TargetDatasourceUID: targetDatasourceUID,
+				},
+			},
+		}, nil
+	}
+
+	data = append(data, importedQuery(importedConditionRefID, expr.DatasourceUID, map[string]interface{}{
+		"refId":      importedConditionRefID,
+		"type":       "math",
+		"expression": importedCondition,
+		"datasource": map[string]string{"type": expr.DatasourceType, "uid": expr.DatasourceUID},
+	}))
+	return apimodels.PostableExtendedRuleNode{
+		ApiRuleNode: &apimodels.ApiRuleNode{
+			For:         rule.For,
+			Labels:      rule.Labels,
+			Annotations: rule.Annotations,
+		},
+		GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+			Title:     rule.Alert,
+			Condition: importedConditionRefID,
+			Data:      data,
+			// Prometheus does not alert when there is no series or the evaluation fails
+			NoDataState:  apimodels.OK,
+			ExecErrState: apimodels.ErrorErrState,
+		},
+	}, nil
+}
+
+func importedQuery(refID, datasourceUID string, m map[string]interface{}) ngmodels.AlertQuery {
+	// a map of strings and JSON values cannot fail to be marshalled
+	raw, _ := json.Marshal(m)
+	return ngmodels.AlertQuery{
+		RefID:             refID,
+		DatasourceUID:     datasourceUID,
+		RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(importedQueryRange)},
+		Model:             raw,
+	}
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	models2 "github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestPrometheusRuleTranslation(t *testing.T) {
+	prometheus := &models2.DataSource{Uid: "prometheus", Type: models2.DS_PROMETHEUS}
+
+	toAlertRule := func(t *testing.T, node apimodels.PostableExtendedRuleNode) *models.AlertRule {
+		t.Helper()
+		return &models.AlertRule{
+			Title:       node.GrafanaManagedAlert.Title,
+			Condition:   node.GrafanaManagedAlert.Condition,
+			Data:        node.GrafanaManagedAlert.Data,
+			Record:      node.GrafanaManagedAlert.Record,
+			For:         time.Duration(node.ApiRuleNode.For),
+			Labels:      node.ApiRuleNode.Labels,
+			Annotations: node.ApiRuleNode.Annotations,
+		}
+	}
+
+	t.Run("alert rule is exported as imported", func(t *testing.T) {
+		rule := apimodels.ApiRuleNode{
+			Alert:       "HighErrorRate",
+			Expr:        `sum(rate(errors_total[5m])) > 10`,
+			For:         model.Duration(5 * time.Minute),
+			Labels:      map[string]string{"severity": "critical"},
+			Annotations: map[string]string{"summary": "too many errors"},
+		}
+		node, err := fromPrometheusRule(rule, prometheus, "")
+		require.NoError(t, err)
+		require.Equal(t, importedConditionRefID, node.GrafanaManagedAlert.Condition)
+		require.Equal(t, apimodels.OK, node.GrafanaManagedAlert.NoDataState)
+		require.Len(t, node.GrafanaManagedAlert.Data, 3)
+		require.Equal(t, prometheus.Uid, node.GrafanaManagedAlert.Data[0].DatasourceUID)
+
+		exported, err := toPrometheusRule(toAlertRule(t, node))
+		require.NoError(t, err)
+		require.Equal(t, rule, exported)
+	})
+
+	t.Run("recording rule is exported as imported", func(t *testing.T) {
+		rule := apimodels.ApiRuleNode{
+			Record: "job:errors:rate5m",
+			Expr:   `sum by (job) (rate(errors_total[5m]))`,
+			Labels: map[string]string{"team": "a"},
+		}
+		node, err := fromPrometheusRule(rule, prometheus, "target")
+		require.NoError(t, err)
+		require.Equal(t, &models.Record{Metric: rule.Record, From: importedReduceRefID, TargetDatasourceUID: "target"}, node.GrafanaManagedAlert.Record)
+
+		exported, err := toPrometheusRule(toAlertRule(t, node))
+		require.NoError(t, err)
+		require.Equal(t, rule, exported)
+	})
+
+	t.Run("imported Loki query is instant", func(t *testing.T) {
+		loki := &models2.DataSource{Uid: "loki", Type: models2.DS_LOKI}
+		node, err := fromPrometheusRule(apimodels.ApiRuleNode{Alert: "Errors", Expr: `count_over_time({job="a"}[5m])`}, loki, "")
+		require.NoError(t, err)
+		m := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(node.GrafanaManagedAlert.Data[0].Model, &m))
+		require.Equal(t, "instant", m["queryType"])
+	})
+
+	t.Run("invalid Prometheus rule is not imported", func(t *testing.T) {
+		_, err := fromPrometheusRule(apimodels.ApiRuleNode{Alert: "NoExpr"}, prometheus, "")
+		require.Error(t, err)
+		_, err = fromPrometheusRule(apimodels.ApiRuleNode{Alert: "Both", Record: "both", Expr: "up"}, prometheus, "")
+		require.Error(t, err)
+	})
+
+	query := func(refID, e string) models.AlertQuery {
+		return models.AlertQuery{RefID: refID, DatasourceUID: prometheus.Uid, Model: json.RawMessage(`{"expr":"` + e + `"}`)}
+	}
+	expression := func(refID, m string) models.AlertQuery {
+		return models.AlertQuery{RefID: refID, DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(m)}
+	}
+
+	testCases := []struct {
+		desc      string
+		condition string
+		data      []models.AlertQuery
+		expected  string
+	}{
+		{
+			desc:      "query condition",
+			condition: "A",
+			data:      []models.AlertQuery{query("A", "up")},
+			expected:  "(up) != 0",
+		},
+		{
+			desc:      "last values compared to a number",
+			condition: "C",
+			data: []models.AlertQuery{
+				query("A", "up"),
+				expression("B", `{"type":"reduce","expression":"A","reducer":"last"}`),
+				expression("C", `{"type":"math","expression":"$B < 1"}`),
+			},
+			expected: "(up) < 1",
+		},
+		{
+			desc:      "last values condition",
+			condition: "B",
+			data: []models.AlertQuery{
+				query("A", "up"),
+				expression("B", `{"type":"reduce","expression":"A","reducer":"last"}`),
+			},
+			expected: "(up) != 0",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			exported, err := toPrometheusRule(&models.AlertRule{Title: "test", Condition: tc.condition, Data: tc.data})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, exported.Expr)
+		})
+	}
+
+	untranslatable := []struct {
+		desc      string
+		condition string
+		data      []models.AlertQuery
+	}{
+		{
+			desc:      "classic condition",
+			condition: "B",
+			data: []models.AlertQuery{
+				query("A", "up"),
+				expression("B", `{"type":"classic_conditions"}`),
+			},
+		},
+		{
+			desc:      "mean of the values",
+			condition: "B",
+			data: []models.AlertQuery{
+				query("A", "up"),
+				expression("B", `{"type":"reduce","expression":"A","reducer":"mean"}`),
+			},
+		},
+		{
+			desc:      "values compared to another query",
+			condition: "C",
+			data: []models.AlertQuery{
+				query("A", "up"),
+				query("B", "down"),
+				expression("C", `{"type":"math","expression":"$A > $B"}`),
+			},
+		},
+		{
+			desc:      "query without expression",
+			condition: "A",
+			data:      []models.AlertQuery{{RefID: "A", DatasourceUID: "graphite", Model: json.RawMessage(`{"target":"a.b"}`)}},
+		},
+	}
+	for _, tc := range untranslatable {
+		t.Run(tc.desc+" is not exported", func(t *testing.T) {
+			_, err := toPrometheusRule(&models.AlertRule{Title: "test", Condition: tc.condition, Data: tc.data})
+			require.ErrorIs(t, err, errNotTranslatable)
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package definitions
 
 import (
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/timeinterval"
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 )
 
@@ -14,6 +18,72 @@ import (
 //     Responses:
 //		 200: GettableAlertmanagers
 
+// swagger:route GET /api/v1/ngalert/senders configuration RouteGetSenders
+//
+//  Get the status of the senders of external Alertmanagers of all the organizations that have one, or of the user's organization only if the user is not a Grafana admin.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//		 200: GettableSenders
+
+// swagger:route GET /api/v1/ngalert/dead_letter configuration RouteGetDeadLetterAlerts
+//
+//  Get the alerts of the user's organization that could not be delivered because it had neither a local notifier nor a sender.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//		 200: GettableDeadLetterAlerts
+//		 500: Failure
+
+// swagger:route DELETE /api/v1/ngalert/dead_letter configuration RoutePurgeDeadLetterAlerts
+//
+//  Deletes the alerts of the user's organization that could not be delivered.
+//
+//     Responses:
+//       200: Ack
+//       500: Failure
+
+// swagger:route POST /api/v1/ngalert/dead_letter/redispatch configuration RouteRedispatchDeadLetterAlerts
+//
+//  Delivers again the alerts of the user's organization that could not be delivered, and deletes the ones delivered.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//		 200: RedispatchedDeadLetterAlerts
+//		 500: Failure
+
+// swagger:route GET /api/v1/ngalert/state_history configuration RouteGetAlertStateHistory
+//
+//  Get the state transitions of the alert instances of the rules of the user's organization in the folders the user can read, most recent first.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//		 200: GettableAlertStateHistory
+//		 400: ValidationError
+//		 403: PermissionDenied
+//		 404: Failure
+//		 500: Failure
+
+// swagger:route GET /api/v1/ngalert/delivery_receipts configuration RouteGetDeliveryReceipts
+//
+//  Get the outcomes of sending the alerts of the user's organization to its external Alertmanager(s), most recent first.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//		 200: GettableDeliveryReceipts
+//		 400: ValidationError
+//		 500: Failure
+
 // swagger:route GET /api/v1/ngalert/admin_config configuration RouteGetNGalertConfig
 //
 //  Get the NGalert configuration of the user's organization, returns 404 if no configuration is present.
@@ -48,7 +118,55 @@ import (
 //       200: Ack
 //       500: Failure
 
-// swagger:parameters RoutePostNGalertConfig
+// swagger:route POST /api/v1/ngalert/admin_config/test configuration RouteTestNGalertConfig
+//
+// Sends a test alert to the external Alertmanagers of a NGalert configuration without saving it.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: TestNGalertConfigResult
+//       400: ValidationError
+
+// swagger:route GET /api/v1/ngalert/admin_config/pause configuration RouteGetExternalDeliveryPause
+//
+//  Get whether the delivery of the alerts of the user's organization to its external Alertmanagers is paused.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//		 200: GettableExternalDeliveryPause
+
+// swagger:route POST /api/v1/ngalert/admin_config/pause configuration RoutePauseExternalDelivery
+//
+// Pauses the delivery of the alerts of the user's organization to its external Alertmanagers, for a duration or until it is resumed. The alerts are sent to the internal Alertmanager only and the NGalert configuration is kept.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: Ack
+//       400: ValidationError
+
+// swagger:route DELETE /api/v1/ngalert/admin_config/pause configuration RouteResumeExternalDelivery
+//
+// Resumes the delivery of the alerts of the user's organization to its external Alertmanagers.
+//
+//     Responses:
+//       200: Ack
+
+// swagger:parameters RoutePauseExternalDelivery
+type ExternalDeliveryPause struct {
+	// in:body
+	Body PostableExternalDeliveryPause
+}
+
+// swagger:parameters RoutePostNGalertConfig RouteTestNGalertConfig
 type NGalertConfig struct {
 	// in:body
 	Body PostableNGalertConfig
@@ -65,18 +183,431 @@ const (
 
 // swagger:model
 type PostableNGalertConfig struct {
+	// Alertmanagers are the URLs of the Alertmanagers. The ones whose scheme is prefixed with dnssrv+, dns+ or
+	// file+ are discovered from DNS SRV records, DNS A records or Prometheus file_sd files, e.g.
+	// dnssrv+https://_alertmanager._tcp.example.com.
 	Alertmanagers       []string            `json:"alertmanagers"`
 	AlertmanagersChoice AlertmanagersChoice `json:"alertmanagersChoice"`
+	Disabled            bool                `json:"disabled,omitempty"`
+	// ExternalRuleUIDs are the rules whose alerts are sent to the external Alertmanagers only, whatever
+	// the Alertmanagers choice is.
+	ExternalRuleUIDs []string `json:"externalRuleUids,omitempty"`
+	// AlertmanagersTLS are the TLS configurations used to send alerts to the Alertmanagers, by Alertmanager URL.
+	AlertmanagersTLS map[string]AlertmanagerTLSConfig `json:"alertmanagersTLS,omitempty"`
+	// AlertmanagersCredentials are the credentials used to send alerts to the Alertmanagers, by Alertmanager URL.
+	// The secrets are stored encrypted and never returned, they must be sent again with every update.
+	AlertmanagersCredentials map[string]PostableAlertmanagerCredentials `json:"alertmanagersCredentials,omitempty"`
+	// AlertmanagersProxyURL is the HTTP(S) or SOCKS5 proxy used to send alerts to the Alertmanagers, except the
+	// ones whose host matches AlertmanagersNoProxy: host names, domain names, IP addresses or CIDRs.
+	AlertmanagersProxyURL string   `json:"alertmanagersProxyUrl,omitempty"`
+	AlertmanagersNoProxy  []string `json:"alertmanagersNoProxy,omitempty"`
+	// AlertmanagersProxyURLs are the proxies of particular Alertmanagers, by Alertmanager URL, used instead of
+	// AlertmanagersProxyURL and AlertmanagersNoProxy.
+	AlertmanagersProxyURLs map[string]string `json:"alertmanagersProxyUrls,omitempty"`
+	// AlertmanagersAPIVersions are the versions of the API, v1 or v2, of particular Alertmanagers, by Alertmanager
+	// URL. Alerts are sent to the v2 API of the other ones.
+	AlertmanagersAPIVersions map[string]string `json:"alertmanagersApiVersions,omitempty"`
+	// AlertmanagersHeaders are the static headers sent to particular Alertmanagers, by Alertmanager URL, e.g.
+	// the X-Scope-OrgID header of a multi-tenant Alertmanager. Sensitive headers are credentials instead.
+	AlertmanagersHeaders map[string]map[string]string `json:"alertmanagersHeaders,omitempty"`
+	// AlertmanagersTimeout is the timeout of the requests to the Alertmanagers, e.g. 30s, 10s by default.
+	// AlertmanagersTimeouts are the timeouts of particular Alertmanagers, by Alertmanager URL.
+	AlertmanagersTimeout  string            `json:"alertmanagersTimeout,omitempty"`
+	AlertmanagersTimeouts map[string]string `json:"alertmanagersTimeouts,omitempty"`
+	// AlertmanagersCompressions are the compressions of the alerts sent to particular Alertmanagers, by Alertmanager
+	// URL: gzip, snappy or none. They take precedence over the compression enabled for the organization, if any.
+	AlertmanagersCompressions map[string]string `json:"alertmanagersCompressions,omitempty"`
+	// PagerDuty is the PagerDuty service alerts are sent to directly, through the PagerDuty Events API v2, along
+	// with the Alertmanagers if any.
+	PagerDuty *PostablePagerDutyConfig `json:"pagerDuty,omitempty"`
+	// Webhooks are the HTTP endpoints alerts are posted to directly, along with the Alertmanagers if any.
+	Webhooks []AlertWebhookConfig `json:"webhooks,omitempty"`
+	// AWS is the Amazon SNS topic or SQS queue alerts are published to directly, along with the Alertmanagers if
+	// any.
+	AWS *PostableAWSConfig `json:"aws,omitempty"`
+	// SilenceSync mirrors the silences of Grafana to the Alertmanagers that expose the v2 API if push, and also
+	// the silences of the Alertmanagers to Grafana if bidirectional. Silences are not mirrored by default.
+	SilenceSync string `json:"silenceSync,omitempty"`
+	// Relabel are the relabeling rules of the alerts sent to all the Alertmanagers, PagerDuty, the webhooks and AWS,
+	// e.g. to strip internal labels or personal data. TargetRelabels are the rules of particular targets, applied
+	// after those, by URL of the Alertmanager, webhook, PagerDuty Events API or SQS queue, or by ARN of the SNS
+	// topic.
+	Relabel        *AlertRelabelConfigs           `json:"relabel,omitempty"`
+	TargetRelabels map[string]AlertRelabelConfigs `json:"targetRelabels,omitempty"`
+	// ResolvedAlerts is how the alerts of the rules are resolved when their state is cleared, because the rule was
+	// updated or deleted, right away by default. RuleResolvedAlerts are the ones of particular rules, by rule UID.
+	ResolvedAlerts     *ResolvedAlertsConfig           `json:"resolvedAlerts,omitempty"`
+	RuleResolvedAlerts map[string]ResolvedAlertsConfig `json:"ruleResolvedAlerts,omitempty"`
+	// MuteTimings are when the firing alerts of the rules are not sent to the external Alertmanager(s), whatever
+	// the mute timings of the Alertmanager(s). RuleMuteTimings are the ones of particular rules, by rule UID.
+	MuteTimings     *MuteTimingsConfig           `json:"muteTimings,omitempty"`
+	RuleMuteTimings map[string]MuteTimingsConfig `json:"ruleMuteTimings,omitempty"`
+	// Grouping is how the alerts of a rule are grouped before being sent to the external Alertmanager(s), each
+	// group being sent as a single alert. The alerts are sent as they are by default.
+	Grouping *AlertGroupingConfig `json:"grouping,omitempty"`
+}
+
+// ResolvedAlertsConfig is how the alerts of a rule are resolved when its state is cleared.
+type ResolvedAlertsConfig struct {
+	// Mode is immediate, the default, grace_window to end the alerts a grace window after the state is cleared,
+	// or suppress to let the Alertmanagers resolve the alerts once they expire.
+	Mode string `json:"mode,omitempty"`
+	// GraceWindow is the grace window of the grace_window mode, e.g. 5m.
+	GraceWindow string `json:"graceWindow,omitempty"`
+}
+
+// MuteTimingsConfig are the time windows during which the firing alerts of a rule are not sent to the external
+// Alertmanager(s).
+type MuteTimingsConfig struct {
+	// TimeIntervals are calendar-based time windows, like the mute time intervals of the Alertmanager.
+	TimeIntervals []timeinterval.TimeInterval `json:"timeIntervals,omitempty"`
+	// Location is the time zone of the time intervals, e.g. Europe/Paris, UTC if empty.
+	Location string `json:"location,omitempty"`
+}
+
+// AlertGroupingConfig groups the alerts of a rule sent to the external Alertmanager(s) by some of their labels.
+// The alert of a group has them, along with the alert name and the internal labels of the rule, and counts the
+// alerts of the group.
+type AlertGroupingConfig struct {
+	// By are the labels the alerts are grouped by.
+	By []string `json:"by,omitempty"`
+	// Examples is the number of alerts of a group whose labels are listed, 3 by default.
+	Examples int `json:"examples,omitempty"`
+}
+
+// AlertRelabelConfigs are the relabeling rules of the labels and of the annotations of the alerts sent to
+// external targets. Alerts dropped by a rule are not sent.
+type AlertRelabelConfigs struct {
+	Labels      []RelabelConfig `json:"labels,omitempty"`
+	Annotations []RelabelConfig `json:"annotations,omitempty"`
+}
+
+// RelabelConfig is a relabeling rule with the semantics of the relabel_config of Prometheus, e.g. the labeldrop
+// action with the regex datasource_uid|folder_id. The defaults of Prometheus apply to the fields that are not set.
+type RelabelConfig struct {
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+	Separator    string   `json:"separator,omitempty"`
+	Regex        string   `json:"regex,omitempty"`
+	Modulus      uint64   `json:"modulus,omitempty"`
+	TargetLabel  string   `json:"targetLabel,omitempty"`
+	Replacement  *string  `json:"replacement,omitempty"`
+	// Action is replace, keep, drop, hashmod, labelmap, labeldrop or labelkeep, replace by default.
+	Action string `json:"action,omitempty"`
+}
+
+// AlertWebhookConfig is an HTTP endpoint alerts are posted to directly.
+type AlertWebhookConfig struct {
+	URL string `json:"url"`
+	// Template is the Go template of the body posted, executed with the alerts as .Alerts, e.g.
+	// {{ range .Alerts }}{{ .Labels.alertname }} {{ end }}. The json function encodes a value as JSON. The body is
+	// the JSON array of the alerts, like the one sent to an Alertmanager, if empty.
+	Template string `json:"template,omitempty"`
+	// ContentType is the content type of the body posted, application/json by default.
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// PostablePagerDutyConfig is the configuration used to send alerts to the PagerDuty Events API v2 directly.
+type PostablePagerDutyConfig struct {
+	// URL is the URL of the Events API, https://events.pagerduty.com/v2/enqueue by default.
+	URL string `json:"url,omitempty"`
+	// Severity is the severity of the events of the alerts without a severity label: critical, error, warning
+	// or info, critical by default.
+	Severity string `json:"severity,omitempty"`
+	// RoutingKey is the integration key of the PagerDuty service. It is stored encrypted and never returned, it
+	// must be sent again with every update.
+	RoutingKey string `json:"routingKey"`
+}
+
+// GettablePagerDutyConfig is the configuration used to send alerts to the PagerDuty Events API v2 directly,
+// without its routing key.
+type GettablePagerDutyConfig struct {
+	URL      string `json:"url,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	// SecureFields are the secrets that are set: routingKey.
+	SecureFields map[string]bool `json:"secureFields"`
+}
+
+// PostableAWSConfig is the configuration used to publish alerts to an Amazon SNS topic or SQS queue directly,
+// one JSON message per alert like the alerts sent to an Alertmanager, with the status and alertname message
+// attributes.
+type PostableAWSConfig struct {
+	// Region is the AWS region of the topic or queue.
+	Region string `json:"region"`
+	// TopicARN is the ARN of the SNS topic and QueueURL the URL of the SQS queue alerts are published to, only
+	// one of them must be set.
+	TopicARN string `json:"topicArn,omitempty"`
+	QueueURL string `json:"queueUrl,omitempty"`
+	// AssumeRoleARN is the ARN of the IAM role assumed to publish the alerts, with the external ID if the trust
+	// policy of the role requires one.
+	AssumeRoleARN string `json:"assumeRoleArn,omitempty"`
+	ExternalID    string `json:"externalId,omitempty"`
+	// Endpoint is the URL of the SNS or SQS API used instead of the one of the region, e.g. a VPC endpoint.
+	Endpoint string `json:"endpoint,omitempty"`
+	// AccessKey and SecretKey are static credentials, the default credentials of the Grafana server are used
+	// if they are not set. They are stored encrypted and never returned, they must be sent again with every
+	// update.
+	AccessKey string `json:"accessKey,omitempty"`
+	SecretKey string `json:"secretKey,omitempty"`
+}
+
+// GettableAWSConfig is the configuration used to publish alerts to an Amazon SNS topic or SQS queue directly,
+// without its credentials.
+type GettableAWSConfig struct {
+	Region        string `json:"region"`
+	TopicARN      string `json:"topicArn,omitempty"`
+	QueueURL      string `json:"queueUrl,omitempty"`
+	AssumeRoleARN string `json:"assumeRoleArn,omitempty"`
+	ExternalID    string `json:"externalId,omitempty"`
+	Endpoint      string `json:"endpoint,omitempty"`
+	// SecureFields are the secrets that are set: accessKey and secretKey.
+	SecureFields map[string]bool `json:"secureFields"`
+}
+
+// PostableAlertmanagerCredentials are the credentials used to send alerts to an external Alertmanager.
+type PostableAlertmanagerCredentials struct {
+	BasicAuthUser     string `json:"basicAuthUser,omitempty"`
+	BasicAuthPassword string `json:"basicAuthPassword,omitempty"`
+	BearerToken       string `json:"bearerToken,omitempty"`
+	// Headers are sent with the alerts, e.g. to pass an API key.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// GettableAlertmanagerCredentials are the credentials used to send alerts to an external Alertmanager, without
+// their secrets.
+type GettableAlertmanagerCredentials struct {
+	BasicAuthUser string `json:"basicAuthUser,omitempty"`
+	// SecureFields are the secrets that are set: basicAuthPassword, bearerToken, or header. followed by the
+	// name of a header.
+	SecureFields map[string]bool `json:"secureFields"`
+}
+
+// AlertmanagerTLSConfig is the TLS configuration used to send alerts to an external Alertmanager. The files
+// are read from the Grafana server.
+type AlertmanagerTLSConfig struct {
+	// CAFile is the CA bundle used to verify the certificate of the Alertmanager.
+	CAFile string `json:"caFile,omitempty"`
+	// CertFile is the client certificate presented to the Alertmanager.
+	CertFile string `json:"certFile,omitempty"`
+	// KeyFile is the key of the client certificate.
+	KeyFile string `json:"keyFile,omitempty"`
+	// InsecureSkipVerify disables the verification of the certificate of the Alertmanager.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
 }
 
 // swagger:model
 type GettableNGalertConfig struct {
-	Alertmanagers       []string            `json:"alertmanagers"`
-	AlertmanagersChoice AlertmanagersChoice `json:"alertmanagersChoice"`
+	Alertmanagers             []string                                   `json:"alertmanagers"`
+	AlertmanagersChoice       AlertmanagersChoice                        `json:"alertmanagersChoice"`
+	Disabled                  bool                                       `json:"disabled"`
+	ExternalRuleUIDs          []string                                   `json:"externalRuleUids,omitempty"`
+	AlertmanagersTLS          map[string]AlertmanagerTLSConfig           `json:"alertmanagersTLS,omitempty"`
+	AlertmanagersCredentials  map[string]GettableAlertmanagerCredentials `json:"alertmanagersCredentials,omitempty"`
+	AlertmanagersProxyURL     string                                     `json:"alertmanagersProxyUrl,omitempty"`
+	AlertmanagersNoProxy      []string                                   `json:"alertmanagersNoProxy,omitempty"`
+	AlertmanagersProxyURLs    map[string]string                          `json:"alertmanagersProxyUrls,omitempty"`
+	AlertmanagersAPIVersions  map[string]string                          `json:"alertmanagersApiVersions,omitempty"`
+	AlertmanagersHeaders      map[string]map[string]string               `json:"alertmanagersHeaders,omitempty"`
+	AlertmanagersTimeout      string                                     `json:"alertmanagersTimeout,omitempty"`
+	AlertmanagersTimeouts     map[string]string                          `json:"alertmanagersTimeouts,omitempty"`
+	AlertmanagersCompressions map[string]string                          `json:"alertmanagersCompressions,omitempty"`
+	PagerDuty                 *GettablePagerDutyConfig                   `json:"pagerDuty,omitempty"`
+	Webhooks                  []AlertWebhookConfig                       `json:"webhooks,omitempty"`
+	AWS                       *GettableAWSConfig                         `json:"aws,omitempty"`
+	SilenceSync               string                                     `json:"silenceSync,omitempty"`
+	Relabel                   *AlertRelabelConfigs                       `json:"relabel,omitempty"`
+	TargetRelabels            map[string]AlertRelabelConfigs             `json:"targetRelabels,omitempty"`
+	ResolvedAlerts            *ResolvedAlertsConfig                      `json:"resolvedAlerts,omitempty"`
+	RuleResolvedAlerts        map[string]ResolvedAlertsConfig            `json:"ruleResolvedAlerts,omitempty"`
+	MuteTimings               *MuteTimingsConfig                         `json:"muteTimings,omitempty"`
+	RuleMuteTimings           map[string]MuteTimingsConfig               `json:"ruleMuteTimings,omitempty"`
+	Grouping                  *AlertGroupingConfig                       `json:"grouping,omitempty"`
+}
+
+// swagger:model
+type TestNGalertConfigResult struct {
+	Results []AlertmanagerTestResult `json:"results"`
+}
+
+// AlertmanagerTestResult is the outcome of sending a test alert to an external Alertmanager.
+type AlertmanagerTestResult struct {
+	URL string `json:"url"`
+	// Success is true if the Alertmanager accepted the test alert.
+	Success bool `json:"success"`
+	// StatusCode is the HTTP status code returned by the Alertmanager, omitted if no response was received.
+	StatusCode int `json:"statusCode,omitempty"`
+	// Latency is how long the Alertmanager took to answer.
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
 }
 
 // swagger:model
 type GettableAlertmanagers struct {
 	Status string                 `json:"status"`
 	Data   v1.AlertManagersResult `json:"data"`
+	// Unhealthy is true when the external Alertmanager(s) failed consecutively too many times.
+	Unhealthy bool `json:"unhealthy"`
+	// Inconsistencies are the reasons why the configuration is logically inconsistent, e.g. Alertmanagers
+	// that are configured but never used.
+	Inconsistencies []string `json:"inconsistencies,omitempty"`
+	// PendingAlertmanagersChoice is a change of the Alertmanagers choice not applied yet.
+	PendingAlertmanagersChoice *PendingAlertmanagersChoice `json:"pendingAlertmanagersChoice,omitempty"`
+}
+
+// swagger:model
+type GettableSenders struct {
+	Senders []GettableSender `json:"senders"`
+}
+
+// GettableSender is the status of the sender of external Alertmanagers of an organization.
+type GettableSender struct {
+	OrgID               int64               `json:"orgId"`
+	AlertmanagersChoice AlertmanagersChoice `json:"alertmanagersChoice"`
+	// ActiveAlertmanagers are the discovered Alertmanagers, DroppedAlertmanagers the ones no longer used.
+	ActiveAlertmanagers  []string `json:"activeAlertmanagers"`
+	DroppedAlertmanagers []string `json:"droppedAlertmanagers"`
+	// LastSuccessfulDelivery is the last time an Alertmanager accepted alerts of the organization, omitted if
+	// none did yet.
+	LastSuccessfulDelivery *time.Time `json:"lastSuccessfulDelivery,omitempty"`
+	// ConfigHash is the hash of the admin configuration applied to the sender.
+	ConfigHash string `json:"configHash"`
+}
+
+// swagger:model
+type GettableDeadLetterAlerts struct {
+	Alerts []GettableDeadLetterAlert `json:"alerts"`
+}
+
+// GettableDeadLetterAlert is an alert that could not be delivered, only its latest version is kept.
+type GettableDeadLetterAlert struct {
+	ID        int64              `json:"id"`
+	RuleUID   string             `json:"ruleUid"`
+	Alert     amv2.PostableAlert `json:"alert"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+// swagger:model
+type RedispatchedDeadLetterAlerts struct {
+	// Redispatched is the number of alerts delivered.
+	Redispatched int `json:"redispatched"`
+}
+
+// swagger:parameters RouteGetAlertStateHistory
+type AlertStateHistoryParams struct {
+	// UID of the rule whose transitions are returned
+	// in: query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+
+	// Labels of the alert instance whose transitions are returned, as name=value pairs
+	// in: query
+	// required: false
+	Labels []string `json:"labels"`
+
+	// Earliest time of the transitions returned, in milliseconds since the epoch
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+
+	// Time the transitions returned are before, in milliseconds since the epoch
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+
+	// Most transitions returned
+	// in: query
+	// required: false
+	// default: 100
+	// maximum: 1000
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type GettableAlertStateHistory struct {
+	Transitions []GettableAlertStateTransition `json:"transitions"`
+}
+
+// GettableAlertStateTransition is a change of the state, or of the reason of the state, of an alert instance.
+type GettableAlertStateTransition struct {
+	RuleUID        string            `json:"ruleUid"`
+	Labels         map[string]string `json:"labels"`
+	PreviousState  string            `json:"previousState"`
+	PreviousReason string            `json:"previousReason,omitempty"`
+	State          string            `json:"state"`
+	Reason         string            `json:"reason,omitempty"`
+	Timestamp      time.Time         `json:"timestamp"`
+	// RuleVersion is the version of the rule whose evaluation changed the state, if known.
+	RuleVersion int64 `json:"ruleVersion,omitempty"`
+}
+
+// swagger:parameters RouteGetDeliveryReceipts
+type DeliveryReceiptsParams struct {
+	// UID of the rule whose alerts' receipts are returned
+	// in: query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+
+	// Labels of the alert whose receipts are returned, as name=value pairs
+	// in: query
+	// required: false
+	Labels []string `json:"labels"`
+
+	// ID of the batch of alerts whose receipts are returned
+	// in: query
+	// required: false
+	BatchID string `json:"batchID"`
+
+	// Earliest time of the receipts returned, in milliseconds since the epoch
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+
+	// Time the receipts returned are before, in milliseconds since the epoch
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+
+	// Most receipts returned
+	// in: query
+	// required: false
+	// default: 100
+	// maximum: 1000
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type GettableDeliveryReceipts struct {
+	Receipts []GettableDeliveryReceipt `json:"receipts"`
+}
+
+// GettableDeliveryReceipt is the outcome of sending an alert to an external Alertmanager.
+type GettableDeliveryReceipt struct {
+	// BatchID identifies the alerts sent together. A batch sent again, or to several targets, keeps its ID.
+	BatchID    string            `json:"batchId"`
+	Target     string            `json:"target"`
+	RuleUID    string            `json:"ruleUid"`
+	Labels     map[string]string `json:"labels"`
+	Delivered  bool              `json:"delivered"`
+	StatusCode int               `json:"statusCode,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Attempts   int               `json:"attempts"`
+	Timestamp  time.Time         `json:"timestamp"`
+}
+
+// swagger:model
+type PostableExternalDeliveryPause struct {
+	// Duration is how long the delivery is paused, e.g. 1h. It is paused until it is resumed if empty.
+	Duration string `json:"duration,omitempty"`
+}
+
+// swagger:model
+type GettableExternalDeliveryPause struct {
+	Paused bool `json:"paused"`
+	// Until is when the delivery resumes, omitted if it is paused until it is resumed.
+	Until *time.Time `json:"until,omitempty"`
+}
+
+type PendingAlertmanagersChoice struct {
+	AlertmanagersChoice AlertmanagersChoice `json:"alertmanagersChoice"`
+	// Since is when the change was first seen.
+	Since time.Time `json:"since"`
 }
@@ -149,6 +149,18 @@ import (
 //       408: Failure
 //       409: AlertManagerNotReady
 
+// swagger:route POST /api/alertmanager/grafana/config/api/v1/templates/test alertmanager RoutePostTestGrafanaTemplates
+//
+// Render Grafana notification templates for every type of contact point without saving them.
+//
+//     Responses:
+//
+//       200: TestTemplatesResults
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: AlertManagerNotFound
+//       409: AlertManagerNotReady
+
 // swagger:route POST /api/alertmanager/{DatasourceUID}/config/api/v1/receivers/test alertmanager RoutePostTestReceivers
 //
 // Test Grafana managed receivers without saving them.
@@ -257,6 +269,8 @@ func (c *TestReceiversConfigBodyParams) ProcessConfig(encrypt EncryptFn) error {
 type TestReceiversConfigAlertParams struct {
 	Annotations model.LabelSet `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 	Labels      model.LabelSet `yaml:"labels,omitempty" json:"labels,omitempty"`
+	// Values are the values of the test alert by RefID, available to the templates as .Values.
+	Values map[string]float64 `yaml:"values,omitempty" json:"values,omitempty"`
 }
 
 // swagger:model
@@ -280,6 +294,38 @@ type TestReceiverConfigResult struct {
 	Error  string `json:"error,omitempty"`
 }
 
+// swagger:parameters RoutePostTestGrafanaTemplates
+type TestTemplatesConfigParams struct {
+	// in:body
+	Body TestTemplatesConfigBodyParams
+}
+
+type TestTemplatesConfigBodyParams struct {
+	// Template defines the templates to test, along with the templates of the configuration.
+	Template string `json:"template"`
+	// Alerts are the alerts the templates are rendered for, e.g. alerts sent before. A test alert is used if
+	// there is none.
+	Alerts []amv2.PostableAlert `json:"alerts,omitempty"`
+	// Title and Message replace the default title and message of the contact points, e.g.
+	// {{ template "mytitle" . }}.
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// swagger:model
+type TestTemplatesResults struct {
+	Results []TestTemplatesResult `json:"results"`
+}
+
+// swagger:model
+type TestTemplatesResult struct {
+	// Type is the type of contact point.
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
 // swagger:parameters RouteCreateSilence RouteCreateGrafanaSilence
 type CreateSilenceParams struct {
 	// in:body
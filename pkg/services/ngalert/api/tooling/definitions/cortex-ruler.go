@@ -122,6 +122,64 @@ import (
 //     Responses:
 //       202: Ack
 
+// swagger:route Get /api/ruler/grafana/api/v1/export/{Namespace} ruler RouteGetNamespaceGrafanaRulesExport
+//
+// Export the rule groups of a namespace as a Prometheus rule file. The rules whose queries and expressions
+// cannot be translated to a PromQL or LogQL expression are not exported, they are listed in comments.
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: PrometheusRuleFile
+
+// swagger:route POST /api/ruler/grafana/api/v1/import/{Namespace} ruler RoutePostNamespaceGrafanaRulesImport
+//
+// Import the rule groups of a Prometheus rule file in a namespace, the rules query the data source. The rule
+// groups of the namespace with the name of an imported group are replaced.
+//
+//     Consumes:
+//     - application/yaml
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+
+// swagger:parameters RouteGetNamespaceGrafanaRulesExport
+type PathNamespaceExportConfig struct {
+	// in: path
+	Namespace string
+}
+
+// swagger:parameters RoutePostNamespaceGrafanaRulesImport
+type NamespaceImportConfig struct {
+	// in: path
+	Namespace string
+	// The UID of the Prometheus or Loki data source queried by the rules.
+	// in: query
+	// required: true
+	DatasourceUID string `json:"datasourceUid"`
+	// The UID of the Prometheus data source the recording rules write to, the queried data source if empty.
+	// in: query
+	// required: false
+	TargetDatasourceUID string `json:"targetDatasourceUid"`
+	// in: body
+	Body PrometheusRuleFile
+}
+
+// PrometheusRuleFile is a Prometheus rule file.
+// swagger:model
+type PrometheusRuleFile struct {
+	Groups []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+// PrometheusRuleGroup is a rule group of a Prometheus rule file.
+type PrometheusRuleGroup struct {
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Rules    []ApiRuleNode  `yaml:"rules" json:"rules"`
+}
+
 // swagger:parameters RoutePostNameRulesConfig RoutePostNameGrafanaRulesConfig
 type NamespaceConfig struct {
 	// in:path
@@ -368,6 +426,16 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	Record       *models.Record      `json:"record,omitempty" yaml:"record,omitempty"`
+	// NotificationSettings routes the alerts of the rule to a contact point instead of the notification policies.
+	NotificationSettings *models.NotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
+	// AlignEvaluation evaluates the rule at multiples of its interval, even if the evaluations of the rules are
+	// jittered.
+	AlignEvaluation bool `json:"align_evaluation,omitempty" yaml:"align_evaluation,omitempty"`
+	// EvaluationTimeout overrides the evaluation timeout of the configuration for the rule, 0 to use it.
+	EvaluationTimeout model.Duration `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty"`
+	// Dependencies are the rules the rule depends on: its firing alerts are inhibited while one of them fires.
+	Dependencies []models.RuleDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
 }
 
 // swagger:model
@@ -387,4 +455,14 @@ type GettableGrafanaRule struct {
 	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
 	Provenance      models.Provenance   `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+	Record          *models.Record      `json:"record,omitempty" yaml:"record,omitempty"`
+	// NotificationSettings routes the alerts of the rule to a contact point instead of the notification policies.
+	NotificationSettings *models.NotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
+	// AlignEvaluation evaluates the rule at multiples of its interval, even if the evaluations of the rules are
+	// jittered.
+	AlignEvaluation bool `json:"align_evaluation,omitempty" yaml:"align_evaluation,omitempty"`
+	// EvaluationTimeout overrides the evaluation timeout of the configuration for the rule, 0 to use it.
+	EvaluationTimeout model.Duration `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty"`
+	// Dependencies are the rules the rule depends on: its firing alerts are inhibited while one of them fires.
+	Dependencies []models.RuleDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
 }
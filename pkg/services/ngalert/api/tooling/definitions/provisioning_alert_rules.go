@@ -1,9 +1,11 @@
 package definitions
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util/cmputil"
 )
 
 // swagger:route GET /api/v1/provisioning/alert-rules/{UID} provisioning stable RouteGetAlertRule
@@ -41,7 +43,60 @@ import (
 //       204: description: The alert rule was deleted successfully.
 //       400: ValidationError
 
-// swagger:parameters RouteGetAlertRule RoutePutAlertRule RouteDeleteAlertRule
+// swagger:route POST /api/v1/provisioning/alert-rules/batch provisioning stable RoutePostAlertRulesBatch
+//
+// Create, update, delete, pause and resume many alert rules in one transaction. If some operations cannot be
+// applied, none is and the response tells why.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: AlertRulesBatchResults
+//       400: AlertRulesBatchResults
+
+// swagger:route POST /api/v1/provisioning/alert-rules/{UID}/pause provisioning stable RoutePostAlertRulePause
+//
+// Pause the evaluation of an alert rule, its alerts are resolved.
+//
+//     Responses:
+//       204: description: The alert rule was paused successfully.
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/alert-rules/{UID}/resume provisioning stable RoutePostAlertRuleResume
+//
+// Resume the evaluation of a paused alert rule.
+//
+//     Responses:
+//       204: description: The alert rule was resumed successfully.
+//       404: description: Not found.
+
+// swagger:route GET /api/v1/provisioning/alert-rules/{UID}/versions provisioning stable RouteGetAlertRuleVersions
+//
+// Get the versions of an alert rule, most recent first.
+//
+//     Responses:
+//       200: AlertRuleVersions
+//       404: description: Not found.
+
+// swagger:route GET /api/v1/provisioning/alert-rules/{UID}/versions/diff provisioning stable RouteGetAlertRuleVersionsDiff
+//
+// Get the changes of an alert rule from one of its versions to another.
+//
+//     Responses:
+//       200: AlertRuleVersionDiff
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/alert-rules/{UID}/versions/{Version}/restore provisioning stable RoutePostAlertRuleVersionRestore
+//
+// Restore a version of an alert rule, as a new version. The rule stays in its folder and rule group.
+//
+//     Responses:
+//       200: AlertRule
+//       404: description: Not found.
+
+// swagger:parameters RouteGetAlertRule RoutePutAlertRule RouteDeleteAlertRule RoutePostAlertRulePause RoutePostAlertRuleResume RouteGetAlertRuleVersions RouteGetAlertRuleVersionsDiff RoutePostAlertRuleVersionRestore
 type AlertRuleUIDReference struct {
 	// in:path
 	UID string
@@ -69,47 +124,168 @@ type AlertRule struct {
 	Annotations  map[string]string          `json:"annotations,omitempty"`
 	Labels       map[string]string          `json:"labels,omitempty"`
 	Provenance   models.Provenance          `json:"provenance,omitempty"`
+	// IsPaused stops the evaluation of the rule.
+	IsPaused bool `json:"isPaused"`
+	// AlignEvaluation evaluates the rule at multiples of its interval, even if the evaluations of the rules are
+	// jittered.
+	AlignEvaluation bool `json:"alignEvaluation"`
+	// EvaluationTimeout overrides the evaluation timeout of the configuration for the rule, 0 to use it.
+	EvaluationTimeout time.Duration `json:"evaluationTimeout,omitempty"`
+	// Dependencies are the rules the rule depends on: its firing alerts are inhibited while one of them fires.
+	Dependencies []models.RuleDependency `json:"dependencies,omitempty"`
 }
 
 func (a *AlertRule) UpstreamModel() models.AlertRule {
 	return models.AlertRule{
-		ID:           a.ID,
-		UID:          a.UID,
-		OrgID:        a.OrgID,
-		NamespaceUID: a.FolderUID,
-		RuleGroup:    a.RuleGroup,
-		Title:        a.Title,
-		Condition:    a.Condition,
-		Data:         a.Data,
-		Updated:      a.Updated,
-		NoDataState:  a.NoDataState,
-		ExecErrState: a.ExecErrState,
-		For:          a.For,
-		Annotations:  a.Annotations,
-		Labels:       a.Labels,
+		ID:                a.ID,
+		UID:               a.UID,
+		OrgID:             a.OrgID,
+		NamespaceUID:      a.FolderUID,
+		RuleGroup:         a.RuleGroup,
+		Title:             a.Title,
+		Condition:         a.Condition,
+		Data:              a.Data,
+		Updated:           a.Updated,
+		NoDataState:       a.NoDataState,
+		ExecErrState:      a.ExecErrState,
+		For:               a.For,
+		Annotations:       a.Annotations,
+		Labels:            a.Labels,
+		IsPaused:          a.IsPaused,
+		AlignEvaluation:   a.AlignEvaluation,
+		EvaluationTimeout: a.EvaluationTimeout,
+		Dependencies:      a.Dependencies,
 	}
 }
 
 func NewAlertRule(rule models.AlertRule, provenance models.Provenance) AlertRule {
 	return AlertRule{
-		ID:           rule.ID,
-		UID:          rule.UID,
-		OrgID:        rule.OrgID,
-		FolderUID:    rule.NamespaceUID,
-		RuleGroup:    rule.RuleGroup,
-		Title:        rule.Title,
-		For:          rule.For,
-		Condition:    rule.Condition,
-		Data:         rule.Data,
-		Updated:      rule.Updated,
-		NoDataState:  rule.NoDataState,
-		ExecErrState: rule.ExecErrState,
-		Annotations:  rule.Annotations,
-		Labels:       rule.Labels,
-		Provenance:   provenance,
+		ID:                rule.ID,
+		UID:               rule.UID,
+		OrgID:             rule.OrgID,
+		FolderUID:         rule.NamespaceUID,
+		RuleGroup:         rule.RuleGroup,
+		Title:             rule.Title,
+		For:               rule.For,
+		Condition:         rule.Condition,
+		Data:              rule.Data,
+		Updated:           rule.Updated,
+		NoDataState:       rule.NoDataState,
+		ExecErrState:      rule.ExecErrState,
+		Annotations:       rule.Annotations,
+		Labels:            rule.Labels,
+		Provenance:        provenance,
+		IsPaused:          rule.IsPaused,
+		AlignEvaluation:   rule.AlignEvaluation,
+		EvaluationTimeout: rule.EvaluationTimeout,
+		Dependencies:      rule.Dependencies,
 	}
 }
 
+// swagger:parameters RouteGetAlertRuleVersionsDiff
+type AlertRuleVersionsDiffParams struct {
+	// Version the changes are from
+	// in: query
+	// required: true
+	From int64 `json:"from"`
+	// Version the changes are to
+	// in: query
+	// required: true
+	To int64 `json:"to"`
+}
+
+// swagger:parameters RoutePostAlertRuleVersionRestore
+type AlertRuleVersionReference struct {
+	// in:path
+	Version int64
+}
+
+// swagger:model
+type AlertRuleVersions []AlertRuleVersion
+
+type AlertRuleVersion struct {
+	Version int64 `json:"version"`
+	// ParentVersion is the version the version was made from, 0 for the first one.
+	ParentVersion int64 `json:"parentVersion"`
+	// RestoredFrom is the version the version restores, if it does.
+	RestoredFrom int64     `json:"restoredFrom,omitempty"`
+	Created      time.Time `json:"created"`
+	// Rule is the rule as defined at the version.
+	Rule AlertRule `json:"rule"`
+}
+
+func NewAlertRuleVersion(v *models.AlertRuleVersion) AlertRuleVersion {
+	return AlertRuleVersion{
+		Version:       v.Version,
+		ParentVersion: v.ParentVersion,
+		RestoredFrom:  v.RestoredFrom,
+		Created:       v.Created,
+		Rule:          NewAlertRule(v.AlertRule(), ""),
+	}
+}
+
+// swagger:model
+type AlertRuleVersionDiff struct {
+	From    int64                    `json:"from"`
+	To      int64                    `json:"to"`
+	Changes []AlertRuleVersionChange `json:"changes"`
+}
+
+type AlertRuleVersionChange struct {
+	// Path is the field of the rule changed, such as Labels[severity].
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+func NewAlertRuleVersionDiff(from, to int64, report cmputil.DiffReport) AlertRuleVersionDiff {
+	value := func(v reflect.Value) interface{} {
+		if !v.IsValid() || !v.CanInterface() {
+			return nil
+		}
+		return v.Interface()
+	}
+	changes := make([]AlertRuleVersionChange, 0, len(report))
+	for _, d := range report {
+		changes = append(changes, AlertRuleVersionChange{Path: d.Path, From: value(d.Left), To: value(d.Right)})
+	}
+	return AlertRuleVersionDiff{From: from, To: to, Changes: changes}
+}
+
+// swagger:parameters RoutePostAlertRulesBatch
+type AlertRulesBatchPayload struct {
+	// in:body
+	Body AlertRulesBatch
+}
+
+// swagger:model
+type AlertRulesBatch struct {
+	// Create are the rules created, a UID is generated for the ones without.
+	Create []AlertRule `json:"create,omitempty"`
+	// Update are the rules updated, identified by UID.
+	Update []AlertRule `json:"update,omitempty"`
+	// Delete, Pause and Resume are the UIDs of the rules deleted, paused and resumed.
+	Delete []string `json:"delete,omitempty"`
+	Pause  []string `json:"pause,omitempty"`
+	Resume []string `json:"resume,omitempty"`
+}
+
+// swagger:model
+type AlertRulesBatchResults struct {
+	// Applied tells whether the operations were applied, none is if some cannot be.
+	Applied bool                    `json:"applied"`
+	Results []AlertRulesBatchResult `json:"results"`
+}
+
+type AlertRulesBatchResult struct {
+	// Operation is one of create, update, delete, pause and resume.
+	Operation string `json:"operation"`
+	UID       string `json:"uid"`
+	Title     string `json:"title,omitempty"`
+	// Error is why the operation cannot be applied.
+	Error string `json:"error,omitempty"`
+}
+
 // swagger:route PUT /api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning stable RoutePutAlertRuleGroup
 //
 // Update the interval of a rule group.
@@ -121,13 +297,29 @@ func NewAlertRule(rule models.AlertRule, provenance models.Provenance) AlertRule
 //       200: AlertRuleGroup
 //       400: ValidationError
 
-// swagger:parameters RoutePutAlertRuleGroup
+// swagger:route POST /api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/pause provisioning stable RoutePostAlertRuleGroupPause
+//
+// Pause the evaluation of all the alert rules of a rule group, their alerts are resolved.
+//
+//     Responses:
+//       204: description: The rule group was paused successfully.
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}/resume provisioning stable RoutePostAlertRuleGroupResume
+//
+// Resume the evaluation of all the alert rules of a rule group.
+//
+//     Responses:
+//       204: description: The rule group was resumed successfully.
+//       404: description: Not found.
+
+// swagger:parameters RoutePutAlertRuleGroup RoutePostAlertRuleGroupPause RoutePostAlertRuleGroupResume
 type FolderUIDPathParam struct {
 	// in:path
 	FolderUID string `json:"FolderUID"`
 }
 
-// swagger:parameters RoutePutAlertRuleGroup
+// swagger:parameters RoutePutAlertRuleGroup RoutePostAlertRuleGroupPause RoutePostAlertRuleGroupResume
 type RuleGroupPathParam struct {
 	// in:path
 	Group string `json:"Group"`
@@ -0,0 +1,139 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/v1/provisioning/maintenance-windows provisioning stable RouteGetMaintenanceWindows
+//
+// Get all the maintenance windows.
+//
+//     Responses:
+//       200: MaintenanceWindows
+
+// swagger:route GET /api/v1/provisioning/maintenance-windows/{UID} provisioning stable RouteGetMaintenanceWindow
+//
+// Get a maintenance window.
+//
+//     Responses:
+//       200: MaintenanceWindow
+//       404: description: Not found.
+
+// swagger:route POST /api/v1/provisioning/maintenance-windows provisioning stable RoutePostMaintenanceWindow
+//
+// Create a new maintenance window.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: MaintenanceWindow
+//       400: ValidationError
+
+// swagger:route PUT /api/v1/provisioning/maintenance-windows/{UID} provisioning stable RoutePutMaintenanceWindow
+//
+// Replace an existing maintenance window.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: MaintenanceWindow
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /api/v1/provisioning/maintenance-windows/{UID} provisioning stable RouteDeleteMaintenanceWindow
+//
+// Delete a maintenance window, along with the record of the alerts it suppressed.
+//
+//     Responses:
+//       204: description: The maintenance window was deleted successfully.
+//       404: description: Not found.
+
+// swagger:route GET /api/v1/provisioning/maintenance-windows/{UID}/suppressed-alerts provisioning stable RouteGetMaintenanceWindowSuppressedAlerts
+//
+// Get the alerts suppressed by a maintenance window, most recently suppressed first.
+//
+//     Responses:
+//       200: MaintenanceSuppressedAlerts
+//       404: description: Not found.
+
+// swagger:parameters RouteGetMaintenanceWindow RoutePutMaintenanceWindow RouteDeleteMaintenanceWindow RouteGetMaintenanceWindowSuppressedAlerts
+type MaintenanceWindowUIDReference struct {
+	// in:path
+	UID string
+}
+
+// swagger:parameters RoutePostMaintenanceWindow RoutePutMaintenanceWindow
+type MaintenanceWindowPayload struct {
+	// in:body
+	Body MaintenanceWindow
+}
+
+// swagger:model
+type MaintenanceWindows []MaintenanceWindow
+
+// swagger:model
+type MaintenanceWindow struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	// StartsAt is when the window starts, included, and EndsAt when it ends, excluded.
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+	// Matchers are the label matchers of the firing alerts suppressed during the window, such as
+	// severity="critical". All the alerts of the organization are suppressed if there is none.
+	Matchers []string `json:"matchers,omitempty"`
+	// SkipEvaluation also stops the evaluation of the rules whose labels, with the alertname and
+	// __alert_rule_uid__ labels, match during the window.
+	SkipEvaluation bool      `json:"skipEvaluation"`
+	Updated        time.Time `json:"updated,omitempty"`
+}
+
+func (w *MaintenanceWindow) UpstreamModel(orgID int64) models.MaintenanceWindow {
+	return models.MaintenanceWindow{
+		OrgID:          orgID,
+		UID:            w.UID,
+		Title:          w.Title,
+		StartsAt:       w.StartsAt,
+		EndsAt:         w.EndsAt,
+		Matchers:       w.Matchers,
+		SkipEvaluation: w.SkipEvaluation,
+	}
+}
+
+func NewMaintenanceWindow(w *models.MaintenanceWindow) MaintenanceWindow {
+	return MaintenanceWindow{
+		UID:            w.UID,
+		Title:          w.Title,
+		StartsAt:       w.StartsAt,
+		EndsAt:         w.EndsAt,
+		Matchers:       w.Matchers,
+		SkipEvaluation: w.SkipEvaluation,
+		Updated:        w.Updated,
+	}
+}
+
+// swagger:model
+type MaintenanceSuppressedAlerts []MaintenanceSuppressedAlert
+
+type MaintenanceSuppressedAlert struct {
+	RuleUID string            `json:"ruleUID"`
+	Labels  map[string]string `json:"labels"`
+	// FirstSuppressedAt and LastSuppressedAt are when the alert was suppressed for the first and last time,
+	// Count how many times it was.
+	FirstSuppressedAt time.Time `json:"firstSuppressedAt"`
+	LastSuppressedAt  time.Time `json:"lastSuppressedAt"`
+	Count             int64     `json:"count"`
+}
+
+func NewMaintenanceSuppressedAlert(s *models.MaintenanceSuppression) MaintenanceSuppressedAlert {
+	return MaintenanceSuppressedAlert{
+		RuleUID:           s.RuleUID,
+		Labels:            s.Labels,
+		FirstSuppressedAt: s.FirstSuppressedAt,
+		LastSuppressedAt:  s.LastSuppressedAt,
+		Count:             s.Count,
+	}
+}
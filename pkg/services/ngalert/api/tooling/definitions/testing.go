@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -52,6 +53,20 @@ import (
 //     Responses:
 //       200: EvalQueriesResponse
 
+// swagger:route Post /api/v1/rule/backtest testing RouteBacktestConfig
+//
+// Replay the evaluations of a rule over a past time range
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestResult
+//       400: ValidationError
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -84,6 +99,60 @@ type EvalQueriesPayload struct {
 	Now  time.Time           `json:"now"`
 }
 
+// swagger:parameters RouteBacktestConfig
+type BacktestConfigRequest struct {
+	// in:body
+	Body BacktestConfig
+}
+
+// swagger:model
+type BacktestConfig struct {
+	// From is the time of the first evaluation.
+	From time.Time `json:"from"`
+	// To is the time after which the rule is no longer evaluated.
+	To time.Time `json:"to"`
+	// Interval is the evaluation interval of the rule, the base interval if it is not set.
+	Interval model.Duration `json:"interval,omitempty"`
+
+	Condition    string              `json:"condition"`
+	Data         []models.AlertQuery `json:"data"`
+	Title        string              `json:"title"`
+	For          model.Duration      `json:"for,omitempty"`
+	Labels       map[string]string   `json:"labels,omitempty"`
+	Annotations  map[string]string   `json:"annotations,omitempty"`
+	NoDataState  NoDataState         `json:"no_data_state"`
+	ExecErrState ExecutionErrorState `json:"exec_err_state"`
+}
+
+// swagger:model
+type BacktestResult struct {
+	// Evaluations are the states of the alert instances after each evaluation of the rule.
+	Evaluations []BacktestEvaluation `json:"evaluations"`
+	// Notifications are the alert instances that would have been sent to the Alertmanager.
+	Notifications []BacktestNotification `json:"notifications"`
+}
+
+type BacktestEvaluation struct {
+	Time      time.Time          `json:"time"`
+	Instances []BacktestInstance `json:"instances"`
+}
+
+type BacktestInstance struct {
+	Labels map[string]string `json:"labels"`
+	State  string            `json:"state"`
+	Reason string            `json:"reason,omitempty"`
+}
+
+type BacktestNotification struct {
+	Time        time.Time         `json:"time"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	State       string            `json:"state"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+	Resolved    bool              `json:"resolved,omitempty"`
+}
+
 func (p *TestRulePayload) UnmarshalJSON(b []byte) error {
 	type plain TestRulePayload
 	if err := json.Unmarshal(b, (*plain)(p)); err != nil {
@@ -246,8 +246,3 @@ func ErrResp(status int, err error, msg string, args ...interface{}) *response.N
 	}
 	return response.Error(status, err.Error(), err)
 }
-
-// accessForbiddenResp creates a response of forbidden access.
-func accessForbiddenResp() response.Response {
-	return ErrResp(http.StatusForbidden, errors.New("Permission denied"), "")
-}
@@ -38,6 +38,7 @@ type evaluatorImpl struct {
 	log             log.Logger
 	dataSourceCache datasources.CacheService
 	secretsService  secrets.Service
+	queryCache      *queryCache
 }
 
 func NewEvaluator(
@@ -50,6 +51,7 @@ func NewEvaluator(
 		log:             log,
 		dataSourceCache: datasourceCache,
 		secretsService:  secretsService,
+		queryCache:      newQueryCache(),
 	}
 }
 
@@ -256,8 +258,17 @@ type NumberValueCapture struct {
 	Value  *float64
 }
 
-func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, exprService *expr.Service, dsCacheService datasources.CacheService, secretsService secrets.Service) ExecutionResults {
-	execResp, err := executeQueriesAndExpressions(ctx, c.Data, now, exprService, dsCacheService, secretsService)
+func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, exprService *expr.Service, dsCacheService datasources.CacheService, secretsService secrets.Service, cache *queryCache) ExecutionResults {
+	exec := func() (*backend.QueryDataResponse, error) {
+		return executeQueriesAndExpressions(ctx, c.Data, now, exprService, dsCacheService, secretsService)
+	}
+	var execResp *backend.QueryDataResponse
+	var err error
+	if cache != nil {
+		execResp, err = cache.execute(ctx, c.Data, now, exec)
+	} else {
+		execResp, err = exec()
+	}
 	if err != nil {
 		return ExecutionResults{Error: err}
 	}
@@ -589,12 +600,16 @@ func (evalResults Results) AsDataFrame() data.Frame {
 
 // ConditionEval executes conditions and evaluates the result.
 func (e *evaluatorImpl) ConditionEval(condition *models.Condition, now time.Time, expressionService *expr.Service) (Results, error) {
-	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.cfg.UnifiedAlerting.EvaluationTimeout)
+	timeout := e.cfg.UnifiedAlerting.EvaluationTimeout
+	if condition.Timeout > 0 {
+		timeout = condition.Timeout
+	}
+	alertCtx, cancelFn := context.WithTimeout(context.Background(), timeout)
 	defer cancelFn()
 
 	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.cfg.ExpressionsEnabled, Log: e.log}
 
-	execResult := executeCondition(alertExecCtx, condition, now, expressionService, e.dataSourceCache, e.secretsService)
+	execResult := executeCondition(alertExecCtx, condition, now, expressionService, e.dataSourceCache, e.secretsService, e.queryCache)
 
 	evalResults := evaluateExecutionResult(execResult, now)
 	return evalResults, nil
@@ -0,0 +1,103 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// queryCache keeps the results of the queries and expressions executed at the time of the latest evaluation, so
+// that the alert rules of a tick that run the same queries over the same time window execute them once. The
+// results of earlier evaluations are dropped as soon as a later evaluation starts.
+type queryCache struct {
+	mtx     sync.Mutex
+	at      time.Time
+	entries map[string]*queryCacheEntry
+}
+
+// queryCacheEntry is the result of the queries and expressions of a key, done is closed once it is known.
+type queryCacheEntry struct {
+	done chan struct{}
+	resp *backend.QueryDataResponse
+	err  error
+}
+
+func newQueryCache() *queryCache {
+	return &queryCache{entries: make(map[string]*queryCacheEntry)}
+}
+
+// execute returns the result of exec for the queries and expressions evaluated at now. exec is called once per
+// key for the evaluations of the same time, the other callers wait for its result. Evaluations of a time older
+// than the latest one are not cached.
+func (c *queryCache) execute(ctx AlertExecCtx, data []models.AlertQuery, now time.Time, exec func() (*backend.QueryDataResponse, error)) (*backend.QueryDataResponse, error) {
+	key := queryCacheKey(ctx.OrgID, data, now)
+
+	c.mtx.Lock()
+	if now.Before(c.at) {
+		c.mtx.Unlock()
+		return exec()
+	}
+	if now.After(c.at) {
+		c.at = now
+		c.entries = make(map[string]*queryCacheEntry)
+	}
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &queryCacheEntry{done: make(chan struct{})}
+		c.entries[key] = entry
+	}
+	c.mtx.Unlock()
+
+	if !ok {
+		entry.resp, entry.err = exec()
+		close(entry.done)
+	} else {
+		select {
+		case <-entry.done:
+		case <-ctx.Ctx.Done():
+			return nil, ctx.Ctx.Err()
+		}
+		ctx.Log.Debug("query results served from the evaluation cache", "org", ctx.OrgID)
+	}
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	return copyQueryDataResponse(entry.resp), nil
+}
+
+// queryCacheKey returns the key of the queries and expressions of the organization, made of the data source,
+// the model and the time window of each of them.
+func queryCacheKey(orgID int64, data []models.AlertQuery, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d", orgID)
+	for _, q := range data {
+		tr := q.RelativeTimeRange.ToTimeRange(now)
+		fmt.Fprintf(&b, "|%q %q %q %d %d %s", q.RefID, q.DatasourceUID, q.QueryType, tr.From.UnixNano(), tr.To.UnixNano(), q.Model)
+	}
+	return b.String()
+}
+
+// copyQueryDataResponse returns a copy of the response whose frames can be given new metadata without changing the
+// ones of the response, the fields of the frames are shared.
+func copyQueryDataResponse(resp *backend.QueryDataResponse) *backend.QueryDataResponse {
+	if resp == nil {
+		return nil
+	}
+	cp := backend.NewQueryDataResponse()
+	for refID, res := range resp.Responses {
+		frames := make(data.Frames, 0, len(res.Frames))
+		for _, f := range res.Frames {
+			frame := *f
+			frames = append(frames, &frame)
+		}
+		res.Frames = frames
+		cp.Responses[refID] = res
+	}
+	return cp
+}
@@ -0,0 +1,74 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCache(t *testing.T) {
+	ctx := AlertExecCtx{OrgID: 1, Ctx: context.Background(), Log: log.New("test")}
+	query := func(model string) []models.AlertQuery {
+		return []models.AlertQuery{{
+			RefID:             "A",
+			DatasourceUID:     "ds",
+			RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Minute)},
+			Model:             json.RawMessage(model),
+		}}
+	}
+
+	calls := 0
+	exec := func() (*backend.QueryDataResponse, error) {
+		calls++
+		resp := backend.NewQueryDataResponse()
+		resp.Responses["A"] = backend.DataResponse{Frames: data.Frames{data.NewFrame("A")}}
+		return resp, nil
+	}
+
+	now := time.Unix(1000, 0)
+	cache := newQueryCache()
+
+	t.Run("duplicate queries of the same time are executed once", func(t *testing.T) {
+		first, err := cache.execute(ctx, query(`{"expr":"up"}`), now, exec)
+		require.NoError(t, err)
+		second, err := cache.execute(ctx, query(`{"expr":"up"}`), now, exec)
+		require.NoError(t, err)
+		require.Equal(t, 1, calls)
+
+		// the frames of the results are not shared
+		first.Responses["A"].Frames[0].SetMeta(&data.FrameMeta{Custom: "first"})
+		require.Nil(t, second.Responses["A"].Frames[0].Meta)
+	})
+
+	t.Run("different queries are executed each", func(t *testing.T) {
+		_, err := cache.execute(ctx, query(`{"expr":"down"}`), now, exec)
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+
+		_, err = cache.execute(AlertExecCtx{OrgID: 2, Ctx: ctx.Ctx, Log: ctx.Log}, query(`{"expr":"up"}`), now, exec)
+		require.NoError(t, err)
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("queries of a later time are executed again", func(t *testing.T) {
+		_, err := cache.execute(ctx, query(`{"expr":"up"}`), now.Add(time.Second), exec)
+		require.NoError(t, err)
+		require.Equal(t, 4, calls)
+	})
+
+	t.Run("queries of an earlier time are not cached", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := cache.execute(ctx, query(`{"expr":"up"}`), now, exec)
+			require.NoError(t, err)
+		}
+		require.Equal(t, 6, calls)
+	})
+}
@@ -0,0 +1,94 @@
+package ngalert
+
+import (
+	"encoding/json"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+)
+
+// LiveEventsChannel is the Grafana Live channel the alert events of an organization are broadcast to: the state
+// transitions of its alert instances and the alerts dispatched to its notifiers.
+const LiveEventsChannel = "grafana/alerting/events"
+
+const (
+	eventTypeStateTransition = "state-transition"
+	eventTypeDispatch        = "dispatch"
+)
+
+// alertEvent is a message of LiveEventsChannel, with the field of its type set.
+type alertEvent struct {
+	Type       string                `json:"type"`
+	Transition *stateTransitionEvent `json:"transition,omitempty"`
+	Dispatch   *dispatchEvent        `json:"dispatch,omitempty"`
+}
+
+type stateTransitionEvent struct {
+	RuleUID        string            `json:"ruleUid"`
+	RuleVersion    int64             `json:"ruleVersion"`
+	Labels         map[string]string `json:"labels"`
+	PreviousState  string            `json:"previousState"`
+	PreviousReason string            `json:"previousReason,omitempty"`
+	State          string            `json:"state"`
+	Reason         string            `json:"reason,omitempty"`
+	TransitionedAt time.Time         `json:"transitionedAt"`
+}
+
+// dispatchEvent are the alerts of a rule put in the internal Alertmanager, local, and sent to the external
+// Alertmanagers, PagerDuty and webhooks, external.
+type dispatchEvent struct {
+	RuleUID      string               `json:"ruleUid"`
+	DispatchedAt time.Time            `json:"dispatchedAt"`
+	Local        []amv2.PostableAlert `json:"local,omitempty"`
+	External     []amv2.PostableAlert `json:"external,omitempty"`
+}
+
+// liveEventPublisher broadcasts the alert events to the subscribers of LiveEventsChannel, in the order they happen.
+type liveEventPublisher struct {
+	publish models.ChannelPublisher
+	log     log.Logger
+}
+
+func (p *liveEventPublisher) publishTransition(t ngmodels.AlertStateTransition) {
+	p.publishEvent(t.OrgID, alertEvent{
+		Type: eventTypeStateTransition,
+		Transition: &stateTransitionEvent{
+			RuleUID:        t.RuleUID,
+			RuleVersion:    t.RuleVersion,
+			Labels:         t.Labels,
+			PreviousState:  string(t.PreviousState),
+			PreviousReason: t.PreviousReason,
+			State:          string(t.State),
+			Reason:         t.Reason,
+			TransitionedAt: t.TransitionedAt,
+		},
+	})
+}
+
+func (p *liveEventPublisher) publishDispatch(e schedule.DispatchEvent) {
+	p.publishEvent(e.OrgID, alertEvent{
+		Type: eventTypeDispatch,
+		Dispatch: &dispatchEvent{
+			RuleUID:      e.RuleUID,
+			DispatchedAt: e.Timestamp,
+			Local:        e.Local,
+			External:     e.External,
+		},
+	})
+}
+
+func (p *liveEventPublisher) publishEvent(orgID int64, e alertEvent) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		p.log.Error("failed to encode the alert event", "org", orgID, "type", e.Type, "err", err)
+		return
+	}
+	if err := p.publish(orgID, LiveEventsChannel, b); err != nil {
+		p.log.Warn("failed to broadcast the alert event", "org", orgID, "type", e.Type, "err", err)
+	}
+}
@@ -46,14 +46,47 @@ type NGAlert struct {
 }
 
 type Scheduler struct {
-	Registerer               prometheus.Registerer
-	BehindSeconds            prometheus.Gauge
-	EvalTotal                *prometheus.CounterVec
-	EvalFailures             *prometheus.CounterVec
-	EvalDuration             *prometheus.SummaryVec
-	GetAlertRulesDuration    prometheus.Histogram
-	SchedulePeriodicDuration prometheus.Histogram
-	Ticker                   *legacyMetrics.Ticker
+	Registerer                 prometheus.Registerer
+	BehindSeconds              prometheus.Gauge
+	EvalTotal                  *prometheus.CounterVec
+	EvalFailures               *prometheus.CounterVec
+	EvalDuration               *prometheus.SummaryVec
+	EvalSkipped                *prometheus.CounterVec
+	EvalSuppressed             *prometheus.CounterVec
+	GetAlertRulesDuration      prometheus.Histogram
+	SchedulePeriodicDuration   prometheus.Histogram
+	Ticker                     *legacyMetrics.Ticker
+	ResolvedAlertsDropped      *prometheus.CounterVec
+	SyncDecisions              *prometheus.CounterVec
+	UnhealthyOrgs              *prometheus.GaugeVec
+	SecondsSinceLastDelivery   *prometheus.GaugeVec
+	LocalFallbackAlerts        *prometheus.CounterVec
+	ExternalSendRetries        *prometheus.CounterVec
+	FallbackThresholdExceeded  *prometheus.GaugeVec
+	InconsistentAdminConfigs   *prometheus.GaugeVec
+	MissingLabelsAlertsDropped *prometheus.CounterVec
+	ExternalAlertsSent         *prometheus.CounterVec
+	ExternalAlertsFailed       *prometheus.CounterVec
+	ExternalSendDuration       *prometheus.HistogramVec
+	ExternalSendPayloadBytes   *prometheus.HistogramVec
+	ExternalSendBodyBytes      *prometheus.HistogramVec
+	SenderConfigReloads        *prometheus.CounterVec
+	AlertsNotDelivered         *prometheus.CounterVec
+	AlertsDroppedAtShutdown    *prometheus.CounterVec
+	ExternalAlertsDeduplicated *prometheus.CounterVec
+	ExternalAlertsRateLimited  *prometheus.CounterVec
+	ExternalAlertsMuted        *prometheus.CounterVec
+	ExternalAlertsGrouped      *prometheus.CounterVec
+	AlertsInhibited            *prometheus.CounterVec
+	AlertsSuppressed           *prometheus.CounterVec
+	AlertsOverQuota            *prometheus.CounterVec
+	SchedulerMembers           prometheus.Gauge
+	DatasourceWaitDuration     *prometheus.HistogramVec
+	RulesOwned                 prometheus.Gauge
+	RulesPaused                prometheus.Gauge
+	DispatchLeader             *prometheus.GaugeVec
+	Heartbeats                 *prometheus.CounterVec
+	DeliveryReceiptsDropped    prometheus.Counter
 }
 
 type MultiOrgAlertmanager struct {
@@ -163,6 +196,24 @@ func newSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org"},
 		),
+		EvalSkipped: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_evaluations_skipped_total",
+				Help:      "The total number of rule evaluations skipped because the rule failed too many times in a row.",
+			},
+			[]string{"org"},
+		),
+		EvalSuppressed: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_evaluations_suppressed_total",
+				Help:      "The total number of rule evaluations skipped because the rule is in a maintenance window.",
+			},
+			[]string{"org"},
+		),
 		GetAlertRulesDuration: promauto.With(r).NewHistogram(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
@@ -182,6 +233,285 @@ func newSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 		),
 		Ticker: legacyMetrics.NewTickerMetrics(r),
+		ResolvedAlertsDropped: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "resolved_alerts_dropped_total",
+				Help:      "The total number of resolved alerts not sent because they started longer ago than the maximum resolved alert age.",
+			},
+			[]string{"org"},
+		),
+		SyncDecisions: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "sync_decision_total",
+				Help:      "The total number of decisions taken for an organization when syncing the admin configuration.",
+			},
+			[]string{"decision"},
+		),
+		UnhealthyOrgs: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "external_alertmanagers_unhealthy",
+				Help:      "Whether the external Alertmanager(s) of an organization are unhealthy (1) or not (0).",
+			},
+			[]string{"org"},
+		),
+		SecondsSinceLastDelivery: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "seconds_since_last_delivery",
+				Help:      "The number of seconds since alerts of an organization were last delivered, -1 if none was ever delivered.",
+			},
+			[]string{"org"},
+		),
+		LocalFallbackAlerts: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "local_fallback_alerts_total",
+				Help:      "The total number of alerts sent to the local notifier because the external Alertmanager(s) were unhealthy.",
+			},
+			[]string{"org"},
+		),
+		ExternalSendRetries: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "external_send_retries_total",
+				Help:      "The total number of retries of failed sends of alerts to external Alertmanager(s).",
+			},
+			[]string{"org"},
+		),
+		FallbackThresholdExceeded: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "external_fallback_threshold_exceeded",
+				Help:      "Whether an organization sending alerts to external Alertmanager(s) only has fallen back to the local notifier for too long (1) or not (0).",
+			},
+			[]string{"org"},
+		),
+		InconsistentAdminConfigs: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "admin_configuration_inconsistent",
+				Help:      "Set to 1 for the organizations whose admin configuration is logically inconsistent.",
+			},
+			[]string{"org"},
+		),
+		MissingLabelsAlertsDropped: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "missing_labels_alerts_dropped_total",
+				Help:      "The total number of alerts not sent to external Alertmanager(s) because they miss required labels.",
+			},
+			[]string{"org"},
+		),
+		ExternalAlertsSent: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "external_alerts_sent_total",
+				Help:      "The total number of alerts accepted by external Alertmanager(s).",
+			},
+			[]string{"org", "alertmanager"},
+		),
+		ExternalAlertsFailed: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "external_alerts_failed_total",
+				Help:      "The total number of alerts that failed to be sent to external Alertmanager(s).",
+			},
+			[]string{"org", "alertmanager"},
+		),
+		ExternalSendDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "external_send_duration_seconds",
+				Help:      "The time to send a batch of alerts to an external Alertmanager, including the retries.",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"org", "alertmanager"},
+		),
+		ExternalSendPayloadBytes: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "external_send_payload_bytes",
+				Help:      "The size of the batches of alerts sent to an external Alertmanager, before compression.",
+				Buckets:   prometheus.ExponentialBuckets(1024, 4, 10),
+			},
+			[]string{"org", "alertmanager"},
+		),
+		ExternalSendBodyBytes: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "external_send_body_bytes",
+				Help:      "The size of the bodies sent to an external Alertmanager, by encoding: gzip, snappy or identity if not compressed.",
+				Buckets:   prometheus.ExponentialBuckets(1024, 4, 10),
+			},
+			[]string{"org", "alertmanager", "encoding"},
+		),
+		SenderConfigReloads: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "sender_config_reloads_total",
+				Help:      "The total number of configurations applied to the senders of external Alertmanager(s).",
+			},
+			[]string{"org", "status"},
+		),
+		AlertsNotDelivered: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "alerts_not_delivered_total",
+				Help:      "The total number of alerts not delivered because neither the local notifier nor external Alertmanager(s) are available.",
+			},
+			[]string{"org"},
+		),
+		AlertsDroppedAtShutdown: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "alerts_dropped_at_shutdown_total",
+				Help:      "The total number of alerts queued to be sent to external Alertmanager(s) that were dropped when their sender stopped.",
+			},
+			[]string{"org"},
+		),
+		ExternalAlertsDeduplicated: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "external_alerts_deduplicated_total",
+				Help:      "The total number of firing alerts not sent again to external Alertmanager(s) because they did not change.",
+			},
+			[]string{"org"},
+		),
+		ExternalAlertsRateLimited: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "external_alerts_rate_limited_total",
+				Help:      "The total number of alerts not sent to external Alertmanager(s) because they exceed the rate limit of the organization.",
+			},
+			[]string{"org"},
+		),
+		ExternalAlertsMuted: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "external_alerts_muted_total",
+				Help:      "The total number of firing alerts not sent to external Alertmanager(s) because of a mute timing.",
+			},
+			[]string{"org"},
+		),
+		ExternalAlertsGrouped: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "external_alerts_grouped_total",
+				Help:      "The total number of alerts sent to external Alertmanager(s) within the alert of their group.",
+			},
+			[]string{"org"},
+		),
+		AlertsInhibited: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "alerts_inhibited_total",
+				Help:      "The total number of firing alerts not delivered because a rule their rule depends on fires.",
+			},
+			[]string{"org"},
+		),
+		AlertsSuppressed: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "alerts_suppressed_total",
+				Help:      "The total number of firing alerts not delivered because they are in a maintenance window.",
+			},
+			[]string{"org"},
+		),
+		AlertsOverQuota: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "alerts_over_quota_total",
+				Help:      "The total number of firing alerts not delivered because they exceed the alert quota of their organization.",
+			},
+			[]string{"org"},
+		),
+		SchedulerMembers: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "scheduler_members",
+				Help:      "The number of schedulers sharing the evaluation of the alert rules, 0 if it is not sharded.",
+			},
+		),
+		DatasourceWaitDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_evaluation_datasource_wait_duration_seconds",
+				Help:      "The time rule evaluations waited for the limit of concurrent evaluations of a datasource.",
+				Buckets:   []float64{.01, .1, .5, 1, 5, 10, 30, 60},
+			},
+			[]string{"org", "datasource_uid"},
+		),
+		RulesOwned: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rules_owned",
+				Help:      "The number of alert rules evaluated by this scheduler.",
+			},
+		),
+		RulesPaused: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rules_paused",
+				Help:      "The number of paused alert rules, not evaluated.",
+			},
+		),
+		DispatchLeader: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "dispatch_leader",
+				Help:      "Whether this scheduler holds the lease to dispatch the alerts of the organization to its external targets.",
+			},
+			[]string{"org"},
+		),
+		Heartbeats: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "heartbeats_total",
+				Help:      "The total number of heartbeats after a sync of the admin configuration, by result: success, failure or skipped.",
+			},
+			[]string{"result"},
+		),
+		DeliveryReceiptsDropped: promauto.With(r).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "delivery_receipts_dropped_total",
+				Help:      "The total number of delivery receipts dropped because their buffer was full or they could not be saved.",
+			},
+		),
 	}
 }
 
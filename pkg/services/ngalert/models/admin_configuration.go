@@ -2,9 +2,19 @@ package models
 
 import (
 	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/prometheus/alertmanager/timeinterval"
+	prometheusModel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/relabel"
+	"gopkg.in/yaml.v2"
 )
 
 type AlertmanagersChoice int
@@ -32,13 +42,365 @@ type AdminConfiguration struct {
 	// SendAlertsTo indicates which set of alertmanagers will handle the alert.
 	SendAlertsTo AlertmanagersChoice `xorm:"send_alerts_to"`
 
+	// Disabled indicates that alerts of the organization are not sent to external Alertmanager(s).
+	Disabled bool `xorm:"disabled"`
+
+	// ExternalRuleUIDs are the rules whose alerts are sent to the external Alertmanager(s) only,
+	// whatever the SendAlertsTo choice of the organization is.
+	ExternalRuleUIDs []string `xorm:"external_rule_uids"`
+
+	// TLSConfigs are the TLS configurations used to send alerts to the Alertmanager(s), by Alertmanager URL.
+	TLSConfigs map[string]AlertmanagerTLSConfig `xorm:"tls_configs"`
+
+	// Credentials are the credentials used to send alerts to the Alertmanager(s), by Alertmanager URL.
+	Credentials map[string]AlertmanagerCredentials `xorm:"credentials"`
+
+	// ProxyURL is the URL of the HTTP(S) or SOCKS5 proxy used to send alerts to the Alertmanager(s), except
+	// the ones whose host matches NoProxy. ProxyURLs are the proxies of particular Alertmanager(s), by
+	// Alertmanager URL, used instead of ProxyURL and NoProxy.
+	ProxyURL  string            `xorm:"proxy_url"`
+	NoProxy   []string          `xorm:"no_proxy"`
+	ProxyURLs map[string]string `xorm:"proxy_urls"`
+
+	// APIVersions are the versions of the API of the Alertmanager(s) that do not expose the v2 API, by
+	// Alertmanager URL. Alerts are sent to the v2 API of the other ones.
+	APIVersions map[string]string `xorm:"api_versions"`
+
+	// Headers are the static headers sent to the Alertmanager(s), by Alertmanager URL, e.g. the X-Scope-OrgID
+	// header of the tenant of a multi-tenant Alertmanager. Sensitive headers are secure settings of the
+	// Credentials instead, which take precedence.
+	Headers map[string]map[string]string `xorm:"headers"`
+
+	// Timeout is the timeout of the requests to the Alertmanager(s), e.g. 30s, 10s if empty. Timeouts are the
+	// timeouts of particular Alertmanager(s), by Alertmanager URL, used instead of Timeout.
+	Timeout  string            `xorm:"timeout"`
+	Timeouts map[string]string `xorm:"timeouts"`
+
+	// Compressions are the encodings of the alerts sent to particular Alertmanager(s), by Alertmanager URL:
+	// gzip, snappy or none. They take precedence over the compression enabled for the organization, if any.
+	Compressions map[string]string `xorm:"compressions"`
+
+	// PagerDuty is the PagerDuty service alerts are sent to directly, through the PagerDuty Events API v2,
+	// along with the Alertmanager(s) if any. Organizations without Alertmanager can send their alerts there only.
+	PagerDuty *PagerDutyConfig `xorm:"pager_duty"`
+
+	// Webhooks are the HTTP endpoints alerts are posted to directly, along with the Alertmanager(s) if any, e.g.
+	// to feed an incident management system that has no Alertmanager in front of it.
+	Webhooks []AlertWebhookConfig `xorm:"webhooks"`
+
+	// AWS is the Amazon SNS topic or SQS queue alerts are published to directly, along with the Alertmanager(s)
+	// if any, e.g. to feed event-driven pipelines without a webhook relay in between.
+	AWS *AWSConfig `xorm:"aws"`
+
+	// SilenceSync is how the silences of the internal Alertmanager are kept in sync with the external
+	// Alertmanager(s), they are not if empty.
+	SilenceSync SilenceSyncMode `xorm:"silence_sync"`
+
+	// Relabel are the relabeling rules of the alerts sent to all the external targets, e.g. to strip internal
+	// labels or personal data. TargetRelabels are the rules of particular targets, applied after those, by URL of
+	// the Alertmanager, webhook, PagerDuty Events API or SQS queue, or by ARN of the SNS topic.
+	Relabel        *AlertRelabelConfigs           `xorm:"relabel"`
+	TargetRelabels map[string]AlertRelabelConfigs `xorm:"target_relabels"`
+
+	// ResolvedAlerts is how the alerts of the rules are resolved when their state is cleared, because the rule was
+	// updated or deleted, nil to resolve them right away. RuleResolvedAlerts are the ones of particular rules, by
+	// rule UID, used instead.
+	ResolvedAlerts     *ResolvedAlertsConfig           `xorm:"resolved_alerts"`
+	RuleResolvedAlerts map[string]ResolvedAlertsConfig `xorm:"rule_resolved_alerts"`
+
+	// MuteTimings are when the firing alerts of the rules are not sent to the external Alertmanager(s), nil to
+	// always send them. RuleMuteTimings are the ones of particular rules, by rule UID, used instead.
+	MuteTimings     *MuteTimingsConfig           `xorm:"mute_timings"`
+	RuleMuteTimings map[string]MuteTimingsConfig `xorm:"rule_mute_timings"`
+
+	// Grouping is how the alerts of a rule are grouped before being sent to the external Alertmanager(s), each
+	// group being sent as a single alert, nil to send the alerts as they are.
+	Grouping *AlertGroupingConfig `xorm:"grouping"`
+
 	CreatedAt int64 `xorm:"created"`
 	UpdatedAt int64 `xorm:"updated"`
 }
 
+// AlertmanagerTLSConfig is the TLS configuration used to send alerts to an external Alertmanager. The files
+// are read from the Grafana server.
+type AlertmanagerTLSConfig struct {
+	// CAFile is the CA bundle used to verify the certificate of the Alertmanager.
+	CAFile string `json:"caFile,omitempty"`
+	// CertFile and KeyFile are the client certificate and key presented to the Alertmanager.
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+	// InsecureSkipVerify disables the verification of the certificate of the Alertmanager.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+const (
+	// BasicAuthPasswordKey, BearerTokenKey and HeaderKeyPrefix followed by the name of a header are the
+	// keys of the secure settings of AlertmanagerCredentials.
+	BasicAuthPasswordKey = "basicAuthPassword"
+	BearerTokenKey       = "bearerToken"
+	HeaderKeyPrefix      = "header."
+)
+
+// PagerDutyRoutingKeyKey is the key of the integration key of the PagerDuty service in the secure settings of
+// PagerDutyConfig.
+const PagerDutyRoutingKeyKey = "routingKey"
+
+// PagerDutyDefaultURL is the URL of the PagerDuty Events API v2 alerts are sent to by default.
+const PagerDutyDefaultURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutySeverities are the severities of the events of the PagerDuty Events API v2.
+var PagerDutySeverities = []string{"critical", "error", "warning", "info"}
+
+// PagerDutyConfig is the configuration used to send alerts to the PagerDuty Events API v2 directly.
+type PagerDutyConfig struct {
+	// URL is the URL of the Events API, PagerDutyDefaultURL if empty.
+	URL string `json:"url,omitempty"`
+	// Severity is the severity of the events of the alerts without a valid severity label, critical if empty.
+	Severity string `json:"severity,omitempty"`
+	// SecureSettings are the encrypted integration key, also known as routing key, of the PagerDuty service.
+	SecureSettings map[string][]byte `json:"secureSettings,omitempty"`
+}
+
+const (
+	// AWSAccessKeyKey and AWSSecretKeyKey are the keys of the static credentials in the secure settings of
+	// AWSConfig.
+	AWSAccessKeyKey = "accessKey"
+	AWSSecretKeyKey = "secretKey"
+)
+
+// AWSConfig is the configuration used to publish alerts to an Amazon SNS topic or SQS queue directly, one
+// message per alert.
+type AWSConfig struct {
+	// Region is the AWS region of the topic or queue.
+	Region string `json:"region"`
+	// TopicARN is the ARN of the SNS topic and QueueURL the URL of the SQS queue alerts are published to, only
+	// one of them is set.
+	TopicARN string `json:"topicArn,omitempty"`
+	QueueURL string `json:"queueUrl,omitempty"`
+	// AssumeRoleARN is the ARN of the IAM role assumed to publish the alerts, with the external ID if the trust
+	// policy of the role requires one.
+	AssumeRoleARN string `json:"assumeRoleArn,omitempty"`
+	ExternalID    string `json:"externalId,omitempty"`
+	// Endpoint is the URL of the SNS or SQS API used instead of the one of the region, e.g. a VPC endpoint.
+	Endpoint string `json:"endpoint,omitempty"`
+	// SecureSettings are the encrypted static credentials, the access key and the secret key. The default
+	// credentials of the Grafana server are used if there are none.
+	SecureSettings map[string][]byte `json:"secureSettings,omitempty"`
+}
+
+// Target returns the ARN of the topic or the URL of the queue alerts are published to.
+func (c *AWSConfig) Target() string {
+	if c.TopicARN != "" {
+		return c.TopicARN
+	}
+	return c.QueueURL
+}
+
+// AlertWebhookConfig is an HTTP endpoint alerts are posted to directly.
+type AlertWebhookConfig struct {
+	URL string `json:"url"`
+	// Template is the Go template of the body posted, executed with the alerts as .Alerts. The body is the JSON
+	// array of the alerts, like the one sent to an Alertmanager, if empty.
+	Template string `json:"template,omitempty"`
+	// ContentType is the content type of the body posted, application/json if empty.
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// WebhookTemplateFuncs are the functions of the templates of AlertWebhookConfig, in addition to the built-in ones.
+var WebhookTemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// ParseWebhookTemplate parses the template of the body posted to a webhook.
+func ParseWebhookTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").Funcs(WebhookTemplateFuncs).Parse(text)
+}
+
+// SilenceSyncMode is how the silences of the internal Alertmanager are kept in sync with the external Alertmanager(s).
+type SilenceSyncMode string
+
+const (
+	// SilenceSyncDisabled does not sync the silences.
+	SilenceSyncDisabled SilenceSyncMode = ""
+	// SilenceSyncPush mirrors the silences created in the internal Alertmanager to the external Alertmanager(s).
+	SilenceSyncPush SilenceSyncMode = "push"
+	// SilenceSyncBidirectional also mirrors the silences created in the external Alertmanager(s) to the
+	// internal Alertmanager.
+	SilenceSyncBidirectional SilenceSyncMode = "bidirectional"
+)
+
+// AlertRelabelConfigs are the relabeling rules of the labels and of the annotations of the alerts sent to external
+// targets, applied in order with the semantics of the relabeling of Prometheus. Alerts whose labels or annotations
+// are dropped are not sent.
+type AlertRelabelConfigs struct {
+	Labels      []RelabelConfig `json:"labels,omitempty"`
+	Annotations []RelabelConfig `json:"annotations,omitempty"`
+}
+
+// RelabelConfig is a relabeling rule, the relabel_config of Prometheus. The defaults of Prometheus apply to the
+// fields that are not set.
+type RelabelConfig struct {
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+	Separator    string   `json:"separator,omitempty"`
+	Regex        string   `json:"regex,omitempty"`
+	Modulus      uint64   `json:"modulus,omitempty"`
+	TargetLabel  string   `json:"targetLabel,omitempty"`
+	Replacement  *string  `json:"replacement,omitempty"`
+	Action       string   `json:"action,omitempty"`
+}
+
+// PrometheusRelabelConfigs returns the relabeling rules of Prometheus of the rules, or an error if one of them is
+// not valid.
+func PrometheusRelabelConfigs(rules []RelabelConfig) ([]*relabel.Config, error) {
+	res := make([]*relabel.Config, 0, len(rules))
+	for i, rule := range rules {
+		// The rules are unmarshaled as in the configuration of Prometheus, that sets the defaults and validates them.
+		fields := map[string]interface{}{}
+		if len(rule.SourceLabels) > 0 {
+			fields["source_labels"] = rule.SourceLabels
+		}
+		if rule.Separator != "" {
+			fields["separator"] = rule.Separator
+		}
+		if rule.Regex != "" {
+			fields["regex"] = rule.Regex
+		}
+		if rule.Modulus != 0 {
+			fields["modulus"] = rule.Modulus
+		}
+		if rule.TargetLabel != "" {
+			fields["target_label"] = rule.TargetLabel
+		}
+		if rule.Replacement != nil {
+			fields["replacement"] = *rule.Replacement
+		}
+		if rule.Action != "" {
+			fields["action"] = rule.Action
+		}
+		b, err := yaml.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		cfg := &relabel.Config{}
+		if err := yaml.UnmarshalStrict(b, cfg); err != nil {
+			return nil, fmt.Errorf("invalid relabeling rule %d: %w", i+1, err)
+		}
+		res = append(res, cfg)
+	}
+	return res, nil
+}
+
+// AlertmanagerAPIVersionV1 and AlertmanagerAPIVersionV2 are the versions of the API alerts can be sent to.
+const (
+	AlertmanagerAPIVersionV1 = "v1"
+	AlertmanagerAPIVersionV2 = "v2"
+)
+
+// CompressionGzip, CompressionSnappy and CompressionNone are the compressions of the alerts sent to an
+// Alertmanager.
+const (
+	CompressionGzip   = "gzip"
+	CompressionSnappy = "snappy"
+	CompressionNone   = "none"
+)
+
+// ResolvedAlertsImmediate, ResolvedAlertsGraceWindow and ResolvedAlertsSuppress are the modes of resolving the
+// alerts of a rule whose state is cleared.
+const (
+	ResolvedAlertsImmediate   = "immediate"
+	ResolvedAlertsGraceWindow = "grace_window"
+	ResolvedAlertsSuppress    = "suppress"
+)
+
+// ResolvedAlertsConfig is how the alerts of a rule are resolved when its state is cleared.
+type ResolvedAlertsConfig struct {
+	// Mode is immediate, the default, grace_window to end the alerts a grace window after the state is cleared,
+	// or suppress to let the Alertmanagers resolve the alerts once they expire.
+	Mode string `json:"mode,omitempty"`
+	// GraceWindow is the grace window of the grace_window mode, e.g. 5m.
+	GraceWindow string `json:"graceWindow,omitempty"`
+}
+
+// MuteTimingsConfig are the time windows during which the firing alerts of a rule are not sent to the external
+// Alertmanager(s).
+type MuteTimingsConfig struct {
+	// TimeIntervals are calendar-based time windows, like the mute time intervals of the Alertmanager.
+	TimeIntervals []timeinterval.TimeInterval `json:"timeIntervals,omitempty"`
+	// Location is the time zone of the time intervals, e.g. Europe/Paris, UTC if empty.
+	Location string `json:"location,omitempty"`
+}
+
+// AlertGroupingConfig groups the alerts of a rule sent to the external Alertmanager(s) by some of their labels.
+type AlertGroupingConfig struct {
+	// By are the labels the alerts are grouped by.
+	By []string `json:"by,omitempty"`
+	// Examples is the number of alerts of a group whose labels are listed, 3 if 0.
+	Examples int `json:"examples,omitempty"`
+}
+
+// AlertmanagerCredentials are the credentials used to send alerts to an external Alertmanager. They are
+// used instead of the user information of the URL of the Alertmanager, if any.
+type AlertmanagerCredentials struct {
+	// BasicAuthUser is the user of the basic authentication, its password is a secure setting.
+	BasicAuthUser string `json:"basicAuthUser,omitempty"`
+	// SecureSettings are the encrypted basic authentication password, bearer token and values of the
+	// headers sent to the Alertmanager.
+	SecureSettings map[string][]byte `json:"secureSettings,omitempty"`
+}
+
+// ResourceType and ResourceID make the admin configuration Provisionable, there is one per organization.
+func (ac *AdminConfiguration) ResourceType() string {
+	return "adminConfiguration"
+}
+
+func (ac *AdminConfiguration) ResourceID() string {
+	return ""
+}
+
 func (ac *AdminConfiguration) AsSHA256() string {
 	h := sha256.New()
 	_, _ = h.Write([]byte(fmt.Sprintf("%v", ac.Alertmanagers)))
+	// Maps are printed sorted by key.
+	if len(ac.TLSConfigs) > 0 {
+		_, _ = h.Write([]byte(fmt.Sprintf("%v", ac.TLSConfigs)))
+	}
+	if len(ac.Credentials) > 0 {
+		_, _ = h.Write([]byte(fmt.Sprintf("%v", ac.Credentials)))
+	}
+	if ac.ProxyURL != "" || len(ac.NoProxy) > 0 || len(ac.ProxyURLs) > 0 {
+		_, _ = h.Write([]byte(fmt.Sprintf("%s%v%v", ac.ProxyURL, ac.NoProxy, ac.ProxyURLs)))
+	}
+	if len(ac.APIVersions) > 0 {
+		_, _ = h.Write([]byte(fmt.Sprintf("%v", ac.APIVersions)))
+	}
+	if len(ac.Headers) > 0 {
+		_, _ = h.Write([]byte(fmt.Sprintf("%v", ac.Headers)))
+	}
+	if ac.Timeout != "" || len(ac.Timeouts) > 0 {
+		_, _ = h.Write([]byte(fmt.Sprintf("%s%v", ac.Timeout, ac.Timeouts)))
+	}
+	if len(ac.Compressions) > 0 {
+		_, _ = h.Write([]byte(fmt.Sprintf("%v", ac.Compressions)))
+	}
+	if ac.PagerDuty != nil {
+		_, _ = h.Write([]byte(fmt.Sprintf("%v", *ac.PagerDuty)))
+	}
+	if len(ac.Webhooks) > 0 {
+		_, _ = h.Write([]byte(fmt.Sprintf("%v", ac.Webhooks)))
+	}
+	if ac.AWS != nil {
+		_, _ = h.Write([]byte(fmt.Sprintf("%v", *ac.AWS)))
+	}
+	if ac.SilenceSync != SilenceSyncDisabled {
+		_, _ = h.Write([]byte(ac.SilenceSync))
+	}
+	if ac.Relabel != nil || len(ac.TargetRelabels) > 0 {
+		b, _ := json.Marshal([]interface{}{ac.Relabel, ac.TargetRelabels})
+		_, _ = h.Write(b)
+	}
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
@@ -50,9 +412,380 @@ func (ac *AdminConfiguration) Validate() error {
 		}
 	}
 
+	for u, tlsCfg := range ac.TLSConfigs {
+		if !ac.hasAlertmanager(u) {
+			return fmt.Errorf("TLS configuration for %s which is not a configured Alertmanager", u)
+		}
+		if (tlsCfg.CertFile == "") != (tlsCfg.KeyFile == "") {
+			return fmt.Errorf("TLS configuration for %s must have both a client certificate and key, or neither", u)
+		}
+	}
+
+	for u, creds := range ac.Credentials {
+		if !ac.hasAlertmanager(u) {
+			return fmt.Errorf("credentials for %s which is not a configured Alertmanager", u)
+		}
+		_, hasPassword := creds.SecureSettings[BasicAuthPasswordKey]
+		_, hasToken := creds.SecureSettings[BearerTokenKey]
+		if hasPassword && creds.BasicAuthUser == "" {
+			return fmt.Errorf("credentials for %s have a basic authentication password without user", u)
+		}
+		if creds.BasicAuthUser != "" && hasToken {
+			return fmt.Errorf("credentials for %s must have either a basic authentication or a bearer token, not both", u)
+		}
+	}
+
+	if ac.ProxyURL != "" {
+		if err := validateProxyURL(ac.ProxyURL); err != nil {
+			return err
+		}
+	}
+	for _, host := range ac.NoProxy {
+		if host == "" {
+			return errors.New("no proxy hosts must not be empty")
+		}
+	}
+	for u, proxyURL := range ac.ProxyURLs {
+		if !ac.hasAlertmanager(u) {
+			return fmt.Errorf("proxy for %s which is not a configured Alertmanager", u)
+		}
+		if err := validateProxyURL(proxyURL); err != nil {
+			return err
+		}
+	}
+
+	for u, version := range ac.APIVersions {
+		if !ac.hasAlertmanager(u) {
+			return fmt.Errorf("API version for %s which is not a configured Alertmanager", u)
+		}
+		if version != AlertmanagerAPIVersionV1 && version != AlertmanagerAPIVersionV2 {
+			return fmt.Errorf("API version %q of %s must be %s or %s", version, u, AlertmanagerAPIVersionV1, AlertmanagerAPIVersionV2)
+		}
+	}
+
+	for u, headers := range ac.Headers {
+		if !ac.hasAlertmanager(u) {
+			return fmt.Errorf("headers for %s which is not a configured Alertmanager", u)
+		}
+		for name, value := range headers {
+			if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+				return fmt.Errorf("invalid header name %q for %s", name, u)
+			}
+			if strings.ContainsAny(value, "\r\n") {
+				return fmt.Errorf("invalid value of header %s for %s", name, u)
+			}
+		}
+	}
+
+	if ac.Timeout != "" {
+		if err := validateTimeout(ac.Timeout); err != nil {
+			return err
+		}
+	}
+	for u, timeout := range ac.Timeouts {
+		if !ac.hasAlertmanager(u) {
+			return fmt.Errorf("timeout for %s which is not a configured Alertmanager", u)
+		}
+		if err := validateTimeout(timeout); err != nil {
+			return fmt.Errorf("%w for %s", err, u)
+		}
+	}
+	for u, compression := range ac.Compressions {
+		if !ac.hasAlertmanager(u) {
+			return fmt.Errorf("compression for %s which is not a configured Alertmanager", u)
+		}
+		switch compression {
+		case CompressionGzip, CompressionSnappy, CompressionNone:
+		default:
+			return fmt.Errorf("unsupported compression %q for %s, must be %s, %s or %s", compression, u, CompressionGzip, CompressionSnappy, CompressionNone)
+		}
+	}
+
+	if ac.PagerDuty != nil {
+		if err := ac.PagerDuty.validate(); err != nil {
+			return err
+		}
+	}
+
+	seen := make(map[string]struct{}, len(ac.Webhooks))
+	for _, wh := range ac.Webhooks {
+		u, err := url.Parse(wh.URL)
+		if err != nil {
+			return fmt.Errorf("invalid webhook URL: %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook %s must be an http or https URL", u.Redacted())
+		}
+		if _, ok := seen[wh.URL]; ok {
+			return fmt.Errorf("webhook %s is configured more than once", u.Redacted())
+		}
+		seen[wh.URL] = struct{}{}
+		if wh.Template != "" {
+			if _, err := ParseWebhookTemplate(wh.Template); err != nil {
+				return fmt.Errorf("invalid template of webhook %s: %w", u.Redacted(), err)
+			}
+		}
+	}
+
+	if ac.AWS != nil {
+		if err := ac.AWS.validate(); err != nil {
+			return err
+		}
+	}
+
+	if ac.Relabel != nil {
+		if err := ac.Relabel.validate(); err != nil {
+			return err
+		}
+	}
+	for u, rules := range ac.TargetRelabels {
+		if !ac.hasTarget(u) {
+			return fmt.Errorf("relabeling rules for %s which is not a configured Alertmanager, webhook, PagerDuty, SNS topic or SQS queue", u)
+		}
+		if err := rules.validate(); err != nil {
+			return fmt.Errorf("%w of %s", err, u)
+		}
+	}
+
+	switch ac.SilenceSync {
+	case SilenceSyncDisabled, SilenceSyncPush, SilenceSyncBidirectional:
+	default:
+		return fmt.Errorf("silence sync %q must be %s or %s", ac.SilenceSync, SilenceSyncPush, SilenceSyncBidirectional)
+	}
+
+	if ac.ResolvedAlerts != nil {
+		if err := ac.ResolvedAlerts.validate(); err != nil {
+			return err
+		}
+	}
+	for uid, rc := range ac.RuleResolvedAlerts {
+		if err := rc.validate(); err != nil {
+			return fmt.Errorf("rule %s: %w", uid, err)
+		}
+	}
+
+	if ac.MuteTimings != nil {
+		if err := ac.MuteTimings.validate(); err != nil {
+			return err
+		}
+	}
+	for uid, mt := range ac.RuleMuteTimings {
+		if err := mt.validate(); err != nil {
+			return fmt.Errorf("rule %s: %w", uid, err)
+		}
+	}
+
+	if ac.Grouping != nil {
+		if err := ac.Grouping.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rc *AlertRelabelConfigs) validate() error {
+	if _, err := PrometheusRelabelConfigs(rc.Labels); err != nil {
+		return fmt.Errorf("labels: %w", err)
+	}
+	if _, err := PrometheusRelabelConfigs(rc.Annotations); err != nil {
+		return fmt.Errorf("annotations: %w", err)
+	}
 	return nil
 }
 
+func (rc *ResolvedAlertsConfig) validate() error {
+	switch rc.Mode {
+	case "", ResolvedAlertsImmediate, ResolvedAlertsSuppress:
+		return nil
+	case ResolvedAlertsGraceWindow:
+		d, err := time.ParseDuration(rc.GraceWindow)
+		if err != nil {
+			return fmt.Errorf("invalid grace window %q of resolved alerts: %w", rc.GraceWindow, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("grace window %q of resolved alerts must be positive", rc.GraceWindow)
+		}
+		return nil
+	default:
+		return fmt.Errorf("resolved alerts mode %q must be %s, %s or %s", rc.Mode, ResolvedAlertsImmediate, ResolvedAlertsGraceWindow, ResolvedAlertsSuppress)
+	}
+}
+
+func (mt *MuteTimingsConfig) validate() error {
+	if len(mt.TimeIntervals) == 0 {
+		return errors.New("mute timings must have at least one time interval")
+	}
+	if _, err := time.LoadLocation(mt.Location); err != nil {
+		return fmt.Errorf("invalid location %q of mute timings: %w", mt.Location, err)
+	}
+	return nil
+}
+
+func (g *AlertGroupingConfig) validate() error {
+	if len(g.By) == 0 {
+		return errors.New("grouping must have at least one label")
+	}
+	for _, name := range g.By {
+		if !prometheusModel.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid label %q of grouping", name)
+		}
+	}
+	if g.Examples < 0 {
+		return errors.New("number of examples of grouping must be 0 or greater")
+	}
+	return nil
+}
+
+func (pd *PagerDutyConfig) validate() error {
+	if pd.URL != "" {
+		u, err := url.Parse(pd.URL)
+		if err != nil {
+			return fmt.Errorf("invalid PagerDuty URL: %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("PagerDuty URL %s must be an http or https URL", u.Redacted())
+		}
+	}
+	if pd.Severity != "" && !IsPagerDutySeverity(pd.Severity) {
+		return fmt.Errorf("PagerDuty severity %q must be one of %s", pd.Severity, strings.Join(PagerDutySeverities, ", "))
+	}
+	if len(pd.SecureSettings[PagerDutyRoutingKeyKey]) == 0 {
+		return errors.New("PagerDuty configuration must have a routing key")
+	}
+	return nil
+}
+
+func (c *AWSConfig) validate() error {
+	if c.Region == "" {
+		return errors.New("AWS configuration must have a region")
+	}
+	if (c.TopicARN == "") == (c.QueueURL == "") {
+		return errors.New("AWS configuration must have either an SNS topic ARN or an SQS queue URL")
+	}
+	if c.TopicARN != "" {
+		if a, err := arn.Parse(c.TopicARN); err != nil || a.Service != "sns" {
+			return fmt.Errorf("invalid SNS topic ARN %q", c.TopicARN)
+		}
+	}
+	if c.QueueURL != "" {
+		if err := validateHTTPURL(c.QueueURL, "SQS queue URL"); err != nil {
+			return err
+		}
+	}
+	if c.AssumeRoleARN != "" {
+		if a, err := arn.Parse(c.AssumeRoleARN); err != nil || a.Service != "iam" {
+			return fmt.Errorf("invalid IAM role ARN %q", c.AssumeRoleARN)
+		}
+	} else if c.ExternalID != "" {
+		return errors.New("AWS external ID requires a role to assume")
+	}
+	if c.Endpoint != "" {
+		if err := validateHTTPURL(c.Endpoint, "AWS endpoint"); err != nil {
+			return err
+		}
+	}
+	if (len(c.SecureSettings[AWSAccessKeyKey]) == 0) != (len(c.SecureSettings[AWSSecretKeyKey]) == 0) {
+		return errors.New("AWS credentials must have both an access key and a secret key")
+	}
+	return nil
+}
+
+func validateHTTPURL(rawURL, name string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s %s must be an http or https URL", name, u.Redacted())
+	}
+	return nil
+}
+
+// IsPagerDutySeverity returns true if the severity is a severity of the events of the PagerDuty Events API v2.
+func IsPagerDutySeverity(severity string) bool {
+	for _, s := range PagerDutySeverities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// HasExternalTargets returns true if alerts of the organization are sent outside of Grafana, to Alertmanager(s)
+// or directly to PagerDuty, webhooks, SNS or SQS.
+func (ac *AdminConfiguration) HasExternalTargets() bool {
+	return len(ac.Alertmanagers) > 0 || ac.PagerDuty != nil || len(ac.Webhooks) > 0 || ac.AWS != nil
+}
+
+func validateTimeout(timeout string) error {
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout %q: %w", timeout, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("timeout %q must be positive", timeout)
+	}
+	return nil
+}
+
+func validateProxyURL(proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("proxy %s must be an http, https or socks5 URL", u.Redacted())
+	}
+	if u.Host == "" {
+		return fmt.Errorf("proxy %s has no host", u.Redacted())
+	}
+	return nil
+}
+
+func (ac *AdminConfiguration) hasAlertmanager(u string) bool {
+	for _, am := range ac.Alertmanagers {
+		if am == u {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTarget returns true if the URL is the one of a configured Alertmanager, webhook or PagerDuty Events API.
+func (ac *AdminConfiguration) hasTarget(u string) bool {
+	if ac.hasAlertmanager(u) {
+		return true
+	}
+	for _, wh := range ac.Webhooks {
+		if wh.URL == u {
+			return true
+		}
+	}
+	if ac.AWS != nil && ac.AWS.Target() == u {
+		return true
+	}
+	return ac.PagerDuty != nil && (ac.PagerDuty.URL == u || (ac.PagerDuty.URL == "" && u == PagerDutyDefaultURL))
+}
+
+// Inconsistencies returns the reasons why the configuration is logically inconsistent, e.g. Alertmanagers
+// that are configured but never used. Inconsistent configurations are still valid.
+func (ac *AdminConfiguration) Inconsistencies() []string {
+	var res []string
+	if ac.SendAlertsTo == ExternalAlertmanagers && !ac.HasExternalTargets() {
+		res = append(res, "alerts are sent to external Alertmanagers only but none is configured")
+	}
+	if ac.SendAlertsTo == InternalAlertmanager && ac.HasExternalTargets() && len(ac.ExternalRuleUIDs) == 0 {
+		res = append(res, "alerts are handled by the internal Alertmanager only but external Alertmanagers are configured")
+	}
+	if len(ac.ExternalRuleUIDs) > 0 && !ac.HasExternalTargets() {
+		res = append(res, "alerts of some rules are sent to external Alertmanagers only but none is configured")
+	}
+	return res
+}
+
 // String implements the Stringer interface
 func (amc AlertmanagersChoice) String() string {
 	return alertmanagersChoiceMap[amc]
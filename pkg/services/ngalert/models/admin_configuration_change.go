@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// AdminConfigurationChangeUpdate and AdminConfigurationChangeDelete are the actions of AdminConfigurationChange.
+const (
+	AdminConfigurationChangeUpdate = "update"
+	AdminConfigurationChangeDelete = "delete"
+)
+
+// AdminConfigurationChange is a change of the admin configuration of an organization, kept to trace why the
+// alerts of the organization are, or are no longer, sent to its external Alertmanager(s).
+type AdminConfigurationChange struct {
+	ID    int64 `xorm:"pk autoincr 'id'"`
+	OrgID int64 `xorm:"org_id"`
+	// UserID and Login are the user who changed the configuration, 0 and empty if it was not changed by a user.
+	UserID int64  `xorm:"user_id"`
+	Login  string `xorm:"login"`
+	Action string `xorm:"action"`
+	// OldAlertmanagers and OldSendAlertsTo are the configuration before the change, empty if there was none.
+	// NewAlertmanagers and NewSendAlertsTo are the configuration after the change, empty if it was deleted.
+	OldAlertmanagers []string  `xorm:"old_alertmanagers"`
+	NewAlertmanagers []string  `xorm:"new_alertmanagers"`
+	OldSendAlertsTo  string    `xorm:"old_send_alerts_to"`
+	NewSendAlertsTo  string    `xorm:"new_send_alerts_to"`
+	CreatedAt        time.Time `xorm:"created_at"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (c *AdminConfigurationChange) TableName() string {
+	return "ngalert_configuration_change"
+}
@@ -77,6 +77,8 @@ const (
 const (
 	RuleUIDLabel      = "__alert_rule_uid__"
 	NamespaceUIDLabel = "__alert_rule_namespace_uid__"
+	// ReceiverLabel is the contact point the alerts of a rule with notification settings are routed to.
+	ReceiverLabel = "__grafana_receiver__"
 
 	// Annotations are actually a set of labels, so technically this is the label name of an annotation.
 	DashboardUIDAnnotation = "__dashboardUid__"
@@ -85,6 +87,17 @@ const (
 	// This isn't a hard-coded secret token, hence the nolint.
 	//nolint:gosec
 	ScreenshotTokenAnnotation = "__alertScreenshotToken__"
+	// ImageURLAnnotation is the URL of the screenshot of an alert, when it was uploaded to an external image
+	// storage, so that external Alertmanager(s) and the notification systems behind them can show it.
+	ImageURLAnnotation = "__alertImageUrl__"
+
+	// ValuesAnnotation is the values of the last evaluation of an alert, by RefID, in JSON. StateReasonAnnotation
+	// is why an alert is in its state when it is not the state of the last evaluation, e.g. NoData or Error.
+	// EvaluationsInStateAnnotation is the number of consecutive evaluations with the state of the last one, up to
+	// the number of evaluations kept.
+	ValuesAnnotation             = "__values__"
+	StateReasonAnnotation        = "__state_reason__"
+	EvaluationsInStateAnnotation = "__evaluations_in_state__"
 )
 
 var (
@@ -92,11 +105,13 @@ var (
 	InternalLabelNameSet = map[string]struct{}{
 		RuleUIDLabel:      {},
 		NamespaceUIDLabel: {},
+		ReceiverLabel:     {},
 	}
 	InternalAnnotationNameSet = map[string]struct{}{
 		DashboardUIDAnnotation:    {},
 		PanelIDAnnotation:         {},
 		ScreenshotTokenAnnotation: {},
+		ImageURLAnnotation:        {},
 	}
 )
 
@@ -122,6 +137,55 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Record makes the rule a recording rule, nil for alert rules.
+	Record *Record `xorm:"record"`
+	// NotificationSettings routes the alerts of the rule to a contact point, nil if they are routed by the
+	// notification policies.
+	NotificationSettings *NotificationSettings `xorm:"notification_settings"`
+	// IsPaused stops the evaluation of the rule, its alerts are resolved.
+	IsPaused bool `xorm:"is_paused"`
+	// AlignEvaluation evaluates the rule at multiples of its interval, even if the evaluations of the rules are
+	// jittered, for rules that need aligned timestamps.
+	AlignEvaluation bool `xorm:"align_evaluation"`
+	// EvaluationTimeout overrides the evaluation timeout of the configuration for the rule, 0 to use it.
+	EvaluationTimeout time.Duration `xorm:"evaluation_timeout"`
+	// Dependencies are the rules of the organization the rule depends on: its firing alerts are inhibited while
+	// one of them fires.
+	Dependencies []RuleDependency `xorm:"dependencies"`
+}
+
+// Record is the definition of a recording rule. Recording rules are evaluated on their interval like alert rules,
+// but instead of alerting, the result of the query or expression From is written to the target data source as the
+// samples of the metric, one per series, labelled with the labels of the series and of the rule.
+type Record struct {
+	// Metric is the name of the metric written.
+	Metric string `json:"metric"`
+	// From is the RefID of the query or expression whose result is written, it must be made of numbers.
+	From string `json:"from"`
+	// TargetDatasourceUID is the UID of the data source the samples are written to.
+	TargetDatasourceUID string `json:"targetDatasourceUid"`
+}
+
+// NotificationSettings are the notification settings of an alert rule. The alerts of a rule with notification
+// settings are routed to its contact point before the notification policies of the Alertmanager are matched.
+type NotificationSettings struct {
+	// Receiver is the name of the contact point.
+	Receiver string `json:"receiver"`
+}
+
+// RuleDependency is a rule another rule depends on, e.g. the rule of the hosts being down for the rule of their
+// disks being full.
+type RuleDependency struct {
+	// RuleUID is the UID of the rule depended on, in the organization of the rule.
+	RuleUID string `json:"ruleUid"`
+	// Equal are the labels a firing alert of the rule must have in common with a firing alert of the rule
+	// depended on to be inhibited, e.g. the host. All the firing alerts of the rule are inhibited if empty.
+	Equal []string `json:"equal,omitempty"`
+}
+
+// IsRecording returns true if the rule is a recording rule.
+func (alertRule *AlertRule) IsRecording() bool {
+	return alertRule.Record != nil
 }
 
 type SchedulableAlertRule struct {
@@ -129,6 +193,9 @@ type SchedulableAlertRule struct {
 	OrgID           int64  `xorm:"org_id"`
 	IntervalSeconds int64
 	Version         int64
+	IsPaused        bool             `xorm:"is_paused"`
+	AlignEvaluation bool             `xorm:"align_evaluation"`
+	Dependencies    []RuleDependency `xorm:"dependencies"`
 }
 
 type LabelOption func(map[string]string)
@@ -253,9 +320,15 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
+	For                  time.Duration
+	Annotations          map[string]string
+	Labels               map[string]string
+	Record               *Record               `xorm:"record"`
+	NotificationSettings *NotificationSettings `xorm:"notification_settings"`
+	IsPaused             bool                  `xorm:"is_paused"`
+	AlignEvaluation      bool                  `xorm:"align_evaluation"`
+	EvaluationTimeout    time.Duration         `xorm:"evaluation_timeout"`
+	Dependencies         []RuleDependency      `xorm:"dependencies"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -334,6 +407,9 @@ type Condition struct {
 
 	// Data is an array of data source queries and/or server side expressions.
 	Data []AlertQuery `json:"data"`
+
+	// Timeout overrides the evaluation timeout of the configuration when it is greater than 0.
+	Timeout time.Duration `json:"-"`
 }
 
 // IsValid checks the condition's validity.
@@ -346,7 +422,8 @@ func (c Condition) IsValid() bool {
 // There are several exceptions:
 // 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations and AlertRule.Labels
 // 2. There are fields that are patched together:
-//    - AlertRule.Condition and AlertRule.Data
+//   - AlertRule.Condition and AlertRule.Data
+//
 // If either of the pair is specified, neither is patched.
 func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRule) {
 	if ruleToPatch.Title == "" {
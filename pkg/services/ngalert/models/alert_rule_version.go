@@ -0,0 +1,34 @@
+package models
+
+import "errors"
+
+// ErrAlertRuleVersionNotFound is returned when a rule does not have the requested version.
+var ErrAlertRuleVersionNotFound = errors.New("alert rule version not found")
+
+// AlertRule returns the rule as it was defined at the version. The fields not kept by versions, such as the
+// dashboard and panel the rule is linked to, are left empty.
+func (v *AlertRuleVersion) AlertRule() AlertRule {
+	return AlertRule{
+		OrgID:                v.RuleOrgID,
+		UID:                  v.RuleUID,
+		NamespaceUID:         v.RuleNamespaceUID,
+		RuleGroup:            v.RuleGroup,
+		Version:              v.Version,
+		Updated:              v.Created,
+		Title:                v.Title,
+		Condition:            v.Condition,
+		Data:                 v.Data,
+		IntervalSeconds:      v.IntervalSeconds,
+		NoDataState:          v.NoDataState,
+		ExecErrState:         v.ExecErrState,
+		For:                  v.For,
+		Annotations:          v.Annotations,
+		Labels:               v.Labels,
+		Record:               v.Record,
+		NotificationSettings: v.NotificationSettings,
+		IsPaused:             v.IsPaused,
+		AlignEvaluation:      v.AlignEvaluation,
+		EvaluationTimeout:    v.EvaluationTimeout,
+		Dependencies:         v.Dependencies,
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// DeadLetterAlert is an alert that could not be delivered because its organization had neither a local
+// notifier nor a sender. It is kept until it is delivered again or purged, only the latest version of an
+// alert of a rule is kept.
+type DeadLetterAlert struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	OrgID   int64  `xorm:"org_id"`
+	RuleUID string `xorm:"rule_uid"`
+	// Fingerprint identifies the alert among the ones of the rule, by its labels.
+	Fingerprint string `xorm:"fingerprint"`
+	// Alert is the alert, as sent to Alertmanagers, in JSON.
+	Alert     string    `xorm:"alert"`
+	CreatedAt time.Time `xorm:"created_at"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (a *DeadLetterAlert) TableName() string {
+	return "alert_dead_letter"
+}
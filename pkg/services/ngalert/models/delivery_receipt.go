@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// DeliveryReceipt is the outcome of sending an alert of a batch to an external target: an Alertmanager, the
+// PagerDuty service or a webhook of the organization.
+type DeliveryReceipt struct {
+	ID    int64 `xorm:"pk autoincr 'id'"`
+	OrgID int64 `xorm:"org_id"`
+	// BatchID identifies the batch of alerts the alert was sent with. The same batch sent to several targets,
+	// or sent again, has the same ID.
+	BatchID string `xorm:"batch_id"`
+	// Target is the URL of the target the alert was sent to, without its credentials.
+	Target     string         `xorm:"target"`
+	RuleUID    string         `xorm:"rule_uid"`
+	Labels     InstanceLabels `xorm:"labels"`
+	LabelsHash string         `xorm:"labels_hash"`
+	// StatusCode is the HTTP status code returned by the target, 0 if no response was received. Error is
+	// the reason the alert was not delivered, empty if it was.
+	StatusCode int    `xorm:"status_code"`
+	Error      string `xorm:"error"`
+	// Attempts is the number of times the batch was sent, more than one if the sends were retried.
+	Attempts int       `xorm:"attempts"`
+	SentAt   time.Time `xorm:"sent_at"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (r *DeliveryReceipt) TableName() string {
+	return "alert_delivery_receipt"
+}
+
+// Delivered returns whether the target accepted the alert.
+func (r *DeliveryReceipt) Delivered() bool {
+	return r.Error == ""
+}
+
+// GetDeliveryReceiptsQuery is the query for the delivery receipts of the alerts of an organization.
+type GetDeliveryReceiptsQuery struct {
+	OrgID int64
+	// RuleUID, Labels and BatchID restrict the receipts to the ones of the alerts of a rule, of the alerts with
+	// exactly these labels and of a batch, if set.
+	RuleUID string
+	Labels  InstanceLabels
+	BatchID string
+	// From and To restrict the receipts to the ones of the alerts sent at or after From and before To, if set.
+	From time.Time
+	To   time.Time
+	// Limit is the most receipts returned, the most recent ones, all of them if 0.
+	Limit int
+
+	Result []*DeliveryReceipt
+}
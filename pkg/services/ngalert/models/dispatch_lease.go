@@ -0,0 +1,18 @@
+package models
+
+// DispatchLease grants a scheduler the dispatch of the alerts of an organization to its external targets, until it
+// expires.
+type DispatchLease struct {
+	ID     int64  `xorm:"pk autoincr 'id'"`
+	OrgID  int64  `xorm:"org_id"`
+	Holder string `xorm:"holder"`
+	// ExpiresAt is when the lease expires unless it is renewed, in seconds since the epoch.
+	ExpiresAt int64 `xorm:"expires_at"`
+	// Version is incremented every time the lease is acquired or renewed, so that a single scheduler does it.
+	Version int64 `xorm:"version"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (l *DispatchLease) TableName() string {
+	return "alert_dispatch_lease"
+}
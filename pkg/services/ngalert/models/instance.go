@@ -34,6 +34,9 @@ const (
 	InstanceStateError InstanceStateType = "Error"
 )
 
+// StateReasonPaused is the reason of the states of paused rules, normal until the rules are resumed.
+const StateReasonPaused = "Paused"
+
 // IsValid checks that the value of InstanceStateType is a valid
 // string.
 func (i InstanceStateType) IsValid() bool {
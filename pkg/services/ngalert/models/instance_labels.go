@@ -8,6 +8,7 @@ import (
 	"sort"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
 )
 
 // InstanceLabels is an extension to data.Labels with methods
@@ -65,6 +66,12 @@ func (il *InstanceLabels) StringAndHash() (string, string, error) {
 	return string(b), fmt.Sprintf("%x", h.Sum(nil)), nil
 }
 
+// Fingerprint returns a hash of the labels that does not depend on their order, the same the Alertmanager
+// identifies alerts with.
+func (il *InstanceLabels) Fingerprint() string {
+	return prometheusModel.Fingerprint(prometheusModel.LabelsToSignature(*il)).String()
+}
+
 // The following is based on SDK code, copied for now
 
 // tupleLables is an alternative representation of Labels (map[string]string) that can be sorted
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"testing"
+
+	prometheusModel "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceLabelsFingerprint(t *testing.T) {
+	labels := InstanceLabels{"alertname": "test", "instance": "a", "job": "node"}
+	same := InstanceLabels{}
+	for _, k := range []string{"job", "instance", "alertname"} {
+		same[k] = labels[k]
+	}
+
+	require.Equal(t, labels.Fingerprint(), same.Fingerprint())
+	require.NotEqual(t, labels.Fingerprint(), (&InstanceLabels{"alertname": "test", "instance": "b", "job": "node"}).Fingerprint())
+
+	lset := prometheusModel.LabelSet{"alertname": "test", "instance": "a", "job": "node"}
+	require.Equal(t, lset.Fingerprint().String(), labels.Fingerprint())
+}
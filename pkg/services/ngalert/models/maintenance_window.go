@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+// ErrMaintenanceWindowNotFound is returned when a maintenance window does not exist in the organization.
+var ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+
+// MaintenanceWindow is a period of time during which the firing alerts of an organization matching its matchers
+// are suppressed, neither put in the local notifier nor sent to external Alertmanager(s). Matching rules are not
+// evaluated at all during the window if SkipEvaluation is set.
+type MaintenanceWindow struct {
+	ID    int64  `xorm:"pk autoincr 'id'"`
+	OrgID int64  `xorm:"org_id"`
+	UID   string `xorm:"uid"`
+	Title string `xorm:"title"`
+	// StartsAt is when the window starts, included, and EndsAt when it ends, excluded.
+	StartsAt time.Time `xorm:"starts_at"`
+	EndsAt   time.Time `xorm:"ends_at"`
+	// Matchers are the label matchers of the alerts suppressed, such as severity="critical", all of which must
+	// match. A window without matchers suppresses all the alerts of the organization.
+	Matchers []string `xorm:"matchers"`
+	// SkipEvaluation also stops the evaluation of the rules whose labels, with the alertname and rule UID
+	// labels, match during the window.
+	SkipEvaluation bool      `xorm:"skip_evaluation"`
+	Updated        time.Time `xorm:"updated"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (w *MaintenanceWindow) TableName() string {
+	return "alert_maintenance_window"
+}
+
+// ActiveAt tells whether the window is active at the time.
+func (w *MaintenanceWindow) ActiveAt(t time.Time) bool {
+	return !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+}
+
+// LabelMatchers returns the parsed matchers of the window.
+func (w *MaintenanceWindow) LabelMatchers() (labels.Matchers, error) {
+	matchers := make(labels.Matchers, 0, len(w.Matchers))
+	for _, s := range w.Matchers {
+		m, err := labels.ParseMatcher(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: %w", s, err)
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// Validate checks that the window has a title, ends after it starts and that its matchers are valid.
+func (w *MaintenanceWindow) Validate() error {
+	if w.Title == "" {
+		return errors.New("title is required")
+	}
+	if w.StartsAt.IsZero() || w.EndsAt.IsZero() {
+		return errors.New("start and end times are required")
+	}
+	if !w.EndsAt.After(w.StartsAt) {
+		return errors.New("end time must be after start time")
+	}
+	_, err := w.LabelMatchers()
+	return err
+}
+
+// MaintenanceSuppression records the suppression of a firing alert by a maintenance window, for audit. Only one
+// suppression is kept per alert of a rule and window, updated every time the alert is suppressed again.
+type MaintenanceSuppression struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	OrgID     int64  `xorm:"org_id"`
+	WindowUID string `xorm:"window_uid"`
+	RuleUID   string `xorm:"rule_uid"`
+	// Fingerprint identifies the alert among the ones of the rule, by its labels.
+	Fingerprint string            `xorm:"fingerprint"`
+	Labels      map[string]string `xorm:"labels"`
+	// FirstSuppressedAt and LastSuppressedAt are when the alert was suppressed for the first and last time by the
+	// window, and Count how many times it was.
+	FirstSuppressedAt time.Time `xorm:"first_suppressed_at"`
+	LastSuppressedAt  time.Time `xorm:"last_suppressed_at"`
+	Count             int64     `xorm:"count"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (s *MaintenanceSuppression) TableName() string {
+	return "alert_maintenance_suppression"
+}
@@ -0,0 +1,14 @@
+package models
+
+// SchedulerMember is a scheduler sharing the evaluation of the alert rules with the other members alive.
+type SchedulerMember struct {
+	ID       int64  `xorm:"pk autoincr 'id'"`
+	MemberID string `xorm:"member_id"`
+	// Heartbeat is the last time the member was alive, in seconds since the epoch.
+	Heartbeat int64 `xorm:"heartbeat"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (m *SchedulerMember) TableName() string {
+	return "alert_scheduler_member"
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// AlertStateTransition is a change of the state, or of the reason of the state, of an alert instance.
+type AlertStateTransition struct {
+	ID         int64          `xorm:"pk autoincr 'id'"`
+	OrgID      int64          `xorm:"org_id"`
+	RuleUID    string         `xorm:"rule_uid"`
+	Labels     InstanceLabels `xorm:"labels"`
+	LabelsHash string         `xorm:"labels_hash"`
+	// RuleVersion is the version of the rule whose evaluation changed the state, 0 for the transitions recorded
+	// before it was.
+	RuleVersion int64 `xorm:"rule_version"`
+	// PreviousState and PreviousReason are the state before the transition, State and Reason the one after.
+	PreviousState  InstanceStateType `xorm:"previous_state"`
+	PreviousReason string            `xorm:"previous_reason"`
+	State          InstanceStateType `xorm:"state"`
+	Reason         string            `xorm:"reason"`
+	// TransitionedAt is the time of the evaluation that changed the state.
+	TransitionedAt time.Time `xorm:"transitioned_at"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (t *AlertStateTransition) TableName() string {
+	return "alert_state_history"
+}
+
+// GetAlertStateHistoryQuery is the query for the state transitions of the alert instances of an organization.
+type GetAlertStateHistoryQuery struct {
+	OrgID int64
+	// RuleUID and Labels restrict the transitions to the ones of a rule and of the instances with exactly
+	// these labels, if set.
+	RuleUID string
+	Labels  InstanceLabels
+	// NamespaceUIDs restricts the transitions to the ones of the rules in these folders, if set.
+	NamespaceUIDs []string
+	// From and To restrict the transitions to the ones at or after From and before To, if set.
+	From time.Time
+	To   time.Time
+	// Limit is the most transitions returned, the most recent ones, all of them if 0.
+	Limit int
+
+	Result []*AlertStateTransition
+}
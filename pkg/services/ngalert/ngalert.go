@@ -2,7 +2,9 @@ package ngalert
 
 import (
 	"context"
+	"fmt"
 	"net/url"
+	"path/filepath"
 
 	"github.com/benbjohnson/clock"
 	"golang.org/x/sync/errgroup"
@@ -11,10 +13,12 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
@@ -24,6 +28,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
@@ -35,7 +40,8 @@ import (
 func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService, routeRegister routing.RouteRegister,
 	sqlStore *sqlstore.SQLStore, kvStore kvstore.KVStore, expressionService *expr.Service, dataProxy *datasourceproxy.DataSourceProxyService,
 	quotaService *quota.QuotaService, secretsService secrets.Service, notificationService notifications.Service, m *metrics.NGAlert,
-	folderService dashboards.FolderService, ac accesscontrol.AccessControl, dashboardService dashboards.DashboardService, renderService rendering.Service) (*AlertNG, error) {
+	folderService dashboards.FolderService, ac accesscontrol.AccessControl, dashboardService dashboards.DashboardService, renderService rendering.Service,
+	liveService *live.GrafanaLive) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:                 cfg,
 		DataSourceCache:     dataSourceCache,
@@ -53,6 +59,7 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 		accesscontrol:       ac,
 		dashboardService:    dashboardService,
 		renderService:       renderService,
+		liveService:         liveService,
 	}
 
 	if ng.IsDisabled() {
@@ -86,10 +93,14 @@ type AlertNG struct {
 	stateManager        *state.Manager
 	folderService       dashboards.FolderService
 	dashboardService    dashboards.DashboardService
+	// liveService broadcasts the alert events to the subscribers of LiveEventsChannel, nil does not.
+	liveService *live.GrafanaLive
 
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
-	accesscontrol        accesscontrol.AccessControl
+	// AlertEnrichers mutate the alerts before they are delivered, enrichers can be registered by plugins.
+	AlertEnrichers *schedule.AlertEnrichers
+	accesscontrol  accesscontrol.AccessControl
 }
 
 func (ng *AlertNG) init() error {
@@ -105,6 +116,15 @@ func (ng *AlertNG) init() error {
 		DashboardService: ng.dashboardService,
 	}
 
+	// The admin configurations provisioned from files are saved before the scheduler starts, it applies them
+	// when it syncs the admin configurations, at start and then on every poll.
+	if ng.Cfg.ProvisioningPath != "" {
+		adminConfigProvisioner := provisioning.NewAdminConfigFileProvisioner(store, store, ng.SecretsService, log.New("ngalert.provisioning.adminconfig"))
+		if _, err := adminConfigProvisioner.Provision(context.Background(), filepath.Join(ng.Cfg.ProvisioningPath, "alerting")); err != nil {
+			return fmt.Errorf("failed to provision the admin configurations: %w", err)
+		}
+	}
+
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
 	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, ng.KVStore, store, decryptFn, multiOrgMetrics, ng.NotificationService, log.New("ngalert.multiorg.alertmanager"), ng.SecretsService)
@@ -123,6 +143,9 @@ func (ng *AlertNG) init() error {
 		return err
 	}
 
+	if ng.AlertEnrichers == nil {
+		ng.AlertEnrichers = schedule.NewAlertEnrichers()
+	}
 	schedCfg := schedule.SchedulerCfg{
 		C:                       clock.New(),
 		BaseInterval:            ng.Cfg.UnifiedAlerting.BaseInterval,
@@ -132,13 +155,45 @@ func (ng *AlertNG) init() error {
 		InstanceStore:           store,
 		RuleStore:               store,
 		AdminConfigStore:        store,
+		DeadLetterStore:         store,
+		DeliveryReceiptStore:    store,
+		MaintenanceWindowStore:  store,
+		AlertEnrichers:          ng.AlertEnrichers,
 		OrgStore:                store,
 		MultiOrgNotifier:        ng.MultiOrgAlertmanager,
 		Metrics:                 ng.Metrics.GetSchedulerMetrics(),
 		AdminConfigPollInterval: ng.Cfg.UnifiedAlerting.AdminConfigPollInterval,
 		DisabledOrgs:            ng.Cfg.UnifiedAlerting.DisabledOrgs,
 		MinRuleInterval:         ng.Cfg.UnifiedAlerting.MinInterval,
+		EvaluationJitter:        ng.Cfg.UnifiedAlerting.EvaluationJitter,
+		DecryptFn:               ng.SecretsService.GetDecryptedValue,
+		DatasourceConcurrency:   ng.datasourceConcurrency,
+		RecordingWriter:         writer.NewDatasourceWriter(ng.DataSourceCache, ng.SecretsService, log.New("ngalert.writer")),
+		HeartbeatURL:            ng.Cfg.UnifiedAlerting.HeartbeatURL,
+		HeartbeatAPIKey:         ng.Cfg.UnifiedAlerting.HeartbeatAPIKey,
+		CircuitBreaker: schedule.CircuitBreaker{
+			Threshold:  ng.Cfg.UnifiedAlerting.CircuitBreakerThreshold,
+			MaxBackoff: ng.Cfg.UnifiedAlerting.CircuitBreakerMaxBackoff,
+		},
+		DefaultAlertQuota: schedule.AlertQuota{
+			PerOrg:  ng.Cfg.UnifiedAlerting.MaxFiringAlertsPerOrg,
+			PerRule: ng.Cfg.UnifiedAlerting.MaxFiringAlertsPerRule,
+		},
+	}
+	if ng.Cfg.UnifiedAlerting.HAEvaluationSharding {
+		schedCfg.MemberStore = store
+		schedCfg.MemberHeartbeatInterval = ng.Cfg.UnifiedAlerting.HAShardingHeartbeatInterval
 	}
+	if ng.Cfg.UnifiedAlerting.HADispatchLeaderElection {
+		if ng.Cfg.UnifiedAlerting.HAEvaluationSharding {
+			// Each rule is evaluated by a single instance already, which dispatches its alerts.
+			ng.Log.Warn("ha_dispatch_leader_election is ignored when ha_evaluation_sharding is enabled")
+		} else {
+			schedCfg.DispatchLeaseStore = store
+			schedCfg.DispatchLeaseInterval = ng.Cfg.UnifiedAlerting.HADispatchLeaseInterval
+		}
+	}
+	applyDeliverySettings(&schedCfg, ng.Cfg.UnifiedAlerting)
 
 	appUrl, err := url.Parse(ng.Cfg.AppURL)
 	if err != nil {
@@ -146,7 +201,20 @@ func (ng *AlertNG) init() error {
 		appUrl = nil
 	}
 
-	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store, ng.SQLStore, ng.dashboardService, ng.imageService)
+	// The state transitions and the alerts dispatched are broadcast to the subscribers of LiveEventsChannel.
+	var events *liveEventPublisher
+	if ng.liveService != nil {
+		events = &liveEventPublisher{publish: ng.liveService.Publish, log: log.New("ngalert.events")}
+		schedCfg.DispatchSink = events.publishDispatch
+	}
+
+	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store, store, ng.SQLStore, ng.dashboardService, ng.imageService)
+	if events != nil {
+		stateManager.OnTransition(events.publishTransition)
+	}
+	if ng.Cfg.UnifiedAlerting.StableAlertFingerprints {
+		stateManager.UseFingerprintKeys()
+	}
 	scheduler := schedule.NewScheduler(schedCfg, ng.ExpressionService, appUrl, stateManager)
 
 	ng.stateManager = stateManager
@@ -157,6 +225,7 @@ func (ng *AlertNG) init() error {
 	contactPointService := provisioning.NewContactPointService(store, ng.SecretsService, store, store, ng.Log)
 	templateService := provisioning.NewTemplateService(store, store, store, ng.Log)
 	muteTimingService := provisioning.NewMuteTimingService(store, store, store, ng.Log)
+	maintenanceWindowService := provisioning.NewMaintenanceWindowService(store, ng.Log)
 	alertRuleService := provisioning.NewAlertRuleService(store, store, store, int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()), ng.Log)
 
 	api := api.API{
@@ -173,6 +242,8 @@ func (ng *AlertNG) init() error {
 		RuleStore:            store,
 		AlertingStore:        store,
 		AdminConfigStore:     store,
+		StateHistoryStore:    store,
+		DeliveryReceiptStore: store,
 		ProvenanceStore:      store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
 		StateManager:         ng.stateManager,
@@ -182,6 +253,7 @@ func (ng *AlertNG) init() error {
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
 		AlertRules:           alertRuleService,
+		MaintenanceWindows:   maintenanceWindowService,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -206,6 +278,15 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	return children.Wait()
 }
 
+// DeliveryHealth returns the health of the delivery of the alerts to external Alertmanager(s). It returns false
+// if this instance does not evaluate alert rules, and so does not deliver alerts.
+func (ng *AlertNG) DeliveryHealth() (schedule.DeliveryHealth, bool) {
+	if ng.IsDisabled() || !ng.Cfg.UnifiedAlerting.ExecuteAlerts || ng.schedule == nil {
+		return schedule.DeliveryHealth{}, false
+	}
+	return ng.schedule.DeliveryHealth(), true
+}
+
 // IsDisabled returns true if the alerting service is disable for this instance.
 func (ng *AlertNG) IsDisabled() bool {
 	if ng.Cfg == nil {
@@ -213,3 +294,92 @@ func (ng *AlertNG) IsDisabled() bool {
 	}
 	return !ng.Cfg.UnifiedAlerting.IsEnabled()
 }
+
+// datasourceConcurrency returns the most alert rules querying the datasource evaluated at the same time, as set
+// in the datasource settings, 0 if there is no limit or the datasource cannot be found.
+func (ng *AlertNG) datasourceConcurrency(ctx context.Context, orgID int64, datasourceUID string) int {
+	ds, err := ng.DataSourceCache.GetDatasourceByUID(ctx, datasourceUID, &models.SignedInUser{
+		OrgId:   orgID,
+		OrgRole: models.ROLE_ADMIN,
+	}, false)
+	if err != nil {
+		return 0
+	}
+	return ds.AlertingMaxConcurrentEvaluations()
+}
+
+// applyDeliverySettings sets the options of the scheduler that control how alerts are delivered, as set in the
+// [unified_alerting] section.
+func applyDeliverySettings(schedCfg *schedule.SchedulerCfg, ua setting.UnifiedAlertingSettings) {
+	schedCfg.DefaultMaxResolvedAlertAge = ua.MaxResolvedAlertAge
+	if len(ua.ExternalLabelMatcherValues) > 0 {
+		schedCfg.DefaultExternalLabelMatcher = &schedule.ExternalLabelMatcher{
+			Label:  ua.ExternalLabelMatcherLabel,
+			Values: ua.ExternalLabelMatcherValues,
+		}
+	}
+	schedCfg.StrictAdminConfig = ua.StrictAdminConfig
+	schedCfg.DefaultSenderQueue.PriorityLabel = ua.ExternalPriorityLabel
+	schedCfg.DefaultSenderQueue.HighPriorityValues = ua.ExternalHighPriorityValues
+	schedCfg.OrderedDeliveryOrgs = ua.OrderedDeliveryOrgs
+	if ua.AdminConfigAuditLog {
+		auditLog := log.New("ngalert.audit")
+		schedCfg.AuditSink = func(e schedule.AuditEvent) {
+			auditLog.Info("sender changed", "org", e.OrgID, "reason", e.Reason, "old_hash", e.OldHash, "new_hash", e.NewHash, "timestamp", e.Timestamp)
+		}
+	}
+	schedCfg.UnhealthyThreshold = ua.ExternalUnhealthyThreshold
+	schedCfg.HealthyThreshold = ua.ExternalHealthyThreshold
+	schedCfg.StartupNotification = ua.StartupNotification
+	schedCfg.StartupNotificationLabels = ua.StartupNotificationLabels
+	schedCfg.RoutingModeStabilization = ua.RoutingModeStabilization
+	schedCfg.CompressedSendsOrgs = ua.CompressedSendsOrgs
+	if len(ua.FingerprintIgnoredLabels) > 0 {
+		schedCfg.Fingerprint = schedule.FingerprintWithoutLabels(ua.FingerprintIgnoredLabels...)
+	}
+	if ua.FingerprintTrimLabels || len(ua.FingerprintLowercaseLabels) > 0 {
+		schedCfg.Fingerprint = schedule.NormalizedFingerprint(schedCfg.Fingerprint, schedule.LabelNormalization{
+			TrimSpace:      ua.FingerprintTrimLabels,
+			LowercaseNames: ua.FingerprintLowercaseLabels,
+		})
+	}
+	schedCfg.LocalFallback = ua.ExternalLocalFallback
+	switch ua.MissingLocalNotifier {
+	case "warn":
+		schedCfg.MissingLocalNotifier = schedule.MissingLocalNotifierWarn
+	case "error":
+		schedCfg.MissingLocalNotifier = schedule.MissingLocalNotifierError
+	default:
+		schedCfg.MissingLocalNotifier = schedule.MissingLocalNotifierIgnore
+	}
+	schedCfg.MaxFallbackDuration = ua.MaxFallbackDuration
+	schedCfg.DefaultRequiredLabels = schedule.RequiredLabels{Labels: ua.ExternalRequiredLabels}
+	switch ua.ExternalMissingLabelsPolicy {
+	case "drop":
+		schedCfg.DefaultRequiredLabels.Policy = schedule.MissingLabelsDrop
+	case "internal":
+		schedCfg.DefaultRequiredLabels.Policy = schedule.MissingLabelsInternal
+	case "default":
+		schedCfg.DefaultRequiredLabels.Policy = schedule.MissingLabelsDefault
+	default:
+		schedCfg.DefaultRequiredLabels.Policy = schedule.MissingLabelsSend
+	}
+	schedCfg.RoutingDecisionLogs = ua.RoutingDecisionLogs
+	switch ua.DuplicateAdminConfigs {
+	case "skip":
+		schedCfg.DuplicateAdminConfigs = schedule.DuplicateAdminConfigSkip
+	default:
+		schedCfg.DuplicateAdminConfigs = schedule.DuplicateAdminConfigLatest
+	}
+	schedCfg.SendBacklogSize = ua.ExternalSendBacklogSize
+	schedCfg.SendBacklogTTL = ua.ExternalSendBacklogTTL
+	schedCfg.SenderDrainTimeout = ua.ExternalSenderDrainTimeout
+	schedCfg.ExternalResendInterval = ua.ExternalResendInterval
+	schedCfg.DefaultSenderConcurrency = ua.ExternalSenderConcurrency
+	schedCfg.DefaultExternalRateLimit = schedule.RateLimit{AlertsPerSecond: ua.ExternalRateLimit, Burst: ua.ExternalRateLimitBurst}
+	schedCfg.DefaultSenderQueue.Capacity = ua.ExternalQueueCapacity
+	schedCfg.DefaultSenderQueue.MaxBatchSize = ua.ExternalMaxBatchSize
+	schedCfg.DefaultSenderQueue.FlushInterval = ua.ExternalFlushInterval
+	schedCfg.DeliveryReceiptTTL = ua.DeliveryReceiptsTTL
+	schedCfg.DeliveryReceiptBufferSize = ua.DeliveryReceiptsBufferSize
+}
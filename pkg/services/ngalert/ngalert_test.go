@@ -0,0 +1,363 @@
+package ngalert
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestApplyDeliverySettings(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		ini    string
+		verify func(*testing.T, schedule.SchedulerCfg)
+	}{
+		{
+			desc: "defaults",
+			ini:  "[unified_alerting]",
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Zero(t, cfg.DefaultMaxResolvedAlertAge)
+				require.Nil(t, cfg.DefaultExternalLabelMatcher)
+				require.False(t, cfg.StrictAdminConfig)
+				require.Empty(t, cfg.OrderedDeliveryOrgs)
+				require.Nil(t, cfg.AuditSink)
+				require.Equal(t, 3, cfg.UnhealthyThreshold)
+				require.Equal(t, 3, cfg.HealthyThreshold)
+				require.False(t, cfg.StartupNotification)
+				require.Zero(t, cfg.RoutingModeStabilization)
+				require.Empty(t, cfg.CompressedSendsOrgs)
+				require.Nil(t, cfg.Fingerprint)
+				require.False(t, cfg.LocalFallback)
+				require.Equal(t, schedule.MissingLocalNotifierIgnore, cfg.MissingLocalNotifier)
+				require.Zero(t, cfg.MaxFallbackDuration)
+				require.Equal(t, schedule.MissingLabelsSend, cfg.DefaultRequiredLabels.Policy)
+				require.False(t, cfg.RoutingDecisionLogs)
+				require.Equal(t, schedule.DuplicateAdminConfigLatest, cfg.DuplicateAdminConfigs)
+				require.Zero(t, cfg.SendBacklogSize)
+				require.Zero(t, cfg.SendBacklogTTL)
+				require.Zero(t, cfg.SenderDrainTimeout)
+				require.Zero(t, cfg.ExternalResendInterval)
+				require.Equal(t, 1, cfg.DefaultSenderConcurrency)
+				require.Zero(t, cfg.DefaultExternalRateLimit.AlertsPerSecond)
+				require.Empty(t, cfg.DefaultSenderQueue.PriorityLabel)
+				require.Equal(t, 10000, cfg.DefaultSenderQueue.Capacity)
+				require.Equal(t, 64, cfg.DefaultSenderQueue.MaxBatchSize)
+				require.Zero(t, cfg.DefaultSenderQueue.FlushInterval)
+				require.Equal(t, 7*24*time.Hour, cfg.DeliveryReceiptTTL)
+				require.Equal(t, 10000, cfg.DeliveryReceiptBufferSize)
+			},
+		},
+		{
+			desc: "max resolved alert age",
+			ini: `[unified_alerting]
+max_resolved_alert_age = 2h`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, 2*time.Hour, cfg.DefaultMaxResolvedAlertAge)
+			},
+		},
+		{
+			desc: "external label matcher",
+			ini: `[unified_alerting]
+external_label_matcher_label = team
+external_label_matcher_values = sre, infra`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, &schedule.ExternalLabelMatcher{Label: "team", Values: []string{"sre", "infra"}}, cfg.DefaultExternalLabelMatcher)
+			},
+		},
+		{
+			desc: "strict admin configuration",
+			ini: `[unified_alerting]
+strict_admin_config = true`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.True(t, cfg.StrictAdminConfig)
+			},
+		},
+		{
+			desc: "priorities",
+			ini: `[unified_alerting]
+external_priority_label = severity
+external_high_priority_values = critical`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, "severity", cfg.DefaultSenderQueue.PriorityLabel)
+				require.Equal(t, []string{"critical"}, cfg.DefaultSenderQueue.HighPriorityValues)
+			},
+		},
+		{
+			desc: "ordered delivery",
+			ini: `[unified_alerting]
+ordered_delivery_orgs = 1, 3`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, map[int64]struct{}{1: {}, 3: {}}, cfg.OrderedDeliveryOrgs)
+			},
+		},
+		{
+			desc: "admin configuration audit log",
+			ini: `[unified_alerting]
+admin_config_audit_log = true`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.NotNil(t, cfg.AuditSink)
+				cfg.AuditSink(schedule.AuditEvent{OrgID: 1, NewHash: "hash", Reason: "create-new-sender"})
+			},
+		},
+		{
+			desc: "health thresholds",
+			ini: `[unified_alerting]
+external_alertmanagers_unhealthy_threshold = 5
+external_alertmanagers_healthy_threshold = 1`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, 5, cfg.UnhealthyThreshold)
+				require.Equal(t, 1, cfg.HealthyThreshold)
+			},
+		},
+		{
+			desc: "startup notification",
+			ini: `[unified_alerting]
+startup_notification = true
+startup_notification_labels = severity=info, team = sre`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.True(t, cfg.StartupNotification)
+				require.Equal(t, map[string]string{"severity": "info", "team": "sre"}, cfg.StartupNotificationLabels)
+			},
+		},
+		{
+			desc: "routing mode stabilization",
+			ini: `[unified_alerting]
+routing_mode_stabilization = 5m`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, 5*time.Minute, cfg.RoutingModeStabilization)
+			},
+		},
+		{
+			desc: "compressed sends",
+			ini: `[unified_alerting]
+compressed_sends_orgs = 2`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, map[int64]struct{}{2: {}}, cfg.CompressedSendsOrgs)
+			},
+		},
+		{
+			desc: "fingerprint ignored labels",
+			ini: `[unified_alerting]
+fingerprint_ignored_labels = pod`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				alert := func(pod string) amv2.PostableAlert {
+					return amv2.PostableAlert{Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "test", "pod": pod}}}
+				}
+				require.NotNil(t, cfg.Fingerprint)
+				require.Equal(t, cfg.Fingerprint(alert("a")), cfg.Fingerprint(alert("b")))
+			},
+		},
+		{
+			desc: "fingerprint label normalization",
+			ini: `[unified_alerting]
+fingerprint_ignored_labels = pod
+fingerprint_trim_labels = true
+fingerprint_lowercase_labels = severity`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				alert := func(labels amv2.LabelSet) amv2.PostableAlert {
+					return amv2.PostableAlert{Alert: amv2.Alert{Labels: labels}}
+				}
+				require.NotNil(t, cfg.Fingerprint)
+				require.Equal(t,
+					cfg.Fingerprint(alert(amv2.LabelSet{"alertname": "test", "severity": "critical", "pod": "a"})),
+					cfg.Fingerprint(alert(amv2.LabelSet{" alertname": "test ", "Severity": "critical", " pod ": "b"})))
+			},
+		},
+		{
+			desc: "local fallback",
+			ini: `[unified_alerting]
+external_alertmanagers_local_fallback = true`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.True(t, cfg.LocalFallback)
+			},
+		},
+		{
+			desc: "missing local notifier",
+			ini: `[unified_alerting]
+missing_local_notifier = error`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, schedule.MissingLocalNotifierError, cfg.MissingLocalNotifier)
+			},
+		},
+		{
+			desc: "max fallback duration",
+			ini: `[unified_alerting]
+max_fallback_duration = 15m`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, 15*time.Minute, cfg.MaxFallbackDuration)
+			},
+		},
+		{
+			desc: "required labels",
+			ini: `[unified_alerting]
+external_required_labels = team, routing_key=default
+external_missing_labels_policy = default`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, schedule.RequiredLabels{
+					Labels: map[string]string{"team": "", "routing_key": "default"},
+					Policy: schedule.MissingLabelsDefault,
+				}, cfg.DefaultRequiredLabels)
+			},
+		},
+		{
+			desc: "routing decision logs",
+			ini: `[unified_alerting]
+routing_decision_logs = true`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.True(t, cfg.RoutingDecisionLogs)
+			},
+		},
+		{
+			desc: "duplicate admin configs",
+			ini: `[unified_alerting]
+duplicate_admin_configs = skip`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, schedule.DuplicateAdminConfigSkip, cfg.DuplicateAdminConfigs)
+			},
+		},
+		{
+			desc: "send backlog",
+			ini: `[unified_alerting]
+external_send_backlog_size = 100
+external_send_backlog_ttl = 1h`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, 100, cfg.SendBacklogSize)
+				require.Equal(t, time.Hour, cfg.SendBacklogTTL)
+			},
+		},
+		{
+			desc: "sender drain timeout",
+			ini: `[unified_alerting]
+external_sender_drain_timeout = 30s`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, 30*time.Second, cfg.SenderDrainTimeout)
+			},
+		},
+		{
+			desc: "external resend interval",
+			ini: `[unified_alerting]
+external_resend_interval = 5m`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, 5*time.Minute, cfg.ExternalResendInterval)
+			},
+		},
+		{
+			desc: "sender concurrency",
+			ini: `[unified_alerting]
+external_sender_concurrency = 4`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, 4, cfg.DefaultSenderConcurrency)
+			},
+		},
+		{
+			desc: "external rate limit",
+			ini: `[unified_alerting]
+external_rate_limit = 0.5
+external_rate_limit_burst = 10`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, schedule.RateLimit{AlertsPerSecond: 0.5, Burst: 10}, cfg.DefaultExternalRateLimit)
+			},
+		},
+		{
+			desc: "sender queue",
+			ini: `[unified_alerting]
+external_queue_capacity = 100
+external_max_batch_size = 10
+external_flush_interval = 5s`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Equal(t, 100, cfg.DefaultSenderQueue.Capacity)
+				require.Equal(t, 10, cfg.DefaultSenderQueue.MaxBatchSize)
+				require.Equal(t, 5*time.Second, cfg.DefaultSenderQueue.FlushInterval)
+			},
+		},
+		{
+			desc: "delivery receipts",
+			ini: `[unified_alerting]
+delivery_receipts_ttl = 0
+delivery_receipts_buffer_size = 100`,
+			verify: func(t *testing.T, cfg schedule.SchedulerCfg) {
+				require.Zero(t, cfg.DeliveryReceiptTTL)
+				require.Equal(t, 100, cfg.DeliveryReceiptBufferSize)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f, err := ini.Load([]byte(tc.ini))
+			require.NoError(t, err)
+			cfg := setting.NewCfg()
+			cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+			require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+
+			var schedCfg schedule.SchedulerCfg
+			applyDeliverySettings(&schedCfg, cfg.UnifiedAlerting)
+			tc.verify(t, schedCfg)
+		})
+	}
+}
+
+func TestExternalRateLimitSettings(t *testing.T) {
+	f, err := ini.Load([]byte(`[unified_alerting]
+external_rate_limit = 1
+external_rate_limit_burst = 2`))
+	require.NoError(t, err)
+	cfg := setting.NewCfg()
+	cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+	require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+
+	fakeAM := store.NewFakeExternalAlertmanager(t)
+	defer fakeAM.Close()
+	adminConfigStore := store.NewFakeAdminConfigStore(t)
+	require.NoError(t, adminConfigStore.UpdateAdminConfiguration(store.UpdateAdminConfigurationCmd{
+		AdminConfiguration: &models.AdminConfiguration{OrgID: 1, Alertmanagers: []string{fakeAM.URL()}, SendAlertsTo: models.ExternalAlertmanagers},
+	}))
+
+	schedCfg := schedule.SchedulerCfg{
+		C:                       clock.NewMock(),
+		BaseInterval:            time.Second,
+		Logger:                  log.New("ngalert test"),
+		Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetSchedulerMetrics(),
+		AdminConfigStore:        adminConfigStore,
+		AdminConfigPollInterval: 10 * time.Minute,
+		CaptureSends:            true,
+	}
+	applyDeliverySettings(&schedCfg, cfg.UnifiedAlerting)
+	sched := schedule.NewScheduler(schedCfg, nil, &url.URL{Scheme: "http", Host: "localhost"}, nil)
+	require.NoError(t, sched.SyncAndApplyConfigFromDatabase())
+	t.Cleanup(func() {
+		require.NoError(t, adminConfigStore.DeleteAdminConfiguration(store.DeleteAdminConfigurationCmd{OrgID: 1}))
+		require.NoError(t, sched.SyncAndApplyConfigFromDatabase())
+	})
+	require.Eventually(t, func() bool {
+		return len(sched.AlertmanagersFor(1)) == 1
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// The organization has no rate limit of its own, the burst is sent and the other alerts are replaced by one.
+	key := models.AlertRuleKey{OrgID: 1, UID: "test"}
+	alerts := definitions.PostableAlerts{}
+	for i := 0; i < 5; i++ {
+		alerts.PostableAlerts = append(alerts.PostableAlerts, amv2.PostableAlert{
+			Alert: amv2.Alert{Labels: amv2.LabelSet{"alertname": "test", "series": fmt.Sprint(i), models.RuleUIDLabel: key.UID}},
+		})
+	}
+	require.NoError(t, sched.Replay(key, alerts))
+	captured := sched.CapturedSends(1)
+	require.Len(t, captured, 1)
+	require.Len(t, captured[0].PostableAlerts, 3)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/prometheus/alertmanager/nflog"
 	"github.com/prometheus/alertmanager/nflog/nflogpb"
 	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/alertmanager/provider/mem"
 	"github.com/prometheus/alertmanager/silence"
 	"github.com/prometheus/alertmanager/template"
@@ -323,6 +324,15 @@ func (am *Alertmanager) ApplyConfig(dbCfg *ngmodels.AlertConfiguration) error {
 }
 
 func (am *Alertmanager) getTemplate() (*template.Template, error) {
+	paths, err := am.templatePaths()
+	if err != nil {
+		return nil, err
+	}
+	return am.templateFromPaths(paths...)
+}
+
+// templatePaths returns the paths of the template files of the configuration.
+func (am *Alertmanager) templatePaths() ([]string, error) {
 	am.reloadConfigMtx.RLock()
 	defer am.reloadConfigMtx.RUnlock()
 	if !am.ready() {
@@ -332,7 +342,7 @@ func (am *Alertmanager) getTemplate() (*template.Template, error) {
 	for name := range am.config.TemplateFiles {
 		paths = append(paths, filepath.Join(am.WorkingDirPath(), name))
 	}
-	return am.templateFromPaths(paths...)
+	return paths, nil
 }
 
 func (am *Alertmanager) templateFromPaths(paths ...string) (*template.Template, error) {
@@ -403,6 +413,11 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 		return fmt.Errorf("failed to build integration map: %w", err)
 	}
 
+	root, err := withReceiverRoutes(cfg.AlertmanagerConfig.Route.AsAMRoute(), cfg.AlertmanagerConfig.Receivers)
+	if err != nil {
+		return fmt.Errorf("failed to build the routes of the contact points: %w", err)
+	}
+
 	// Now, let's put together our notification pipeline
 	routingStage := make(notify.RoutingStage, len(integrationsMap))
 
@@ -426,7 +441,7 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 		routingStage[name] = notify.MultiStage{meshStage, silencingStage, timeMuteStage, inhibitionStage, stage}
 	}
 
-	am.route = dispatch.NewRoute(cfg.AlertmanagerConfig.Route.AsAMRoute(), nil)
+	am.route = dispatch.NewRoute(root, nil)
 	am.dispatcher = dispatch.NewDispatcher(am.alerts, am.route, routingStage, am.marker, am.timeoutFunc, &nilLimits{}, am.logger, am.dispatcherMetrics)
 
 	am.wg.Add(1)
@@ -447,6 +462,25 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	return nil
 }
 
+// withReceiverRoutes returns the root route with a route per receiver prepended to its routes. The route of a
+// receiver matches the alerts of the rules whose notification settings set the receiver, so that they are routed to
+// it rather than by the notification policies. The routes inherit the options of the root route.
+func withReceiverRoutes(root *config.Route, receivers []*apimodels.PostableApiReceiver) (*config.Route, error) {
+	routes := make([]*config.Route, 0, len(receivers)+len(root.Routes))
+	for _, r := range receivers {
+		m, err := labels.NewMatcher(labels.MatchEqual, ngmodels.ReceiverLabel, r.Name)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, &config.Route{
+			Receiver: r.Name,
+			Matchers: config.Matchers{m},
+		})
+	}
+	root.Routes = append(routes, root.Routes...)
+	return root, nil
+}
+
 func (am *Alertmanager) WorkingDirPath() string {
 	return filepath.Join(am.Settings.DataPath, workingDir, strconv.Itoa(int(am.orgID)))
 }
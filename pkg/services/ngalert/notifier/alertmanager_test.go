@@ -12,6 +12,9 @@ import (
 
 	"github.com/go-openapi/strfmt"
 	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/alertmanager/provider/mem"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/client_golang/prometheus"
@@ -21,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -414,3 +418,38 @@ func TestSilenceCleanup(t *testing.T) {
 		return len(found) == 2
 	}, 6*time.Second, 150*time.Millisecond)
 }
+
+func TestWithReceiverRoutes(t *testing.T) {
+	teamMatcher, err := labels.NewMatcher(labels.MatchEqual, "team", "a")
+	require.NoError(t, err)
+	root := &config.Route{
+		Receiver: "default",
+		GroupBy:  []model.LabelName{"alertname"},
+		Routes: []*config.Route{
+			{Receiver: "team", Matchers: config.Matchers{teamMatcher}},
+		},
+	}
+	receivers := []*apimodels.PostableApiReceiver{
+		{Receiver: config.Receiver{Name: "default"}},
+		{Receiver: config.Receiver{Name: "team"}},
+		{Receiver: config.Receiver{Name: "rule"}},
+	}
+	root, err = withReceiverRoutes(root, receivers)
+	require.NoError(t, err)
+	route := dispatch.NewRoute(root, nil)
+
+	receiversOf := func(ls model.LabelSet) []string {
+		var names []string
+		for _, r := range route.Match(ls) {
+			names = append(names, r.RouteOpts.Receiver)
+			require.Equal(t, map[model.LabelName]struct{}{"alertname": {}}, r.RouteOpts.GroupBy)
+		}
+		return names
+	}
+
+	require.Equal(t, []string{"default"}, receiversOf(model.LabelSet{"alertname": "test"}))
+	require.Equal(t, []string{"team"}, receiversOf(model.LabelSet{"alertname": "test", "team": "a"}))
+	require.Equal(t, []string{"rule"}, receiversOf(model.LabelSet{"alertname": "test", "team": "a", ngmodels.ReceiverLabel: "rule"}))
+	// an unknown contact point falls back to the notification policies
+	require.Equal(t, []string{"team"}, receiversOf(model.LabelSet{"alertname": "test", "team": "a", ngmodels.ReceiverLabel: "deleted"}))
+}
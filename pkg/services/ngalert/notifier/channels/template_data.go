@@ -2,9 +2,11 @@ package channels
 
 import (
 	"context"
+	"encoding/json"
 	"net/url"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,6 +32,10 @@ type ExtendedAlert struct {
 	PanelURL     string      `json:"panelURL"`
 	ValueString  string      `json:"valueString"`
 	ImageURL     string      `json:"imageURL,omitempty"`
+
+	Values             map[string]float64 `json:"values,omitempty"`
+	StateReason        string             `json:"stateReason,omitempty"`
+	EvaluationsInState int                `json:"evaluationsInState,omitempty"`
 }
 
 type ExtendedAlerts []ExtendedAlert
@@ -67,6 +73,17 @@ func extendAlert(alert template.Alert, externalURL string, logger log.Logger) *E
 		Fingerprint:  alert.Fingerprint,
 	}
 
+	// fill in the details of the evaluations of the alert rule
+	extended.StateReason = alert.Annotations[ngmodels.StateReasonAnnotation]
+	if values := alert.Annotations[ngmodels.ValuesAnnotation]; values != "" {
+		if err := json.Unmarshal([]byte(values), &extended.Values); err != nil {
+			logger.Debug("failed to parse the values of the alert", "values", values, "err", err.Error())
+		}
+	}
+	if n := alert.Annotations[ngmodels.EvaluationsInStateAnnotation]; n != "" {
+		extended.EvaluationsInState, _ = strconv.Atoi(n)
+	}
+
 	// fill in some grafana-specific urls
 	if len(externalURL) == 0 {
 		return extended
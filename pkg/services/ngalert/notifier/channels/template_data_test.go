@@ -0,0 +1,37 @@
+package channels
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestExtendAlertEvaluationDetails(t *testing.T) {
+	alert := template.Alert{
+		Status: "firing",
+		Labels: template.KV{"alertname": "alert1"},
+		Annotations: template.KV{
+			"ann1":                     "annv1",
+			"__values__":               `{"A":1.5,"B":2}`,
+			"__state_reason__":         "NoData",
+			"__evaluations_in_state__": "3",
+		},
+	}
+
+	extended := extendAlert(alert, "", log.NewNopLogger())
+	require.Equal(t, map[string]float64{"A": 1.5, "B": 2}, extended.Values)
+	require.Equal(t, "NoData", extended.StateReason)
+	require.Equal(t, 3, extended.EvaluationsInState)
+	require.Equal(t, template.KV{"alertname": "alert1"}, extended.Labels)
+	require.Equal(t, template.KV{"ann1": "annv1"}, extended.Annotations)
+
+	t.Run("alerts without evaluation details", func(t *testing.T) {
+		extended := extendAlert(template.Alert{Annotations: template.KV{"__values__": "invalid"}}, "", log.NewNopLogger())
+		require.Nil(t, extended.Values)
+		require.Empty(t, extended.StateReason)
+		require.Zero(t, extended.EvaluationsInState)
+	})
+}
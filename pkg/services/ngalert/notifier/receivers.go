@@ -2,13 +2,16 @@ package notifier
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"sort"
+	"strings"
 	"time"
 
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/prometheus/alertmanager/notify"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/prometheus/common/model"
@@ -229,11 +232,50 @@ func newTestAlert(c apimodels.TestReceiversConfigBodyParams, startsAt, updatedAt
 				alert.Labels[k] = v
 			}
 		}
+		if len(c.Alert.Values) > 0 {
+			if b, err := json.Marshal(c.Alert.Values); err == nil {
+				alert.Annotations[ngmodels.ValuesAnnotation] = model.LabelValue(b)
+			}
+			// the value string of the default test alert does not match the values given, it is replaced
+			// unless it is given as well
+			if _, ok := c.Alert.Annotations["__value_string__"]; !ok {
+				alert.Annotations["__value_string__"] = model.LabelValue(testValueString(c.Alert.Values, alert.Labels))
+			}
+		}
 	}
 
 	return alert
 }
 
+// testValueString returns the value string of a test alert with the given values, formatted like the one of
+// the alerts of a rule.
+func testValueString(values map[string]float64, labels model.LabelSet) string {
+	refIDs := make([]string, 0, len(values))
+	for refID := range values {
+		refIDs = append(refIDs, refID)
+	}
+	sort.Strings(refIDs)
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, labels[model.LabelName(name)]))
+	}
+
+	var sb strings.Builder
+	for i, refID := range refIDs {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "[ var='%s' labels={%s} value=%v ]", refID, strings.Join(pairs, ", "), values[refID])
+	}
+	return sb.String()
+}
+
 func processNotifierError(config *apimodels.PostableGrafanaReceiver, err error) error {
 	if err == nil {
 		return nil
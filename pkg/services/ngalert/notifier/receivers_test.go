@@ -5,7 +5,9 @@ import (
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -80,3 +82,39 @@ func TestProcessNotifierError(t *testing.T) {
 		require.Equal(t, err, processNotifierError(r, err))
 	})
 }
+
+func TestNewTestAlert(t *testing.T) {
+	now := time.Now()
+
+	t.Run("the default test alert is returned without alert", func(t *testing.T) {
+		alert := newTestAlert(definitions.TestReceiversConfigBodyParams{}, now, now)
+		require.Equal(t, model.LabelValue("TestAlert"), alert.Labels["alertname"])
+		require.Equal(t, model.LabelValue("[ metric='foo' labels={instance=bar} value=10 ]"), alert.Annotations["__value_string__"])
+		require.NotContains(t, alert.Annotations, model.LabelName("__values__"))
+	})
+
+	t.Run("the labels, annotations and values of the alert are added", func(t *testing.T) {
+		alert := newTestAlert(definitions.TestReceiversConfigBodyParams{
+			Alert: &definitions.TestReceiversConfigAlertParams{
+				Labels:      model.LabelSet{"alertname": "DiskFull", "host": "a"},
+				Annotations: model.LabelSet{"summary": "Disk {{ $labels.host }} is full"},
+				Values:      map[string]float64{"B": 95.5, "A": 1},
+			},
+		}, now, now)
+		require.Equal(t, model.LabelSet{"alertname": "DiskFull", "instance": "Grafana", "host": "a"}, alert.Labels)
+		require.Equal(t, model.LabelValue("Disk {{ $labels.host }} is full"), alert.Annotations["summary"])
+		require.Equal(t, model.LabelValue(`{"A":1,"B":95.5}`), alert.Annotations["__values__"])
+		require.Equal(t, model.LabelValue("[ var='A' labels={alertname=DiskFull, host=a, instance=Grafana} value=1 ], "+
+			"[ var='B' labels={alertname=DiskFull, host=a, instance=Grafana} value=95.5 ]"), alert.Annotations["__value_string__"])
+	})
+
+	t.Run("a value string given is kept", func(t *testing.T) {
+		alert := newTestAlert(definitions.TestReceiversConfigBodyParams{
+			Alert: &definitions.TestReceiversConfigAlertParams{
+				Annotations: model.LabelSet{"__value_string__": "custom"},
+				Values:      map[string]float64{"A": 1},
+			},
+		}, now, now)
+		require.Equal(t, model.LabelValue("custom"), alert.Annotations["__value_string__"])
+	})
+}
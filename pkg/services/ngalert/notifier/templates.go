@@ -0,0 +1,146 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+const (
+	// testTemplatesFile is the name of the file of the templates tested, parsed after the ones of the configuration.
+	testTemplatesFile = "__test__.tmpl"
+	// testTemplatesReceiver is the name of the receiver the templates are rendered for.
+	testTemplatesReceiver = "test"
+)
+
+// defaultMessages are the default messages of the types of contact points that do not use default.message.
+// The message of an email is empty by default, the alerts are listed in its HTML body.
+var defaultMessages = map[string]string{
+	"email": "",
+	"teams": `{{ template "teams.default.message" . }}`,
+}
+
+type InvalidTemplateError struct {
+	Err error
+}
+
+func (e InvalidTemplateError) Error() string {
+	return fmt.Sprintf("the template is invalid: %s", e.Err)
+}
+
+type TestTemplatesResult struct {
+	Type    string
+	Title   string
+	Message string
+	Error   error
+}
+
+// TestTemplates renders the title and message of every type of contact point with the templates of the
+// configuration and the ones tested, without notifying anything. The group labels are the labels the alerts
+// have in common.
+func (am *Alertmanager) TestTemplates(ctx context.Context, c apimodels.TestTemplatesConfigBodyParams) ([]TestTemplatesResult, error) {
+	paths, err := am.templatePaths()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "ngalert-templates-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	file := filepath.Join(dir, testTemplatesFile)
+	if err := os.WriteFile(file, []byte(c.Template), 0600); err != nil {
+		return nil, err
+	}
+	tmpl, err := am.templateFromPaths(append(paths, file)...)
+	if err != nil {
+		return nil, InvalidTemplateError{Err: err}
+	}
+
+	alerts := newTestTemplatesAlerts(c, time.Now())
+	ctx = notify.WithReceiverName(ctx, testTemplatesReceiver)
+	ctx = notify.WithGroupLabels(ctx, commonLabels(alerts))
+
+	notifiers := GetAvailableNotifiers()
+	results := make([]TestTemplatesResult, 0, len(notifiers))
+	for _, n := range notifiers {
+		title, message := c.Title, c.Message
+		if title == "" {
+			title = channels.DefaultMessageTitleEmbed
+		}
+		if message == "" {
+			var ok bool
+			if message, ok = defaultMessages[n.Type]; !ok {
+				message = `{{ template "default.message" . }}`
+			}
+		}
+
+		var tmplErr error
+		expand, _ := channels.TmplText(ctx, tmpl, alerts, am.logger, &tmplErr)
+		res := TestTemplatesResult{Type: n.Type, Title: expand(title), Message: expand(message)}
+		if tmplErr != nil {
+			res = TestTemplatesResult{Type: n.Type, Error: tmplErr}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// newTestTemplatesAlerts returns the alerts the templates are rendered for, the test alert of the receivers if
+// there is none.
+func newTestTemplatesAlerts(c apimodels.TestTemplatesConfigBodyParams, now time.Time) []*types.Alert {
+	if len(c.Alerts) == 0 {
+		alert := newTestAlert(apimodels.TestReceiversConfigBodyParams{}, now, now)
+		return []*types.Alert{&alert}
+	}
+
+	alerts := make([]*types.Alert, 0, len(c.Alerts))
+	for _, a := range c.Alerts {
+		alert := &types.Alert{
+			Alert: model.Alert{
+				Labels:       model.LabelSet{},
+				Annotations:  model.LabelSet{},
+				StartsAt:     time.Time(a.StartsAt),
+				EndsAt:       time.Time(a.EndsAt),
+				GeneratorURL: a.GeneratorURL.String(),
+			},
+			UpdatedAt: now,
+		}
+		for k, v := range a.Labels {
+			alert.Labels[model.LabelName(k)] = model.LabelValue(v)
+		}
+		for k, v := range a.Annotations {
+			alert.Annotations[model.LabelName(k)] = model.LabelValue(v)
+		}
+		if alert.StartsAt.IsZero() {
+			alert.StartsAt = now
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// commonLabels returns the labels all the alerts have in common.
+func commonLabels(alerts []*types.Alert) model.LabelSet {
+	res := alerts[0].Labels.Clone()
+	for _, a := range alerts[1:] {
+		for k, v := range res {
+			if a.Labels[k] != v {
+				delete(res, k)
+			}
+		}
+	}
+	return res
+}
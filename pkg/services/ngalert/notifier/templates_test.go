@@ -0,0 +1,72 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestTestTemplates(t *testing.T) {
+	am := setupAMTest(t)
+	cfg, err := Load([]byte(setting.GetAlertmanagerDefaultConfiguration()))
+	require.NoError(t, err)
+	require.NoError(t, am.applyConfig(cfg, nil))
+
+	resultsByType := func(results []TestTemplatesResult) map[string]TestTemplatesResult {
+		m := make(map[string]TestTemplatesResult, len(results))
+		for _, r := range results {
+			m[r.Type] = r
+		}
+		return m
+	}
+
+	t.Run("the templates are rendered for every type of contact point", func(t *testing.T) {
+		results, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigBodyParams{
+			Template: `{{ define "mytitle" }}{{ len .Alerts.Firing }} firing for {{ .CommonLabels.team }}{{ end }}`,
+			Title:    `{{ template "mytitle" . }}`,
+			Alerts: []models.PostableAlert{
+				{Alert: models.Alert{Labels: models.LabelSet{"alertname": "DiskFull", "team": "ops", "host": "a"}}},
+				{Alert: models.Alert{Labels: models.LabelSet{"alertname": "DiskFull", "team": "ops", "host": "b"}}},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, len(GetAvailableNotifiers()))
+		for _, r := range results {
+			require.NoError(t, r.Error)
+			require.Equal(t, "2 firing for ops", r.Title)
+		}
+
+		byType := resultsByType(results)
+		require.Contains(t, byType["slack"].Message, "host = a")
+		require.Contains(t, byType["teams"].Message, "host = b")
+		require.Empty(t, byType["email"].Message)
+	})
+
+	t.Run("a test alert is used without alerts", func(t *testing.T) {
+		results, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigBodyParams{})
+		require.NoError(t, err)
+		slack := resultsByType(results)["slack"]
+		require.NoError(t, slack.Error)
+		require.Contains(t, slack.Title, "TestAlert")
+		require.Contains(t, slack.Message, "summary = Notification test")
+	})
+
+	t.Run("invalid templates are rejected", func(t *testing.T) {
+		_, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigBodyParams{Template: `{{ define "mytitle" }}`})
+		require.ErrorAs(t, err, &InvalidTemplateError{})
+	})
+
+	t.Run("errors rendering the templates are returned", func(t *testing.T) {
+		results, err := am.TestTemplates(context.Background(), apimodels.TestTemplatesConfigBodyParams{Title: `{{ template "missing" . }}`})
+		require.NoError(t, err)
+		for _, r := range results {
+			require.Error(t, r.Error)
+			require.Empty(t, r.Title)
+		}
+	})
+}
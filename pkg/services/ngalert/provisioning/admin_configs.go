@@ -0,0 +1,513 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/prometheus/alertmanager/timeinterval"
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// AdminConfigStore is a store of admin configurations.
+type AdminConfigStore interface {
+	GetAdminConfiguration(orgID int64) (*models.AdminConfiguration, error)
+	UpdateAdminConfiguration(store.UpdateAdminConfigurationCmd) error
+	DeleteAdminConfiguration(store.DeleteAdminConfigurationCmd) error
+}
+
+// adminConfigsProvisioningLogin is the login recorded with the changes of the admin configurations made by the
+// provisioning files.
+const adminConfigsProvisioningLogin = "provisioning"
+
+// AdminConfigFileProvisioner provisions the admin configurations of organizations, their external Alertmanager(s),
+// PagerDuty service and webhooks, from YAML files. The configurations it saves are marked as provisioned from a
+// file, they cannot be changed through the API anymore.
+//
+// The files look like:
+//
+//	apiVersion: 1
+//	adminConfigs:
+//	  - orgId: 1
+//	    sendAlertsTo: external
+//	    alertmanagers:
+//	      - https://alertmanager.example.com
+//	    credentials:
+//	      https://alertmanager.example.com:
+//	        basicAuthUser: grafana
+//	        basicAuthPassword: $AM_PASSWORD
+//	deleteAdminConfigs:
+//	  - orgId: 2
+type AdminConfigFileProvisioner struct {
+	store          AdminConfigStore
+	prov           ProvisioningStore
+	secretsService secrets.Service
+	log            log.Logger
+}
+
+func NewAdminConfigFileProvisioner(store AdminConfigStore, prov ProvisioningStore, secretsService secrets.Service, log log.Logger) *AdminConfigFileProvisioner {
+	return &AdminConfigFileProvisioner{
+		store:          store,
+		prov:           prov,
+		secretsService: secretsService,
+		log:            log,
+	}
+}
+
+type adminConfigsFile struct {
+	APIVersion         values.Int64Value            `yaml:"apiVersion"`
+	AdminConfigs       []*adminConfigFromFile       `yaml:"adminConfigs"`
+	DeleteAdminConfigs []*deleteAdminConfigFromFile `yaml:"deleteAdminConfigs"`
+}
+
+type adminConfigFromFile struct {
+	OrgID              values.Int64Value                 `yaml:"orgId"`
+	Alertmanagers      []string                          `yaml:"alertmanagers"`
+	SendAlertsTo       values.StringValue                `yaml:"sendAlertsTo"`
+	Disabled           values.BoolValue                  `yaml:"disabled"`
+	ExternalRuleUIDs   []string                          `yaml:"externalRuleUids"`
+	TLS                map[string]tlsFromFile            `yaml:"tls"`
+	Credentials        map[string]credentialsFromFile    `yaml:"credentials"`
+	ProxyURL           values.StringValue                `yaml:"proxyUrl"`
+	NoProxy            []string                          `yaml:"noProxy"`
+	ProxyURLs          map[string]string                 `yaml:"proxyUrls"`
+	APIVersions        map[string]string                 `yaml:"apiVersions"`
+	Headers            map[string]map[string]string      `yaml:"headers"`
+	Timeout            values.StringValue                `yaml:"timeout"`
+	Timeouts           map[string]string                 `yaml:"timeouts"`
+	Compressions       map[string]string                 `yaml:"compressions"`
+	PagerDuty          *pagerDutyFromFile                `yaml:"pagerDuty"`
+	Webhooks           []webhookFromFile                 `yaml:"webhooks"`
+	AWS                *awsFromFile                      `yaml:"aws"`
+	SilenceSync        values.StringValue                `yaml:"silenceSync"`
+	Relabel            *relabelConfigsFromFile           `yaml:"relabel"`
+	TargetRelabels     map[string]relabelConfigsFromFile `yaml:"targetRelabels"`
+	ResolvedAlerts     *resolvedAlertsFromFile           `yaml:"resolvedAlerts"`
+	RuleResolvedAlerts map[string]resolvedAlertsFromFile `yaml:"ruleResolvedAlerts"`
+	MuteTimings        *muteTimingsFromFile              `yaml:"muteTimings"`
+	RuleMuteTimings    map[string]muteTimingsFromFile    `yaml:"ruleMuteTimings"`
+	Grouping           *groupingFromFile                 `yaml:"grouping"`
+}
+
+type groupingFromFile struct {
+	By       []string `yaml:"by"`
+	Examples int      `yaml:"examples"`
+}
+
+type muteTimingsFromFile struct {
+	TimeIntervals []timeinterval.TimeInterval `yaml:"timeIntervals"`
+	Location      string                      `yaml:"location"`
+}
+
+type resolvedAlertsFromFile struct {
+	Mode        string `yaml:"mode"`
+	GraceWindow string `yaml:"graceWindow"`
+}
+
+type relabelConfigsFromFile struct {
+	Labels      []relabelConfigFromFile `yaml:"labels"`
+	Annotations []relabelConfigFromFile `yaml:"annotations"`
+}
+
+type relabelConfigFromFile struct {
+	SourceLabels []string `yaml:"sourceLabels"`
+	Separator    string   `yaml:"separator"`
+	Regex        string   `yaml:"regex"`
+	Modulus      uint64   `yaml:"modulus"`
+	TargetLabel  string   `yaml:"targetLabel"`
+	Replacement  *string  `yaml:"replacement"`
+	Action       string   `yaml:"action"`
+}
+
+func (rc relabelConfigsFromFile) toAlertRelabelConfigs() models.AlertRelabelConfigs {
+	var res models.AlertRelabelConfigs
+	for _, r := range rc.Labels {
+		res.Labels = append(res.Labels, models.RelabelConfig(r))
+	}
+	for _, r := range rc.Annotations {
+		res.Annotations = append(res.Annotations, models.RelabelConfig(r))
+	}
+	return res
+}
+
+type tlsFromFile struct {
+	CAFile             string `yaml:"caFile"`
+	CertFile           string `yaml:"certFile"`
+	KeyFile            string `yaml:"keyFile"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
+}
+
+type webhookFromFile struct {
+	URL         values.StringValue `yaml:"url"`
+	Template    string             `yaml:"template"`
+	ContentType string             `yaml:"contentType"`
+}
+
+// credentialsFromFile are the credentials of an Alertmanager, the secrets are expanded from the environment
+// variables they refer to.
+type credentialsFromFile struct {
+	BasicAuthUser     values.StringValue    `yaml:"basicAuthUser"`
+	BasicAuthPassword values.StringValue    `yaml:"basicAuthPassword"`
+	BearerToken       values.StringValue    `yaml:"bearerToken"`
+	Headers           values.StringMapValue `yaml:"headers"`
+}
+
+type pagerDutyFromFile struct {
+	URL        values.StringValue `yaml:"url"`
+	Severity   values.StringValue `yaml:"severity"`
+	RoutingKey values.StringValue `yaml:"routingKey"`
+}
+
+// awsFromFile is the SNS topic or SQS queue alerts are published to, the credentials are expanded from the
+// environment variables they refer to.
+type awsFromFile struct {
+	Region        values.StringValue `yaml:"region"`
+	TopicARN      values.StringValue `yaml:"topicArn"`
+	QueueURL      values.StringValue `yaml:"queueUrl"`
+	AssumeRoleARN values.StringValue `yaml:"assumeRoleArn"`
+	ExternalID    values.StringValue `yaml:"externalId"`
+	Endpoint      values.StringValue `yaml:"endpoint"`
+	AccessKey     values.StringValue `yaml:"accessKey"`
+	SecretKey     values.StringValue `yaml:"secretKey"`
+}
+
+type deleteAdminConfigFromFile struct {
+	OrgID values.Int64Value `yaml:"orgId"`
+}
+
+// Provision applies the YAML files of the directory: the admin configurations of the files replace the ones of
+// their organization, unless they are the same, and the ones of the deleteAdminConfigs are deleted. It returns
+// whether an admin configuration was changed. A missing directory provisions nothing.
+func (p *AdminConfigFileProvisioner) Provision(ctx context.Context, path string) (bool, error) {
+	files, err := readAdminConfigsFiles(path)
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	for _, file := range files {
+		for _, d := range file.DeleteAdminConfigs {
+			deleted, err := p.delete(ctx, orgIDOrDefault(d.OrgID.Value()))
+			if err != nil {
+				return changed, err
+			}
+			changed = changed || deleted
+		}
+	}
+	for _, file := range files {
+		for _, fromFile := range file.AdminConfigs {
+			cfg, err := p.toAdminConfiguration(ctx, fromFile)
+			if err != nil {
+				return changed, fmt.Errorf("invalid admin configuration of the organization %d: %w", cfg.OrgID, err)
+			}
+			updated, err := p.update(ctx, cfg)
+			if err != nil {
+				return changed, err
+			}
+			changed = changed || updated
+		}
+	}
+	return changed, nil
+}
+
+func readAdminConfigsFiles(path string) ([]*adminConfigsFile, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read the alerting provisioning directory %s: %w", path, err)
+	}
+
+	var files []*adminConfigsFile
+	orgs := make(map[int64]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !(strings.HasSuffix(entry.Name(), ".yaml") || strings.HasSuffix(entry.Name(), ".yml")) {
+			continue
+		}
+		filename := filepath.Join(path, entry.Name())
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because `filename` comes from the provisioning path
+		raw, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		var file adminConfigsFile
+		if err := yaml.Unmarshal(raw, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse the provisioning file %s: %w", filename, err)
+		}
+		for _, cfg := range file.AdminConfigs {
+			orgID := orgIDOrDefault(cfg.OrgID.Value())
+			if other, ok := orgs[orgID]; ok {
+				return nil, fmt.Errorf("the admin configuration of the organization %d is provisioned by both %s and %s", orgID, other, filename)
+			}
+			orgs[orgID] = filename
+		}
+		files = append(files, &file)
+	}
+	return files, nil
+}
+
+// orgIDOrDefault returns the ID of the main organization if orgID is not set.
+func orgIDOrDefault(orgID int64) int64 {
+	if orgID < 1 {
+		return 1
+	}
+	return orgID
+}
+
+// toAdminConfiguration validates an admin configuration of a file and converts it to the one that is stored, with
+// its secrets encrypted.
+func (p *AdminConfigFileProvisioner) toAdminConfiguration(ctx context.Context, fromFile *adminConfigFromFile) (*models.AdminConfiguration, error) {
+	cfg := &models.AdminConfiguration{
+		OrgID:            orgIDOrDefault(fromFile.OrgID.Value()),
+		Alertmanagers:    fromFile.Alertmanagers,
+		Disabled:         fromFile.Disabled.Value(),
+		ExternalRuleUIDs: fromFile.ExternalRuleUIDs,
+		ProxyURL:         fromFile.ProxyURL.Value(),
+		NoProxy:          fromFile.NoProxy,
+		ProxyURLs:        fromFile.ProxyURLs,
+		APIVersions:      fromFile.APIVersions,
+		Headers:          fromFile.Headers,
+		Timeout:          fromFile.Timeout.Value(),
+		Timeouts:         fromFile.Timeouts,
+		Compressions:     fromFile.Compressions,
+		SilenceSync:      models.SilenceSyncMode(fromFile.SilenceSync.Value()),
+	}
+	if len(fromFile.TLS) > 0 {
+		cfg.TLSConfigs = make(map[string]models.AlertmanagerTLSConfig, len(fromFile.TLS))
+		for u, tlsCfg := range fromFile.TLS {
+			cfg.TLSConfigs[u] = models.AlertmanagerTLSConfig(tlsCfg)
+		}
+	}
+	for _, wh := range fromFile.Webhooks {
+		cfg.Webhooks = append(cfg.Webhooks, models.AlertWebhookConfig{URL: wh.URL.Value(), Template: wh.Template, ContentType: wh.ContentType})
+	}
+	if fromFile.Relabel != nil {
+		relabel := fromFile.Relabel.toAlertRelabelConfigs()
+		cfg.Relabel = &relabel
+	}
+	if len(fromFile.TargetRelabels) > 0 {
+		cfg.TargetRelabels = make(map[string]models.AlertRelabelConfigs, len(fromFile.TargetRelabels))
+		for u, rc := range fromFile.TargetRelabels {
+			cfg.TargetRelabels[u] = rc.toAlertRelabelConfigs()
+		}
+	}
+	if fromFile.ResolvedAlerts != nil {
+		resolved := models.ResolvedAlertsConfig(*fromFile.ResolvedAlerts)
+		cfg.ResolvedAlerts = &resolved
+	}
+	if len(fromFile.RuleResolvedAlerts) > 0 {
+		cfg.RuleResolvedAlerts = make(map[string]models.ResolvedAlertsConfig, len(fromFile.RuleResolvedAlerts))
+		for uid, rc := range fromFile.RuleResolvedAlerts {
+			cfg.RuleResolvedAlerts[uid] = models.ResolvedAlertsConfig(rc)
+		}
+	}
+	if fromFile.MuteTimings != nil {
+		mt := models.MuteTimingsConfig(*fromFile.MuteTimings)
+		cfg.MuteTimings = &mt
+	}
+	if len(fromFile.RuleMuteTimings) > 0 {
+		cfg.RuleMuteTimings = make(map[string]models.MuteTimingsConfig, len(fromFile.RuleMuteTimings))
+		for uid, mt := range fromFile.RuleMuteTimings {
+			cfg.RuleMuteTimings[uid] = models.MuteTimingsConfig(mt)
+		}
+	}
+	if fromFile.Grouping != nil {
+		g := models.AlertGroupingConfig(*fromFile.Grouping)
+		cfg.Grouping = &g
+	}
+	if fromFile.AWS != nil {
+		cfg.AWS = &models.AWSConfig{
+			Region:        fromFile.AWS.Region.Value(),
+			TopicARN:      fromFile.AWS.TopicARN.Value(),
+			QueueURL:      fromFile.AWS.QueueURL.Value(),
+			AssumeRoleARN: fromFile.AWS.AssumeRoleARN.Value(),
+			ExternalID:    fromFile.AWS.ExternalID.Value(),
+			Endpoint:      fromFile.AWS.Endpoint.Value(),
+		}
+		settings := make(map[string]string)
+		if v := fromFile.AWS.AccessKey.Value(); v != "" {
+			settings[models.AWSAccessKeyKey] = v
+		}
+		if v := fromFile.AWS.SecretKey.Value(); v != "" {
+			settings[models.AWSSecretKeyKey] = v
+		}
+		encrypted, err := p.encrypt(ctx, settings)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.AWS.SecureSettings = encrypted
+	}
+
+	sendAlertsTo := fromFile.SendAlertsTo.Value()
+	if sendAlertsTo == "" {
+		sendAlertsTo = models.AllAlertmanagers.String()
+	}
+	choice, err := models.StringToAlertmanagersChoice(sendAlertsTo)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.SendAlertsTo = choice
+	if choice == models.ExternalAlertmanagers && !cfg.HasExternalTargets() && fromFile.PagerDuty == nil {
+		return cfg, errors.New("at least one Alertmanager, PagerDuty, webhook, SNS topic or SQS queue must be provided to send the alerts to external Alertmanagers only")
+	}
+
+	if len(fromFile.Credentials) > 0 {
+		cfg.Credentials = make(map[string]models.AlertmanagerCredentials, len(fromFile.Credentials))
+		for u, creds := range fromFile.Credentials {
+			settings := make(map[string]string)
+			if v := creds.BasicAuthPassword.Value(); v != "" {
+				settings[models.BasicAuthPasswordKey] = v
+			}
+			if v := creds.BearerToken.Value(); v != "" {
+				settings[models.BearerTokenKey] = v
+			}
+			for k, v := range creds.Headers.Value() {
+				settings[models.HeaderKeyPrefix+k] = v
+			}
+			encrypted, err := p.encrypt(ctx, settings)
+			if err != nil {
+				return cfg, err
+			}
+			cfg.Credentials[u] = models.AlertmanagerCredentials{BasicAuthUser: creds.BasicAuthUser.Value(), SecureSettings: encrypted}
+		}
+	}
+	if fromFile.PagerDuty != nil {
+		cfg.PagerDuty = &models.PagerDutyConfig{URL: fromFile.PagerDuty.URL.Value(), Severity: fromFile.PagerDuty.Severity.Value()}
+		if v := fromFile.PagerDuty.RoutingKey.Value(); v != "" {
+			encrypted, err := p.encrypt(ctx, map[string]string{models.PagerDutyRoutingKeyKey: v})
+			if err != nil {
+				return cfg, err
+			}
+			cfg.PagerDuty.SecureSettings = encrypted
+		}
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func (p *AdminConfigFileProvisioner) encrypt(ctx context.Context, settings map[string]string) (map[string][]byte, error) {
+	if len(settings) == 0 {
+		return nil, nil
+	}
+	return p.secretsService.EncryptJsonData(ctx, settings, secrets.WithoutScope())
+}
+
+func (p *AdminConfigFileProvisioner) update(ctx context.Context, cfg *models.AdminConfiguration) (bool, error) {
+	existing, err := p.store.GetAdminConfiguration(cfg.OrgID)
+	if err != nil && !errors.Is(err, store.ErrNoAdminConfiguration) {
+		return false, err
+	}
+	if existing != nil && err == nil {
+		same, err := p.sameConfiguration(ctx, existing, cfg)
+		if err != nil {
+			return false, err
+		}
+		if same {
+			return false, p.prov.SetProvenance(ctx, cfg, cfg.OrgID, models.ProvenanceFile)
+		}
+	}
+
+	cmd := store.UpdateAdminConfigurationCmd{AdminConfiguration: cfg, Login: adminConfigsProvisioningLogin}
+	if err := p.store.UpdateAdminConfiguration(cmd); err != nil {
+		return false, fmt.Errorf("failed to save the admin configuration of the organization %d: %w", cfg.OrgID, err)
+	}
+	if err := p.prov.SetProvenance(ctx, cfg, cfg.OrgID, models.ProvenanceFile); err != nil {
+		return true, err
+	}
+	p.log.Info("provisioned the admin configuration", "org", cfg.OrgID)
+	return true, nil
+}
+
+func (p *AdminConfigFileProvisioner) delete(ctx context.Context, orgID int64) (bool, error) {
+	existing, err := p.store.GetAdminConfiguration(orgID)
+	if err != nil && !errors.Is(err, store.ErrNoAdminConfiguration) {
+		return false, err
+	}
+	if err != nil || existing == nil {
+		return false, nil
+	}
+	cmd := store.DeleteAdminConfigurationCmd{OrgID: orgID, Login: adminConfigsProvisioningLogin}
+	if err := p.store.DeleteAdminConfiguration(cmd); err != nil {
+		return false, fmt.Errorf("failed to delete the admin configuration of the organization %d: %w", orgID, err)
+	}
+	if err := p.prov.DeleteProvenance(ctx, &models.AdminConfiguration{OrgID: orgID}, orgID); err != nil {
+		return true, err
+	}
+	p.log.Info("deleted the provisioned admin configuration", "org", orgID)
+	return true, nil
+}
+
+// sameConfiguration returns whether the stored configuration is the provisioned one. The secrets are encrypted
+// with a random nonce, they are compared decrypted.
+func (p *AdminConfigFileProvisioner) sameConfiguration(ctx context.Context, stored, provisioned *models.AdminConfiguration) (bool, error) {
+	withoutSecrets := func(cfg models.AdminConfiguration) *models.AdminConfiguration {
+		if cfg.Credentials != nil {
+			creds := make(map[string]models.AlertmanagerCredentials, len(cfg.Credentials))
+			for u, c := range cfg.Credentials {
+				creds[u] = models.AlertmanagerCredentials{BasicAuthUser: c.BasicAuthUser}
+			}
+			cfg.Credentials = creds
+		}
+		if cfg.PagerDuty != nil {
+			cfg.PagerDuty = &models.PagerDutyConfig{URL: cfg.PagerDuty.URL, Severity: cfg.PagerDuty.Severity}
+		}
+		if cfg.AWS != nil {
+			aws := *cfg.AWS
+			aws.SecureSettings = nil
+			cfg.AWS = &aws
+		}
+		return &cfg
+	}
+	if withoutSecrets(*stored).AsSHA256() != withoutSecrets(*provisioned).AsSHA256() ||
+		stored.SendAlertsTo != provisioned.SendAlertsTo || stored.Disabled != provisioned.Disabled ||
+		!reflect.DeepEqual(stored.ExternalRuleUIDs, provisioned.ExternalRuleUIDs) {
+		return false, nil
+	}
+
+	secureSettings := func(cfg *models.AdminConfiguration) map[string]map[string][]byte {
+		s := make(map[string]map[string][]byte)
+		for u, c := range cfg.Credentials {
+			s[u] = c.SecureSettings
+		}
+		if cfg.PagerDuty != nil {
+			s[models.PagerDutyRoutingKeyKey] = cfg.PagerDuty.SecureSettings
+		}
+		if cfg.AWS != nil {
+			s["aws"] = cfg.AWS.SecureSettings
+		}
+		return s
+	}
+	storedSecrets, provisionedSecrets := secureSettings(stored), secureSettings(provisioned)
+	for k := range storedSecrets {
+		if _, ok := provisionedSecrets[k]; !ok {
+			provisionedSecrets[k] = nil
+		}
+	}
+	for k, provisionedSettings := range provisionedSecrets {
+		a, err := p.secretsService.DecryptJsonData(ctx, storedSecrets[k])
+		if err != nil {
+			// the stored secrets cannot be decrypted anymore, they are replaced
+			return false, nil
+		}
+		b, err := p.secretsService.DecryptJsonData(ctx, provisionedSettings)
+		if err != nil {
+			return false, err
+		}
+		if len(a) != len(b) || (len(a) > 0 && !reflect.DeepEqual(a, b)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
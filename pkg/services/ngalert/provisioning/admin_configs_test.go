@@ -0,0 +1,235 @@
+package provisioning
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+func TestAdminConfigFileProvisioner(t *testing.T) {
+	ctx := context.Background()
+	writeFile := func(t *testing.T, dir, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "admin.yaml"), []byte(content), 0600))
+	}
+	createSut := func(t *testing.T) (*AdminConfigFileProvisioner, *store.FakeAdminConfigStore, *fakeProvisioningStore) {
+		t.Helper()
+		adminStore := store.NewFakeAdminConfigStore(t)
+		prov := NewFakeProvisioningStore()
+		secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+		return NewAdminConfigFileProvisioner(adminStore, prov, secretsService, log.NewNopLogger()), adminStore, prov
+	}
+
+	t.Run("provisions the admin configurations of the files", func(t *testing.T) {
+		t.Setenv("TEST_AM_PASSWORD", "secret")
+		dir := t.TempDir()
+		writeFile(t, dir, `
+apiVersion: 1
+adminConfigs:
+  - orgId: 2
+    sendAlertsTo: external
+    alertmanagers:
+      - https://alertmanager.example.com
+    credentials:
+      https://alertmanager.example.com:
+        basicAuthUser: grafana
+        basicAuthPassword: $TEST_AM_PASSWORD
+    tls:
+      https://alertmanager.example.com:
+        insecureSkipVerify: true
+    timeout: 20s
+`)
+		sut, adminStore, prov := createSut(t)
+
+		changed, err := sut.Provision(ctx, dir)
+		require.NoError(t, err)
+		require.True(t, changed)
+
+		cfg := adminStore.Configs[2]
+		require.NotNil(t, cfg)
+		require.Equal(t, []string{"https://alertmanager.example.com"}, cfg.Alertmanagers)
+		require.Equal(t, models.ExternalAlertmanagers, cfg.SendAlertsTo)
+		require.Equal(t, "20s", cfg.Timeout)
+		require.True(t, cfg.TLSConfigs["https://alertmanager.example.com"].InsecureSkipVerify)
+		creds := cfg.Credentials["https://alertmanager.example.com"]
+		require.Equal(t, "grafana", creds.BasicAuthUser)
+		decrypted, err := sut.secretsService.DecryptJsonData(ctx, creds.SecureSettings)
+		require.NoError(t, err)
+		require.Equal(t, "secret", decrypted[models.BasicAuthPasswordKey])
+
+		provenance, err := prov.GetProvenance(ctx, &models.AdminConfiguration{OrgID: 2}, 2)
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceFile, provenance)
+
+		t.Run("the same configuration is not saved again", func(t *testing.T) {
+			changed, err := sut.Provision(ctx, dir)
+			require.NoError(t, err)
+			require.False(t, changed)
+			require.Len(t, adminStore.Changes, 1)
+			require.Equal(t, "provisioning", adminStore.Changes[0].Login)
+		})
+
+		t.Run("a changed secret is saved", func(t *testing.T) {
+			t.Setenv("TEST_AM_PASSWORD", "another secret")
+			changed, err := sut.Provision(ctx, dir)
+			require.NoError(t, err)
+			require.True(t, changed)
+		})
+
+		t.Run("deleted configuration is deleted", func(t *testing.T) {
+			writeFile(t, dir, `
+apiVersion: 1
+deleteAdminConfigs:
+  - orgId: 2
+`)
+			changed, err := sut.Provision(ctx, dir)
+			require.NoError(t, err)
+			require.True(t, changed)
+			require.NotContains(t, adminStore.Configs, int64(2))
+
+			provenance, err := prov.GetProvenance(ctx, &models.AdminConfiguration{OrgID: 2}, 2)
+			require.NoError(t, err)
+			require.Equal(t, models.ProvenanceNone, provenance)
+
+			changed, err = sut.Provision(ctx, dir)
+			require.NoError(t, err)
+			require.False(t, changed)
+		})
+	})
+
+	t.Run("organization defaults to the main one", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, `
+adminConfigs:
+  - alertmanagers:
+      - https://alertmanager.example.com
+`)
+		sut, adminStore, _ := createSut(t)
+
+		_, err := sut.Provision(ctx, dir)
+		require.NoError(t, err)
+		require.Contains(t, adminStore.Configs, int64(1))
+		require.Equal(t, models.AllAlertmanagers, adminStore.Configs[1].SendAlertsTo)
+	})
+
+	t.Run("mute timings are provisioned", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, `
+adminConfigs:
+  - orgId: 1
+    muteTimings:
+      location: Europe/Paris
+      timeIntervals:
+        - weekdays: [saturday, sunday]
+    ruleMuteTimings:
+      cpu-usage:
+        timeIntervals:
+          - times:
+              - start_time: "22:00"
+                end_time: "24:00"
+`)
+		sut, adminStore, _ := createSut(t)
+
+		_, err := sut.Provision(ctx, dir)
+		require.NoError(t, err)
+		cfg := adminStore.Configs[1]
+		require.Equal(t, "Europe/Paris", cfg.MuteTimings.Location)
+		require.Len(t, cfg.MuteTimings.TimeIntervals, 1)
+		require.Equal(t, []timeinterval.WeekdayRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 6, End: 6}}, {InclusiveRange: timeinterval.InclusiveRange{Begin: 0, End: 0}}}, cfg.MuteTimings.TimeIntervals[0].Weekdays)
+		require.Equal(t, []timeinterval.TimeRange{{StartMinute: 22 * 60, EndMinute: 24 * 60}}, cfg.RuleMuteTimings["cpu-usage"].TimeIntervals[0].Times)
+	})
+
+	t.Run("grouping is provisioned", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, `
+adminConfigs:
+  - orgId: 1
+    grouping:
+      by: [cluster, namespace]
+      examples: 5
+`)
+		sut, adminStore, _ := createSut(t)
+
+		_, err := sut.Provision(ctx, dir)
+		require.NoError(t, err)
+		require.Equal(t, &models.AlertGroupingConfig{By: []string{"cluster", "namespace"}, Examples: 5}, adminStore.Configs[1].Grouping)
+	})
+
+	t.Run("missing directory provisions nothing", func(t *testing.T) {
+		sut, adminStore, _ := createSut(t)
+
+		changed, err := sut.Provision(ctx, filepath.Join(t.TempDir(), "missing"))
+		require.NoError(t, err)
+		require.False(t, changed)
+		require.Empty(t, adminStore.Configs)
+	})
+
+	t.Run("invalid configurations are not provisioned", func(t *testing.T) {
+		testCases := []struct {
+			desc    string
+			content string
+		}{
+			{
+				desc: "unknown choice",
+				content: `
+adminConfigs:
+  - sendAlertsTo: elsewhere
+`,
+			},
+			{
+				desc: "external without target",
+				content: `
+adminConfigs:
+  - sendAlertsTo: external
+`,
+			},
+			{
+				desc: "invalid timeout",
+				content: `
+adminConfigs:
+  - alertmanagers:
+      - https://alertmanager.example.com
+    timeout: soon
+`,
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.desc, func(t *testing.T) {
+				dir := t.TempDir()
+				writeFile(t, dir, tc.content)
+				sut, adminStore, _ := createSut(t)
+
+				_, err := sut.Provision(ctx, dir)
+				require.Error(t, err)
+				require.Empty(t, adminStore.Configs)
+			})
+		}
+	})
+
+	t.Run("organization provisioned by two files is an error", func(t *testing.T) {
+		dir := t.TempDir()
+		content := `
+adminConfigs:
+  - orgId: 1
+    alertmanagers:
+      - https://alertmanager.example.com
+`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte(content), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "b.yml"), []byte(content), 0600))
+		sut, adminStore, _ := createSut(t)
+
+		_, err := sut.Provision(ctx, dir)
+		require.Error(t, err)
+		require.Empty(t, adminStore.Configs)
+	})
+}
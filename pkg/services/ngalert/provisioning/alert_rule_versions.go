@@ -0,0 +1,97 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util/cmputil"
+)
+
+// GetAlertRuleVersions returns the versions of a rule, most recent first.
+func (service *AlertRuleService) GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) ([]*models.AlertRuleVersion, error) {
+	if _, _, err := service.GetAlertRule(ctx, orgID, ruleUID); err != nil {
+		return nil, err
+	}
+	return service.ruleStore.GetAlertRuleVersions(ctx, orgID, ruleUID)
+}
+
+// DiffAlertRuleVersions returns the changes of the definition of a rule from one of its versions to another.
+func (service *AlertRuleService) DiffAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string, from, to int64) (cmputil.DiffReport, error) {
+	versions, err := service.GetAlertRuleVersions(ctx, orgID, ruleUID)
+	if err != nil {
+		return nil, err
+	}
+	fromVersion, err := findAlertRuleVersion(versions, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := findAlertRuleVersion(versions, to)
+	if err != nil {
+		return nil, err
+	}
+	fromRule, toRule := fromVersion.AlertRule(), toVersion.AlertRule()
+	return fromRule.Diff(&toRule, "ID", "Version", "Updated"), nil
+}
+
+// RestoreAlertRuleVersion makes the definition of a rule the one of one of its versions, as a new version. The
+// rule stays in its current folder and group, keeps the interval of the group, and is not paused or resumed.
+func (service *AlertRuleService) RestoreAlertRuleVersion(ctx context.Context, orgID int64, ruleUID string, version int64, provenance models.Provenance) (models.AlertRule, error) {
+	storedRule, storedProvenance, err := service.GetAlertRule(ctx, orgID, ruleUID)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
+		return models.AlertRule{}, fmt.Errorf("cannot changed provenance from '%s' to '%s'", storedProvenance, provenance)
+	}
+	versions, err := service.ruleStore.GetAlertRuleVersions(ctx, orgID, ruleUID)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	restored, err := findAlertRuleVersion(versions, version)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+
+	rule := restored.AlertRule()
+	rule.ID = storedRule.ID
+	rule.Version = storedRule.Version
+	rule.Updated = time.Now()
+	rule.NamespaceUID = storedRule.NamespaceUID
+	rule.RuleGroup = storedRule.RuleGroup
+	rule.IntervalSeconds = storedRule.IntervalSeconds
+	rule.DashboardUID = storedRule.DashboardUID
+	rule.PanelID = storedRule.PanelID
+	rule.IsPaused = storedRule.IsPaused
+
+	service.log.Info("restore rule version", "ID", storedRule.ID, "version", version)
+	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		err := service.ruleStore.UpdateAlertRules(ctx, []store.UpdateRule{
+			{
+				Existing:     &storedRule,
+				New:          rule,
+				RestoredFrom: version,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		return service.provenanceStore.SetProvenance(ctx, &rule, rule.OrgID, provenance)
+	})
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	rule.Version = storedRule.Version + 1
+	return rule, nil
+}
+
+func findAlertRuleVersion(versions []*models.AlertRuleVersion, version int64) (*models.AlertRuleVersion, error) {
+	for _, v := range versions {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: version %d", models.ErrAlertRuleVersionNotFound, version)
+}
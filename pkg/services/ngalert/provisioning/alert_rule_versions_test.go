@@ -0,0 +1,81 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestAlertRuleVersions(t *testing.T) {
+	ruleService := createAlertRuleService(t)
+	ctx := context.Background()
+	var orgID int64 = 1
+
+	rule := dummyRule("versioned", orgID)
+	rule.Labels = map[string]string{"severity": "warning"}
+	rule.Data[0].RelativeTimeRange.From = models.Duration(time.Minute)
+	rule, err := ruleService.CreateAlertRule(ctx, rule, models.ProvenanceNone)
+	require.NoError(t, err)
+
+	rule.Title = "versioned updated"
+	rule.Labels = map[string]string{"severity": "critical"}
+	_, err = ruleService.UpdateAlertRule(ctx, rule, models.ProvenanceNone)
+	require.NoError(t, err)
+
+	t.Run("versions are listed most recent first", func(t *testing.T) {
+		versions, err := ruleService.GetAlertRuleVersions(ctx, orgID, rule.UID)
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		require.EqualValues(t, 2, versions[0].Version)
+		require.EqualValues(t, 1, versions[0].ParentVersion)
+		require.Equal(t, "versioned updated", versions[0].Title)
+		require.EqualValues(t, 1, versions[1].Version)
+		require.Equal(t, "versioned", versions[1].Title)
+
+		_, err = ruleService.GetAlertRuleVersions(ctx, orgID, "missing")
+		require.ErrorIs(t, err, models.ErrAlertRuleNotFound)
+	})
+
+	t.Run("diff reports the changes between versions", func(t *testing.T) {
+		diff, err := ruleService.DiffAlertRuleVersions(ctx, orgID, rule.UID, 1, 2)
+		require.NoError(t, err)
+		paths := make([]string, 0, len(diff))
+		for _, d := range diff {
+			paths = append(paths, d.Path)
+		}
+		require.ElementsMatch(t, []string{"Title", "Labels[severity]"}, paths)
+		require.Equal(t, "versioned updated", diff.GetDiffsForField("Title")[0].Right.Interface())
+
+		_, err = ruleService.DiffAlertRuleVersions(ctx, orgID, rule.UID, 1, 5)
+		require.ErrorIs(t, err, models.ErrAlertRuleVersionNotFound)
+	})
+
+	t.Run("restoring a version creates a new version", func(t *testing.T) {
+		restored, err := ruleService.RestoreAlertRuleVersion(ctx, orgID, rule.UID, 1, models.ProvenanceAPI)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, restored.Version)
+		require.Equal(t, "versioned", restored.Title)
+
+		stored, provenance, err := ruleService.GetAlertRule(ctx, orgID, rule.UID)
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceAPI, provenance)
+		require.Equal(t, "versioned", stored.Title)
+		require.Equal(t, map[string]string{"severity": "warning"}, stored.Labels)
+		require.Equal(t, rule.RuleGroup, stored.RuleGroup)
+
+		versions, err := ruleService.GetAlertRuleVersions(ctx, orgID, rule.UID)
+		require.NoError(t, err)
+		require.Len(t, versions, 3)
+		require.EqualValues(t, 3, versions[0].Version)
+		require.EqualValues(t, 1, versions[0].RestoredFrom)
+
+		_, err = ruleService.RestoreAlertRuleVersion(ctx, orgID, rule.UID, 1, models.ProvenanceFile)
+		require.Error(t, err)
+		_, err = ruleService.RestoreAlertRuleVersion(ctx, orgID, rule.UID, 7, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleVersionNotFound)
+	})
+}
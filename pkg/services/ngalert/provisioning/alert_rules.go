@@ -149,3 +149,257 @@ func (service *AlertRuleService) DeleteAlertRule(ctx context.Context, orgID int6
 func (service *AlertRuleService) UpdateAlertGroup(ctx context.Context, orgID int64, folderUID, roulegroup string, interval int64) error {
 	return service.ruleStore.UpdateRuleGroup(ctx, orgID, folderUID, roulegroup, interval)
 }
+
+// ErrAlertRulesBatchRejected is returned when some operations of a batch cannot be applied, none is then.
+var ErrAlertRulesBatchRejected = errors.New("some operations of the batch cannot be applied")
+
+type AlertRuleOperation string
+
+const (
+	AlertRuleCreate AlertRuleOperation = "create"
+	AlertRuleUpdate AlertRuleOperation = "update"
+	AlertRuleDelete AlertRuleOperation = "delete"
+	AlertRulePause  AlertRuleOperation = "pause"
+	AlertRuleResume AlertRuleOperation = "resume"
+)
+
+// AlertRulesBatch is a batch of operations on the alert rules of an organization. The rules updated, deleted,
+// paused and resumed are identified by UID.
+type AlertRulesBatch struct {
+	Create []models.AlertRule
+	Update []models.AlertRule
+	Delete []string
+	Pause  []string
+	Resume []string
+}
+
+type AlertRuleBatchResult struct {
+	Operation AlertRuleOperation
+	UID       string
+	Title     string
+	// Error is why the operation cannot be applied, nil if it can.
+	Error error
+}
+
+// ApplyAlertRulesBatch applies all the operations of the batch in one transaction. The operations are checked
+// first: if some cannot be applied, none is and ErrAlertRulesBatchRejected is returned along with the results
+// telling why.
+func (service *AlertRuleService) ApplyAlertRulesBatch(ctx context.Context, orgID int64, batch AlertRulesBatch, provenance models.Provenance) ([]AlertRuleBatchResult, error) {
+	q := &models.ListAlertRulesQuery{OrgID: orgID}
+	if err := service.ruleStore.ListAlertRules(ctx, q); err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*models.AlertRule, len(q.Result))
+	intervals := make(map[models.AlertRuleGroupKey]int64)
+	for _, r := range q.Result {
+		existing[r.UID] = r
+		intervals[r.GetGroupKey()] = r.IntervalSeconds
+	}
+	provenances, err := service.provenanceStore.GetProvenances(ctx, orgID, (&models.AlertRule{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	// seen are the rules of the operations checked so far, a rule can be in a single operation of the batch
+	seen := make(map[string]struct{})
+	var (
+		results []AlertRuleBatchResult
+		inserts []models.AlertRule
+		updates []store.UpdateRule
+		deletes []string
+		failed  bool
+	)
+	check := func(op AlertRuleOperation, uid, title string, err error) bool {
+		if err == nil {
+			if _, ok := seen[uid]; ok {
+				err = errors.New("the rule is in another operation of the batch")
+			}
+			seen[uid] = struct{}{}
+		}
+		results = append(results, AlertRuleBatchResult{Operation: op, UID: uid, Title: title, Error: err})
+		failed = failed || err != nil
+		return err == nil
+	}
+	// stored returns the rule changed by an operation, checking that its provenance allows it
+	stored := func(uid string) (*models.AlertRule, error) {
+		rule, ok := existing[uid]
+		if !ok {
+			return nil, models.ErrAlertRuleNotFound
+		}
+		if storedProvenance := provenances[uid]; storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
+			return nil, fmt.Errorf("cannot change the rule with provenance '%s', needs '%s'", provenance, storedProvenance)
+		}
+		return rule, nil
+	}
+	interval := func(rule models.AlertRule) int64 {
+		if i, ok := intervals[rule.GetGroupKey()]; ok {
+			return i
+		}
+		// the rules of new groups get the default interval, the following ones in the batch the same
+		intervals[rule.GetGroupKey()] = service.defaultInterval
+		return service.defaultInterval
+	}
+
+	for _, rule := range batch.Create {
+		rule.OrgID = orgID
+		if rule.UID == "" {
+			rule.UID = util.GenerateShortUID()
+		}
+		err := validateBatchRule(rule)
+		if _, ok := existing[rule.UID]; ok && err == nil {
+			err = errors.New("a rule with this UID already exists")
+		}
+		if !check(AlertRuleCreate, rule.UID, rule.Title, err) {
+			continue
+		}
+		rule.IntervalSeconds = interval(rule)
+		rule.Updated = now
+		inserts = append(inserts, rule)
+	}
+	for _, rule := range batch.Update {
+		rule.OrgID = orgID
+		existingRule, err := stored(rule.UID)
+		if err == nil {
+			err = validateBatchRule(rule)
+		}
+		if !check(AlertRuleUpdate, rule.UID, rule.Title, err) {
+			continue
+		}
+		rule.ID = existingRule.ID
+		rule.IntervalSeconds = interval(rule)
+		rule.Updated = now
+		updates = append(updates, store.UpdateRule{Existing: existingRule, New: rule})
+	}
+	for _, uid := range batch.Delete {
+		existingRule, err := stored(uid)
+		var title string
+		if existingRule != nil {
+			title = existingRule.Title
+		}
+		if check(AlertRuleDelete, uid, title, err) {
+			deletes = append(deletes, uid)
+		}
+	}
+	pauses := []struct {
+		op   AlertRuleOperation
+		uids []string
+	}{{AlertRulePause, batch.Pause}, {AlertRuleResume, batch.Resume}}
+	for _, p := range pauses {
+		for _, uid := range p.uids {
+			existingRule, err := stored(uid)
+			var title string
+			if existingRule != nil {
+				title = existingRule.Title
+			}
+			if !check(p.op, uid, title, err) || existingRule.IsPaused == (p.op == AlertRulePause) {
+				continue
+			}
+			rule := *existingRule
+			rule.IsPaused = p.op == AlertRulePause
+			rule.Updated = now
+			updates = append(updates, store.UpdateRule{Existing: existingRule, New: rule})
+		}
+	}
+	if failed {
+		return results, ErrAlertRulesBatchRejected
+	}
+
+	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if len(inserts) > 0 {
+			if _, err := service.ruleStore.InsertAlertRules(ctx, inserts); err != nil {
+				return err
+			}
+		}
+		if len(updates) > 0 {
+			if err := service.ruleStore.UpdateAlertRules(ctx, updates); err != nil {
+				return err
+			}
+		}
+		if len(deletes) > 0 {
+			if err := service.ruleStore.DeleteAlertRulesByUID(ctx, orgID, deletes...); err != nil {
+				return err
+			}
+		}
+		for i := range inserts {
+			if err := service.provenanceStore.SetProvenance(ctx, &inserts[i], orgID, provenance); err != nil {
+				return err
+			}
+		}
+		for i := range updates {
+			if err := service.provenanceStore.SetProvenance(ctx, &updates[i].New, orgID, provenance); err != nil {
+				return err
+			}
+		}
+		for _, uid := range deletes {
+			if err := service.provenanceStore.DeleteProvenance(ctx, &models.AlertRule{OrgID: orgID, UID: uid}, orgID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// SetAlertRulePaused pauses or resumes the evaluation of a rule.
+func (service *AlertRuleService) SetAlertRulePaused(ctx context.Context, orgID int64, ruleUID string, paused bool, provenance models.Provenance) error {
+	return service.setPaused(ctx, orgID, []string{ruleUID}, paused, provenance)
+}
+
+// SetAlertGroupPaused pauses or resumes the evaluation of all the rules of a group. The rules added to the
+// group later are not paused.
+func (service *AlertRuleService) SetAlertGroupPaused(ctx context.Context, orgID int64, folderUID, rulegroup string, paused bool, provenance models.Provenance) error {
+	q := &models.ListAlertRulesQuery{OrgID: orgID, NamespaceUIDs: []string{folderUID}, RuleGroup: rulegroup}
+	if err := service.ruleStore.ListAlertRules(ctx, q); err != nil {
+		return err
+	}
+	if len(q.Result) == 0 {
+		return store.ErrAlertRuleGroupNotFound
+	}
+	uids := make([]string, 0, len(q.Result))
+	for _, r := range q.Result {
+		uids = append(uids, r.UID)
+	}
+	return service.setPaused(ctx, orgID, uids, paused, provenance)
+}
+
+func (service *AlertRuleService) setPaused(ctx context.Context, orgID int64, uids []string, paused bool, provenance models.Provenance) error {
+	batch := AlertRulesBatch{Resume: uids}
+	if paused {
+		batch = AlertRulesBatch{Pause: uids}
+	}
+	results, err := service.ApplyAlertRulesBatch(ctx, orgID, batch, provenance)
+	if errors.Is(err, ErrAlertRulesBatchRejected) {
+		for _, r := range results {
+			if r.Error != nil {
+				return r.Error
+			}
+		}
+	}
+	return err
+}
+
+// validateBatchRule checks the fields of a rule created or updated by a batch, so that the operation is
+// rejected before anything is applied.
+func validateBatchRule(rule models.AlertRule) error {
+	switch {
+	case rule.Title == "":
+		return fmt.Errorf("%w: title is empty", models.ErrAlertRuleFailedValidation)
+	case rule.NamespaceUID == "":
+		return fmt.Errorf("%w: folder UID is empty", models.ErrAlertRuleFailedValidation)
+	case rule.RuleGroup == "":
+		return fmt.Errorf("%w: rule group is empty", models.ErrAlertRuleFailedValidation)
+	case rule.Condition == "" || len(rule.Data) == 0:
+		return fmt.Errorf("%w: no condition or queries", models.ErrAlertRuleFailedValidation)
+	}
+	if _, err := models.ErrStateFromString(string(rule.ExecErrState)); err != nil {
+		return fmt.Errorf("%w: %s", models.ErrAlertRuleFailedValidation, err)
+	}
+	if _, err := models.NoDataStateFromString(string(rule.NoDataState)); err != nil {
+		return fmt.Errorf("%w: %s", models.ErrAlertRuleFailedValidation, err)
+	}
+	return nil
+}
@@ -125,12 +125,137 @@ func TestAlertRuleService(t *testing.T) {
 	})
 }
 
+func TestApplyAlertRulesBatch(t *testing.T) {
+	ruleService := createAlertRuleService(t)
+	ctx := context.Background()
+	var orgID int64 = 1
+
+	newRule := func(title string) models.AlertRule {
+		rule := dummyRule(title, orgID)
+		rule.NamespaceUID = "folder"
+		rule.RuleGroup = "batch"
+		// the rules are read back from the database, their time range must survive it
+		rule.Data[0].RelativeTimeRange.From = models.Duration(time.Minute)
+		return rule
+	}
+	updated, err := ruleService.CreateAlertRule(ctx, newRule("updated"), models.ProvenanceNone)
+	require.NoError(t, err)
+	deleted, err := ruleService.CreateAlertRule(ctx, newRule("deleted"), models.ProvenanceNone)
+	require.NoError(t, err)
+	paused, err := ruleService.CreateAlertRule(ctx, newRule("paused"), models.ProvenanceNone)
+	require.NoError(t, err)
+	provisioned, err := ruleService.CreateAlertRule(ctx, newRule("provisioned"), models.ProvenanceFile)
+	require.NoError(t, err)
+
+	t.Run("nothing is applied if some operations cannot be", func(t *testing.T) {
+		invalid := newRule("")
+		results, err := ruleService.ApplyAlertRulesBatch(ctx, orgID, AlertRulesBatch{
+			Create: []models.AlertRule{newRule("created"), invalid},
+			Delete: []string{deleted.UID, provisioned.UID, "missing"},
+			Pause:  []string{deleted.UID},
+		}, models.ProvenanceAPI)
+		require.ErrorIs(t, err, ErrAlertRulesBatchRejected)
+		require.Len(t, results, 6)
+		require.NoError(t, results[0].Error)
+		require.ErrorIs(t, results[1].Error, models.ErrAlertRuleFailedValidation)
+		require.NoError(t, results[2].Error)
+		require.Error(t, results[3].Error)
+		require.ErrorIs(t, results[4].Error, models.ErrAlertRuleNotFound)
+		require.Equal(t, AlertRulePause, results[5].Operation)
+		require.Error(t, results[5].Error)
+
+		_, _, err = ruleService.GetAlertRule(ctx, orgID, deleted.UID)
+		require.NoError(t, err)
+		_, _, err = ruleService.GetAlertRule(ctx, orgID, results[0].UID)
+		require.ErrorIs(t, err, models.ErrAlertRuleNotFound)
+	})
+
+	t.Run("all the operations are applied", func(t *testing.T) {
+		update := updated
+		update.Title = "updated again"
+		results, err := ruleService.ApplyAlertRulesBatch(ctx, orgID, AlertRulesBatch{
+			Create: []models.AlertRule{newRule("created")},
+			Update: []models.AlertRule{update},
+			Delete: []string{deleted.UID},
+			Pause:  []string{paused.UID},
+		}, models.ProvenanceAPI)
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+		for _, r := range results {
+			require.NoError(t, r.Error)
+		}
+
+		created, provenance, err := ruleService.GetAlertRule(ctx, orgID, results[0].UID)
+		require.NoError(t, err)
+		require.Equal(t, "created", created.Title)
+		require.Equal(t, updated.IntervalSeconds, created.IntervalSeconds)
+		require.Equal(t, models.ProvenanceAPI, provenance)
+
+		rule, _, err := ruleService.GetAlertRule(ctx, orgID, updated.UID)
+		require.NoError(t, err)
+		require.Equal(t, "updated again", rule.Title)
+
+		_, _, err = ruleService.GetAlertRule(ctx, orgID, deleted.UID)
+		require.ErrorIs(t, err, models.ErrAlertRuleNotFound)
+
+		rule, _, err = ruleService.GetAlertRule(ctx, orgID, paused.UID)
+		require.NoError(t, err)
+		require.True(t, rule.IsPaused)
+	})
+
+	t.Run("paused rules are resumed", func(t *testing.T) {
+		_, err := ruleService.ApplyAlertRulesBatch(ctx, orgID, AlertRulesBatch{Resume: []string{paused.UID}}, models.ProvenanceAPI)
+		require.NoError(t, err)
+		rule, _, err := ruleService.GetAlertRule(ctx, orgID, paused.UID)
+		require.NoError(t, err)
+		require.False(t, rule.IsPaused)
+	})
+}
+
+func TestSetAlertGroupPaused(t *testing.T) {
+	ruleService := createAlertRuleService(t)
+	ctx := context.Background()
+	var orgID int64 = 1
+
+	var uids []string
+	for _, title := range []string{"first", "second"} {
+		rule := dummyRule(title, orgID)
+		rule.NamespaceUID = "folder"
+		rule.RuleGroup = "paused"
+		rule.Data[0].RelativeTimeRange.From = models.Duration(time.Minute)
+		rule, err := ruleService.CreateAlertRule(ctx, rule, models.ProvenanceNone)
+		require.NoError(t, err)
+		uids = append(uids, rule.UID)
+	}
+	isPaused := func(uid string) bool {
+		rule, _, err := ruleService.GetAlertRule(ctx, orgID, uid)
+		require.NoError(t, err)
+		return rule.IsPaused
+	}
+
+	require.NoError(t, ruleService.SetAlertRulePaused(ctx, orgID, uids[0], true, models.ProvenanceAPI))
+	require.True(t, isPaused(uids[0]))
+	require.False(t, isPaused(uids[1]))
+
+	require.NoError(t, ruleService.SetAlertGroupPaused(ctx, orgID, "folder", "paused", true, models.ProvenanceAPI))
+	require.True(t, isPaused(uids[0]))
+	require.True(t, isPaused(uids[1]))
+
+	require.NoError(t, ruleService.SetAlertGroupPaused(ctx, orgID, "folder", "paused", false, models.ProvenanceAPI))
+	require.False(t, isPaused(uids[0]))
+	require.False(t, isPaused(uids[1]))
+
+	require.ErrorIs(t, ruleService.SetAlertRulePaused(ctx, orgID, "missing", true, models.ProvenanceAPI), models.ErrAlertRuleNotFound)
+	require.ErrorIs(t, ruleService.SetAlertGroupPaused(ctx, orgID, "folder", "missing", true, models.ProvenanceAPI), store.ErrAlertRuleGroupNotFound)
+}
+
 func createAlertRuleService(t *testing.T) AlertRuleService {
 	t.Helper()
 	sqlStore := sqlstore.InitTestDB(t)
 	store := store.DBstore{
 		SQLStore:     sqlStore,
 		BaseInterval: time.Second * 10,
+		Logger:       log.New("testing"),
 	}
 	return AlertRuleService{
 		ruleStore:       store,
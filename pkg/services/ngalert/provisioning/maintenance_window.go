@@ -0,0 +1,82 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type MaintenanceWindowService struct {
+	store store.MaintenanceWindowStore
+	log   log.Logger
+}
+
+func NewMaintenanceWindowService(store store.MaintenanceWindowStore, log log.Logger) *MaintenanceWindowService {
+	return &MaintenanceWindowService{
+		store: store,
+		log:   log,
+	}
+}
+
+// GetMaintenanceWindows returns the maintenance windows of the org, by start time.
+func (svc *MaintenanceWindowService) GetMaintenanceWindows(ctx context.Context, orgID int64) ([]*models.MaintenanceWindow, error) {
+	return svc.store.GetMaintenanceWindows(ctx, orgID)
+}
+
+// GetMaintenanceWindow returns the maintenance window of the org with the UID.
+func (svc *MaintenanceWindowService) GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error) {
+	return svc.store.GetMaintenanceWindow(ctx, orgID, uid)
+}
+
+// CreateMaintenanceWindow adds a new maintenance window within its org. A UID is generated if it has none.
+func (svc *MaintenanceWindowService) CreateMaintenanceWindow(ctx context.Context, w models.MaintenanceWindow) (*models.MaintenanceWindow, error) {
+	if err := w.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	if w.UID != "" {
+		_, err := svc.store.GetMaintenanceWindow(ctx, w.OrgID, w.UID)
+		if err == nil {
+			return nil, fmt.Errorf("%w: %s", ErrValidation, "a maintenance window with this UID already exists")
+		}
+		if !errors.Is(err, models.ErrMaintenanceWindowNotFound) {
+			return nil, err
+		}
+	}
+	if err := svc.store.SaveMaintenanceWindow(ctx, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// UpdateMaintenanceWindow replaces the existing maintenance window of its org with the same UID.
+func (svc *MaintenanceWindowService) UpdateMaintenanceWindow(ctx context.Context, w models.MaintenanceWindow) (*models.MaintenanceWindow, error) {
+	if err := w.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	if _, err := svc.store.GetMaintenanceWindow(ctx, w.OrgID, w.UID); err != nil {
+		return nil, err
+	}
+	if err := svc.store.SaveMaintenanceWindow(ctx, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// DeleteMaintenanceWindow deletes the maintenance window of the org with the UID, along with the record of the
+// alerts it suppressed.
+func (svc *MaintenanceWindowService) DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string) error {
+	return svc.store.DeleteMaintenanceWindow(ctx, orgID, uid)
+}
+
+// GetSuppressedAlerts returns the alerts suppressed by the maintenance window of the org with the UID, most
+// recently suppressed first.
+func (svc *MaintenanceWindowService) GetSuppressedAlerts(ctx context.Context, orgID int64, uid string) ([]*models.MaintenanceSuppression, error) {
+	if _, err := svc.store.GetMaintenanceWindow(ctx, orgID, uid); err != nil {
+		return nil, err
+	}
+	return svc.store.GetMaintenanceSuppressions(ctx, orgID, uid)
+}
@@ -0,0 +1,64 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindowService(t *testing.T) {
+	now := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+	validWindow := func() models.MaintenanceWindow {
+		return models.MaintenanceWindow{
+			OrgID:    1,
+			Title:    "database upgrade",
+			StartsAt: now,
+			EndsAt:   now.Add(time.Hour),
+			Matchers: []string{`service="db"`},
+		}
+	}
+
+	t.Run("invalid windows are rejected", func(t *testing.T) {
+		sut := NewMaintenanceWindowService(store.NewFakeMaintenanceWindowStore(t), log.NewNopLogger())
+		for name, mutate := range map[string]func(w *models.MaintenanceWindow){
+			"without title":        func(w *models.MaintenanceWindow) { w.Title = "" },
+			"ending before start":  func(w *models.MaintenanceWindow) { w.EndsAt = w.StartsAt.Add(-time.Minute) },
+			"with invalid matcher": func(w *models.MaintenanceWindow) { w.Matchers = []string{"service"} },
+		} {
+			t.Run(name, func(t *testing.T) {
+				w := validWindow()
+				mutate(&w)
+				_, err := sut.CreateMaintenanceWindow(context.Background(), w)
+				require.ErrorIs(t, err, ErrValidation)
+			})
+		}
+	})
+
+	t.Run("created windows get a UID and cannot be created twice", func(t *testing.T) {
+		sut := NewMaintenanceWindowService(store.NewFakeMaintenanceWindowStore(t), log.NewNopLogger())
+		created, err := sut.CreateMaintenanceWindow(context.Background(), validWindow())
+		require.NoError(t, err)
+		require.NotEmpty(t, created.UID)
+
+		again := validWindow()
+		again.UID = created.UID
+		_, err = sut.CreateMaintenanceWindow(context.Background(), again)
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("only existing windows are updated", func(t *testing.T) {
+		sut := NewMaintenanceWindowService(store.NewFakeMaintenanceWindowStore(t), log.NewNopLogger())
+		w := validWindow()
+		w.UID = "missing"
+		_, err := sut.UpdateMaintenanceWindow(context.Background(), w)
+		require.ErrorIs(t, err, models.ErrMaintenanceWindowNotFound)
+
+		_, err = sut.GetSuppressedAlerts(context.Background(), 1, "missing")
+		require.ErrorIs(t, err, models.ErrMaintenanceWindowNotFound)
+	})
+}
@@ -0,0 +1,31 @@
+package schedule
+
+import (
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// capture records the alerts sent to external Alertmanager(s) if captureSends is set, only used for tests.
+type capture struct {
+	captureSends bool
+	capturedMtx  sync.Mutex
+	captured     map[int64][]definitions.PostableAlerts
+}
+
+// CapturedSends returns the alerts sent to external Alertmanager(s) for a particular organization,
+// in the order they were sent. Alerts are only captured if the scheduler was configured to do so.
+func (sch *schedule) CapturedSends(orgID int64) []definitions.PostableAlerts {
+	sch.capturedMtx.Lock()
+	defer sch.capturedMtx.Unlock()
+	return append([]definitions.PostableAlerts{}, sch.captured[orgID]...)
+}
+
+func (sch *schedule) captureSend(orgID int64, alerts definitions.PostableAlerts) {
+	if !sch.captureSends {
+		return
+	}
+	sch.capturedMtx.Lock()
+	defer sch.capturedMtx.Unlock()
+	sch.captured[orgID] = append(sch.captured[orgID], alerts)
+}
@@ -0,0 +1,126 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	prometheusModel "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// errResultsErrors is the failure of an evaluation whose results are all errors without telling why.
+var errResultsErrors = errors.New("all the results of the evaluation are errors")
+
+// ruleCircuit is the state of the circuit breaker of a rule, owned by the routine of the rule.
+type ruleCircuit struct {
+	// failures is the number of evaluations that failed in a row.
+	failures int
+	// openedAt is when the rule was backed off for the first time since it last evaluated successfully, and
+	// skipUntil is when it is evaluated again.
+	openedAt  time.Time
+	skipUntil time.Time
+}
+
+// open tells whether the rule is backed off.
+func (c *ruleCircuit) open() bool {
+	return !c.openedAt.IsZero()
+}
+
+// skip tells whether the evaluation scheduled at now is skipped because the rule is backed off.
+func (c *ruleCircuit) skip(now time.Time) bool {
+	return now.Before(c.skipUntil)
+}
+
+// evaluationFailed records that the evaluation of the rule scheduled at now failed. Once the rule failed the
+// threshold of the circuit breaker, it is backed off and the alert reporting it is returned, nil otherwise.
+func (sch *schedule) evaluationFailed(key models.AlertRuleKey, c *ruleCircuit, interval time.Duration, now time.Time, err error) *amv2.PostableAlert {
+	c.failures++
+	threshold := sch.circuitBreaker.Threshold
+	if threshold <= 0 || c.failures < threshold {
+		return nil
+	}
+
+	backoff := sch.circuitBreaker.backoff(interval, c.failures-threshold)
+	if !c.open() {
+		c.openedAt = now
+	}
+	c.skipUntil = now.Add(backoff)
+	annotations := amv2.LabelSet{
+		"description": fmt.Sprintf("The rule failed to evaluate %d times in a row, it is not evaluated for %s.", c.failures, backoff),
+		"count":       fmt.Sprint(c.failures),
+	}
+	if err != nil {
+		annotations["error"] = err.Error()
+	}
+	return &amv2.PostableAlert{
+		Alert:       amv2.Alert{Labels: circuitOpenLabels(key)},
+		Annotations: annotations,
+		StartsAt:    strfmt.DateTime(c.openedAt),
+		// the alert is sent again if the next evaluation fails, and resolved once one succeeds
+		EndsAt: strfmt.DateTime(c.skipUntil.Add(backoff)),
+	}
+}
+
+// evaluationSucceeded records that the evaluation of the rule scheduled at now succeeded. If the rule was backed
+// off, it returns the alert reporting it resolved, nil otherwise.
+func (sch *schedule) evaluationSucceeded(key models.AlertRuleKey, c *ruleCircuit, now time.Time) *amv2.PostableAlert {
+	defer func() {
+		*c = ruleCircuit{}
+	}()
+	if !c.open() {
+		return nil
+	}
+	return &amv2.PostableAlert{
+		Alert:    amv2.Alert{Labels: circuitOpenLabels(key)},
+		StartsAt: strfmt.DateTime(c.openedAt),
+		EndsAt:   strfmt.DateTime(now),
+	}
+}
+
+// backoff returns how long a rule evaluated every interval is backed off after failing the given number of
+// times beyond the threshold: its interval, doubled after every failure, up to the max backoff.
+func (cb CircuitBreaker) backoff(interval time.Duration, failures int) time.Duration {
+	maxBackoff := cb.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultCircuitBreakerMaxBackoff
+	}
+	backoff := interval
+	for i := 0; i < failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+// circuitOpenLabels returns the labels of the alert sent while the rule is backed off.
+func circuitOpenLabels(key models.AlertRuleKey) amv2.LabelSet {
+	return amv2.LabelSet{
+		prometheusModel.AlertNameLabel: circuitOpenAlertName,
+		"org_id":                       fmt.Sprint(key.OrgID),
+		"rule_uid":                     key.UID,
+	}
+}
+
+// resultsError returns the error of the first result of an evaluation whose results are all errors, e.g. because
+// the datasource is down, nil if some are not.
+func resultsError(results eval.Results) error {
+	if len(results) == 0 {
+		return nil
+	}
+	for _, r := range results {
+		if r.State != eval.Error {
+			return nil
+		}
+	}
+	if results[0].Error != nil {
+		return results[0].Error
+	}
+	return errResultsErrors
+}
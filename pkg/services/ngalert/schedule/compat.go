@@ -1,9 +1,12 @@
 package schedule
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"net/url"
 	"path"
+	"strconv"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -27,6 +30,8 @@ const (
 
 // stateToPostableAlert converts a state to a model that is accepted by Alertmanager. Annotations and Labels are copied from the state.
 // - if state has at least one result, a new label '__value_string__' is added to the label set
+// - if state has at least one result, the annotations '__values__' and '__evaluations_in_state__' are added
+// - if state has a reason, a new annotation '__state_reason__' is added
 // - the alert's GeneratorURL is constructed to point to the alert detail view
 // - if evaluation state is either NoData or Error, the resulting set of labels is changed:
 //   - original alert name (label: model.AlertNameLabel) is backed up to OriginalAlertName
@@ -41,8 +46,13 @@ func stateToPostableAlert(alertState *state.State, appURL *url.URL) *models.Post
 
 	if alertState.Image != nil {
 		nA[ngModels.ScreenshotTokenAnnotation] = alertState.Image.Token
+		if alertState.Image.URL != "" {
+			nA[ngModels.ImageURLAnnotation] = alertState.Image.URL
+		}
 	}
 
+	addEvaluationAnnotations(nA, alertState)
+
 	var urlStr string
 	if uid := nL[ngModels.RuleUIDLabel]; len(uid) > 0 && appURL != nil {
 		u := *appURL
@@ -73,6 +83,38 @@ func stateToPostableAlert(alertState *state.State, appURL *url.URL) *models.Post
 	}
 }
 
+// addEvaluationAnnotations adds the values of the last evaluation of the state, the number of consecutive
+// evaluations in its state and the reason of the state to the annotations, so that they are available to the
+// templates of the Alertmanagers.
+func addEvaluationAnnotations(annotations data.Labels, alertState *state.State) {
+	if alertState.StateReason != "" {
+		annotations[ngModels.StateReasonAnnotation] = alertState.StateReason
+	}
+	if len(alertState.Results) == 0 {
+		return
+	}
+
+	last := alertState.Results[len(alertState.Results)-1]
+	if len(last.Values) > 0 {
+		values := make(map[string]float64, len(last.Values))
+		for refID, v := range last.Values {
+			// NaN and infinite values cannot be encoded in JSON.
+			if v != nil && !math.IsNaN(*v) && !math.IsInf(*v, 0) {
+				values[refID] = *v
+			}
+		}
+		if b, err := json.Marshal(values); err == nil {
+			annotations[ngModels.ValuesAnnotation] = string(b)
+		}
+	}
+
+	inState := 0
+	for i := len(alertState.Results) - 1; i >= 0 && alertState.Results[i].EvaluationState == last.EvaluationState; i-- {
+		inState++
+	}
+	annotations[ngModels.EvaluationsInStateAnnotation] = strconv.Itoa(inState)
+}
+
 // NoDataAlert is a special alert sent by Grafana to the Alertmanager, that indicates we received no data from the datasource.
 // It effectively replaces the legacy behavior of "Keep Last State" by separating the regular alerting flow from the no data scenario into a separate alerts.
 // The Alert is defined as:
@@ -132,10 +174,16 @@ func FromAlertStateToPostableAlerts(firingStates []*state.State, stateManager *s
 }
 
 // FromAlertsStateToStoppedAlert converts firingStates that have evaluation state either eval.Alerting or eval.NoData or eval.Error to models.PostableAlert that are accepted by notifiers.
-// Returns a list of alert instances that have expiration time.Now
-func FromAlertsStateToStoppedAlert(firingStates []*state.State, appURL *url.URL, clock clock.Clock) apimodels.PostableAlerts {
+// Returns a list of alert instances that have expiration time.Now, or later with a grace window, and none if resolved alerts are suppressed.
+func FromAlertsStateToStoppedAlert(firingStates []*state.State, appURL *url.URL, clock clock.Clock, policy ResolvedAlertsPolicy) apimodels.PostableAlerts {
+	if policy.Mode == ResolvedAlertsSuppress {
+		return apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{}}
+	}
 	alerts := apimodels.PostableAlerts{PostableAlerts: make([]models.PostableAlert, 0, len(firingStates))}
 	ts := clock.Now()
+	if policy.Mode == ResolvedAlertsGraceWindow {
+		ts = ts.Add(policy.GraceWindow)
+	}
 	for _, alertState := range firingStates {
 		if alertState.State == eval.Normal || alertState.State == eval.Pending {
 			continue
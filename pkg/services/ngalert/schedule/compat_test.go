@@ -2,6 +2,7 @@ package schedule
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"net/url"
 	"testing"
@@ -133,6 +134,51 @@ func Test_stateToPostableAlert(t *testing.T) {
 
 					require.Equal(t, expected, result.Annotations)
 				})
+
+				t.Run("add __alertImageUrl__ if the image was uploaded", func(t *testing.T) {
+					alertState := randomState(tc.state)
+					alertState.Annotations = randomMapOfStrings()
+					alertState.Image = &ngModels.Image{Token: "test_token", URL: "https://images.example.com/test.png"}
+
+					result := stateToPostableAlert(alertState, appURL)
+
+					expected := make(models.LabelSet, len(alertState.Annotations)+2)
+					for k, v := range alertState.Annotations {
+						expected[k] = v
+					}
+					expected["__alertScreenshotToken__"] = alertState.Image.Token
+					expected["__alertImageUrl__"] = alertState.Image.URL
+
+					require.Equal(t, expected, result.Annotations)
+				})
+
+				t.Run("add evaluation annotations if it has results", func(t *testing.T) {
+					alertState := randomState(tc.state)
+					alertState.Annotations = randomMapOfStrings()
+					alertState.StateReason = eval.NoData.String()
+					v1, v2, nan := 1.5, 2.5, math.NaN()
+					previous := eval.Alerting
+					if tc.state == eval.Alerting {
+						previous = eval.Normal
+					}
+					alertState.Results = []state.Evaluation{
+						{EvaluationState: previous},
+						{EvaluationState: tc.state, Values: map[string]*float64{"A": &v1}},
+						{EvaluationState: tc.state, Values: map[string]*float64{"A": &v2, "B": nil, "C": &nan}},
+					}
+
+					result := stateToPostableAlert(alertState, appURL)
+
+					expected := make(models.LabelSet, len(alertState.Annotations)+3)
+					for k, v := range alertState.Annotations {
+						expected[k] = v
+					}
+					expected["__values__"] = `{"A":2.5}`
+					expected["__state_reason__"] = eval.NoData.String()
+					expected["__evaluations_in_state__"] = "2"
+
+					require.Equal(t, expected, result.Annotations)
+				})
 			})
 
 			switch tc.state {
@@ -232,9 +278,22 @@ func Test_FromAlertsStateToStoppedAlert(t *testing.T) {
 		expected = append(expected, *alert)
 	}
 
-	result := FromAlertsStateToStoppedAlert(states, appURL, clk)
+	result := FromAlertsStateToStoppedAlert(states, appURL, clk, ResolvedAlertsPolicy{})
 
 	require.Equal(t, expected, result.PostableAlerts)
+
+	t.Run("alerts end after the grace window", func(t *testing.T) {
+		result := FromAlertsStateToStoppedAlert(states, appURL, clk, ResolvedAlertsPolicy{Mode: ResolvedAlertsGraceWindow, GraceWindow: time.Minute})
+		require.Len(t, result.PostableAlerts, len(expected))
+		for _, alert := range result.PostableAlerts {
+			require.Equal(t, strfmt.DateTime(clk.Now().Add(time.Minute)), alert.EndsAt)
+		}
+	})
+
+	t.Run("no alerts if resolved alerts are suppressed", func(t *testing.T) {
+		result := FromAlertsStateToStoppedAlert(states, appURL, clk, ResolvedAlertsPolicy{Mode: ResolvedAlertsSuppress})
+		require.Empty(t, result.PostableAlerts)
+	})
 }
 
 func randomMapOfStrings() map[string]string {
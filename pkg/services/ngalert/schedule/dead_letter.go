@@ -0,0 +1,104 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// errNoDeadLetterStore is returned by the operations on the alerts that could not be delivered when they are not kept.
+var errNoDeadLetterStore = errors.New("alerts that could not be delivered are not kept")
+
+// saveDeadLetterAlerts keeps the alerts of the rule that could not be delivered, if there is a dead letter store.
+func (sch *schedule) saveDeadLetterAlerts(key models.AlertRuleKey, alerts definitions.PostableAlerts, logger log.Logger) {
+	if sch.deadLetterStore == nil {
+		return
+	}
+
+	deadLetters := make([]*models.DeadLetterAlert, 0, len(alerts.PostableAlerts))
+	for _, a := range alerts.PostableAlerts {
+		b, err := json.Marshal(a)
+		if err != nil {
+			logger.Error("failed to marshal the alert that could not be delivered", "err", err)
+			continue
+		}
+		deadLetters = append(deadLetters, &models.DeadLetterAlert{
+			OrgID:       key.OrgID,
+			RuleUID:     key.UID,
+			Fingerprint: labelsToModel(a.Labels).Fingerprint().String(),
+			Alert:       string(b),
+			CreatedAt:   sch.clock.Now(),
+		})
+	}
+	if err := sch.deadLetterStore.SaveDeadLetterAlerts(context.Background(), deadLetters); err != nil {
+		logger.Error("failed to save the alerts that could not be delivered", "count", len(deadLetters), "err", err)
+	}
+}
+
+// DeadLetterAlerts returns the alerts of the organization that could not be delivered, oldest first.
+func (sch *schedule) DeadLetterAlerts(ctx context.Context, orgID int64) ([]*models.DeadLetterAlert, error) {
+	if sch.deadLetterStore == nil {
+		return nil, errNoDeadLetterStore
+	}
+	return sch.deadLetterStore.GetDeadLetterAlerts(ctx, orgID)
+}
+
+// RedispatchDeadLetterAlerts delivers again, rule by rule, the alerts of the organization that could not be
+// delivered and deletes the ones delivered. It returns how many were delivered, and stops at the first rule
+// whose alerts still cannot be delivered.
+func (sch *schedule) RedispatchDeadLetterAlerts(ctx context.Context, orgID int64) (int, error) {
+	deadLetters, err := sch.DeadLetterAlerts(ctx, orgID)
+	if err != nil {
+		return 0, err
+	}
+
+	var ruleUIDs []string
+	byRule := map[string][]*models.DeadLetterAlert{}
+	for _, d := range deadLetters {
+		if _, ok := byRule[d.RuleUID]; !ok {
+			ruleUIDs = append(ruleUIDs, d.RuleUID)
+		}
+		byRule[d.RuleUID] = append(byRule[d.RuleUID], d)
+	}
+
+	var redispatched int
+	for _, uid := range ruleUIDs {
+		key := models.AlertRuleKey{OrgID: orgID, UID: uid}
+		logger := sch.log.New("uid", uid, "org", orgID, "redispatch", true)
+		alerts := definitions.PostableAlerts{}
+		ids := make([]int64, 0, len(byRule[uid]))
+		for _, d := range byRule[uid] {
+			var a amv2.PostableAlert
+			if err := json.Unmarshal([]byte(d.Alert), &a); err != nil {
+				logger.Error("failed to unmarshal the alert that could not be delivered, it is deleted", "id", d.ID, "err", err)
+			} else {
+				alerts.PostableAlerts = append(alerts.PostableAlerts, a)
+			}
+			ids = append(ids, d.ID)
+		}
+
+		// Alerts that reach at least one notifier are delivered.
+		if err := sch.notify(key, alerts, logger); errors.Is(err, errNoNotifier) {
+			return redispatched, err
+		}
+		if err := sch.deadLetterStore.DeleteDeadLetterAlerts(ctx, orgID, ids...); err != nil {
+			return redispatched, err
+		}
+		redispatched += len(alerts.PostableAlerts)
+	}
+	return redispatched, nil
+}
+
+// PurgeDeadLetterAlerts deletes the alerts of the organization that could not be delivered.
+func (sch *schedule) PurgeDeadLetterAlerts(ctx context.Context, orgID int64) error {
+	if sch.deadLetterStore == nil {
+		return errNoDeadLetterStore
+	}
+	return sch.deadLetterStore.DeleteDeadLetterAlerts(ctx, orgID)
+}
@@ -0,0 +1,168 @@
+package schedule
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/sender"
+)
+
+// dedup holds the firing alerts sent to external Alertmanager(s), so that they are not sent again before
+// externalResendInterval if they did not change. sentAlerts holds, per rule, the alerts last sent to them by
+// fingerprint, and sentAlertIDs, per organization, where to find them by the ID the sender reports them with.
+type dedup struct {
+	externalResendInterval time.Duration
+	fingerprint            FingerprintFunc
+	sentAlertsMtx          sync.Mutex
+	sentAlerts             map[models.AlertRuleKey]map[string]sentAlert
+	sentAlertIDs           map[int64]map[string]sentAlertRef
+}
+
+// sentAlert is a firing alert sent to external Alertmanager(s).
+type sentAlert struct {
+	// content identifies everything sent but the labels and the end of the alert, which changes after every
+	// evaluation.
+	content string
+	endsAt  time.Time
+	sentAt  time.Time
+	// delivered is set once an external Alertmanager accepted the alert, sends are asynchronous.
+	delivered bool
+	// id is the ID of the alert in the results of the sender.
+	id string
+}
+
+// sentAlertRef is where a sent alert is found: its rule and its fingerprint.
+type sentAlertRef struct {
+	key models.AlertRuleKey
+	fp  string
+}
+
+// dedupExternalAlerts removes the firing alerts of the rule that were sent to external Alertmanager(s) less
+// than the resend interval ago and did not change since. An alert is sent again anyway once half of the time
+// it was valid for when it was sent has elapsed, so that it is not resolved before the next evaluation. Firing
+// alerts of the batch with the same fingerprint are the same alert, only the first of them is sent.
+func (sch *schedule) dedupExternalAlerts(key models.AlertRuleKey, alerts definitions.PostableAlerts, logger log.Logger) definitions.PostableAlerts {
+	if sch.externalResendInterval <= 0 {
+		return alerts
+	}
+
+	now := sch.clock.Now()
+	sch.sentAlertsMtx.Lock()
+	defer sch.sentAlertsMtx.Unlock()
+	prev := sch.sentAlerts[key]
+	// Only the alerts of this batch are kept, the ones that are not firing anymore must be sent again. The
+	// alerts that are sent are recorded by recordExternalAlerts.
+	sent := make(map[string]sentAlert, len(alerts.PostableAlerts))
+	kept := make([]amv2.PostableAlert, 0, len(alerts.PostableAlerts))
+	seen := make(map[string]struct{}, len(alerts.PostableAlerts))
+	for _, a := range alerts.PostableAlerts {
+		endsAt := time.Time(a.EndsAt)
+		if !endsAt.IsZero() && !endsAt.After(now) {
+			kept = append(kept, a)
+			continue
+		}
+
+		fp := sch.fingerprint(a)
+		if _, ok := seen[fp]; ok {
+			continue
+		}
+		seen[fp] = struct{}{}
+		if p, ok := prev[fp]; ok && p.delivered && p.content == sentAlertContent(a) &&
+			now.Sub(p.sentAt) < sch.externalResendInterval &&
+			p.endsAt.Sub(now) > p.endsAt.Sub(p.sentAt)/2 {
+			sent[fp] = p
+			continue
+		}
+		kept = append(kept, a)
+	}
+	for fp, p := range prev {
+		if _, ok := sent[fp]; !ok {
+			sch.forgetSentAlertID(key, p)
+		}
+	}
+	sch.sentAlerts[key] = sent
+
+	if deduped := len(alerts.PostableAlerts) - len(kept); deduped > 0 {
+		logger.Debug("not sending alerts that did not change to external Alertmanager(s)", "count", deduped)
+		sch.metrics.ExternalAlertsDeduplicated.WithLabelValues(fmt.Sprint(key.OrgID)).Add(float64(deduped))
+	}
+	return definitions.PostableAlerts{PostableAlerts: kept}
+}
+
+// recordExternalAlerts records the firing alerts of the rule sent to external Alertmanager(s). Once
+// markExternalAlertsDelivered reports them accepted, dedupExternalAlerts does not send them again until the
+// resend interval. Only the alerts actually sent must be recorded, not the ones dropped after deduplication.
+func (sch *schedule) recordExternalAlerts(key models.AlertRuleKey, alerts definitions.PostableAlerts) {
+	if sch.externalResendInterval <= 0 {
+		return
+	}
+
+	now := sch.clock.Now()
+	sch.sentAlertsMtx.Lock()
+	defer sch.sentAlertsMtx.Unlock()
+	sent, ok := sch.sentAlerts[key]
+	if !ok {
+		sent = make(map[string]sentAlert, len(alerts.PostableAlerts))
+		sch.sentAlerts[key] = sent
+	}
+	ids, ok := sch.sentAlertIDs[key.OrgID]
+	if !ok {
+		ids = map[string]sentAlertRef{}
+		sch.sentAlertIDs[key.OrgID] = ids
+	}
+	for _, a := range alerts.PostableAlerts {
+		endsAt := time.Time(a.EndsAt)
+		if !endsAt.IsZero() && !endsAt.After(now) {
+			continue
+		}
+		fp := sch.fingerprint(a)
+		if p, ok := sent[fp]; ok {
+			sch.forgetSentAlertID(key, p)
+		}
+		id := sender.AlertID(a)
+		sent[fp] = sentAlert{content: sentAlertContent(a), endsAt: endsAt, sentAt: now, id: id}
+		ids[id] = sentAlertRef{key: key, fp: fp}
+	}
+}
+
+// forgetSentAlertID removes the ID of the alert sent by the rule, unless it now identifies another alert. It must
+// be called with the sent alerts lock held.
+func (sch *schedule) forgetSentAlertID(key models.AlertRuleKey, a sentAlert) {
+	ids := sch.sentAlertIDs[key.OrgID]
+	if ref, ok := ids[a.id]; ok && ref.key == key && sch.sentAlerts[key][ref.fp].id == a.id {
+		delete(ids, a.id)
+	}
+}
+
+// markExternalAlertsDelivered marks the alerts of the organization an external Alertmanager accepted as
+// delivered, from the IDs the sender reported them with, whatever their relabeling by the sender.
+func (sch *schedule) markExternalAlertsDelivered(orgID int64, alertIDs []string) {
+	if sch.externalResendInterval <= 0 {
+		return
+	}
+
+	sch.sentAlertsMtx.Lock()
+	defer sch.sentAlertsMtx.Unlock()
+	for _, id := range alertIDs {
+		ref, ok := sch.sentAlertIDs[orgID][id]
+		if !ok {
+			continue
+		}
+		if a, ok := sch.sentAlerts[ref.key][ref.fp]; ok && a.id == id {
+			a.delivered = true
+			sch.sentAlerts[ref.key][ref.fp] = a
+		}
+	}
+}
+
+// sentAlertContent returns what identifies the content of an alert sent, all but its labels, which its
+// fingerprint identifies, and its end.
+func sentAlertContent(a amv2.PostableAlert) string {
+	return fmt.Sprintf("%v%s%v", a.Annotations, a.GeneratorURL, time.Time(a.StartsAt).UnixNano())
+}
@@ -0,0 +1,79 @@
+package schedule
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// deliveryPause holds whether the alerts are sent to the local notifier only, for all organizations or some.
+type deliveryPause struct {
+	// externalDeliveryPaused is set to 1 while alerts are not sent to external Alertmanager(s).
+	externalDeliveryPaused int32
+	// pausedOrgs are the organizations whose alerts are sent to the local notifier only, with when their
+	// external delivery resumes, zero if it is resumed explicitly.
+	pausedOrgsMtx sync.Mutex
+	pausedOrgs    map[int64]time.Time
+}
+
+// PauseExternalDelivery stops sending alerts to external Alertmanager(s), for all organizations, until
+// ResumeExternalDelivery is called. The senders are still synced with the admin configuration and
+// alerts are all sent to the local notifier, as when the external delivery of an organization is paused.
+func (sch *schedule) PauseExternalDelivery() {
+	atomic.StoreInt32(&sch.externalDeliveryPaused, 1)
+	sch.log.Info("external delivery paused")
+}
+
+// ResumeExternalDelivery resumes sending alerts to external Alertmanager(s).
+func (sch *schedule) ResumeExternalDelivery() {
+	atomic.StoreInt32(&sch.externalDeliveryPaused, 0)
+	sch.log.Info("external delivery resumed")
+}
+
+// ExternalDeliveryPaused returns true if alerts are not sent to external Alertmanager(s).
+func (sch *schedule) ExternalDeliveryPaused() bool {
+	return atomic.LoadInt32(&sch.externalDeliveryPaused) == 1
+}
+
+// PauseExternalDeliveryFor sends the alerts of an organization to the local notifier only, for the given duration,
+// or until ResumeExternalDeliveryFor is called if it is 0. The admin configuration and the sender of the
+// organization are kept, so that its external delivery can be resumed right away.
+func (sch *schedule) PauseExternalDeliveryFor(orgID int64, duration time.Duration) {
+	var until time.Time
+	if duration > 0 {
+		until = sch.clock.Now().Add(duration)
+	}
+	sch.pausedOrgsMtx.Lock()
+	sch.pausedOrgs[orgID] = until
+	sch.pausedOrgsMtx.Unlock()
+	sch.log.Info("external delivery paused", "org", orgID, "until", until)
+}
+
+// ResumeExternalDeliveryFor resumes sending the alerts of an organization to its external Alertmanager(s).
+func (sch *schedule) ResumeExternalDeliveryFor(orgID int64) {
+	sch.pausedOrgsMtx.Lock()
+	_, paused := sch.pausedOrgs[orgID]
+	delete(sch.pausedOrgs, orgID)
+	sch.pausedOrgsMtx.Unlock()
+	if paused {
+		sch.log.Info("external delivery resumed", "org", orgID)
+	}
+}
+
+// ExternalDeliveryPausedFor returns true if the alerts of an organization are sent to the local notifier only,
+// along with when its external delivery resumes, zero if it must be resumed explicitly. A pause that is over
+// is resumed.
+func (sch *schedule) ExternalDeliveryPausedFor(orgID int64) (bool, time.Time) {
+	sch.pausedOrgsMtx.Lock()
+	defer sch.pausedOrgsMtx.Unlock()
+	until, paused := sch.pausedOrgs[orgID]
+	if !paused {
+		return false, time.Time{}
+	}
+	if !until.IsZero() && !sch.clock.Now().Before(until) {
+		delete(sch.pausedOrgs, orgID)
+		sch.log.Info("external delivery resumed at the end of the pause", "org", orgID)
+		return false, time.Time{}
+	}
+	return true, until
+}
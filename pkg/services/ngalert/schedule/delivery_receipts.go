@@ -0,0 +1,149 @@
+package schedule
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/sender"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+const (
+	// defaultDeliveryReceiptBufferSize is the number of delivery receipts waiting to be saved. The receipts are
+	// saved deliveryReceiptBatchSize at a time, every deliveryReceiptFlushInterval or as soon as a batch is
+	// waiting, and the receipts older than their TTL are deleted every deliveryReceiptPruneInterval.
+	defaultDeliveryReceiptBufferSize = 10000
+	deliveryReceiptBatchSize         = 500
+	deliveryReceiptFlushInterval     = 5 * time.Second
+	deliveryReceiptPruneInterval     = time.Hour
+)
+
+// receipts keeps the outcomes of sending the alerts to external targets in deliveryReceiptStore, nil does not
+// keep them. deliveryReceipts are the receipts waiting to be saved, at most deliveryReceiptBufferSize, and
+// deliveryReceiptsReady is signaled when a batch of them is waiting.
+type receipts struct {
+	deliveryReceiptStore      store.DeliveryReceiptStore
+	deliveryReceiptsMtx       sync.Mutex
+	deliveryReceipts          []*models.DeliveryReceipt
+	deliveryReceiptsReady     chan struct{}
+	deliveryReceiptBufferSize int
+	// deliveryReceiptTTL is how long the delivery receipts are kept, 0 keeps them forever.
+	deliveryReceiptTTL time.Duration
+}
+
+// saveDeliveryReceipts keeps the outcome of a send to an external target of the organization for each alert sent,
+// if there is a delivery receipt store. The receipts are buffered and saved in batches by deliveryReceiptSync,
+// the oldest receipts are dropped when the buffer is full.
+func (sch *schedule) saveDeliveryReceipts(orgID int64, res sender.SendResult) {
+	if sch.deliveryReceiptStore == nil || len(res.AlertLabels) == 0 {
+		return
+	}
+
+	target := res.Alertmanager
+	if u, err := url.Parse(target); err == nil {
+		target = u.Redacted()
+	}
+	var errMsg string
+	if res.Err != nil {
+		errMsg = res.Err.Error()
+	}
+	receipts := make([]*models.DeliveryReceipt, 0, len(res.AlertLabels))
+	for _, l := range res.AlertLabels {
+		receipts = append(receipts, &models.DeliveryReceipt{
+			OrgID:      orgID,
+			BatchID:    res.BatchID,
+			Target:     target,
+			RuleUID:    l[models.RuleUIDLabel],
+			Labels:     models.InstanceLabels(l),
+			StatusCode: res.StatusCode,
+			Error:      errMsg,
+			Attempts:   res.Attempts,
+			SentAt:     res.Timestamp,
+		})
+	}
+
+	sch.deliveryReceiptsMtx.Lock()
+	sch.deliveryReceipts = append(sch.deliveryReceipts, receipts...)
+	dropped := len(sch.deliveryReceipts) - sch.deliveryReceiptBufferSize
+	if dropped > 0 {
+		sch.deliveryReceipts = append([]*models.DeliveryReceipt(nil), sch.deliveryReceipts[dropped:]...)
+	}
+	ready := len(sch.deliveryReceipts) >= deliveryReceiptBatchSize
+	sch.deliveryReceiptsMtx.Unlock()
+
+	if dropped > 0 {
+		sch.metrics.DeliveryReceiptsDropped.Add(float64(dropped))
+		sch.log.Warn("delivery receipt buffer is full, dropping the oldest receipts", "count", dropped)
+	}
+	if ready {
+		select {
+		case sch.deliveryReceiptsReady <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// deliveryReceiptSync saves the buffered delivery receipts every flush interval or as soon as a batch is
+// waiting, and deletes the receipts older than their TTL every prune interval. When the context is done, the
+// receipts still buffered are saved.
+func (sch *schedule) deliveryReceiptSync(ctx context.Context) {
+	flush := time.NewTicker(deliveryReceiptFlushInterval)
+	defer flush.Stop()
+	prune := time.NewTicker(deliveryReceiptPruneInterval)
+	defer prune.Stop()
+	sch.pruneDeliveryReceipts(ctx)
+	for {
+		select {
+		case <-flush.C:
+			sch.flushDeliveryReceipts(ctx)
+		case <-sch.deliveryReceiptsReady:
+			sch.flushDeliveryReceipts(ctx)
+		case <-prune.C:
+			sch.pruneDeliveryReceipts(ctx)
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), deliveryReceiptFlushInterval)
+			defer cancel()
+			sch.flushDeliveryReceipts(flushCtx)
+			return
+		}
+	}
+}
+
+// flushDeliveryReceipts saves the buffered delivery receipts, a batch at a time. The receipts of a batch that
+// cannot be saved are dropped, so that the buffer does not fill up while the database is unavailable.
+func (sch *schedule) flushDeliveryReceipts(ctx context.Context) {
+	for {
+		sch.deliveryReceiptsMtx.Lock()
+		n := len(sch.deliveryReceipts)
+		if n > deliveryReceiptBatchSize {
+			n = deliveryReceiptBatchSize
+		}
+		batch := sch.deliveryReceipts[:n:n]
+		sch.deliveryReceipts = sch.deliveryReceipts[n:]
+		sch.deliveryReceiptsMtx.Unlock()
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := sch.deliveryReceiptStore.SaveDeliveryReceipts(ctx, batch); err != nil {
+			sch.metrics.DeliveryReceiptsDropped.Add(float64(len(batch)))
+			sch.log.Error("failed to save the delivery receipts", "count", len(batch), "err", err)
+		}
+	}
+}
+
+// pruneDeliveryReceipts deletes the delivery receipts older than their TTL, if they are not kept forever.
+func (sch *schedule) pruneDeliveryReceipts(ctx context.Context) {
+	if sch.deliveryReceiptTTL <= 0 {
+		return
+	}
+	deleted, err := sch.deliveryReceiptStore.DeleteDeliveryReceipts(ctx, sch.clock.Now().Add(-sch.deliveryReceiptTTL))
+	if err != nil {
+		sch.log.Error("failed to delete the expired delivery receipts", "err", err)
+		return
+	}
+	sch.log.Debug("deleted the expired delivery receipts", "count", deleted)
+}
@@ -0,0 +1,91 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// AlertEnricher mutates the alerts of a rule before they are routed, put in the local notifier and sent to
+// external Alertmanager(s), e.g. to add labels or annotations or to rewrite the generator URL.
+type AlertEnricher interface {
+	Enrich(ctx context.Context, key models.AlertRuleKey, alerts []amv2.PostableAlert) error
+}
+
+// AlertEnricherFunc is a function used as an AlertEnricher.
+type AlertEnricherFunc func(ctx context.Context, key models.AlertRuleKey, alerts []amv2.PostableAlert) error
+
+// Enrich calls the function.
+func (f AlertEnricherFunc) Enrich(ctx context.Context, key models.AlertRuleKey, alerts []amv2.PostableAlert) error {
+	return f(ctx, key, alerts)
+}
+
+// AlertEnrichers is a registry of the enrichers of the alerts, applied in the order they were registered.
+// Enrichers can be registered and unregistered at any time, e.g. by plugins.
+type AlertEnrichers struct {
+	mtx       sync.RWMutex
+	names     []string
+	enrichers map[string]AlertEnricher
+}
+
+func NewAlertEnrichers() *AlertEnrichers {
+	return &AlertEnrichers{enrichers: map[string]AlertEnricher{}}
+}
+
+// Register adds an enricher, applied after the ones already registered. Names must be unique.
+func (r *AlertEnrichers) Register(name string, e AlertEnricher) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.enrichers[name]; ok {
+		return fmt.Errorf("alert enricher %s is already registered", name)
+	}
+	r.names = append(r.names, name)
+	r.enrichers[name] = e
+	return nil
+}
+
+// Unregister removes an enricher, if registered.
+func (r *AlertEnrichers) Unregister(name string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.enrichers[name]; !ok {
+		return
+	}
+	delete(r.enrichers, name)
+	for i, n := range r.names {
+		if n == name {
+			r.names = append(r.names[:i:i], r.names[i+1:]...)
+			break
+		}
+	}
+}
+
+// Names returns the names of the enrichers, in the order they are applied.
+func (r *AlertEnrichers) Names() []string {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return append([]string(nil), r.names...)
+}
+
+// enrich applies the enrichers to the alerts of the rule. An enricher failing is logged and the next ones are
+// applied anyway, to the alerts as it left them.
+func (r *AlertEnrichers) enrich(ctx context.Context, key models.AlertRuleKey, alerts []amv2.PostableAlert, logger log.Logger) {
+	r.mtx.RLock()
+	names := r.names
+	enrichers := make([]AlertEnricher, 0, len(names))
+	for _, name := range names {
+		enrichers = append(enrichers, r.enrichers[name])
+	}
+	r.mtx.RUnlock()
+
+	for i, e := range enrichers {
+		if err := e.Enrich(ctx, key, alerts); err != nil {
+			logger.Error("failed to enrich alerts", "enricher", names[i], "err", err)
+		}
+	}
+}
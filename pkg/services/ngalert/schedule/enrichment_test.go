@@ -0,0 +1,114 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestAlertEnrichers(t *testing.T) {
+	addLabel := func(name, value string) AlertEnricher {
+		return AlertEnricherFunc(func(_ context.Context, _ models.AlertRuleKey, alerts []amv2.PostableAlert) error {
+			for i := range alerts {
+				alerts[i].Labels[name] = value
+			}
+			return nil
+		})
+	}
+
+	t.Run("enrichers are applied in the order they are registered", func(t *testing.T) {
+		r := NewAlertEnrichers()
+		require.NoError(t, r.Register("first", addLabel("label", "first")))
+		require.NoError(t, r.Register("second", addLabel("label", "second")))
+		require.Equal(t, []string{"first", "second"}, r.Names())
+
+		alerts := []amv2.PostableAlert{{Alert: amv2.Alert{Labels: amv2.LabelSet{}}}}
+		r.enrich(context.Background(), models.AlertRuleKey{}, alerts, log.NewNopLogger())
+		require.Equal(t, "second", alerts[0].Labels["label"])
+	})
+
+	t.Run("names must be unique", func(t *testing.T) {
+		r := NewAlertEnrichers()
+		require.NoError(t, r.Register("first", addLabel("label", "first")))
+		require.EqualError(t, r.Register("first", addLabel("label", "other")), "alert enricher first is already registered")
+	})
+
+	t.Run("enrichers can be unregistered", func(t *testing.T) {
+		r := NewAlertEnrichers()
+		require.NoError(t, r.Register("first", addLabel("first", "true")))
+		require.NoError(t, r.Register("second", addLabel("second", "true")))
+		require.NoError(t, r.Register("third", addLabel("third", "true")))
+		r.Unregister("second")
+		r.Unregister("unknown")
+		require.Equal(t, []string{"first", "third"}, r.Names())
+
+		alerts := []amv2.PostableAlert{{Alert: amv2.Alert{Labels: amv2.LabelSet{}}}}
+		r.enrich(context.Background(), models.AlertRuleKey{}, alerts, log.NewNopLogger())
+		require.Equal(t, amv2.LabelSet{"first": "true", "third": "true"}, alerts[0].Labels)
+	})
+
+	t.Run("the next enrichers are applied when one fails", func(t *testing.T) {
+		r := NewAlertEnrichers()
+		require.NoError(t, r.Register("failing", AlertEnricherFunc(func(_ context.Context, _ models.AlertRuleKey, alerts []amv2.PostableAlert) error {
+			alerts[0].Labels["failing"] = "true"
+			return errors.New("failed")
+		})))
+		require.NoError(t, r.Register("next", addLabel("next", "true")))
+
+		alerts := []amv2.PostableAlert{{Alert: amv2.Alert{Labels: amv2.LabelSet{}}}}
+		r.enrich(context.Background(), models.AlertRuleKey{}, alerts, log.NewNopLogger())
+		require.Equal(t, amv2.LabelSet{"failing": "true", "next": "true"}, alerts[0].Labels)
+	})
+}
+
+func TestAlertEnrichersBeforeDelivery(t *testing.T) {
+	fakeAM := store.NewFakeExternalAlertmanager(t)
+	defer fakeAM.Close()
+	fakeAdminConfigStore := store.NewFakeAdminConfigStore(t)
+	adminConfig := &models.AdminConfiguration{OrgID: 1, Alertmanagers: []string{fakeAM.Server.URL}, SendAlertsTo: models.ExternalAlertmanagers}
+	cmd := store.UpdateAdminConfigurationCmd{AdminConfiguration: adminConfig}
+	require.NoError(t, fakeAdminConfigStore.UpdateAdminConfiguration(cmd))
+
+	sched, _ := setupScheduler(t, store.NewFakeRuleStore(t), &store.FakeInstanceStore{}, fakeAdminConfigStore, nil)
+	sched.captureSends = true
+	sched.enrichers = NewAlertEnrichers()
+	require.NoError(t, sched.SyncAndApplyConfigFromDatabase())
+	t.Cleanup(func() {
+		sched.adminConfigMtx.Lock()
+		defer sched.adminConfigMtx.Unlock()
+		for _, s := range sched.senders {
+			s.Stop()
+		}
+	})
+
+	require.NoError(t, sched.enrichers.Register("runbook", AlertEnricherFunc(func(_ context.Context, key models.AlertRuleKey, alerts []amv2.PostableAlert) error {
+		for i := range alerts {
+			alerts[i].Labels["org"] = "main"
+			alerts[i].Annotations["runbook_url"] = "https://runbooks.example.com/" + key.UID
+			alerts[i].GeneratorURL = strfmt.URI("https://grafana.example.com/alerting/grafana/" + key.UID + "/view")
+		}
+		return nil
+	})))
+
+	key := models.AlertRuleKey{OrgID: 1, UID: "test"}
+	require.NoError(t, sched.Replay(key, definitions.PostableAlerts{PostableAlerts: []amv2.PostableAlert{{
+		Alert:       amv2.Alert{Labels: amv2.LabelSet{"alertname": "enriched"}, GeneratorURL: "http://localhost:3000/alerting/grafana/test/view"},
+		Annotations: amv2.LabelSet{},
+	}}}))
+
+	captured := sched.CapturedSends(1)
+	require.Len(t, captured, 1)
+	sent := captured[0].PostableAlerts[0]
+	require.Equal(t, amv2.LabelSet{"alertname": "enriched", "org": "main"}, sent.Labels)
+	require.Equal(t, amv2.LabelSet{"runbook_url": "https://runbooks.example.com/test"}, sent.Annotations)
+	require.Equal(t, "https://grafana.example.com/alerting/grafana/test/view", string(sent.GeneratorURL))
+}
@@ -0,0 +1,81 @@
+package schedule
+
+import (
+	"strings"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+)
+
+// FingerprintFunc returns what identifies an alert: alerts with the same fingerprint are the same alert, whatever
+// the rest of their labels.
+type FingerprintFunc func(amv2.PostableAlert) string
+
+// DefaultFingerprint returns the fingerprint of the labels of the alert, the one the Alertmanager identifies
+// alerts with.
+func DefaultFingerprint(a amv2.PostableAlert) string {
+	return labelsToModel(a.Labels).Fingerprint().String()
+}
+
+// FingerprintWithoutLabels returns a FingerprintFunc ignoring the given labels, e.g. volatile labels that do not
+// change what an alert is about.
+func FingerprintWithoutLabels(names ...string) FingerprintFunc {
+	if len(names) == 0 {
+		return DefaultFingerprint
+	}
+	ignored := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		ignored[n] = struct{}{}
+	}
+	return func(a amv2.PostableAlert) string {
+		ls := make(amv2.LabelSet, len(a.Labels))
+		for k, v := range a.Labels {
+			if _, ok := ignored[k]; !ok {
+				ls[k] = v
+			}
+		}
+		return labelsToModel(ls).Fingerprint().String()
+	}
+}
+
+// LabelNormalization is how the labels of the alerts are normalized before their fingerprint is computed, so that
+// alerts that differ only in how their labels are written are the same alert. The fingerprint does not depend on
+// the order of the labels, they are sorted by name.
+type LabelNormalization struct {
+	// TrimSpace removes the leading and trailing whitespace of the names and values of the labels.
+	TrimSpace bool
+	// LowercaseNames are the names of the labels matched whatever their case, and lowercased, e.g. Severity is
+	// severity if severity is one of them.
+	LowercaseNames []string
+}
+
+// NormalizedFingerprint returns a FingerprintFunc returning the fingerprint of the alert with its labels normalized,
+// using DefaultFingerprint if fp is nil. Labels that are the same once normalized keep the smallest of their values.
+func NormalizedFingerprint(fp FingerprintFunc, n LabelNormalization) FingerprintFunc {
+	if fp == nil {
+		fp = DefaultFingerprint
+	}
+	if !n.TrimSpace && len(n.LowercaseNames) == 0 {
+		return fp
+	}
+	lowercase := make(map[string]struct{}, len(n.LowercaseNames))
+	for _, name := range n.LowercaseNames {
+		lowercase[strings.ToLower(name)] = struct{}{}
+	}
+	return func(a amv2.PostableAlert) string {
+		ls := make(amv2.LabelSet, len(a.Labels))
+		for k, v := range a.Labels {
+			if n.TrimSpace {
+				k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+			}
+			if _, ok := lowercase[strings.ToLower(k)]; ok {
+				k = strings.ToLower(k)
+			}
+			if prev, ok := ls[k]; ok && prev <= v {
+				continue
+			}
+			ls[k] = v
+		}
+		a.Labels = ls
+		return fp(a)
+	}
+}
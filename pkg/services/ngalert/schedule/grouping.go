@@ -0,0 +1,209 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	prometheusModel "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// defaultGroupingExamples is the number of alerts of a group listed in the alert sent instead of them, when the
+// grouping of the organization does not set it.
+const defaultGroupingExamples = 3
+
+// groupedLabels are the labels of the alerts of a rule kept by the alert sent instead of the alerts of a group,
+// along with the labels the alerts are grouped by.
+var groupedLabels = []string{prometheusModel.AlertNameLabel, models.RuleUIDLabel, models.NamespaceUIDLabel, models.ReceiverLabel}
+
+// AlertGrouping groups the alerts of a rule sent to external Alertmanager(s) by some of their labels, to reduce
+// the noise of rules with many series. The alerts of a group are sent as a single alert counting them, with the
+// labels of a few of them as examples.
+type AlertGrouping struct {
+	// By are the labels the alerts are grouped by. The alert of a group has them, along with the alert name and
+	// the internal labels of the rule.
+	By []string
+	// Examples is the number of alerts of a group whose labels are listed, 3 by default.
+	Examples int
+}
+
+// grouping holds how the alerts sent to external Alertmanager(s) are grouped, per organization. groupedAlerts
+// holds, per rule, the firing alerts of the groups sent to them.
+type grouping struct {
+	externalGroupings map[int64]AlertGrouping
+	// adminExternalGroupings are the ones of the admin configurations, which take precedence, guarded by
+	// adminConfigMtx.
+	adminExternalGroupings map[int64]AlertGrouping
+	groupedAlertsMtx       sync.Mutex
+	groupedAlerts          map[models.AlertRuleKey]map[string]amv2.PostableAlert
+}
+
+// alertGroup is a group of alerts of a rule sent to external Alertmanager(s) as a single alert.
+type alertGroup struct {
+	labels amv2.LabelSet
+	// firing are the firing alerts of the group, the ones sent by previous evaluations included. startsAt and
+	// endsAt are the earliest start and latest end of the alerts of the batch that are resolved.
+	firing   []amv2.PostableAlert
+	startsAt time.Time
+	endsAt   time.Time
+}
+
+// groupExternalAlerts replaces the alerts of the rule by an alert per group of alerts with the same values for
+// the labels of the grouping of the organization, if any. Alerts are not always all sent after every evaluation,
+// so the firing alerts of the groups sent before are kept until they are resolved or expire: the alert of a group
+// counts all of them, and is resolved once none of them is firing anymore. It must be called with adminConfigMtx
+// held.
+func (sch *schedule) groupExternalAlerts(key models.AlertRuleKey, alerts definitions.PostableAlerts, logger log.Logger) definitions.PostableAlerts {
+	grouping, ok := sch.groupingFor(key.OrgID)
+	if !ok || len(grouping.By) == 0 || len(alerts.PostableAlerts) == 0 {
+		return alerts
+	}
+
+	now := sch.clock.Now()
+	groups := make(map[prometheusModel.Fingerprint]*alertGroup)
+	sch.groupedAlertsMtx.Lock()
+	firing, ok := sch.groupedAlerts[key]
+	if !ok {
+		firing = make(map[string]amv2.PostableAlert)
+	}
+	for _, a := range alerts.PostableAlerts {
+		ls := grouping.labelsOf(a.Labels)
+		gfp := labelsToModel(ls).Fingerprint()
+		g, ok := groups[gfp]
+		if !ok {
+			g = &alertGroup{labels: ls}
+			groups[gfp] = g
+		}
+
+		fp := sch.fingerprint(a)
+		endsAt := time.Time(a.EndsAt)
+		if endsAt.IsZero() || endsAt.After(now) {
+			firing[fp] = a
+			continue
+		}
+		delete(firing, fp)
+		if startsAt := time.Time(a.StartsAt); g.startsAt.IsZero() || startsAt.Before(g.startsAt) {
+			g.startsAt = startsAt
+		}
+		if endsAt.After(g.endsAt) {
+			g.endsAt = endsAt
+		}
+	}
+	for fp, a := range firing {
+		if endsAt := time.Time(a.EndsAt); !endsAt.IsZero() && !endsAt.After(now) {
+			delete(firing, fp)
+			continue
+		}
+		if g, ok := groups[labelsToModel(grouping.labelsOf(a.Labels)).Fingerprint()]; ok {
+			g.firing = append(g.firing, a)
+		}
+	}
+	if len(firing) > 0 {
+		sch.groupedAlerts[key] = firing
+	} else {
+		delete(sch.groupedAlerts, key)
+	}
+	sch.groupedAlertsMtx.Unlock()
+
+	grouped := make([]amv2.PostableAlert, 0, len(groups))
+	for _, g := range groups {
+		grouped = append(grouped, g.alert(grouping.examples()))
+	}
+	sort.Slice(grouped, func(i, j int) bool {
+		return labelsToModel(grouped[i].Labels).String() < labelsToModel(grouped[j].Labels).String()
+	})
+	logger.Debug("alerts are grouped before being sent to external Alertmanager(s)", "count", len(alerts.PostableAlerts), "groups", len(grouped))
+	sch.metrics.ExternalAlertsGrouped.WithLabelValues(fmt.Sprint(key.OrgID)).Add(float64(len(alerts.PostableAlerts)))
+	return definitions.PostableAlerts{PostableAlerts: grouped}
+}
+
+// groupingFor returns the grouping of the organization, the one of its admin configuration first. It must be
+// called with adminConfigMtx held.
+func (sch *schedule) groupingFor(orgID int64) (AlertGrouping, bool) {
+	if g, ok := sch.adminExternalGroupings[orgID]; ok {
+		return g, true
+	}
+	g, ok := sch.externalGroupings[orgID]
+	return g, ok
+}
+
+// labelsOf returns the labels of the alert sent instead of the alerts of the group the labels belong to.
+func (g AlertGrouping) labelsOf(ls amv2.LabelSet) amv2.LabelSet {
+	res := make(amv2.LabelSet, len(g.By)+len(groupedLabels))
+	for _, names := range [][]string{groupedLabels, g.By} {
+		for _, name := range names {
+			if v, ok := ls[name]; ok {
+				res[name] = v
+			}
+		}
+	}
+	return res
+}
+
+// examples returns the number of alerts of a group listed in the alert sent instead of them.
+func (g AlertGrouping) examples() int {
+	if g.Examples > 0 {
+		return g.Examples
+	}
+	return defaultGroupingExamples
+}
+
+// alert returns the alert sent instead of the alerts of the group. It is firing as long as one of them is,
+// with the annotations they have in common, the number of firing alerts in the count annotation and the
+// labels of some of them in the examples annotation, one per line.
+func (g *alertGroup) alert(examples int) amv2.PostableAlert {
+	if len(g.firing) == 0 {
+		return amv2.PostableAlert{
+			Alert:    amv2.Alert{Labels: g.labels},
+			StartsAt: strfmt.DateTime(g.startsAt),
+			EndsAt:   strfmt.DateTime(g.endsAt),
+		}
+	}
+
+	sort.Slice(g.firing, func(i, j int) bool {
+		return labelsToModel(g.firing[i].Labels).String() < labelsToModel(g.firing[j].Labels).String()
+	})
+	res := amv2.PostableAlert{
+		Alert:       amv2.Alert{Labels: g.labels, GeneratorURL: g.firing[0].GeneratorURL},
+		Annotations: amv2.LabelSet{},
+		StartsAt:    g.firing[0].StartsAt,
+		EndsAt:      g.firing[0].EndsAt,
+	}
+	for k, v := range g.firing[0].Annotations {
+		res.Annotations[k] = v
+	}
+	lines := make([]string, 0, examples)
+	for i, a := range g.firing {
+		for k, v := range res.Annotations {
+			if a.Annotations[k] != v {
+				delete(res.Annotations, k)
+			}
+		}
+		if time.Time(a.StartsAt).Before(time.Time(res.StartsAt)) {
+			res.StartsAt = a.StartsAt
+		}
+		if endsAt := time.Time(res.EndsAt); !endsAt.IsZero() && (time.Time(a.EndsAt).IsZero() || time.Time(a.EndsAt).After(endsAt)) {
+			res.EndsAt = a.EndsAt
+		}
+		if i < examples {
+			ls := make(prometheusModel.LabelSet, len(a.Labels))
+			for k, v := range a.Labels {
+				if _, ok := g.labels[k]; !ok {
+					ls[prometheusModel.LabelName(k)] = prometheusModel.LabelValue(v)
+				}
+			}
+			lines = append(lines, ls.String())
+		}
+	}
+	res.Annotations["count"] = fmt.Sprint(len(g.firing))
+	res.Annotations["examples"] = strings.Join(lines, "\n")
+	return res
+}
@@ -0,0 +1,52 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// heartbeatTimeout is the timeout of the pings of the heartbeat URL.
+const heartbeatTimeout = 10 * time.Second
+
+// sendHeartbeat pings the heartbeat URL, if any, unless the external Alertmanager(s) of an organization are
+// unhealthy. The heartbeat tells that the admin configuration is synced and that the senders deliver the
+// alerts, so that its absence tells that the alerting pipeline is down.
+func (sch *schedule) sendHeartbeat(ctx context.Context) {
+	if sch.heartbeatURL == "" {
+		return
+	}
+	if h := sch.DeliveryHealth(); h.UnhealthyOrgs > 0 {
+		sch.log.Debug("heartbeat is not sent as the external Alertmanager(s) of some organizations are unhealthy", "count", h.UnhealthyOrgs)
+		sch.metrics.Heartbeats.WithLabelValues("skipped").Inc()
+		return
+	}
+
+	if err := sch.pingHeartbeat(ctx); err != nil {
+		sch.log.Warn("failed to send heartbeat", "err", err)
+		sch.metrics.Heartbeats.WithLabelValues("failure").Inc()
+		return
+	}
+	sch.metrics.Heartbeats.WithLabelValues("success").Inc()
+}
+
+// pingHeartbeat sends a GET request to the heartbeat URL, authenticated as OpsGenie expects if there is an API key.
+func (sch *schedule) pingHeartbeat(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sch.heartbeatURL, nil)
+	if err != nil {
+		return err
+	}
+	if sch.heartbeatAPIKey != "" {
+		req.Header.Set("Authorization", "GenieKey "+sch.heartbeatAPIKey)
+	}
+	resp, err := sch.heartbeatClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
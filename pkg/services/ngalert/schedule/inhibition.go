@@ -0,0 +1,97 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// updateRuleDependencies records the dependencies of the rules fetched, the ones of the rules without dependencies
+// or deleted are forgotten.
+func (sch *schedule) updateRuleDependencies(rules []*models.SchedulableAlertRule) {
+	deps := make(map[models.AlertRuleKey][]models.RuleDependency)
+	for _, r := range rules {
+		if len(r.Dependencies) > 0 {
+			deps[r.GetKey()] = r.Dependencies
+		}
+	}
+	sch.ruleDependenciesMtx.Lock()
+	sch.ruleDependencies = deps
+	sch.ruleDependenciesMtx.Unlock()
+}
+
+// inhibitAlerts drops the firing alerts of the rule inhibited by one of its dependencies, so that they are handled
+// neither by the local notifier nor by external Alertmanager(s). Resolved alerts are kept, so that the alerts firing
+// before the dependency are resolved as usual.
+func (sch *schedule) inhibitAlerts(key models.AlertRuleKey, alerts definitions.PostableAlerts, logger log.Logger) definitions.PostableAlerts {
+	sch.ruleDependenciesMtx.RLock()
+	deps := sch.ruleDependencies[key]
+	sch.ruleDependenciesMtx.RUnlock()
+	if len(deps) == 0 || len(alerts.PostableAlerts) == 0 {
+		return alerts
+	}
+
+	// firing are the firing states of the rules depended on, fetched once.
+	firing := make(map[string][]*state.State, len(deps))
+	for _, dep := range deps {
+		if _, ok := firing[dep.RuleUID]; ok || dep.RuleUID == key.UID {
+			continue
+		}
+		var states []*state.State
+		for _, s := range sch.stateManager.GetStatesForRuleUID(key.OrgID, dep.RuleUID) {
+			if s.State == eval.Alerting {
+				states = append(states, s)
+			}
+		}
+		firing[dep.RuleUID] = states
+	}
+
+	now := sch.clock.Now()
+	kept := make([]amv2.PostableAlert, 0, len(alerts.PostableAlerts))
+	for _, a := range alerts.PostableAlerts {
+		if endsAt := time.Time(a.EndsAt); !endsAt.IsZero() && !endsAt.After(now) {
+			kept = append(kept, a)
+			continue
+		}
+		if uid, ok := inhibitedBy(deps, firing, a); ok {
+			logger.Debug("alert is inhibited by a rule the rule depends on", "labels", a.Labels, "inhibited_by", uid)
+			continue
+		}
+		kept = append(kept, a)
+	}
+	if inhibited := len(alerts.PostableAlerts) - len(kept); inhibited > 0 {
+		sch.metrics.AlertsInhibited.WithLabelValues(fmt.Sprint(key.OrgID)).Add(float64(inhibited))
+	}
+	return definitions.PostableAlerts{PostableAlerts: kept}
+}
+
+// inhibitedBy returns the UID of the first rule depended on with a firing state that has the same values as the
+// alert for the labels of the dependency.
+func inhibitedBy(deps []models.RuleDependency, firing map[string][]*state.State, a amv2.PostableAlert) (string, bool) {
+	for _, dep := range deps {
+		for _, s := range firing[dep.RuleUID] {
+			if dependencyMatches(dep, s, a) {
+				return dep.RuleUID, true
+			}
+		}
+	}
+	return "", false
+}
+
+// dependencyMatches tells whether the firing state of the rule depended on inhibits the alert: both have the same
+// values for the labels of the dependency, a missing label being equal to an empty one like in the Alertmanager.
+func dependencyMatches(dep models.RuleDependency, s *state.State, a amv2.PostableAlert) bool {
+	for _, name := range dep.Equal {
+		if s.Labels[name] != a.Labels[name] {
+			return false
+		}
+	}
+	return true
+}
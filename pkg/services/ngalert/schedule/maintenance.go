@@ -0,0 +1,123 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	prometheusModel "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// maintenanceWindow is a maintenance window active as of the last tick, with its parsed matchers.
+type maintenanceWindow struct {
+	*models.MaintenanceWindow
+	matchers labels.Matchers
+}
+
+// refreshMaintenanceWindows fetches the maintenance windows active at the tick. The windows of the previous
+// tick are kept if they cannot be fetched.
+func (sch *schedule) refreshMaintenanceWindows(ctx context.Context, tick time.Time) {
+	if sch.maintenanceWindowStore == nil {
+		return
+	}
+	windows, err := sch.maintenanceWindowStore.GetActiveMaintenanceWindows(ctx, tick)
+	if err != nil {
+		sch.log.Error("failed to fetch the active maintenance windows", "err", err)
+		return
+	}
+	active := make(map[int64][]maintenanceWindow, len(windows))
+	for _, w := range windows {
+		matchers, err := w.LabelMatchers()
+		if err != nil {
+			sch.log.Warn("maintenance window with invalid matchers is ignored", "org_id", w.OrgID, "uid", w.UID, "err", err)
+			continue
+		}
+		active[w.OrgID] = append(active[w.OrgID], maintenanceWindow{MaintenanceWindow: w, matchers: matchers})
+	}
+	sch.maintenanceMtx.Lock()
+	sch.maintenanceWindows = active
+	sch.maintenanceMtx.Unlock()
+}
+
+// maintenanceWindowFor returns the first maintenance window of the organization active at the time whose
+// matchers match the labels, if any.
+func (sch *schedule) maintenanceWindowFor(orgID int64, lset prometheusModel.LabelSet, now time.Time, skipEvaluation bool) (*models.MaintenanceWindow, bool) {
+	sch.maintenanceMtx.RLock()
+	defer sch.maintenanceMtx.RUnlock()
+	for _, w := range sch.maintenanceWindows[orgID] {
+		if skipEvaluation && !w.SkipEvaluation {
+			continue
+		}
+		if w.ActiveAt(now) && w.matchers.Matches(lset) {
+			return w.MaintenanceWindow, true
+		}
+	}
+	return nil, false
+}
+
+// skipEvaluation tells whether the rule is not evaluated because of an active maintenance window. The matchers of
+// the window are matched against the labels of the rule, with its alertname and UID labels.
+func (sch *schedule) skipEvaluation(r *models.AlertRule, now time.Time) (*models.MaintenanceWindow, bool) {
+	lset := make(prometheusModel.LabelSet, len(r.Labels)+3)
+	for k, v := range r.Labels {
+		lset[prometheusModel.LabelName(k)] = prometheusModel.LabelValue(v)
+	}
+	lset[prometheusModel.AlertNameLabel] = prometheusModel.LabelValue(r.Title)
+	lset[models.RuleUIDLabel] = prometheusModel.LabelValue(r.UID)
+	lset[models.NamespaceUIDLabel] = prometheusModel.LabelValue(r.NamespaceUID)
+	return sch.maintenanceWindowFor(r.OrgID, lset, now, true)
+}
+
+// suppressAlerts drops the firing alerts of the rule matching an active maintenance window, so that they are
+// handled neither by the local notifier nor by external Alertmanager(s), and records their suppression. Resolved
+// alerts are kept, so that the alerts firing before the window are resolved as usual.
+func (sch *schedule) suppressAlerts(key models.AlertRuleKey, alerts definitions.PostableAlerts, logger log.Logger) definitions.PostableAlerts {
+	if len(alerts.PostableAlerts) == 0 {
+		return alerts
+	}
+	sch.maintenanceMtx.RLock()
+	hasWindows := len(sch.maintenanceWindows[key.OrgID]) > 0
+	sch.maintenanceMtx.RUnlock()
+	if !hasWindows {
+		return alerts
+	}
+
+	now := sch.clock.Now()
+	kept := make([]amv2.PostableAlert, 0, len(alerts.PostableAlerts))
+	var suppressions []*models.MaintenanceSuppression
+	for _, a := range alerts.PostableAlerts {
+		if endsAt := time.Time(a.EndsAt); !endsAt.IsZero() && !endsAt.After(now) {
+			kept = append(kept, a)
+			continue
+		}
+		lset := labelsToModel(a.Labels)
+		w, ok := sch.maintenanceWindowFor(key.OrgID, lset, now, false)
+		if !ok {
+			kept = append(kept, a)
+			continue
+		}
+		logger.Debug("alert is suppressed by a maintenance window", "labels", a.Labels, "window_uid", w.UID)
+		suppressions = append(suppressions, &models.MaintenanceSuppression{
+			OrgID:            key.OrgID,
+			WindowUID:        w.UID,
+			RuleUID:          key.UID,
+			Fingerprint:      lset.Fingerprint().String(),
+			Labels:           a.Labels,
+			LastSuppressedAt: now,
+		})
+	}
+	if len(suppressions) == 0 {
+		return alerts
+	}
+	sch.metrics.AlertsSuppressed.WithLabelValues(fmt.Sprint(key.OrgID)).Add(float64(len(suppressions)))
+	if err := sch.maintenanceWindowStore.SaveMaintenanceSuppressions(context.Background(), suppressions); err != nil {
+		logger.Error("failed to save the alerts suppressed by maintenance windows", "count", len(suppressions), "err", err)
+	}
+	return definitions.PostableAlerts{PostableAlerts: kept}
+}
@@ -0,0 +1,99 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/timeinterval"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// MuteTimings are the time windows during which firing alerts are not sent to external Alertmanager(s). The
+// state of the rules is still recorded, and the alerts are handled by the local notifier as usual.
+type MuteTimings struct {
+	// TimeIntervals are calendar-based time windows, like the mute time intervals of the Alertmanager.
+	TimeIntervals []timeinterval.TimeInterval
+	// Location is the time zone of the time intervals, UTC if nil.
+	Location *time.Location
+}
+
+// muting holds when the firing alerts are not sent to external Alertmanager(s).
+type muting struct {
+	// muteTimings and ruleMuteTimings are the mute timings of the organizations and of the rules.
+	muteTimings     map[int64]MuteTimings
+	ruleMuteTimings map[models.AlertRuleKey]MuteTimings
+	// adminMuteTimings and adminRuleMuteTimings are the ones of the admin configurations, which take precedence,
+	// guarded by adminConfigMtx.
+	adminMuteTimings     map[int64]MuteTimings
+	adminRuleMuteTimings map[models.AlertRuleKey]MuteTimings
+}
+
+// muteExternalAlerts removes the firing alerts of the rule if the current time is in one of its mute timings, or
+// the ones of its organization. Resolved alerts are still sent, so that the alerts sent before the mute timing
+// are resolved.
+func (sch *schedule) muteExternalAlerts(key models.AlertRuleKey, alerts definitions.PostableAlerts, logger log.Logger) definitions.PostableAlerts {
+	mt, ok := sch.muteTimingsFor(key)
+	now := sch.clock.Now()
+	if !ok || !mt.contains(now) {
+		return alerts
+	}
+
+	kept := make([]amv2.PostableAlert, 0, len(alerts.PostableAlerts))
+	for _, a := range alerts.PostableAlerts {
+		endsAt := time.Time(a.EndsAt)
+		if !endsAt.IsZero() && !endsAt.After(now) {
+			kept = append(kept, a)
+		}
+	}
+	muted := len(alerts.PostableAlerts) - len(kept)
+	if muted > 0 {
+		logger.Debug("alerts are muted, they are not sent to external Alertmanager(s)", "count", muted)
+		sch.metrics.ExternalAlertsMuted.WithLabelValues(fmt.Sprint(key.OrgID)).Add(float64(muted))
+	}
+	return definitions.PostableAlerts{PostableAlerts: kept}
+}
+
+// muteTimingsFor returns the mute timings of the rule, of the admin configuration first, or else the ones of its
+// organization. It must be called with adminConfigMtx held.
+func (sch *schedule) muteTimingsFor(key models.AlertRuleKey) (MuteTimings, bool) {
+	if mt, ok := sch.adminRuleMuteTimings[key]; ok {
+		return mt, true
+	}
+	if mt, ok := sch.ruleMuteTimings[key]; ok {
+		return mt, true
+	}
+	if mt, ok := sch.adminMuteTimings[key.OrgID]; ok {
+		return mt, true
+	}
+	mt, ok := sch.muteTimings[key.OrgID]
+	return mt, ok
+}
+
+// muteTimingsOf returns the mute timings of an admin configuration. An invalid location, applied when the admin
+// configuration is not strict, is UTC.
+func muteTimingsOf(cfg models.MuteTimingsConfig) MuteTimings {
+	loc, err := time.LoadLocation(cfg.Location)
+	if err != nil {
+		loc = time.UTC
+	}
+	return MuteTimings{TimeIntervals: cfg.TimeIntervals, Location: loc}
+}
+
+// contains returns true if the time is in one of the time intervals.
+func (mt MuteTimings) contains(t time.Time) bool {
+	loc := mt.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	for _, ti := range mt.TimeIntervals {
+		if ti.ContainsTime(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package schedule
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// pauseAlertRule stops the routine of a rule that was paused. Unlike the ones of deleted rules, its states are
+// kept, as normal with the reason Paused, until the rule is resumed.
+func (sch *schedule) pauseAlertRule(key models.AlertRuleKey) {
+	sch.pausingMtx.Lock()
+	sch.pausing[key] = struct{}{}
+	sch.pausingMtx.Unlock()
+	sch.log.Debug("alert rule paused", "uid", key.UID, "org_id", key.OrgID)
+	sch.DeleteAlertRule(key)
+}
+
+// takePausing tells whether the routine of the rule is stopped because the rule was paused.
+func (sch *schedule) takePausing(key models.AlertRuleKey) bool {
+	sch.pausingMtx.Lock()
+	defer sch.pausingMtx.Unlock()
+	_, ok := sch.pausing[key]
+	delete(sch.pausing, key)
+	return ok
+}
+
+// updatePausedRules records the rules paused as of the tick, and removes the states of the rules deleted while
+// they were paused, that no routine removes.
+func (sch *schedule) updatePausedRules(paused map[models.AlertRuleKey]struct{}) {
+	for key := range sch.pausedRules {
+		if _, ok := paused[key]; !ok && !sch.registry.exists(key) {
+			sch.stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+		}
+	}
+	sch.pausedRules = paused
+	sch.metrics.RulesPaused.Set(float64(len(paused)))
+}
+
+// pauseStates sets the states of the paused rule to normal, with the reason Paused, and returns its alerts
+// resolved.
+func (sch *schedule) pauseStates(key models.AlertRuleKey) definitions.PostableAlerts {
+	states := sch.stateManager.GetStatesForRuleUID(key.OrgID, key.UID)
+	now := sch.clock.Now()
+	pausedStates := make([]*state.State, 0, len(states))
+	for _, s := range states {
+		paused := *s
+		paused.StateReason = models.StateReasonPaused
+		pausedStates = append(pausedStates, &paused)
+	}
+	expiredAlerts := FromAlertsStateToStoppedAlert(pausedStates, sch.appURL, sch.clock, sch.resolvedAlertsPolicy(key))
+	for _, s := range pausedStates {
+		if s.State != eval.Normal {
+			s.State = eval.Normal
+			s.StartsAt = now
+		}
+		s.EndsAt = now
+		s.Resolved = false
+	}
+	sch.stateManager.Put(pausedStates)
+	// the context of the routine is done, the states are saved nonetheless
+	sch.saveAlertStates(context.Background(), pausedStates)
+	return expiredAlerts
+}
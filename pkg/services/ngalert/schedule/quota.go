@@ -0,0 +1,141 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	prometheusModel "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// AlertQuota limits the number of alerts of an organization firing at the same time that are delivered, to protect
+// the notifiers from rules with too many series. The firing alerts of a rule over the quota are replaced by a
+// single alert counting them.
+type AlertQuota struct {
+	// PerOrg is the most alerts of the organization firing at the same time, 0 for no limit. The alerts of the
+	// other rules are counted up to PerRule.
+	PerOrg int
+	// PerRule is the most alerts of each rule of the organization firing at the same time, 0 for no limit.
+	PerRule int
+}
+
+func (q AlertQuota) enabled() bool {
+	return q.PerOrg > 0 || q.PerRule > 0
+}
+
+// alertQuotaFor returns the alert quota of an organization.
+func (sch *schedule) alertQuotaFor(orgID int64) AlertQuota {
+	if quota, ok := sch.alertQuotas[orgID]; ok {
+		return quota
+	}
+	return sch.defaultAlertQuota
+}
+
+// applyAlertQuota drops the firing alerts of the rule over the alert quota of its organization, and adds a single
+// alert counting them instead. The alerts firing for the longest time are kept, so that the alerts delivered do
+// not change from one evaluation to the next. Resolved alerts are kept, as well as the alerts without a firing
+// state such as the no data and error alerts.
+func (sch *schedule) applyAlertQuota(key models.AlertRuleKey, alerts definitions.PostableAlerts, logger log.Logger) definitions.PostableAlerts {
+	quota := sch.alertQuotaFor(key.OrgID)
+	if !quota.enabled() || len(alerts.PostableAlerts) == 0 {
+		return alerts
+	}
+
+	firing := firingStates(sch.stateManager.GetStatesForRuleUID(key.OrgID, key.UID))
+	allowed := len(firing)
+	if quota.PerRule > 0 && allowed > quota.PerRule {
+		allowed = quota.PerRule
+	}
+	if quota.PerOrg > 0 {
+		left := quota.PerOrg - sch.firingAlertsOfOtherRules(key, quota)
+		if left < 0 {
+			left = 0
+		}
+		if allowed > left {
+			allowed = left
+		}
+	}
+	if allowed >= len(firing) {
+		return alerts
+	}
+
+	sort.Slice(firing, func(i, j int) bool {
+		if !firing[i].StartsAt.Equal(firing[j].StartsAt) {
+			return firing[i].StartsAt.Before(firing[j].StartsAt)
+		}
+		return firing[i].CacheId < firing[j].CacheId
+	})
+	overQuota := make(map[prometheusModel.Fingerprint]struct{}, len(firing)-allowed)
+	for _, s := range firing[allowed:] {
+		overQuota[labelsToModel(amv2.LabelSet(s.Labels)).Fingerprint()] = struct{}{}
+	}
+
+	now := sch.clock.Now()
+	kept := make([]amv2.PostableAlert, 0, allowed+1)
+	for _, a := range alerts.PostableAlerts {
+		if endsAt := time.Time(a.EndsAt); !endsAt.IsZero() && !endsAt.After(now) {
+			kept = append(kept, a)
+			continue
+		}
+		if _, ok := overQuota[labelsToModel(a.Labels).Fingerprint()]; ok {
+			continue
+		}
+		kept = append(kept, a)
+	}
+	if dropped := len(alerts.PostableAlerts) - len(kept); dropped > 0 {
+		sch.metrics.AlertsOverQuota.WithLabelValues(fmt.Sprint(key.OrgID)).Add(float64(dropped))
+	}
+
+	logger.Warn("firing alerts exceed the alert quota of the organization, they are replaced by a single alert", "firing", len(firing), "quota", allowed)
+	kept = append(kept, amv2.PostableAlert{
+		Alert: amv2.Alert{Labels: amv2.LabelSet{
+			prometheusModel.AlertNameLabel: quotaExceededAlertName,
+			"org_id":                       fmt.Sprint(key.OrgID),
+			"rule_uid":                     key.UID,
+		}},
+		Annotations: amv2.LabelSet{
+			"description": fmt.Sprintf("%d firing alerts of the rule are not delivered because they exceed the alert quota of the organization.", len(firing)-allowed),
+			"count":       fmt.Sprint(len(firing) - allowed),
+		},
+		StartsAt: strfmt.DateTime(now),
+		EndsAt:   strfmt.DateTime(now.Add(quotaExceededAlertDuration)),
+	})
+	return definitions.PostableAlerts{PostableAlerts: kept}
+}
+
+// firingAlertsOfOtherRules counts the firing alerts of the organization of the rule, but the ones of the rule. The
+// alerts of each rule are counted up to the quota per rule, as the ones over it are not delivered.
+func (sch *schedule) firingAlertsOfOtherRules(key models.AlertRuleKey, quota AlertQuota) int {
+	perRule := make(map[string]int)
+	for _, s := range sch.stateManager.GetAll(key.OrgID) {
+		if s.State == eval.Alerting && s.AlertRuleUID != key.UID {
+			perRule[s.AlertRuleUID]++
+		}
+	}
+	total := 0
+	for _, count := range perRule {
+		if quota.PerRule > 0 && count > quota.PerRule {
+			count = quota.PerRule
+		}
+		total += count
+	}
+	return total
+}
+
+func firingStates(states []*state.State) []*state.State {
+	firing := make([]*state.State, 0, len(states))
+	for _, s := range states {
+		if s.State == eval.Alerting {
+			firing = append(firing, s)
+		}
+	}
+	return firing
+}
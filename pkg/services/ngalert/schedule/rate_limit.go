@@ -0,0 +1,112 @@
+package schedule
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	prometheusModel "github.com/prometheus/common/model"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// rateLimitedAlertName is the name of the alert sent instead of the alerts of a rule exceeding the rate
+	// limit of its organization. rateLimitedAlertDuration is how long after it is sent it is resolved.
+	rateLimitedAlertName     = "GrafanaRateLimited"
+	rateLimitedAlertDuration = 5 * time.Minute
+)
+
+// RateLimit is a token bucket limiting the number of alerts sent to external Alertmanager(s).
+type RateLimit struct {
+	// AlertsPerSecond is the rate at which the bucket fills up.
+	AlertsPerSecond float64
+	// Burst is the size of the bucket, the number of alerts that can be sent at once. It defaults to
+	// AlertsPerSecond, and at least 1.
+	Burst int
+}
+
+// rateLimiting holds the rate limits of the alerts sent to external Alertmanager(s), per organization.
+// Organizations not present use defaultExternalRateLimit. rateLimiters are the token buckets enforcing them,
+// created on first use.
+type rateLimiting struct {
+	externalRateLimits       map[int64]RateLimit
+	defaultExternalRateLimit RateLimit
+	rateLimitersMtx          sync.Mutex
+	rateLimiters             map[int64]*rate.Limiter
+}
+
+// rateLimitExternalAlerts replaces the alerts of the rule exceeding the rate limit of its organization with a
+// single alert counting them, which is not rate limited. Resolved alerts are not rate limited either, so that
+// the alerts sent before are always resolved.
+func (sch *schedule) rateLimitExternalAlerts(key models.AlertRuleKey, alerts definitions.PostableAlerts, logger log.Logger) definitions.PostableAlerts {
+	limiter := sch.rateLimiter(key.OrgID)
+	if limiter == nil || len(alerts.PostableAlerts) == 0 {
+		return alerts
+	}
+
+	now := sch.clock.Now()
+	kept := make([]amv2.PostableAlert, 0, len(alerts.PostableAlerts))
+	for _, a := range alerts.PostableAlerts {
+		if endsAt := time.Time(a.EndsAt); !endsAt.IsZero() && !endsAt.After(now) {
+			kept = append(kept, a)
+			continue
+		}
+		if limiter.AllowN(now, 1) {
+			kept = append(kept, a)
+		}
+	}
+	limited := len(alerts.PostableAlerts) - len(kept)
+	if limited == 0 {
+		return alerts
+	}
+
+	logger.Warn("alerts exceed the rate limit of external Alertmanager(s), they are replaced by a single alert", "count", limited)
+	sch.metrics.ExternalAlertsRateLimited.WithLabelValues(fmt.Sprint(key.OrgID)).Add(float64(limited))
+	kept = append(kept, amv2.PostableAlert{
+		Alert: amv2.Alert{Labels: amv2.LabelSet{
+			prometheusModel.AlertNameLabel: rateLimitedAlertName,
+			"org_id":                       fmt.Sprint(key.OrgID),
+			"rule_uid":                     key.UID,
+		}},
+		Annotations: amv2.LabelSet{
+			"description": fmt.Sprintf("%d alerts of the rule were not sent because they exceed the rate limit of the organization.", limited),
+			"count":       fmt.Sprint(limited),
+		},
+		StartsAt: strfmt.DateTime(now),
+		EndsAt:   strfmt.DateTime(now.Add(rateLimitedAlertDuration)),
+	})
+	return definitions.PostableAlerts{PostableAlerts: kept}
+}
+
+// rateLimiter returns the token bucket of an organization, nil if it has no rate limit.
+func (sch *schedule) rateLimiter(orgID int64) *rate.Limiter {
+	limit, ok := sch.externalRateLimits[orgID]
+	if !ok {
+		limit = sch.defaultExternalRateLimit
+	}
+	if limit.AlertsPerSecond <= 0 {
+		return nil
+	}
+
+	sch.rateLimitersMtx.Lock()
+	defer sch.rateLimitersMtx.Unlock()
+	limiter, ok := sch.rateLimiters[orgID]
+	if !ok {
+		burst := limit.Burst
+		if burst <= 0 {
+			burst = int(limit.AlertsPerSecond)
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		limiter = rate.NewLimiter(rate.Limit(limit.AlertsPerSecond), burst)
+		sch.rateLimiters[orgID] = limiter
+	}
+	return limiter
+}
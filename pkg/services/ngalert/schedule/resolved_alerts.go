@@ -0,0 +1,107 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ResolvedAlertsMode is how the alerts of a rule are resolved when its state is cleared, because the rule was
+// updated or deleted.
+type ResolvedAlertsMode int
+
+const (
+	// ResolvedAlertsImmediate sends the alerts with an EndsAt of when the state is cleared.
+	ResolvedAlertsImmediate ResolvedAlertsMode = iota
+	// ResolvedAlertsGraceWindow sends the alerts with an EndsAt a grace window after the state is cleared, so
+	// that alerts fired again by the new version of the rule in the meantime are not resolved.
+	ResolvedAlertsGraceWindow
+	// ResolvedAlertsSuppress does not send the alerts, the Alertmanagers resolve them once they expire.
+	ResolvedAlertsSuppress
+)
+
+// ResolvedAlertsPolicy configures how the alerts of a rule are resolved when its state is cleared.
+type ResolvedAlertsPolicy struct {
+	Mode ResolvedAlertsMode
+	// GraceWindow is how long after the state is cleared the alerts end with ResolvedAlertsGraceWindow.
+	GraceWindow time.Duration
+}
+
+// resolving holds how the alerts of a rule are resolved when its state is cleared.
+type resolving struct {
+	// maxResolvedAlertAge is the maximum age, per organization, of resolved alerts sent when a rule's state is
+	// cleared. Organizations not present use defaultMaxResolvedAlertAge.
+	maxResolvedAlertAge        map[int64]time.Duration
+	defaultMaxResolvedAlertAge time.Duration
+	// resolvedAlerts and ruleResolvedAlerts are the policies of the organizations and of the rules.
+	resolvedAlerts     map[int64]ResolvedAlertsPolicy
+	ruleResolvedAlerts map[models.AlertRuleKey]ResolvedAlertsPolicy
+	// adminResolvedAlerts and adminRuleResolvedAlerts are the ones of the admin configurations, which take
+	// precedence, guarded by adminConfigMtx.
+	adminResolvedAlerts     map[int64]ResolvedAlertsPolicy
+	adminRuleResolvedAlerts map[models.AlertRuleKey]ResolvedAlertsPolicy
+}
+
+// resolvedAlertsPolicy returns how the alerts of a rule are resolved when its state is cleared: the policy of the
+// rule, of the admin configuration first, or else the one of the organization.
+func (sch *schedule) resolvedAlertsPolicy(key models.AlertRuleKey) ResolvedAlertsPolicy {
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+	if p, ok := sch.adminRuleResolvedAlerts[key]; ok {
+		return p
+	}
+	if p, ok := sch.ruleResolvedAlerts[key]; ok {
+		return p
+	}
+	if p, ok := sch.adminResolvedAlerts[key.OrgID]; ok {
+		return p
+	}
+	return sch.resolvedAlerts[key.OrgID]
+}
+
+// resolvedAlertsPolicyOf returns the policy of the resolved alerts of an admin configuration. Invalid ones,
+// applied when the admin configuration is not strict, resolve the alerts immediately.
+func resolvedAlertsPolicyOf(cfg models.ResolvedAlertsConfig) ResolvedAlertsPolicy {
+	switch cfg.Mode {
+	case models.ResolvedAlertsGraceWindow:
+		if d, err := time.ParseDuration(cfg.GraceWindow); err == nil && d > 0 {
+			return ResolvedAlertsPolicy{Mode: ResolvedAlertsGraceWindow, GraceWindow: d}
+		}
+	case models.ResolvedAlertsSuppress:
+		return ResolvedAlertsPolicy{Mode: ResolvedAlertsSuppress}
+	}
+	return ResolvedAlertsPolicy{}
+}
+
+// dropOldResolvedAlerts removes the resolved alerts that started longer ago than the maximum resolved alert age of the organization.
+func (sch *schedule) dropOldResolvedAlerts(orgID int64, alerts definitions.PostableAlerts, logger log.Logger) definitions.PostableAlerts {
+	maxAge, ok := sch.maxResolvedAlertAge[orgID]
+	if !ok {
+		maxAge = sch.defaultMaxResolvedAlertAge
+	}
+	if maxAge <= 0 {
+		return alerts
+	}
+
+	now := sch.clock.Now()
+	kept := make([]amv2.PostableAlert, 0, len(alerts.PostableAlerts))
+	for _, a := range alerts.PostableAlerts {
+		resolved := !time.Time(a.EndsAt).IsZero() && !time.Time(a.EndsAt).After(now)
+		if resolved && now.Sub(time.Time(a.StartsAt)) > maxAge {
+			continue
+		}
+		kept = append(kept, a)
+	}
+
+	if dropped := len(alerts.PostableAlerts) - len(kept); dropped > 0 {
+		logger.Debug("dropping resolved alerts older than the maximum age", "count", dropped, "max_age", maxAge)
+		sch.metrics.ResolvedAlertsDropped.WithLabelValues(fmt.Sprint(orgID)).Add(float64(dropped))
+	}
+
+	return definitions.PostableAlerts{PostableAlerts: kept}
+}
@@ -2,10 +2,16 @@ package schedule
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"net/http"
 	"net/url"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/grafana/grafana/pkg/expr"
@@ -19,9 +25,86 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+	"github.com/grafana/grafana/pkg/util"
 
 	"github.com/benbjohnson/clock"
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	prometheusModel "github.com/prometheus/common/model"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultExternalMatcherLabel = "severity"
+
+	// defaultSenderStopConcurrency is the maximum number of senders stopped at the same time.
+	defaultSenderStopConcurrency = 10
+	// defaultSenderStopTimeout is how long we wait for the senders to stop when syncing the admin configuration.
+	defaultSenderStopTimeout = time.Minute
+
+	// defaultMemberHeartbeatInterval is how often the scheduler records that it is alive, when the evaluation
+	// of the alert rules is sharded. memberExpiryHeartbeats is the number of heartbeats a member can miss
+	// before it is gone and its rules are evaluated by the other members.
+	defaultMemberHeartbeatInterval = 10 * time.Second
+	memberExpiryHeartbeats         = 3
+
+	// defaultDispatchLeaseInterval is how often the scheduler renews the leases of the organizations it
+	// dispatches the alerts of, when the dispatch is elected. A lease expires after dispatchLeaseExpiryRenewals
+	// intervals without renewal, and is then acquired by another scheduler.
+	defaultDispatchLeaseInterval = 10 * time.Second
+	dispatchLeaseExpiryRenewals  = 3
+
+	// defaultUnhealthyThreshold is the number of consecutive failures after which an organization is unhealthy.
+	defaultUnhealthyThreshold = 3
+	// defaultHealthyThreshold is the number of consecutive successes after which an organization is healthy again.
+	defaultHealthyThreshold = 3
+
+	// testAlertName is the name of the alert sent to test an admin configuration.
+	testAlertName = "GrafanaTestAlert"
+	// testAlertDuration is how long after it is sent the test alert is resolved.
+	testAlertDuration = 5 * time.Minute
+
+	// quotaExceededAlertName is the name of the alert sent instead of the firing alerts of a rule over the alert
+	// quota of its organization. quotaExceededAlertDuration is how long after it is sent it is resolved.
+	quotaExceededAlertName     = "GrafanaAlertQuotaExceeded"
+	quotaExceededAlertDuration = 5 * time.Minute
+
+	// circuitOpenAlertName is the name of the alert sent while a rule is backed off by the circuit breaker.
+	circuitOpenAlertName = "GrafanaRuleEvaluationFailing"
+	// defaultCircuitBreakerMaxBackoff is the longest a rule is backed off when the circuit breaker does not set it.
+	defaultCircuitBreakerMaxBackoff = time.Hour
+
+	// decisionHistorySize is the number of sync decisions kept per organization.
+	decisionHistorySize = 100
+)
+
+// defaultRetryPolicy is the retry policy of the organizations that do not configure one.
+var defaultRetryPolicy = RetryPolicy{Retries: 2, Backoff: 500 * time.Millisecond}
+
+// errNoNotifier is returned when alerts can be handled neither by the local notifier nor by a sender.
+var errNoNotifier = errors.New("no external or internal notifier")
+
+// errNoRecordingWriter is returned when a recording rule is evaluated by a scheduler without RecordingWriter.
+var errNoRecordingWriter = errors.New("no writer for the samples of recording rules")
+
+// errNoLocalNotifier is returned when alerts that must be handled by both the local notifier and a sender
+// are only handled by the sender, and the MissingLocalNotifierPolicy of the scheduler is MissingLocalNotifierError.
+var errNoLocalNotifier = errors.New("no internal notifier")
+
+// Decisions taken for an organization when syncing the admin configuration.
+const (
+	syncDecisionNoopNoAlertmanagers = "no-op-no-AMs"
+	syncDecisionNoopInternal        = "no-op-internal"
+	syncDecisionStopNoAlertmanagers = "stop-no-AMs"
+	syncDecisionNoopSameHash        = "no-op-same-hash"
+	syncDecisionApplyNewConfig      = "apply-new-config"
+	syncDecisionCreateNewSender     = "create-new-sender"
+	syncDecisionStopped             = "gc-stopped"
 )
 
 // ScheduleService is an interface for a service that schedules the evaluation
@@ -39,6 +122,55 @@ type ScheduleService interface {
 	// DroppedAlertmanagersFor returns all the dropped Alertmanager URLs for the
 	// organization.
 	DroppedAlertmanagersFor(orgID int64) []*url.URL
+
+	// IsUnhealthy returns true if the external Alertmanager(s) of the
+	// organization failed consecutively too many times.
+	IsUnhealthy(orgID int64) bool
+
+	// PendingRoutingModeChangeFor returns the change of the Alertmanagers
+	// choice of the organization that is not applied yet, if any.
+	PendingRoutingModeChangeFor(orgID int64) (PendingRoutingModeChange, bool)
+
+	// InconsistenciesFor returns the reasons why the admin configuration of
+	// the organization is logically inconsistent.
+	InconsistenciesFor(orgID int64) []string
+
+	// SenderStatuses returns the status of the senders of all the
+	// organizations.
+	SenderStatuses() []SenderStatus
+
+	// AdminConfigurationChanged notifies the scheduler that the admin
+	// configuration of an organization has been changed.
+	AdminConfigurationChanged()
+
+	// SendTestAlert sends a test alert to the external Alertmanager(s) of an
+	// admin configuration that is not saved yet.
+	SendTestAlert(ctx context.Context, cfg *models.AdminConfiguration) []sender.AMTestResult
+
+	// DeadLetterAlerts returns the alerts of the organization that could not
+	// be delivered.
+	DeadLetterAlerts(ctx context.Context, orgID int64) ([]*models.DeadLetterAlert, error)
+	// RedispatchDeadLetterAlerts delivers again the alerts of the organization
+	// that could not be delivered.
+	RedispatchDeadLetterAlerts(ctx context.Context, orgID int64) (int, error)
+	// PurgeDeadLetterAlerts deletes the alerts of the organization that could
+	// not be delivered.
+	PurgeDeadLetterAlerts(ctx context.Context, orgID int64) error
+
+	// PauseExternalDeliveryFor sends the alerts of the organization to the
+	// local notifier only, for the given duration or until resumed if 0.
+	PauseExternalDeliveryFor(orgID int64, duration time.Duration)
+	// ResumeExternalDeliveryFor resumes sending the alerts of the
+	// organization to its external Alertmanager(s).
+	ResumeExternalDeliveryFor(orgID int64)
+	// ExternalDeliveryPausedFor returns whether the external delivery of the
+	// organization is paused, and when it resumes.
+	ExternalDeliveryPausedFor(orgID int64) (bool, time.Time)
+
+	// DeliveryHealth returns the health of the delivery of the alerts to
+	// external Alertmanager(s), across organizations.
+	DeliveryHealth() DeliveryHealth
+
 	// UpdateAlertRule notifies scheduler that a rule has been changed
 	UpdateAlertRule(key models.AlertRuleKey)
 	// DeleteAlertRule notifies scheduler that a rule has been changed
@@ -76,9 +208,14 @@ type schedule struct {
 
 	evaluator eval.Evaluator
 
-	ruleStore         store.RuleStore
-	instanceStore     store.InstanceStore
-	adminConfigStore  store.AdminConfigurationStore
+	ruleStore        store.RuleStore
+	instanceStore    store.InstanceStore
+	adminConfigStore store.AdminConfigurationStore
+	// enrichers mutate the alerts before they are delivered, nil if there is none.
+	enrichers *AlertEnrichers
+	// deadLetterStore keeps the alerts that could not be delivered, nil drops them.
+	deadLetterStore store.DeadLetterStore
+
 	orgStore          store.OrgStore
 	expressionService *expr.Service
 
@@ -90,33 +227,548 @@ type schedule struct {
 	metrics          *metrics.Scheduler
 
 	// Senders help us send alerts to external Alertmanagers.
-	adminConfigMtx          sync.RWMutex
-	sendAlertsTo            map[int64]models.AlertmanagersChoice
-	sendersCfgHash          map[int64]string
-	senders                 map[int64]*sender.Sender
+	adminConfigMtx sync.RWMutex
+	sendAlertsTo   map[int64]models.AlertmanagersChoice
+	sendersCfgHash map[int64]string
+	senders        map[int64]*sender.Sender
+	// inconsistencies holds the reasons why the admin configuration of organizations is inconsistent.
+	inconsistencies map[int64][]string
+	// externalRules are, per organization, the rules whose alerts are sent to external Alertmanager(s) only.
+	externalRules         map[int64]map[string]struct{}
+	senderStopConcurrency int
+	senderStopTimeout     time.Duration
+	// senderLocks serialize the creation, configuration and stop of the sender of each organization.
+	senderLocksMtx          sync.Mutex
+	senderLocks             map[int64]orgLock
 	adminConfigPollInterval time.Duration
-	disabledOrgs            map[int64]struct{}
-	minRuleInterval         time.Duration
+	// adminConfigChanged triggers a sync of the admin configuration without waiting for the next poll.
+	adminConfigChanged    chan struct{}
+	disabledOrgs          map[int64]struct{}
+	compressedSendsOrgs   map[int64]struct{}
+	orderedDeliveryOrgs   map[int64]struct{}
+	localFallback         bool
+	missingLocalNotifier  MissingLocalNotifierPolicy
+	duplicateAdminConfigs DuplicateAdminConfigPolicy
+	routingDecisionLogs   bool
+	retryPolicies         map[int64]RetryPolicy
+	sendQuorums           map[int64]SendQuorum
+	sendBacklogSize       int
+	sendBacklogTTL        time.Duration
+	decryptFn             sender.DecryptFn
+	senderDrainTimeout    time.Duration
+	senderConcurrency     map[int64]int
+	senderQueues          map[int64]SenderQueue
+	defaultSenderQueue    SenderQueue
+	minRuleInterval       time.Duration
+	// defaultSenderConcurrency is the sender concurrency of the organizations without one.
+	defaultSenderConcurrency int
+
+	// strictAdminConfig rejects admin configurations with any invalid Alertmanager instead of applying the valid ones.
+	strictAdminConfig bool
+
+	// externalLabelMatchers restricts, per organization, the alerts forwarded to external Alertmanager(s).
+	// Organizations not present use defaultExternalLabelMatcher, if any.
+	externalLabelMatchers       map[int64]ExternalLabelMatcher
+	defaultExternalLabelMatcher *ExternalLabelMatcher
+	// requiredLabels are, per organization, the labels alerts must have to be sent to external Alertmanager(s).
+	// Organizations not present use defaultRequiredLabels.
+	requiredLabels        map[int64]RequiredLabels
+	defaultRequiredLabels RequiredLabels
+
+	// fallbackSince is when organizations handling alerts with external Alertmanager(s) only started to fall
+	// back to the local notifier because none was discovered. fallbackExceeded holds the ones that did for
+	// longer than maxFallbackDuration.
+	maxFallbackDuration   time.Duration
+	sustainedFallbackFunc func(orgID int64, since time.Time)
+	fallbackMtx           sync.Mutex
+	fallbackSince         map[int64]time.Time
+	fallbackExceeded      map[int64]struct{}
+
+	// decisionHistory holds, per organization, the most recent decisions taken when syncing the admin configuration.
+	decisionHistoryMtx sync.Mutex
+	decisionHistory    map[int64][]DecisionRecord
+
+	// auditSink receives an AuditEvent for every change of the senders.
+	auditSink func(AuditEvent)
+	// dispatchSink receives a DispatchEvent for every batch of alerts dispatched to the notifiers.
+	dispatchSink func(DispatchEvent)
+
+	// heartbeatURL is pinged after every successful sync of the admin configuration while the senders are
+	// healthy, with heartbeatAPIKey as OpsGenie key if set. No heartbeat is sent if it is empty.
+	heartbeatURL    string
+	heartbeatAPIKey string
+	heartbeatClient *http.Client
+
+	// unhealthyThreshold and healthyThreshold are the number of consecutive failures, respectively successes,
+	// after which the external Alertmanager(s) of an organization are considered unhealthy, respectively healthy again.
+	unhealthyThreshold int
+	healthyThreshold   int
+	healthMtx          sync.Mutex
+	health             map[int64]*orgHealth
+
+	// createdAt is when the scheduler was created and lastConfigSync the last time the admin configuration
+	// was synced successfully, guarded by adminConfigMtx. The sync is stale after maxConfigSyncAge.
+	createdAt        time.Time
+	lastConfigSync   time.Time
+	maxConfigSyncAge time.Duration
+
+	// routingModeStabilization is how long a change of the Alertmanagers choice of an organization must
+	// be stable before it is applied. pendingRoutingModes holds the changes not applied yet.
+	routingModeStabilization time.Duration
+	pendingRoutingModes      map[int64]PendingRoutingModeChange
+
+	// lastDelivery is the last time alerts of an organization were delivered, locally or externally.
+	// It is zero for organizations that tried to deliver alerts but never succeeded.
+	deliveryMtx  sync.Mutex
+	lastDelivery map[int64]time.Time
+
+	// ruleDependencies are the rules inhibiting the firing alerts of some rules while they fire, the dependencies
+	// of the rules fetched on the last tick.
+	ruleDependenciesMtx sync.RWMutex
+	ruleDependencies    map[models.AlertRuleKey][]models.RuleDependency
+
+	// datasourceConcurrency limits the rule evaluations querying each datasource at the same time, enforced by
+	// datasourceSemaphores.
+	datasourceConcurrency   func(ctx context.Context, orgID int64, datasourceUID string) int
+	datasourceSemaphoresMtx sync.Mutex
+	datasourceSemaphores    map[datasourceKey]*datasourceSemaphore
+
+	// evaluationJitter spreads the evaluations of the rules over their interval, except for the rules whose
+	// evaluations are aligned.
+	evaluationJitter bool
+
+	// circuitBreaker backs off the rules failing to evaluate too many times in a row.
+	circuitBreaker CircuitBreaker
+
+	// memberStore shards the evaluation of the alert rules among the members alive, this scheduler included.
+	// handedOff are the rules whose routine is stopped because another member evaluates them now.
+	memberStore             store.SchedulerMemberStore
+	memberID                string
+	memberHeartbeatInterval time.Duration
+	membersMtx              sync.RWMutex
+	members                 []string
+	handedOffMtx            sync.Mutex
+	handedOff               map[models.AlertRuleKey]struct{}
+
+	// pausing are the rules whose routine is stopped because they are paused, and pausedRules the rules paused
+	// as of the last tick, used by the ticks only.
+	pausingMtx  sync.Mutex
+	pausing     map[models.AlertRuleKey]struct{}
+	pausedRules map[models.AlertRuleKey]struct{}
+
+	// dispatchLeaseStore elects the single scheduler that dispatches the alerts of each organization to its
+	// external targets, the memberID holding the lease of the organization. dispatchLeases are the organizations
+	// whose lease this scheduler holds.
+	dispatchLeaseStore    store.DispatchLeaseStore
+	dispatchLeaseInterval time.Duration
+	dispatchLeasesMtx     sync.RWMutex
+	dispatchLeases        map[int64]struct{}
+
+	recordingWriter writer.Writer
+
+	// maintenanceWindowStore keeps the maintenance windows, and the alerts they suppressed. maintenanceWindows
+	// are, per organization, the windows active as of the last tick.
+	maintenanceWindowStore store.MaintenanceWindowStore
+	maintenanceMtx         sync.RWMutex
+	maintenanceWindows     map[int64][]maintenanceWindow
+
+	// alertQuotas are, per organization, the quotas of the firing alerts delivered. Organizations not present
+	// use defaultAlertQuota.
+	alertQuotas       map[int64]AlertQuota
+	defaultAlertQuota AlertQuota
+
+	// The state of the features of the delivery of the alerts, defined along with them.
+	muting
+	dedup
+	rateLimiting
+	grouping
+	capture
+	deliveryPause
+	startupNotifications
+	receipts
+	resolving
+}
+
+// datasourceKey identifies a datasource, whose UID is unique in its organization only.
+type datasourceKey struct {
+	orgID int64
+	uid   string
+}
+
+// datasourceSemaphore limits the rule evaluations querying a datasource at the same time.
+type datasourceSemaphore struct {
+	limit int
+	sem   *semaphore.Weighted
 }
 
 // SchedulerCfg is the scheduler configuration.
 type SchedulerCfg struct {
-	C                       clock.Clock
-	BaseInterval            time.Duration
-	Logger                  log.Logger
-	EvalAppliedFunc         func(models.AlertRuleKey, time.Time)
-	MaxAttempts             int64
-	StopAppliedFunc         func(models.AlertRuleKey)
-	Evaluator               eval.Evaluator
-	RuleStore               store.RuleStore
-	OrgStore                store.OrgStore
-	InstanceStore           store.InstanceStore
-	AdminConfigStore        store.AdminConfigurationStore
+	C                clock.Clock
+	BaseInterval     time.Duration
+	Logger           log.Logger
+	EvalAppliedFunc  func(models.AlertRuleKey, time.Time)
+	MaxAttempts      int64
+	StopAppliedFunc  func(models.AlertRuleKey)
+	Evaluator        eval.Evaluator
+	RuleStore        store.RuleStore
+	OrgStore         store.OrgStore
+	InstanceStore    store.InstanceStore
+	AdminConfigStore store.AdminConfigurationStore
+	// AlertEnrichers mutate the alerts of the rules before they are routed and delivered.
+	AlertEnrichers *AlertEnrichers
+	// DeadLetterStore keeps the alerts that could be delivered neither locally nor externally, to deliver them
+	// again once the configuration is fixed. Without it, they are dropped.
+	DeadLetterStore store.DeadLetterStore
+	// DeliveryReceiptStore keeps the outcome of sending every alert to every external target of its organization,
+	// to track the delivery of the alerts. Without it, the outcomes are not kept.
+	DeliveryReceiptStore store.DeliveryReceiptStore
+	// DeliveryReceiptBufferSize is the number of delivery receipts waiting to be saved, the oldest are dropped
+	// when it is full. It defaults to 10000.
+	DeliveryReceiptBufferSize int
+	// DeliveryReceiptTTL is how long the delivery receipts are kept. 0 keeps them forever.
+	DeliveryReceiptTTL      time.Duration
 	MultiOrgNotifier        *notifier.MultiOrgAlertmanager
 	Metrics                 *metrics.Scheduler
 	AdminConfigPollInterval time.Duration
 	DisabledOrgs            map[int64]struct{}
 	MinRuleInterval         time.Duration
+	// CompressedSendsOrgs are the organizations whose alerts are sent gzip compressed to their external
+	// Alertmanager(s), for the ones that accept it.
+	CompressedSendsOrgs map[int64]struct{}
+	// OrderedDeliveryOrgs are the organizations whose alerts are sent to their external Alertmanager(s) in the
+	// order they are sent, for Alertmanager(s) that require it, at the cost of throughput: their alerts are sent
+	// a batch at a time, whatever their sender concurrency and queue priorities, and, if the backlog is enabled,
+	// a batch failing to be sent is sent again from the backlog before any newer batch. See sender.SetOrdered.
+	OrderedDeliveryOrgs map[int64]struct{}
+	// LocalFallback sends the alerts of organizations handling alerts with external Alertmanager(s) only
+	// to the local notifier as well, while the external Alertmanager(s) are unhealthy.
+	LocalFallback bool
+	// MissingLocalNotifier is what happens when the alerts of an organization handling alerts with all
+	// Alertmanagers cannot be put in the local notifier because it does not exist yet.
+	MissingLocalNotifier MissingLocalNotifierPolicy
+	// DuplicateAdminConfigs is what happens when more than one admin configuration is found for an organization.
+	DuplicateAdminConfigs DuplicateAdminConfigPolicy
+	// RetryPolicies are, per organization, the retries of the sends to external Alertmanager(s) failing
+	// with a transient error. Organizations not present use a default policy.
+	RetryPolicies map[int64]RetryPolicy
+	// SendQuorums are, per organization, how many external Alertmanager(s) must accept the alerts for a send
+	// to be successful, which drives the health, delivery and fallback of the organization. Organizations not
+	// present use SendQuorumBestEffort.
+	SendQuorums map[int64]SendQuorum
+	// SendBacklogSize is the number of batches of alerts that could not be sent because an external
+	// Alertmanager was unreachable that each sender keeps to send them again. 0 disables the backlog.
+	SendBacklogSize int
+	// SendBacklogTTL is how long the batches of alerts of the backlog are sent again, 0 means until they are sent.
+	SendBacklogTTL time.Duration
+	// DecryptFn decrypts the credentials of the external Alertmanager(s).
+	DecryptFn sender.DecryptFn
+	// SenderDrainTimeout is how long a sender being stopped keeps sending the alerts it has queued, 0 stops
+	// it right away. Senders are waited for up to one minute, a longer drain finishes in the background.
+	SenderDrainTimeout time.Duration
+	// SenderConcurrency is, per organization, the number of batches of alerts sent to the external
+	// Alertmanager(s) at the same time, for organizations with many alerts. Organizations not present use
+	// DefaultSenderConcurrency, one batch at a time if it is not set. They apply to the senders created after
+	// they are set.
+	SenderConcurrency        map[int64]int
+	DefaultSenderConcurrency int
+	// SenderQueues are, per organization, the queues of the alerts sent to the external Alertmanager(s), to trade
+	// latency for fewer requests in large installations. Organizations not present use DefaultSenderQueue. They
+	// apply to the senders created after they are set.
+	SenderQueues       map[int64]SenderQueue
+	DefaultSenderQueue SenderQueue
+	// MaxFallbackDuration is how long an organization handling alerts with external Alertmanager(s) only can
+	// fall back to the local notifier, because none was discovered, before it is reported. 0 disables it.
+	MaxFallbackDuration time.Duration
+	// SustainedFallbackFunc, if set, is called once when an organization falls back to the local notifier
+	// for longer than MaxFallbackDuration. It has no setting, it is meant for the code creating the scheduler.
+	SustainedFallbackFunc func(orgID int64, since time.Time)
+	// MaxResolvedAlertAge is the maximum age, per organization, of resolved alerts sent when a rule's state is cleared.
+	// Resolved alerts that started before that are dropped. Organizations not present use DefaultMaxResolvedAlertAge,
+	// 0 for no limit.
+	MaxResolvedAlertAge        map[int64]time.Duration
+	DefaultMaxResolvedAlertAge time.Duration
+	// ResolvedAlerts are, per organization, how the alerts of a rule are resolved when its state is cleared.
+	// RuleResolvedAlerts override them for some rules. Rules and organizations not present resolve their
+	// alerts immediately. The ones of the admin configuration of the organization take precedence.
+	ResolvedAlerts     map[int64]ResolvedAlertsPolicy
+	RuleResolvedAlerts map[models.AlertRuleKey]ResolvedAlertsPolicy
+	// MuteTimings are, per organization, when firing alerts are not sent to external Alertmanager(s), independently
+	// of the mute timings of the Alertmanager(s). RuleMuteTimings override them for some rules. The ones of the admin
+	// configuration of the organization take precedence.
+	MuteTimings     map[int64]MuteTimings
+	RuleMuteTimings map[models.AlertRuleKey]MuteTimings
+	// ExternalResendInterval is how long firing alerts that did not change are not sent again to external
+	// Alertmanager(s), 0 sends them after every evaluation. They are sent again before the external
+	// Alertmanager(s) would resolve them anyway.
+	ExternalResendInterval time.Duration
+	// Fingerprint identifies the alerts deduplicated by ExternalResendInterval and the firing alerts of the groups
+	// of ExternalGroupings, DefaultFingerprint if nil. The alerts an external Alertmanager accepted are identified
+	// from their labels only.
+	Fingerprint FingerprintFunc
+	// ExternalRateLimits are, per organization, the rate limits of the alerts sent to external Alertmanager(s).
+	// The alerts of a rule exceeding it are replaced by a single alert counting them. Organizations not
+	// present use DefaultExternalRateLimit, each with its own bucket, which has no limit by default.
+	ExternalRateLimits       map[int64]RateLimit
+	DefaultExternalRateLimit RateLimit
+	// ExternalGroupings are, per organization, how the alerts of a rule are grouped before being sent to
+	// external Alertmanager(s), each group being sent as a single alert. Organizations not present send the
+	// alerts as they are. The grouping of the admin configuration of the organization takes precedence.
+	ExternalGroupings map[int64]AlertGrouping
+	// ExternalLabelMatchers restricts, per organization, the alerts forwarded to external Alertmanager(s).
+	// Organizations not present use DefaultExternalLabelMatcher, or forward all their alerts if it is nil.
+	ExternalLabelMatchers       map[int64]ExternalLabelMatcher
+	DefaultExternalLabelMatcher *ExternalLabelMatcher
+	// RequiredLabels are, per organization, the labels alerts must have to be sent to external Alertmanager(s),
+	// and what to do with the alerts missing some. Organizations not present use DefaultRequiredLabels, which
+	// sends all alerts as they are by default.
+	RequiredLabels        map[int64]RequiredLabels
+	DefaultRequiredLabels RequiredLabels
+	// DatasourceConcurrency returns the most rule evaluations querying the datasource at the same time, 0 if
+	// there is no limit. Rules querying several datasources wait for all of them before being evaluated.
+	DatasourceConcurrency func(ctx context.Context, orgID int64, datasourceUID string) int
+	// EvaluationJitter spreads the evaluations of the rules with the same interval over the interval, instead
+	// of evaluating all of them on the same tick. Each rule is evaluated at a fixed offset, in base intervals,
+	// derived from its key. The rules with AlignEvaluation set are evaluated at multiples of their interval anyway.
+	EvaluationJitter bool
+	// CircuitBreaker stops evaluating every interval the rules that keep failing to evaluate, so that a broken
+	// datasource is not queried over and over. It is disabled by default.
+	CircuitBreaker CircuitBreaker
+	// MemberStore shards the evaluation of the alert rules among the schedulers sharing the database: each
+	// rule is evaluated by a single member alive, chosen by rendezvous hashing of its key. Without it, the
+	// scheduler evaluates all the rules.
+	MemberStore store.SchedulerMemberStore
+	// MemberID identifies the scheduler among the members, a random one is generated if empty.
+	MemberID string
+	// MemberHeartbeatInterval is how often the scheduler records that it is alive and fetches the members
+	// alive. Members are gone after memberExpiryHeartbeats intervals without heartbeat.
+	MemberHeartbeatInterval time.Duration
+	// DispatchLeaseStore elects, for each organization, the single scheduler sharing the database that sends
+	// its alerts to its external targets. The other schedulers keep their sender running, so that they take
+	// over as soon as they acquire the lease, but do not send anything. The lease is renewed every
+	// DispatchLeaseInterval. Without it, every scheduler sends the alerts it evaluates.
+	DispatchLeaseStore    store.DispatchLeaseStore
+	DispatchLeaseInterval time.Duration
+	// RecordingWriter writes the samples of the recording rules to their target datasource. Recording rules
+	// fail to evaluate without it.
+	RecordingWriter writer.Writer
+	// MaintenanceWindowStore keeps the maintenance windows during which the firing alerts matching them are
+	// suppressed, and the rules matching them are not evaluated if the window says so. Without it, nothing is
+	// suppressed.
+	MaintenanceWindowStore store.MaintenanceWindowStore
+	// AlertQuotas are, per organization, the most firing alerts of the organization and of each of its rules
+	// delivered, the others being replaced by a single alert per rule. Organizations not present use
+	// DefaultAlertQuota, which has no limit by default.
+	AlertQuotas       map[int64]AlertQuota
+	DefaultAlertQuota AlertQuota
+	// StrictAdminConfig rejects admin configurations with any invalid Alertmanager. By default, the valid
+	// Alertmanager(s) are applied and the invalid ones are reported by InvalidAlertmanagersFor.
+	StrictAdminConfig bool
+	// CaptureSends records the alerts sent to external Alertmanager(s) so that they can be retrieved
+	// with CapturedSends. It is only meant to be used in tests.
+	CaptureSends bool
+	// AuditSink, if set, receives an AuditEvent for every configuration applied to a sender and every
+	// sender created or stopped. It is never called while holding a lock.
+	AuditSink func(AuditEvent)
+	// DispatchSink, if set, receives a DispatchEvent for every batch of alerts of a rule put in the internal
+	// Alertmanager or sent to the external targets. It must not block, it is never called while holding a lock.
+	DispatchSink func(DispatchEvent)
+	// HeartbeatURL, if set, is pinged after every successful sync of the admin configuration while no
+	// organization is unhealthy, e.g. an OpsGenie heartbeat or a healthchecks.io check, so that the absence of
+	// heartbeats tells that the alerting pipeline is down. HeartbeatAPIKey is the API key of OpsGenie, if any.
+	HeartbeatURL    string
+	HeartbeatAPIKey string
+	// UnhealthyThreshold is the number of consecutive failures to apply the configuration of, or to send alerts
+	// to, the external Alertmanager(s) of an organization after which the organization is flagged unhealthy.
+	UnhealthyThreshold int
+	// HealthyThreshold is the number of consecutive successes after which an unhealthy organization is
+	// flagged healthy again.
+	HealthyThreshold int
+	// StartupNotification sends a one-time informational alert to the external Alertmanager(s) of an
+	// organization when its sender starts, after its configuration is applied for the first time.
+	StartupNotification bool
+	// StartupNotificationLabels are added to, or override, the labels of the startup notification alert.
+	StartupNotificationLabels map[string]string
+	// RoutingModeStabilization is how long a change of the Alertmanagers choice of an organization must be
+	// stable before it is applied, so that organizations that toggle it do not create and stop senders
+	// repeatedly. Changes are applied immediately by default.
+	RoutingModeStabilization time.Duration
+	// RoutingDecisionLogs logs, at info level, a single entry with a fixed set of keys for each batch of
+	// alerts routed to the notifiers, so that delivery behavior can be analyzed from the logs.
+	RoutingDecisionLogs bool
+	// MaxConfigSyncAge is how long the admin configuration can go without being synced successfully before
+	// DeliveryHealth reports the sync stale. It defaults to three times AdminConfigPollInterval.
+	MaxConfigSyncAge time.Duration
+}
+
+// PendingRoutingModeChange is a change of the Alertmanagers choice of an organization waiting to be stable.
+type PendingRoutingModeChange struct {
+	SendAlertsTo models.AlertmanagersChoice
+	// Since is when the change was first seen.
+	Since time.Time
+}
+
+// SenderStatus is the status of the sender of an organization.
+type SenderStatus struct {
+	OrgID        int64
+	SendAlertsTo models.AlertmanagersChoice
+	// Alertmanagers are the discovered Alertmanager(s), DroppedAlertmanagers the ones no longer used.
+	Alertmanagers        []*url.URL
+	DroppedAlertmanagers []*url.URL
+	// LastSuccess is the last time an Alertmanager accepted alerts of the organization, zero if none did yet.
+	LastSuccess time.Time
+	// ConfigHash is the hash of the admin configuration applied to the sender.
+	ConfigHash string
+}
+
+// DeliveryHealth is the health of the delivery of the alerts to external Alertmanager(s), across organizations.
+type DeliveryHealth struct {
+	// FailingAlertmanagers is the number of Alertmanagers whose last send failed.
+	FailingAlertmanagers int
+	// UnhealthyOrgs is the number of organizations whose external Alertmanager(s) are flagged unhealthy.
+	UnhealthyOrgs int
+	// LastConfigSync is the last time the admin configuration was synced successfully, zero if it was not yet.
+	LastConfigSync time.Time
+	// ConfigSyncStale is true if the admin configuration was not synced successfully for longer than the
+	// maximum age of the sync, since the scheduler was created if it was not yet. The pipeline is likely stuck.
+	ConfigSyncStale bool
+}
+
+// DecisionRecord is a decision taken for an organization when syncing the admin configuration.
+type DecisionRecord struct {
+	Timestamp time.Time
+	// Decision is the kind of decision, e.g. "apply-new-config".
+	Decision string
+	Reason   string
+}
+
+// AuditEvent records a change of the external Alertmanager(s) configuration of an organization.
+type AuditEvent struct {
+	OrgID     int64
+	Timestamp time.Time
+	// OldHash and NewHash are the hashes of the admin configuration before and after the change,
+	// empty when there was no sender before or after it.
+	OldHash string
+	NewHash string
+	// Reason is the sync decision that led to the change, e.g. "apply-new-config".
+	Reason string
+}
+
+// DispatchEvent records the alerts of a rule dispatched to the notifiers of its organization.
+type DispatchEvent struct {
+	OrgID     int64
+	RuleUID   string
+	Timestamp time.Time
+	// Local are the alerts put in the internal Alertmanager, External the ones sent to the external targets.
+	Local    []amv2.PostableAlert
+	External []amv2.PostableAlert
+}
+
+// RequiredLabels are labels that alerts must have to be sent to external Alertmanager(s), e.g. a routing key.
+type RequiredLabels struct {
+	// Labels maps the names of the required labels to the values set by the MissingLabelsDefault policy.
+	Labels map[string]string
+	Policy MissingLabelsPolicy
+}
+
+// MissingLabelsPolicy is what happens to the alerts missing required labels.
+type MissingLabelsPolicy int
+
+const (
+	// MissingLabelsSend sends the alerts to external Alertmanager(s) as they are.
+	MissingLabelsSend MissingLabelsPolicy = iota
+	// MissingLabelsDrop does not send the alerts to external Alertmanager(s).
+	MissingLabelsDrop
+	// MissingLabelsInternal handles the alerts with the internal Alertmanager only.
+	MissingLabelsInternal
+	// MissingLabelsDefault sends the alerts to external Alertmanager(s) with the default values of the missing labels.
+	MissingLabelsDefault
+)
+
+// MissingLocalNotifierPolicy is the behavior when the alerts of an organization handling alerts with all
+// Alertmanagers are sent to its external Alertmanager(s) but its local notifier does not exist.
+type MissingLocalNotifierPolicy int
+
+const (
+	// MissingLocalNotifierIgnore logs the missing local notifier at debug level.
+	MissingLocalNotifierIgnore MissingLocalNotifierPolicy = iota
+	// MissingLocalNotifierWarn logs the missing local notifier as a warning.
+	MissingLocalNotifierWarn
+	// MissingLocalNotifierError makes the delivery of the alerts fail.
+	MissingLocalNotifierError
+)
+
+// DuplicateAdminConfigPolicy is the behavior when the store returns more than one admin configuration for
+// an organization. The duplicates are reported as an inconsistency of the organization in both cases.
+type DuplicateAdminConfigPolicy int
+
+const (
+	// DuplicateAdminConfigLatest applies the most recently updated configuration, the one with the highest ID
+	// if they were updated at the same time.
+	DuplicateAdminConfigLatest DuplicateAdminConfigPolicy = iota
+	// DuplicateAdminConfigSkip applies none of the configurations, the sender of the organization is kept as is.
+	DuplicateAdminConfigSkip
+)
+
+// SendQuorum is how many external Alertmanager(s) of an organization must accept the alerts for a send to be
+// successful. Except for SendQuorumBestEffort, it is evaluated over the most recent send to each Alertmanager.
+type SendQuorum int
+
+const (
+	// SendQuorumBestEffort considers each send to an Alertmanager on its own.
+	SendQuorumBestEffort SendQuorum = iota
+	// SendQuorumAny requires any Alertmanager to accept the alerts.
+	SendQuorumAny
+	// SendQuorumMajority requires more than half of the Alertmanager(s) to accept the alerts.
+	SendQuorumMajority
+	// SendQuorumAll requires all the Alertmanager(s) to accept the alerts.
+	SendQuorumAll
+)
+
+// RetryPolicy configures the retries of the sends to external Alertmanager(s) failing with a network error,
+// a 5xx or a 429 status code.
+type RetryPolicy struct {
+	// Retries is the number of times a failed send is retried, 0 disables retries.
+	Retries int
+	// Backoff is the time waited before the first retry, it doubles before each subsequent one.
+	Backoff time.Duration
+}
+
+// SenderQueue configures the queue of the alerts sent to external Alertmanager(s). Zero values use the defaults.
+type SenderQueue struct {
+	// Capacity is the number of alerts queued, per batch of alerts sent at the same time, 10000 by default.
+	// Alerts sent while the queue is full are dropped.
+	Capacity int
+	// MaxBatchSize is the most alerts sent in a request, 64 by default and at most.
+	MaxBatchSize int
+	// FlushInterval is how long alerts are buffered before being queued, so that they are sent in fewer requests.
+	// Alerts are queued right away by default, and as soon as there is a full batch of them.
+	FlushInterval time.Duration
+	// PriorityLabel and HighPriorityValues send the alerts with one of the values of the label before the other
+	// alerts, each priority having its own queue of Capacity alerts. No label, the default, queues all the alerts
+	// together. Batches are filled with high priority alerts first, after the flush interval. The alerts are rate
+	// limited before they are queued, whatever their priority, and the alerts sent again from the backlog do not
+	// wait in the queues.
+	PriorityLabel      string
+	HighPriorityValues []string
+}
+
+// CircuitBreaker backs off the rules failing to evaluate, or whose results are all errors, a number of times in a
+// row: the evaluations of the rule are skipped for its interval, doubled after every failure of the evaluations
+// still attempted, and an alert reporting it is sent until the rule evaluates successfully again.
+type CircuitBreaker struct {
+	// Threshold is the number of failures in a row after which the rule is backed off, 0 disables it.
+	Threshold int
+	// MaxBackoff is the longest a rule is backed off, an hour by default.
+	MaxBackoff time.Duration
+}
+
+// ExternalLabelMatcher selects the alerts forwarded to external Alertmanager(s) by the value of a label,
+// e.g. only alerts with a critical severity. Alerts that do not match are handled by the internal Alertmanager.
+type ExternalLabelMatcher struct {
+	// Label is the name of the label to match, defaults to "severity".
+	Label string
+	// Values is the set of label values forwarded to external Alertmanager(s).
+	Values []string
 }
 
 // NewScheduler returns a new schedule.
@@ -138,6 +790,8 @@ func NewScheduler(cfg SchedulerCfg, expressionService *expr.Service, appURL *url
 		orgStore:                cfg.OrgStore,
 		expressionService:       expressionService,
 		adminConfigStore:        cfg.AdminConfigStore,
+		deadLetterStore:         cfg.DeadLetterStore,
+		enrichers:               cfg.AlertEnrichers,
 		multiOrgNotifier:        cfg.MultiOrgNotifier,
 		metrics:                 cfg.Metrics,
 		appURL:                  appURL,
@@ -145,9 +799,141 @@ func NewScheduler(cfg SchedulerCfg, expressionService *expr.Service, appURL *url
 		sendAlertsTo:            map[int64]models.AlertmanagersChoice{},
 		senders:                 map[int64]*sender.Sender{},
 		sendersCfgHash:          map[int64]string{},
+		inconsistencies:         map[int64][]string{},
+		externalRules:           map[int64]map[string]struct{}{},
+		senderStopConcurrency:   defaultSenderStopConcurrency,
+		senderStopTimeout:       defaultSenderStopTimeout,
+		senderLocks:             map[int64]orgLock{},
 		adminConfigPollInterval: cfg.AdminConfigPollInterval,
+		adminConfigChanged:      make(chan struct{}, 1),
 		disabledOrgs:            cfg.DisabledOrgs,
+		compressedSendsOrgs:     cfg.CompressedSendsOrgs,
+		orderedDeliveryOrgs:     cfg.OrderedDeliveryOrgs,
+		localFallback:           cfg.LocalFallback,
+		missingLocalNotifier:    cfg.MissingLocalNotifier,
+		duplicateAdminConfigs:   cfg.DuplicateAdminConfigs,
+		routingDecisionLogs:     cfg.RoutingDecisionLogs,
+		retryPolicies:           cfg.RetryPolicies,
+		sendQuorums:             cfg.SendQuorums,
+		sendBacklogSize:         cfg.SendBacklogSize,
+		sendBacklogTTL:          cfg.SendBacklogTTL,
+		decryptFn:               cfg.DecryptFn,
+		senderDrainTimeout:      cfg.SenderDrainTimeout,
+		senderConcurrency:       cfg.SenderConcurrency,
+		senderQueues:            cfg.SenderQueues,
+		defaultSenderQueue:      cfg.DefaultSenderQueue,
 		minRuleInterval:         cfg.MinRuleInterval,
+		externalLabelMatchers:   cfg.ExternalLabelMatchers,
+		requiredLabels:          cfg.RequiredLabels,
+		strictAdminConfig:       cfg.StrictAdminConfig,
+		auditSink:               cfg.AuditSink,
+		dispatchSink:            cfg.DispatchSink,
+		heartbeatURL:            cfg.HeartbeatURL,
+		heartbeatAPIKey:         cfg.HeartbeatAPIKey,
+		heartbeatClient:         &http.Client{Timeout: heartbeatTimeout},
+		unhealthyThreshold:      cfg.UnhealthyThreshold,
+		healthyThreshold:        cfg.HealthyThreshold,
+		health:                  map[int64]*orgHealth{},
+		createdAt:               cfg.C.Now(),
+		maxConfigSyncAge:        cfg.MaxConfigSyncAge,
+
+		routingModeStabilization: cfg.RoutingModeStabilization,
+		pendingRoutingModes:      map[int64]PendingRoutingModeChange{},
+		lastDelivery:             map[int64]time.Time{},
+		decisionHistory:          map[int64][]DecisionRecord{},
+		maxFallbackDuration:      cfg.MaxFallbackDuration,
+		sustainedFallbackFunc:    cfg.SustainedFallbackFunc,
+		fallbackSince:            map[int64]time.Time{},
+		fallbackExceeded:         map[int64]struct{}{},
+		datasourceConcurrency:    cfg.DatasourceConcurrency,
+		datasourceSemaphores:     map[datasourceKey]*datasourceSemaphore{},
+		evaluationJitter:         cfg.EvaluationJitter,
+		circuitBreaker:           cfg.CircuitBreaker,
+		memberStore:              cfg.MemberStore,
+		memberID:                 cfg.MemberID,
+		memberHeartbeatInterval:  cfg.MemberHeartbeatInterval,
+		handedOff:                map[models.AlertRuleKey]struct{}{},
+		pausing:                  map[models.AlertRuleKey]struct{}{},
+		pausedRules:              map[models.AlertRuleKey]struct{}{},
+		dispatchLeaseStore:       cfg.DispatchLeaseStore,
+		dispatchLeaseInterval:    cfg.DispatchLeaseInterval,
+		dispatchLeases:           map[int64]struct{}{},
+		recordingWriter:          cfg.RecordingWriter,
+		maintenanceWindowStore:   cfg.MaintenanceWindowStore,
+		maintenanceWindows:       map[int64][]maintenanceWindow{},
+		alertQuotas:              cfg.AlertQuotas,
+		defaultAlertQuota:        cfg.DefaultAlertQuota,
+
+		defaultExternalLabelMatcher: cfg.DefaultExternalLabelMatcher,
+		defaultRequiredLabels:       cfg.DefaultRequiredLabels,
+		defaultSenderConcurrency:    cfg.DefaultSenderConcurrency,
+
+		muting: muting{
+			muteTimings:     cfg.MuteTimings,
+			ruleMuteTimings: cfg.RuleMuteTimings,
+		},
+		dedup: dedup{
+			externalResendInterval: cfg.ExternalResendInterval,
+			fingerprint:            cfg.Fingerprint,
+			sentAlerts:             map[models.AlertRuleKey]map[string]sentAlert{},
+			sentAlertIDs:           map[int64]map[string]sentAlertRef{},
+		},
+		rateLimiting: rateLimiting{
+			externalRateLimits:       cfg.ExternalRateLimits,
+			rateLimiters:             map[int64]*rate.Limiter{},
+			defaultExternalRateLimit: cfg.DefaultExternalRateLimit,
+		},
+		grouping: grouping{
+			externalGroupings: cfg.ExternalGroupings,
+			groupedAlerts:     map[models.AlertRuleKey]map[string]amv2.PostableAlert{},
+		},
+		capture: capture{
+			captureSends: cfg.CaptureSends,
+			captured:     map[int64][]definitions.PostableAlerts{},
+		},
+		deliveryPause: deliveryPause{
+			pausedOrgs: map[int64]time.Time{},
+		},
+		startupNotifications: startupNotifications{
+			startupNotification:       cfg.StartupNotification,
+			startupNotificationLabels: cfg.StartupNotificationLabels,
+		},
+		receipts: receipts{
+			deliveryReceiptStore:      cfg.DeliveryReceiptStore,
+			deliveryReceiptsReady:     make(chan struct{}, 1),
+			deliveryReceiptBufferSize: cfg.DeliveryReceiptBufferSize,
+			deliveryReceiptTTL:        cfg.DeliveryReceiptTTL,
+		},
+		resolving: resolving{
+			maxResolvedAlertAge:        cfg.MaxResolvedAlertAge,
+			resolvedAlerts:             cfg.ResolvedAlerts,
+			ruleResolvedAlerts:         cfg.RuleResolvedAlerts,
+			defaultMaxResolvedAlertAge: cfg.DefaultMaxResolvedAlertAge,
+		},
+	}
+	if sch.fingerprint == nil {
+		sch.fingerprint = DefaultFingerprint
+	}
+	if sch.unhealthyThreshold <= 0 {
+		sch.unhealthyThreshold = defaultUnhealthyThreshold
+	}
+	if sch.healthyThreshold <= 0 {
+		sch.healthyThreshold = defaultHealthyThreshold
+	}
+	if sch.maxConfigSyncAge <= 0 {
+		sch.maxConfigSyncAge = 3 * sch.adminConfigPollInterval
+	}
+	if sch.memberID == "" {
+		sch.memberID = util.GenerateShortUID()
+	}
+	if sch.memberHeartbeatInterval <= 0 {
+		sch.memberHeartbeatInterval = defaultMemberHeartbeatInterval
+	}
+	if sch.dispatchLeaseInterval <= 0 {
+		sch.dispatchLeaseInterval = defaultDispatchLeaseInterval
+	}
+	if sch.deliveryReceiptBufferSize <= 0 {
+		sch.deliveryReceiptBufferSize = defaultDeliveryReceiptBufferSize
 	}
 	return &sch
 }
@@ -172,6 +958,36 @@ func (sch *schedule) Run(ctx context.Context) error {
 		}
 	}()
 
+	if sch.memberStore != nil {
+		// The members are known before the first tick, so that the scheduler does not evaluate all the rules.
+		sch.syncMembers(ctx)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sch.membershipSync(ctx); err != nil {
+				sch.log.Error("failure while running the scheduler membership sync", "err", err)
+			}
+		}()
+	}
+
+	if sch.dispatchLeaseStore != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sch.dispatchLeaseSync(ctx); err != nil {
+				sch.log.Error("failure while running the dispatch lease sync", "err", err)
+			}
+		}()
+	}
+
+	if sch.deliveryReceiptStore != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sch.deliveryReceiptSync(ctx)
+		}()
+	}
+
 	wg.Wait()
 	return nil
 }
@@ -188,36 +1004,111 @@ func (sch *schedule) SyncAndApplyConfigFromDatabase() error {
 	sch.log.Debug("found admin configurations", "count", len(cfgs))
 
 	orgsFound := make(map[int64]struct{}, len(cfgs))
+	stopReasons := map[int64]string{}
+	var auditEvents []AuditEvent
+	audit := func(orgID int64, oldHash, newHash, reason string) {
+		if sch.auditSink == nil {
+			return
+		}
+		auditEvents = append(auditEvents, AuditEvent{
+			OrgID:     orgID,
+			Timestamp: sch.clock.Now(),
+			OldHash:   oldHash,
+			NewHash:   newHash,
+			Reason:    reason,
+		})
+	}
+
 	sch.adminConfigMtx.Lock()
+	sch.inconsistencies = map[int64][]string{}
+	sch.externalRules = map[int64]map[string]struct{}{}
+	sch.adminResolvedAlerts = map[int64]ResolvedAlertsPolicy{}
+	sch.adminRuleResolvedAlerts = map[models.AlertRuleKey]ResolvedAlertsPolicy{}
+	sch.adminMuteTimings = map[int64]MuteTimings{}
+	sch.adminRuleMuteTimings = map[models.AlertRuleKey]MuteTimings{}
+	sch.adminExternalGroupings = map[int64]AlertGrouping{}
+	sch.metrics.InconsistentAdminConfigs.Reset()
+	cfgs, duplicates := sch.dedupAdminConfigs(cfgs)
+	for orgID, count := range duplicates {
+		sch.inconsistencies[orgID] = append(sch.inconsistencies[orgID], fmt.Sprintf("found %d admin configurations for the organization", count))
+		sch.metrics.InconsistentAdminConfigs.WithLabelValues(fmt.Sprint(orgID)).Set(1)
+		if sch.duplicateAdminConfigs == DuplicateAdminConfigSkip {
+			sch.log.Error("found more than one admin configuration, none will be applied", "org", orgID, "count", count)
+			// Keep the running sender, if any, as is.
+			orgsFound[orgID] = struct{}{}
+			continue
+		}
+		sch.log.Error("found more than one admin configuration, the most recently updated will be applied", "org", orgID, "count", count)
+	}
 	for _, cfg := range cfgs {
 		_, isDisabledOrg := sch.disabledOrgs[cfg.OrgID]
-		if isDisabledOrg {
+		if isDisabledOrg || cfg.Disabled {
 			sch.log.Debug("skipping starting sender for disabled org", "org", cfg.OrgID)
 			continue
 		}
 
-		// Update the Alertmanagers choice for the organization.
-		sch.sendAlertsTo[cfg.OrgID] = cfg.SendAlertsTo
+		if inconsistencies := cfg.Inconsistencies(); len(inconsistencies) > 0 {
+			sch.log.Warn("admin configuration is inconsistent", "org", cfg.OrgID, "inconsistencies", inconsistencies)
+			sch.inconsistencies[cfg.OrgID] = append(sch.inconsistencies[cfg.OrgID], inconsistencies...)
+			sch.metrics.InconsistentAdminConfigs.WithLabelValues(fmt.Sprint(cfg.OrgID)).Set(1)
+		}
+
+		// Update the Alertmanagers choice for the organization, once it is stable.
+		sendAlertsTo := sch.stableRoutingMode(cfg.OrgID, cfg.SendAlertsTo)
+		sch.sendAlertsTo[cfg.OrgID] = sendAlertsTo
+		if len(cfg.ExternalRuleUIDs) > 0 {
+			rules := make(map[string]struct{}, len(cfg.ExternalRuleUIDs))
+			for _, uid := range cfg.ExternalRuleUIDs {
+				rules[uid] = struct{}{}
+			}
+			sch.externalRules[cfg.OrgID] = rules
+		}
+		if cfg.ResolvedAlerts != nil {
+			sch.adminResolvedAlerts[cfg.OrgID] = resolvedAlertsPolicyOf(*cfg.ResolvedAlerts)
+		}
+		for uid, rc := range cfg.RuleResolvedAlerts {
+			sch.adminRuleResolvedAlerts[models.AlertRuleKey{OrgID: cfg.OrgID, UID: uid}] = resolvedAlertsPolicyOf(rc)
+		}
+		if cfg.MuteTimings != nil {
+			sch.adminMuteTimings[cfg.OrgID] = muteTimingsOf(*cfg.MuteTimings)
+		}
+		for uid, mt := range cfg.RuleMuteTimings {
+			sch.adminRuleMuteTimings[models.AlertRuleKey{OrgID: cfg.OrgID, UID: uid}] = muteTimingsOf(mt)
+		}
+		if cfg.Grouping != nil {
+			sch.adminExternalGroupings[cfg.OrgID] = AlertGrouping(*cfg.Grouping)
+		}
 
 		orgsFound[cfg.OrgID] = struct{}{} // keep track of the which senders we need to keep.
 
 		existing, ok := sch.senders[cfg.OrgID]
 
-		// We have no running sender and no Alertmanager(s) configured, no-op.
-		if !ok && len(cfg.Alertmanagers) == 0 {
+		if sch.strictAdminConfig && cfg.HasExternalTargets() {
+			if err := cfg.Validate(); err != nil {
+				sch.log.Error("invalid admin configuration, it will not be applied", "err", err, "org", cfg.OrgID)
+				continue
+			}
+		}
+
+		// We have no running sender and no Alertmanager(s), PagerDuty, webhook, SNS topic or SQS queue configured, no-op.
+		if !ok && !cfg.HasExternalTargets() {
 			sch.log.Debug("no external alertmanagers configured", "org", cfg.OrgID)
+			sch.recordSyncDecision(cfg.OrgID, syncDecisionNoopNoAlertmanagers, "no external alertmanagers configured")
 			continue
 		}
 		//  We have no running sender and alerts are handled internally, no-op.
-		if !ok && cfg.SendAlertsTo == models.InternalAlertmanager {
+		if !ok && sendAlertsTo == models.InternalAlertmanager && len(cfg.ExternalRuleUIDs) == 0 {
 			sch.log.Debug("alerts are handled internally", "org", cfg.OrgID)
+			sch.recordSyncDecision(cfg.OrgID, syncDecisionNoopInternal, "alerts are handled internally")
 			continue
 		}
 
-		// We have a running sender but no Alertmanager(s) configured, shut it down.
-		if ok && len(cfg.Alertmanagers) == 0 {
+		// We have a running sender but no Alertmanager(s), PagerDuty, webhook, SNS topic or SQS queue configured, shut it down.
+		if ok && !cfg.HasExternalTargets() {
 			sch.log.Debug("no external alertmanager(s) configured, sender will be stopped", "org", cfg.OrgID)
+			sch.recordSyncDecision(cfg.OrgID, syncDecisionStopNoAlertmanagers, "no external alertmanager(s) configured, sender will be stopped")
 			delete(orgsFound, cfg.OrgID)
+			stopReasons[cfg.OrgID] = syncDecisionStopNoAlertmanagers
 			continue
 		}
 
@@ -225,104 +1116,1022 @@ func (sch *schedule) SyncAndApplyConfigFromDatabase() error {
 		if ok {
 			if sch.sendersCfgHash[cfg.OrgID] == cfg.AsSHA256() {
 				sch.log.Debug("sender configuration is the same as the one running, no-op", "org", cfg.OrgID, "alertmanagers", cfg.Alertmanagers)
+				sch.recordSyncDecision(cfg.OrgID, syncDecisionNoopSameHash, "sender configuration is the same as the one running")
 				continue
 			}
 
 			sch.log.Debug("applying new configuration to sender", "org", cfg.OrgID, "alertmanagers", cfg.Alertmanagers)
+			sch.recordSyncDecision(cfg.OrgID, syncDecisionApplyNewConfig, "applying new configuration to sender")
+			// The admin configuration lock is held, so do not wait for the sender to be available.
+			lock := sch.senderLock(cfg.OrgID)
+			if !lock.TryLock() {
+				sch.log.Warn("sender is busy, the configuration will be applied at the next sync", "org", cfg.OrgID)
+				continue
+			}
 			err := existing.ApplyConfig(cfg)
+			lock.Unlock()
+			sch.recordHealth(cfg.OrgID, err)
+			sch.recordConfigReload(cfg.OrgID, err)
 			if err != nil {
 				sch.log.Error("failed to apply configuration", "err", err, "org", cfg.OrgID)
 				continue
 			}
+			audit(cfg.OrgID, sch.sendersCfgHash[cfg.OrgID], cfg.AsSHA256(), syncDecisionApplyNewConfig)
 			sch.sendersCfgHash[cfg.OrgID] = cfg.AsSHA256()
 			continue
 		}
 
 		// No sender and have Alertmanager(s) to send to - start a new one.
 		sch.log.Info("creating new sender for the external alertmanagers", "org", cfg.OrgID, "alertmanagers", cfg.Alertmanagers)
+		sch.recordSyncDecision(cfg.OrgID, syncDecisionCreateNewSender, "creating new sender for the external alertmanagers")
+		// A previous sender of the organization that is still stopping holds the lock. The admin configuration
+		// lock is held, so do not wait for it and create the sender at the next sync instead.
+		lock := sch.senderLock(cfg.OrgID)
+		if !lock.TryLock() {
+			sch.log.Warn("previous sender is still stopping, the sender will be created at the next sync", "org", cfg.OrgID)
+			continue
+		}
 		s, err := sender.New(sch.metrics)
 		if err != nil {
+			lock.Unlock()
 			sch.log.Error("unable to start the sender", "err", err, "org", cfg.OrgID)
+			sch.recordHealth(cfg.OrgID, err)
 			continue
 		}
 
+		orgID := cfg.OrgID
+		retryPolicy, ok := sch.retryPolicies[cfg.OrgID]
+		if !ok {
+			retryPolicy = defaultRetryPolicy
+		}
+		s.SetRetries(retryPolicy.Retries, retryPolicy.Backoff)
+		s.SetBacklog(sch.sendBacklogSize, sch.sendBacklogTTL)
+		s.SetDrainTimeout(sch.senderDrainTimeout)
+		_, ordered := sch.orderedDeliveryOrgs[cfg.OrgID]
+		s.SetOrdered(ordered)
+		concurrency, ok := sch.senderConcurrency[cfg.OrgID]
+		if !ok {
+			concurrency = sch.defaultSenderConcurrency
+		}
+		s.SetConcurrency(concurrency)
+		queue, ok := sch.senderQueues[cfg.OrgID]
+		if !ok {
+			queue = sch.defaultSenderQueue
+		}
+		s.SetQueue(queue.Capacity, queue.MaxBatchSize, queue.FlushInterval)
+		s.SetPriority(queue.PriorityLabel, queue.HighPriorityValues)
+		quorum := sch.sendQuorums[cfg.OrgID]
+		s.OnSendResult(func(res sender.SendResult) {
+			if res.Attempts > 1 {
+				sch.metrics.ExternalSendRetries.WithLabelValues(fmt.Sprint(orgID)).Add(float64(res.Attempts - 1))
+			}
+			sch.recordSendMetrics(orgID, res)
+			sch.saveDeliveryReceipts(orgID, res)
+			err := res.Err
+			if quorum != SendQuorumBestEffort {
+				err = quorumErr(quorum, s.LastSendResults())
+			}
+			sch.recordHealth(orgID, err)
+			if err == nil {
+				sch.recordDelivery(orgID)
+			}
+			if res.Err == nil {
+				sch.markExternalAlertsDelivered(orgID, res.AlertIDs)
+			}
+		})
+		_, compressed := sch.compressedSendsOrgs[cfg.OrgID]
+		s.SetCompression(compressed)
+		s.SetDecryptFn(sch.decryptFn)
+		if sch.multiOrgNotifier != nil {
+			s.SetSilenceStore(orgSilences{orgID: orgID, moa: sch.multiOrgNotifier})
+		}
 		sch.senders[cfg.OrgID] = s
 		s.Run()
 
 		err = s.ApplyConfig(cfg)
+		lock.Unlock()
+		sch.recordHealth(cfg.OrgID, err)
+		sch.recordConfigReload(cfg.OrgID, err)
 		if err != nil {
 			sch.log.Error("failed to apply configuration", "err", err, "org", cfg.OrgID)
+			audit(cfg.OrgID, "", "", syncDecisionCreateNewSender)
 			continue
 		}
 
+		audit(cfg.OrgID, "", cfg.AsSHA256(), syncDecisionCreateNewSender)
 		sch.sendersCfgHash[cfg.OrgID] = cfg.AsSHA256()
+
+		if sch.startupNotification {
+			go sch.sendStartupNotification(cfg.OrgID, s)
+		}
 	}
 
 	sendersToStop := map[int64]*sender.Sender{}
 
 	for orgID, s := range sch.senders {
 		if _, exists := orgsFound[orgID]; !exists {
+			// The lock is released once the sender is stopped. If the sender is busy, it is stopped at the next
+			// sync instead.
+			if !sch.senderLock(orgID).TryLock() {
+				sch.log.Warn("sender is busy, it will be stopped at the next sync", "org", orgID)
+				continue
+			}
 			sendersToStop[orgID] = s
+			reason, ok := stopReasons[orgID]
+			if !ok {
+				reason = syncDecisionStopped
+			}
+			audit(orgID, sch.sendersCfgHash[orgID], "", reason)
+			sch.resetHealth(orgID)
 			delete(sch.senders, orgID)
 			delete(sch.sendersCfgHash, orgID)
 		}
 	}
+	sch.lastConfigSync = sch.clock.Now()
 	sch.adminConfigMtx.Unlock()
 
-	// We can now stop these senders w/o having to hold a lock.
-	for orgID, s := range sendersToStop {
-		sch.log.Info("stopping sender", "org", orgID)
-		s.Stop()
-		sch.log.Info("stopped sender", "org", orgID)
+	for _, e := range auditEvents {
+		sch.auditSink(e)
 	}
 
+	// We can now stop these senders w/o having to hold the admin configuration lock.
+	sch.stopSenders(sendersToStop)
+
 	sch.log.Debug("finish of admin configuration sync")
 
 	return nil
 }
 
-// AlertmanagersFor returns all the discovered Alertmanager(s) for a particular organization.
-func (sch *schedule) AlertmanagersFor(orgID int64) []*url.URL {
-	sch.adminConfigMtx.RLock()
-	defer sch.adminConfigMtx.RUnlock()
-	s, ok := sch.senders[orgID]
-	if !ok {
-		return []*url.URL{}
+// dedupAdminConfigs returns the admin configurations with one configuration per organization, according to
+// the policy for duplicates, and the number of configurations found for the organizations that have more than one.
+func (sch *schedule) dedupAdminConfigs(cfgs []*models.AdminConfiguration) ([]*models.AdminConfiguration, map[int64]int) {
+	counts := make(map[int64]int, len(cfgs))
+	latest := make(map[int64]*models.AdminConfiguration, len(cfgs))
+	for _, cfg := range cfgs {
+		counts[cfg.OrgID]++
+		l, ok := latest[cfg.OrgID]
+		if !ok || cfg.UpdatedAt > l.UpdatedAt || (cfg.UpdatedAt == l.UpdatedAt && cfg.ID > l.ID) {
+			latest[cfg.OrgID] = cfg
+		}
+	}
+
+	duplicates := map[int64]int{}
+	deduped := make([]*models.AdminConfiguration, 0, len(latest))
+	for _, cfg := range cfgs {
+		if counts[cfg.OrgID] > 1 {
+			duplicates[cfg.OrgID] = counts[cfg.OrgID]
+			if sch.duplicateAdminConfigs == DuplicateAdminConfigSkip {
+				continue
+			}
+		}
+		if latest[cfg.OrgID] == cfg {
+			deduped = append(deduped, cfg)
+		}
+	}
+
+	return deduped, duplicates
+}
+
+// recordSyncDecision counts a decision taken for the organization when syncing the admin configuration
+// and keeps it in the decision history of the organization.
+func (sch *schedule) recordSyncDecision(orgID int64, decision, reason string) {
+	sch.metrics.SyncDecisions.WithLabelValues(decision).Inc()
+
+	sch.decisionHistoryMtx.Lock()
+	defer sch.decisionHistoryMtx.Unlock()
+	history := append(sch.decisionHistory[orgID], DecisionRecord{
+		Timestamp: sch.clock.Now(),
+		Decision:  decision,
+		Reason:    reason,
+	})
+	if len(history) > decisionHistorySize {
+		history = history[len(history)-decisionHistorySize:]
+	}
+	sch.decisionHistory[orgID] = history
+}
+
+// DecisionHistory returns the most recent decisions taken for the organization when syncing the admin
+// configuration, oldest first.
+func (sch *schedule) DecisionHistory(orgID int64) []DecisionRecord {
+	sch.decisionHistoryMtx.Lock()
+	defer sch.decisionHistoryMtx.Unlock()
+	history := make([]DecisionRecord, len(sch.decisionHistory[orgID]))
+	copy(history, sch.decisionHistory[orgID])
+	return history
+}
+
+// stableRoutingMode returns the Alertmanagers choice to apply for the organization. A change of the choice
+// is only applied once the configuration kept it for routingModeStabilization, until then the current
+// choice is kept and the change is pending. It must be called while holding adminConfigMtx.
+func (sch *schedule) stableRoutingMode(orgID int64, desired models.AlertmanagersChoice) models.AlertmanagersChoice {
+	current, ok := sch.sendAlertsTo[orgID]
+	if !ok || current == desired || sch.routingModeStabilization <= 0 {
+		delete(sch.pendingRoutingModes, orgID)
+		return desired
+	}
+
+	now := sch.clock.Now()
+	pending, ok := sch.pendingRoutingModes[orgID]
+	if !ok || pending.SendAlertsTo != desired {
+		sch.log.Debug("alertmanagers choice changed, waiting for it to be stable", "org", orgID, "current", current, "desired", desired)
+		sch.pendingRoutingModes[orgID] = PendingRoutingModeChange{SendAlertsTo: desired, Since: now}
+		return current
+	}
+
+	if now.Sub(pending.Since) < sch.routingModeStabilization {
+		return current
+	}
+
+	sch.log.Debug("alertmanagers choice is stable, applying it", "org", orgID, "current", current, "desired", desired)
+	delete(sch.pendingRoutingModes, orgID)
+	return desired
+}
+
+// PendingRoutingModeChangeFor returns the change of the Alertmanagers choice of the organization that
+// is waiting to be stable, if any.
+func (sch *schedule) PendingRoutingModeChangeFor(orgID int64) (PendingRoutingModeChange, bool) {
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+	p, ok := sch.pendingRoutingModes[orgID]
+	return p, ok
+}
+
+// orgLock is a lock that can also be acquired without waiting, which sync.Mutex does not allow.
+type orgLock chan struct{}
+
+func (l orgLock) Lock() {
+	l <- struct{}{}
+}
+
+// TryLock acquires the lock if it is free, and returns whether it did.
+func (l orgLock) TryLock() bool {
+	select {
+	case l <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l orgLock) Unlock() {
+	<-l
+}
+
+// senderLock returns the lock serializing the creation, configuration and stop of the sender of the organization.
+// It must not be waited for while holding adminConfigMtx, as a sender can take long to stop.
+func (sch *schedule) senderLock(orgID int64) orgLock {
+	sch.senderLocksMtx.Lock()
+	defer sch.senderLocksMtx.Unlock()
+	l, ok := sch.senderLocks[orgID]
+	if !ok {
+		l = make(orgLock, 1)
+		sch.senderLocks[orgID] = l
+	}
+	return l
+}
+
+// stopSenders stops the senders concurrently, at most senderStopConcurrency at a time. It waits for them
+// for up to senderStopTimeout, senders that did not stop by then keep stopping in the background.
+// The locks of their organizations must be held, they are released once the senders are stopped or, for
+// the ones still stopping, once senderStopTimeout is over.
+func (sch *schedule) stopSenders(senders map[int64]*sender.Sender) {
+	if len(senders) == 0 {
+		return
+	}
+
+	var (
+		wg      sync.WaitGroup
+		stopped int64
+		sem     = make(chan struct{}, sch.senderStopConcurrency)
+		unlocks = make(map[int64]func(), len(senders))
+	)
+	for orgID := range senders {
+		var once sync.Once
+		lock := sch.senderLock(orgID)
+		unlocks[orgID] = func() { once.Do(lock.Unlock) }
+	}
+	for orgID, s := range senders {
+		wg.Add(1)
+		go func(orgID int64, s *sender.Sender) {
+			defer wg.Done()
+			defer unlocks[orgID]()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			sch.log.Info("stopping sender", "org", orgID)
+			s.Stop()
+			sch.log.Info("stopped sender", "org", orgID)
+			if dropped := s.DroppedAtStop(); dropped > 0 {
+				sch.log.Warn("alerts dropped when stopping the sender", "org", orgID, "count", dropped)
+				sch.metrics.AlertsDroppedAtShutdown.WithLabelValues(fmt.Sprint(orgID)).Add(float64(dropped))
+			}
+			sch.recordSyncDecision(orgID, syncDecisionStopped, "sender is not needed anymore, stopped it")
+			atomic.AddInt64(&stopped, 1)
+		}(orgID, s)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		sch.log.Debug("stopped senders", "count", len(senders))
+	case <-sch.clock.After(sch.senderStopTimeout):
+		sch.log.Warn("timed out waiting for senders to stop", "stopped", atomic.LoadInt64(&stopped), "count", len(senders))
+		// Do not keep the organizations locked, new senders can start while the old ones finish stopping.
+		for _, unlock := range unlocks {
+			unlock()
+		}
+	}
+}
+
+// recordDeliveryAttempt records that the organization has alerts to deliver.
+func (sch *schedule) recordDeliveryAttempt(orgID int64) {
+	sch.deliveryMtx.Lock()
+	defer sch.deliveryMtx.Unlock()
+	if _, ok := sch.lastDelivery[orgID]; !ok {
+		sch.lastDelivery[orgID] = time.Time{}
+	}
+}
+
+// recordDelivery records that alerts of the organization were delivered.
+func (sch *schedule) recordDelivery(orgID int64) {
+	sch.deliveryMtx.Lock()
+	defer sch.deliveryMtx.Unlock()
+	sch.lastDelivery[orgID] = sch.clock.Now()
+}
+
+// LastDeliveryTime returns the last time alerts of the organization were delivered, to the local
+// notifier or to external Alertmanager(s). It returns false if none was delivered yet.
+func (sch *schedule) LastDeliveryTime(orgID int64) (time.Time, bool) {
+	sch.deliveryMtx.Lock()
+	defer sch.deliveryMtx.Unlock()
+	t := sch.lastDelivery[orgID]
+	return t, !t.IsZero()
+}
+
+// updateDeliveryMetrics updates the seconds since the last delivery of every organization that tried
+// to deliver alerts, -1 for the ones that never succeeded.
+func (sch *schedule) updateDeliveryMetrics() {
+	sch.deliveryMtx.Lock()
+	defer sch.deliveryMtx.Unlock()
+	now := sch.clock.Now()
+	for orgID, t := range sch.lastDelivery {
+		since := -1.0
+		if !t.IsZero() {
+			since = now.Sub(t).Seconds()
+		}
+		sch.metrics.SecondsSinceLastDelivery.WithLabelValues(fmt.Sprint(orgID)).Set(since)
+	}
+}
+
+// updateFallbacks tracks for how long the organizations handling alerts with external Alertmanager(s) only
+// have been falling back to the local notifier because none was discovered, and reports the ones that did
+// for longer than maxFallbackDuration.
+func (sch *schedule) updateFallbacks() {
+	if sch.maxFallbackDuration <= 0 {
+		return
+	}
+
+	sch.adminConfigMtx.RLock()
+	inFallback := map[int64]struct{}{}
+	for orgID, choice := range sch.sendAlertsTo {
+		if choice != models.ExternalAlertmanagers {
+			continue
+		}
+		if s, ok := sch.senders[orgID]; !ok || len(s.Alertmanagers()) == 0 {
+			inFallback[orgID] = struct{}{}
+		}
+	}
+	sch.adminConfigMtx.RUnlock()
+
+	now := sch.clock.Now()
+	exceeded := map[int64]time.Time{}
+	sch.fallbackMtx.Lock()
+	for orgID := range sch.fallbackSince {
+		if _, ok := inFallback[orgID]; !ok {
+			delete(sch.fallbackSince, orgID)
+			delete(sch.fallbackExceeded, orgID)
+			sch.metrics.FallbackThresholdExceeded.DeleteLabelValues(fmt.Sprint(orgID))
+		}
+	}
+	for orgID := range inFallback {
+		since, ok := sch.fallbackSince[orgID]
+		if !ok {
+			sch.fallbackSince[orgID] = now
+			continue
+		}
+		if _, ok := sch.fallbackExceeded[orgID]; ok || now.Sub(since) <= sch.maxFallbackDuration {
+			continue
+		}
+		sch.log.Warn("no external alertmanager discovered for too long, alerts are handled by the local notifier", "org", orgID, "since", since)
+		sch.fallbackExceeded[orgID] = struct{}{}
+		sch.metrics.FallbackThresholdExceeded.WithLabelValues(fmt.Sprint(orgID)).Set(1)
+		exceeded[orgID] = since
+	}
+	sch.fallbackMtx.Unlock()
+
+	if sch.sustainedFallbackFunc != nil {
+		for orgID, since := range exceeded {
+			sch.sustainedFallbackFunc(orgID, since)
+		}
+	}
+}
+
+// orgHealth tracks the consecutive failures and successes of the external Alertmanager(s) of an organization.
+type orgHealth struct {
+	failures  int
+	successes int
+	unhealthy bool
+}
+
+// recordHealth records the outcome of applying the configuration of, or sending alerts to, the external
+// Alertmanager(s) of an organization. The organization is flagged unhealthy after unhealthyThreshold
+// consecutive failures, and healthy again after healthyThreshold consecutive successes.
+func (sch *schedule) recordHealth(orgID int64, err error) {
+	sch.healthMtx.Lock()
+	defer sch.healthMtx.Unlock()
+	h, ok := sch.health[orgID]
+	if !ok {
+		h = &orgHealth{}
+		sch.health[orgID] = h
+	}
+
+	if err != nil {
+		h.failures++
+		h.successes = 0
+		if !h.unhealthy && h.failures >= sch.unhealthyThreshold {
+			h.unhealthy = true
+			sch.log.Warn("external alertmanagers are unhealthy", "org", orgID, "failures", h.failures, "err", err)
+			sch.metrics.UnhealthyOrgs.WithLabelValues(fmt.Sprint(orgID)).Set(1)
+		}
+		return
+	}
+
+	h.successes++
+	h.failures = 0
+	if h.unhealthy && h.successes >= sch.healthyThreshold {
+		h.unhealthy = false
+		sch.log.Info("external alertmanagers are healthy again", "org", orgID)
+		sch.metrics.UnhealthyOrgs.WithLabelValues(fmt.Sprint(orgID)).Set(0)
+	}
+}
+
+// recordConfigReload counts a configuration applied to the sender of the organization.
+func (sch *schedule) recordConfigReload(orgID int64, err error) {
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	sch.metrics.SenderConfigReloads.WithLabelValues(fmt.Sprint(orgID), status).Inc()
+}
+
+// recordSendMetrics counts the alerts of a send to an external Alertmanager of the organization.
+func (sch *schedule) recordSendMetrics(orgID int64, res sender.SendResult) {
+	org := fmt.Sprint(orgID)
+	sch.metrics.ExternalSendDuration.WithLabelValues(org, res.Alertmanager).Observe(res.Duration.Seconds())
+	if res.PayloadBytes > 0 && res.SentBytes > 0 {
+		sch.metrics.ExternalSendPayloadBytes.WithLabelValues(org, res.Alertmanager).Observe(float64(res.PayloadBytes))
+		sch.metrics.ExternalSendBodyBytes.WithLabelValues(org, res.Alertmanager, res.Encoding).Observe(float64(res.SentBytes))
+	}
+	if res.Err != nil {
+		sch.metrics.ExternalAlertsFailed.WithLabelValues(org, res.Alertmanager).Add(float64(res.Alerts))
+		return
+	}
+	sch.metrics.ExternalAlertsSent.WithLabelValues(org, res.Alertmanager).Add(float64(res.Alerts))
+}
+
+// orgSilences are the silences of the internal Alertmanager of an organization. The Alertmanager is looked up on
+// every use, as it can be created after the sender of the organization.
+type orgSilences struct {
+	orgID int64
+	moa   *notifier.MultiOrgAlertmanager
+}
+
+func (o orgSilences) ListSilences(filter []string) (definitions.GettableSilences, error) {
+	am, err := o.moa.AlertmanagerFor(o.orgID)
+	if err != nil {
+		return nil, err
+	}
+	return am.ListSilences(filter)
+}
+
+func (o orgSilences) CreateSilence(ps *definitions.PostableSilence) (string, error) {
+	am, err := o.moa.AlertmanagerFor(o.orgID)
+	if err != nil {
+		return "", err
+	}
+	return am.CreateSilence(ps)
+}
+
+func (o orgSilences) DeleteSilence(silenceID string) error {
+	am, err := o.moa.AlertmanagerFor(o.orgID)
+	if err != nil {
+		return err
+	}
+	return am.DeleteSilence(silenceID)
+}
+
+// quorumErr returns an error if the most recent sends to the Alertmanager(s) do not meet the quorum.
+// Alertmanager(s) that were not sent alerts yet, and the targets alerts are sent to directly, are not taken
+// into account.
+func quorumErr(quorum SendQuorum, results map[string]sender.SendResult) error {
+	var accepted, total int
+	var lastErr error
+	for _, r := range results {
+		if r.Direct {
+			continue
+		}
+		total++
+		if r.Err == nil {
+			accepted++
+		} else {
+			lastErr = r.Err
+		}
+	}
+
+	ok := lastErr == nil
+	switch quorum {
+	case SendQuorumAny:
+		ok = ok || accepted > 0
+	case SendQuorumMajority:
+		ok = ok || accepted*2 > total
+	}
+	if ok {
+		return nil
+	}
+
+	return fmt.Errorf("%d out of %d alertmanagers accepted the alerts, the quorum is not met: %w", accepted, total, lastErr)
+}
+
+// resetHealth forgets the health of the external Alertmanager(s) of an organization.
+func (sch *schedule) resetHealth(orgID int64) {
+	sch.healthMtx.Lock()
+	defer sch.healthMtx.Unlock()
+	delete(sch.health, orgID)
+	sch.metrics.UnhealthyOrgs.DeleteLabelValues(fmt.Sprint(orgID))
+}
+
+// IsUnhealthy returns true if the external Alertmanager(s) of the organization are flagged unhealthy.
+func (sch *schedule) IsUnhealthy(orgID int64) bool {
+	sch.healthMtx.Lock()
+	defer sch.healthMtx.Unlock()
+	h, ok := sch.health[orgID]
+	return ok && h.unhealthy
+}
+
+// DeliveryHealth returns the health of the delivery of the alerts to external Alertmanager(s), across organizations.
+func (sch *schedule) DeliveryHealth() DeliveryHealth {
+	var h DeliveryHealth
+	sch.adminConfigMtx.RLock()
+	h.LastConfigSync = sch.lastConfigSync
+	senders := make([]*sender.Sender, 0, len(sch.senders))
+	for _, s := range sch.senders {
+		senders = append(senders, s)
+	}
+	sch.adminConfigMtx.RUnlock()
+
+	for _, s := range senders {
+		for _, res := range s.LastSendResults() {
+			if res.Err != nil {
+				h.FailingAlertmanagers++
+			}
+		}
+	}
+
+	sch.healthMtx.Lock()
+	for _, oh := range sch.health {
+		if oh.unhealthy {
+			h.UnhealthyOrgs++
+		}
+	}
+	sch.healthMtx.Unlock()
+
+	if sch.maxConfigSyncAge > 0 {
+		since := h.LastConfigSync
+		if since.IsZero() {
+			since = sch.createdAt
+		}
+		h.ConfigSyncStale = sch.clock.Now().Sub(since) > sch.maxConfigSyncAge
+	}
+	return h
+}
+
+// AlertmanagersFor returns all the discovered Alertmanager(s) for a particular organization.
+func (sch *schedule) AlertmanagersFor(orgID int64) []*url.URL {
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+	s, ok := sch.senders[orgID]
+	if !ok {
+		return []*url.URL{}
+	}
+
+	return s.Alertmanagers()
+}
+
+// SenderStatuses returns the status of the senders of all the organizations, sorted by organization.
+// Organizations without sender are not included.
+func (sch *schedule) SenderStatuses() []SenderStatus {
+	sch.adminConfigMtx.RLock()
+	statuses := make([]SenderStatus, 0, len(sch.senders))
+	senders := make([]*sender.Sender, 0, len(sch.senders))
+	for orgID, s := range sch.senders {
+		statuses = append(statuses, SenderStatus{
+			OrgID:        orgID,
+			SendAlertsTo: sch.sendAlertsTo[orgID],
+			ConfigHash:   sch.sendersCfgHash[orgID],
+		})
+		senders = append(senders, s)
+	}
+	sch.adminConfigMtx.RUnlock()
+
+	for i, s := range senders {
+		statuses[i].Alertmanagers = s.Alertmanagers()
+		statuses[i].DroppedAlertmanagers = s.DroppedAlertmanagers()
+		statuses[i].LastSuccess = s.QueueStats().LastSuccess
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].OrgID < statuses[j].OrgID
+	})
+	return statuses
+}
+
+// DroppedAlertmanagersFor returns all the dropped Alertmanager(s) for a particular organization.
+func (sch *schedule) DroppedAlertmanagersFor(orgID int64) []*url.URL {
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+	s, ok := sch.senders[orgID]
+	if !ok {
+		return []*url.URL{}
+	}
+
+	return s.DroppedAlertmanagers()
+}
+
+// OrgsWithNoHealthyAlertmanagers returns the organizations that have a sender for their external Alertmanager(s)
+// but all of these were dropped, i.e. the organizations that cannot deliver alerts externally right now.
+func (sch *schedule) OrgsWithNoHealthyAlertmanagers() []int64 {
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+	orgs := []int64{}
+	for orgID, s := range sch.senders {
+		if len(s.Alertmanagers()) == 0 && len(s.DroppedAlertmanagers()) > 0 {
+			orgs = append(orgs, orgID)
+		}
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i] < orgs[j] })
+	return orgs
+}
+
+// InconsistenciesFor returns the reasons why the admin configuration of a particular organization is
+// logically inconsistent, as found by the last sync.
+func (sch *schedule) InconsistenciesFor(orgID int64) []string {
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+	return sch.inconsistencies[orgID]
+}
+
+// TestAlertmanagers tests the connectivity to the external Alertmanager(s) of a particular organization, without
+// sending any alert. It returns nothing if the organization has no sender.
+func (sch *schedule) TestAlertmanagers(ctx context.Context, orgID int64) []sender.AMTestResult {
+	sch.adminConfigMtx.RLock()
+	s, ok := sch.senders[orgID]
+	sch.adminConfigMtx.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	return s.TestAlertmanagers(ctx)
+}
+
+// SendTestAlert sends a test alert to every external Alertmanager of an admin configuration, without applying
+// the configuration, and returns whether each of them accepted it. The alert resolves by itself.
+func (sch *schedule) SendTestAlert(ctx context.Context, cfg *models.AdminConfiguration) []sender.AMTestResult {
+	now := sch.clock.Now()
+	alerts := definitions.PostableAlerts{PostableAlerts: []amv2.PostableAlert{{
+		Alert: amv2.Alert{Labels: amv2.LabelSet{
+			prometheusModel.AlertNameLabel: testAlertName,
+			"org_id":                       fmt.Sprint(cfg.OrgID),
+		}},
+		Annotations: amv2.LabelSet{
+			"description": "Test alert sent by Grafana to check the connectivity to this Alertmanager.",
+		},
+		StartsAt: strfmt.DateTime(now),
+		EndsAt:   strfmt.DateTime(now.Add(testAlertDuration)),
+	}}}
+
+	sch.log.Debug("sending test alert", "org", cfg.OrgID, "alertmanagers", len(cfg.Alertmanagers))
+	return sender.SendTestAlerts(ctx, cfg, sch.decryptFn, alerts)
+}
+
+// InvalidAlertmanagersFor returns the Alertmanager(s) for a particular organization that could not be applied and why.
+func (sch *schedule) InvalidAlertmanagersFor(orgID int64) map[string]error {
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+	s, ok := sch.senders[orgID]
+	if !ok {
+		return map[string]error{}
+	}
+
+	return s.InvalidAlertmanagers()
+}
+
+// LastSendResult returns the result of the most recent send to each of the discovered Alertmanager(s) for a particular organization.
+func (sch *schedule) LastSendResult(orgID int64) map[string]sender.SendResult {
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+	s, ok := sch.senders[orgID]
+	if !ok {
+		return map[string]sender.SendResult{}
+	}
+
+	return s.LastSendResults()
+}
+
+// SenderQueueStats returns the number of alerts queued, sent and dropped by the sender of a particular organization.
+// It returns false if the organization has no sender.
+func (sch *schedule) SenderQueueStats(orgID int64) (sender.QueueStats, bool) {
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+	s, ok := sch.senders[orgID]
+	if !ok {
+		return sender.QueueStats{}, false
+	}
+
+	return s.QueueStats(), true
+}
+
+// UpdateAlertRule looks for the active rule evaluation and commands it to update the rule
+func (sch *schedule) UpdateAlertRule(key models.AlertRuleKey) {
+	ruleInfo, err := sch.registry.get(key)
+	if err != nil {
+		return
+	}
+	ruleInfo.update()
+}
+
+// DeleteAlertRule stops evaluation of the rule, deletes it from active rules, and cleans up state cache.
+func (sch *schedule) DeleteAlertRule(key models.AlertRuleKey) {
+	ruleInfo, ok := sch.registry.del(key)
+	if !ok {
+		sch.log.Info("unable to delete alert rule routine information by key", "uid", key.UID, "org_id", key.OrgID)
+		return
+	}
+	// stop rule evaluation
+	ruleInfo.stop()
+
+	sch.sentAlertsMtx.Lock()
+	for _, a := range sch.sentAlerts[key] {
+		sch.forgetSentAlertID(key, a)
+	}
+	delete(sch.sentAlerts, key)
+	sch.sentAlertsMtx.Unlock()
+
+	sch.groupedAlertsMtx.Lock()
+	delete(sch.groupedAlerts, key)
+	sch.groupedAlertsMtx.Unlock()
+}
+
+// acquireDatasources waits until the rule can query its datasources without exceeding their limit of
+// concurrent evaluations, and returns the function releasing them once the rule is evaluated. Datasources
+// are acquired in order of UID, so that rules querying the same ones do not wait for each other forever.
+func (sch *schedule) acquireDatasources(ctx context.Context, rule *models.AlertRule) (func(), error) {
+	var acquired []*semaphore.Weighted
+	release := func() {
+		for _, sem := range acquired {
+			sem.Release(1)
+		}
+	}
+	if sch.datasourceConcurrency == nil {
+		return release, nil
+	}
+
+	uids := make([]string, 0, len(rule.Data))
+	for _, q := range rule.Data {
+		if !expr.IsDataSource(q.DatasourceUID) {
+			uids = append(uids, q.DatasourceUID)
+		}
+	}
+	sort.Strings(uids)
+
+	orgID := fmt.Sprint(rule.OrgID)
+	for i, uid := range uids {
+		if i > 0 && uids[i-1] == uid {
+			continue
+		}
+		sem := sch.datasourceSemaphore(datasourceKey{orgID: rule.OrgID, uid: uid}, sch.datasourceConcurrency(ctx, rule.OrgID, uid))
+		if sem == nil {
+			continue
+		}
+		start := time.Now()
+		if err := sem.Acquire(ctx, 1); err != nil {
+			release()
+			return nil, err
+		}
+		sch.metrics.DatasourceWaitDuration.WithLabelValues(orgID, uid).Observe(time.Since(start).Seconds())
+		acquired = append(acquired, sem)
+	}
+	return release, nil
+}
+
+// datasourceSemaphore returns the semaphore enforcing the limit of concurrent evaluations of the datasource,
+// nil if there is no limit. A new one is created when the limit changes, the evaluations holding the previous
+// one release it as usual.
+func (sch *schedule) datasourceSemaphore(key datasourceKey, limit int) *semaphore.Weighted {
+	sch.datasourceSemaphoresMtx.Lock()
+	defer sch.datasourceSemaphoresMtx.Unlock()
+	if limit <= 0 {
+		delete(sch.datasourceSemaphores, key)
+		return nil
+	}
+	s, ok := sch.datasourceSemaphores[key]
+	if !ok || s.limit != limit {
+		s = &datasourceSemaphore{limit: limit, sem: semaphore.NewWeighted(int64(limit))}
+		sch.datasourceSemaphores[key] = s
+	}
+	return s.sem
+}
+
+// evaluationOffset returns the tick, modulo the frequency of the rule in ticks, on which the rule is evaluated.
+// It is derived from the key so that it is the same across restarts and schedulers.
+func (sch *schedule) evaluationOffset(rule *models.SchedulableAlertRule, frequency int64) int64 {
+	if !sch.evaluationJitter || frequency <= 1 || rule.AlignEvaluation {
+		return 0
+	}
+	key := rule.GetKey()
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d/%s", key.OrgID, key.UID)
+	return int64(h.Sum64() % uint64(frequency))
+}
+
+// membershipSync records that the scheduler is alive and fetches the members alive every heartbeat interval.
+// When the context is done, the scheduler leaves so that the other members take over its rules at once.
+func (sch *schedule) membershipSync(ctx context.Context) error {
+	for {
+		select {
+		case <-time.After(sch.memberHeartbeatInterval):
+			sch.syncMembers(ctx)
+		case <-ctx.Done():
+			leaveCtx, cancel := context.WithTimeout(context.Background(), sch.memberHeartbeatInterval)
+			defer cancel()
+			return sch.memberStore.DeleteSchedulerMember(leaveCtx, sch.memberID)
+		}
+	}
+}
+
+// syncMembers records that the scheduler is alive and fetches the members alive. The members are kept as
+// they are if the database cannot be reached.
+func (sch *schedule) syncMembers(ctx context.Context) {
+	now := sch.clock.Now()
+	if err := sch.memberStore.HeartbeatSchedulerMember(ctx, sch.memberID, now); err != nil {
+		sch.log.Error("unable to record the scheduler heartbeat", "member", sch.memberID, "err", err)
+		return
+	}
+	members, err := sch.memberStore.GetSchedulerMembers(ctx, now.Add(-memberExpiryHeartbeats*sch.memberHeartbeatInterval))
+	if err != nil {
+		sch.log.Error("unable to fetch the scheduler members", "err", err)
+		return
+	}
+	if i := sort.SearchStrings(members, sch.memberID); i == len(members) || members[i] != sch.memberID {
+		members = append(members, sch.memberID)
+		sort.Strings(members)
+	}
+
+	sch.membersMtx.Lock()
+	changed := fmt.Sprint(sch.members) != fmt.Sprint(members)
+	sch.members = members
+	sch.membersMtx.Unlock()
+	if changed {
+		sch.log.Info("scheduler members changed", "member", sch.memberID, "members", members)
+	}
+	sch.metrics.SchedulerMembers.Set(float64(len(members)))
+}
+
+// ownsRule returns whether the scheduler evaluates the rule, always true if the evaluation is not sharded or
+// the members are not known yet. The owner is the member with the highest hash of its ID and the rule key, so
+// that only the rules of the members joining or leaving change owner.
+func (sch *schedule) ownsRule(key models.AlertRuleKey) bool {
+	if sch.memberStore == nil {
+		return true
+	}
+	sch.membersMtx.RLock()
+	defer sch.membersMtx.RUnlock()
+	if len(sch.members) == 0 {
+		return true
+	}
+
+	var owner string
+	var highest uint64
+	for _, member := range sch.members {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%d/%s", member, key.OrgID, key.UID)))
+		if h := binary.BigEndian.Uint64(sum[:8]); owner == "" || h > highest {
+			owner, highest = member, h
+		}
+	}
+	return owner == sch.memberID
+}
+
+// handOffAlertRule stops the routine of a rule another member evaluates now. Unlike a deleted rule, the
+// alerts of the rule are not resolved, and its states are left to the new owner.
+func (sch *schedule) handOffAlertRule(key models.AlertRuleKey) {
+	sch.handedOffMtx.Lock()
+	sch.handedOff[key] = struct{}{}
+	sch.handedOffMtx.Unlock()
+	sch.log.Debug("alert rule handed off to another scheduler member", "uid", key.UID, "org_id", key.OrgID)
+	sch.DeleteAlertRule(key)
+}
+
+// dispatchLeaseSync acquires or renews the leases of the organizations with a sender every lease interval.
+// When the context is done, the scheduler releases its leases so that the other schedulers take over at once.
+func (sch *schedule) dispatchLeaseSync(ctx context.Context) error {
+	for {
+		select {
+		case <-time.After(sch.dispatchLeaseInterval):
+			sch.syncDispatchLeases(ctx)
+		case <-ctx.Done():
+			sch.dispatchLeasesMtx.Lock()
+			sch.dispatchLeases = map[int64]struct{}{}
+			sch.dispatchLeasesMtx.Unlock()
+			releaseCtx, cancel := context.WithTimeout(context.Background(), sch.dispatchLeaseInterval)
+			defer cancel()
+			return sch.dispatchLeaseStore.ReleaseDispatchLeases(releaseCtx, sch.memberID)
+		}
 	}
-
-	return s.Alertmanagers()
 }
 
-// DroppedAlertmanagersFor returns all the dropped Alertmanager(s) for a particular organization.
-func (sch *schedule) DroppedAlertmanagersFor(orgID int64) []*url.URL {
+// syncDispatchLeases acquires or renews the leases of the organizations with a sender. An organization whose
+// lease cannot be renewed because the database cannot be reached is not dispatched anymore, as its lease may
+// expire and be acquired by another scheduler.
+func (sch *schedule) syncDispatchLeases(ctx context.Context) {
 	sch.adminConfigMtx.RLock()
-	defer sch.adminConfigMtx.RUnlock()
-	s, ok := sch.senders[orgID]
-	if !ok {
-		return []*url.URL{}
+	orgIDs := make([]int64, 0, len(sch.senders))
+	for orgID := range sch.senders {
+		orgIDs = append(orgIDs, orgID)
 	}
+	sch.adminConfigMtx.RUnlock()
 
-	return s.DroppedAlertmanagers()
-}
+	leases := make(map[int64]struct{}, len(orgIDs))
+	now := sch.clock.Now()
+	for _, orgID := range orgIDs {
+		acquired, err := sch.dispatchLeaseStore.AcquireDispatchLease(ctx, orgID, sch.memberID, now, dispatchLeaseExpiryRenewals*sch.dispatchLeaseInterval)
+		if err != nil {
+			sch.log.Error("unable to acquire the dispatch lease", "org", orgID, "member", sch.memberID, "err", err)
+			continue
+		}
+		if acquired {
+			leases[orgID] = struct{}{}
+		}
+	}
 
-// UpdateAlertRule looks for the active rule evaluation and commands it to update the rule
-func (sch *schedule) UpdateAlertRule(key models.AlertRuleKey) {
-	ruleInfo, err := sch.registry.get(key)
-	if err != nil {
-		return
+	sch.dispatchLeasesMtx.Lock()
+	prev := sch.dispatchLeases
+	sch.dispatchLeases = leases
+	sch.dispatchLeasesMtx.Unlock()
+
+	for _, orgID := range orgIDs {
+		_, had := prev[orgID]
+		_, has := leases[orgID]
+		switch {
+		case has && !had:
+			sch.log.Info("dispatching the alerts of the organization to its external targets", "org", orgID, "member", sch.memberID)
+		case had && !has:
+			sch.log.Info("another scheduler dispatches the alerts of the organization to its external targets", "org", orgID, "member", sch.memberID)
+		}
+		if has {
+			sch.metrics.DispatchLeader.WithLabelValues(fmt.Sprint(orgID)).Set(1)
+		} else {
+			sch.metrics.DispatchLeader.WithLabelValues(fmt.Sprint(orgID)).Set(0)
+		}
+		delete(prev, orgID)
+	}
+	// The organizations left have no sender anymore.
+	for orgID := range prev {
+		sch.metrics.DispatchLeader.DeleteLabelValues(fmt.Sprint(orgID))
 	}
-	ruleInfo.update()
 }
 
-// DeleteAlertRule stops evaluation of the rule, deletes it from active rules, and cleans up state cache.
-func (sch *schedule) DeleteAlertRule(key models.AlertRuleKey) {
-	ruleInfo, ok := sch.registry.del(key)
-	if !ok {
-		sch.log.Info("unable to delete alert rule routine information by key", "uid", key.UID, "org_id", key.OrgID)
-		return
+// dispatches returns whether the scheduler sends the alerts of the organization to its external targets, always
+// true if the dispatch is not elected.
+func (sch *schedule) dispatches(orgID int64) bool {
+	if sch.dispatchLeaseStore == nil {
+		return true
 	}
-	// stop rule evaluation
-	ruleInfo.stop()
+	sch.dispatchLeasesMtx.RLock()
+	defer sch.dispatchLeasesMtx.RUnlock()
+	_, ok := sch.dispatchLeases[orgID]
+	return ok
+}
+
+// takeHandedOff returns whether the rule was handed off to another member, and forgets it.
+func (sch *schedule) takeHandedOff(key models.AlertRuleKey) bool {
+	sch.handedOffMtx.Lock()
+	defer sch.handedOffMtx.Unlock()
+	_, ok := sch.handedOff[key]
+	delete(sch.handedOff, key)
+	return ok
 }
 
 func (sch *schedule) adminConfigSync(ctx context.Context) error {
@@ -331,15 +2140,29 @@ func (sch *schedule) adminConfigSync(ctx context.Context) error {
 		case <-time.After(sch.adminConfigPollInterval):
 			if err := sch.SyncAndApplyConfigFromDatabase(); err != nil {
 				sch.log.Error("unable to sync admin configuration", "err", err)
+			} else {
+				sch.sendHeartbeat(ctx)
+			}
+		case <-sch.adminConfigChanged:
+			sch.log.Debug("admin configuration changed, syncing")
+			if err := sch.SyncAndApplyConfigFromDatabase(); err != nil {
+				sch.log.Error("unable to sync admin configuration", "err", err)
+			} else {
+				sch.sendHeartbeat(ctx)
 			}
 		case <-ctx.Done():
 			// Stop sending alerts to all external Alertmanager(s).
 			sch.adminConfigMtx.Lock()
+			senders := make(map[int64]*sender.Sender, len(sch.senders))
 			for orgID, s := range sch.senders {
 				delete(sch.senders, orgID) // delete before we stop to make sure we don't accept any more alerts.
-				s.Stop()
+				senders[orgID] = s
 			}
 			sch.adminConfigMtx.Unlock()
+			for orgID := range senders {
+				sch.senderLock(orgID).Lock()
+			}
+			sch.stopSenders(senders)
 
 			return nil
 		}
@@ -357,6 +2180,9 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 			// in wall clock time.
 			start := time.Now().Round(0)
 			sch.metrics.BehindSeconds.Set(start.Sub(tick).Seconds())
+			sch.updateDeliveryMetrics()
+			sch.updateFallbacks()
+			sch.refreshMaintenanceWindows(ctx, tick)
 
 			tickNum := tick.Unix() / int64(sch.baseInterval.Seconds())
 			disabledOrgs := make([]int64, 0, len(sch.disabledOrgs))
@@ -366,6 +2192,7 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 
 			alertRules := sch.getAlertRules(ctx, disabledOrgs)
 			sch.log.Debug("alert rules fetched", "count", len(alertRules), "disabled_orgs", disabledOrgs)
+			sch.updateRuleDependencies(alertRules)
 
 			// registeredDefinitions is a map used for finding deleted alert rules
 			// initially it is assigned to all known alert rules from the previous cycle
@@ -380,10 +2207,32 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 			}
 
 			readyToRun := make([]readyToRunItem, 0)
+			notOwned := make(map[models.AlertRuleKey]struct{})
+			paused := make(map[models.AlertRuleKey]struct{})
 			for _, item := range alertRules {
 				key := item.GetKey()
 				itemVersion := item.Version
+				if !sch.ownsRule(key) {
+					notOwned[key] = struct{}{}
+					if _, ok := registeredDefinitions[key]; !ok {
+						// The states of the rule are the ones of its owner, kept up to date in the database.
+						sch.stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+					}
+					continue
+				}
+				if item.IsPaused {
+					paused[key] = struct{}{}
+					if _, ok := registeredDefinitions[key]; ok {
+						sch.pauseAlertRule(key)
+						delete(registeredDefinitions, key)
+					}
+					continue
+				}
 				ruleInfo, newRoutine := sch.registry.getOrCreateInfo(ctx, key)
+				if newRoutine && sch.memberStore != nil {
+					// The rule may have been evaluated by another member until now.
+					sch.stateManager.WarmRule(ctx, key.OrgID, key.UID)
+				}
 
 				// enforce minimum evaluation interval
 				if item.IntervalSeconds < int64(sch.minRuleInterval.Seconds()) {
@@ -407,7 +2256,7 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 				}
 
 				itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
-				if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
+				if item.IntervalSeconds != 0 && tickNum%itemFrequency == sch.evaluationOffset(item, itemFrequency) {
 					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo, version: itemVersion})
 				}
 
@@ -431,10 +2280,18 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 				})
 			}
 
-			// unregister and stop routines of the deleted alert rules
+			// unregister and stop routines of the deleted alert rules, and of the ones another member evaluates now
 			for key := range registeredDefinitions {
+				if _, ok := notOwned[key]; ok {
+					sch.handOffAlertRule(key)
+					continue
+				}
 				sch.DeleteAlertRule(key)
 			}
+			sch.updatePausedRules(paused)
+			if sch.memberStore != nil {
+				sch.metrics.RulesOwned.Set(float64(len(alertRules) - len(notOwned)))
+			}
 
 			sch.metrics.SchedulePeriodicDuration.Observe(time.Since(start).Seconds())
 		case <-ctx.Done():
@@ -463,55 +2320,30 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 	evalTotal := sch.metrics.EvalTotal.WithLabelValues(orgID)
 	evalDuration := sch.metrics.EvalDuration.WithLabelValues(orgID)
 	evalTotalFailures := sch.metrics.EvalFailures.WithLabelValues(orgID)
+	evalSkipped := sch.metrics.EvalSkipped.WithLabelValues(orgID)
+	evalSuppressed := sch.metrics.EvalSuppressed.WithLabelValues(orgID)
 
 	notify := func(alerts definitions.PostableAlerts, logger log.Logger) {
-		if len(alerts.PostableAlerts) == 0 {
-			logger.Debug("no alerts to put in the notifier or to send to external Alertmanager(s)")
-			return
-		}
-
-		// Send alerts to local notifier if they need to be handled internally
-		// or if no external AMs have been discovered yet.
-		var localNotifierExist, externalNotifierExist bool
-		if sch.sendAlertsTo[key.OrgID] == models.ExternalAlertmanagers && len(sch.AlertmanagersFor(key.OrgID)) > 0 {
-			logger.Debug("no alerts to put in the notifier")
-		} else {
-			logger.Debug("sending alerts to local notifier", "count", len(alerts.PostableAlerts), "alerts", alerts.PostableAlerts)
-			n, err := sch.multiOrgNotifier.AlertmanagerFor(key.OrgID)
-			if err == nil {
-				localNotifierExist = true
-				if err := n.PutAlerts(alerts); err != nil {
-					logger.Error("failed to put alerts in the local notifier", "count", len(alerts.PostableAlerts), "err", err)
-				}
-			} else {
-				if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
-					logger.Debug("local notifier was not found")
-				} else {
-					logger.Error("local notifier is not available", "err", err)
-				}
-			}
-		}
-
-		// Send alerts to external Alertmanager(s) if we have a sender for this organization
-		// and alerts are not being handled just internally.
-		sch.adminConfigMtx.RLock()
-		defer sch.adminConfigMtx.RUnlock()
-		s, ok := sch.senders[key.OrgID]
-		if ok && sch.sendAlertsTo[key.OrgID] != models.InternalAlertmanager {
-			logger.Debug("sending alerts to external notifier", "count", len(alerts.PostableAlerts), "alerts", alerts.PostableAlerts)
-			s.SendAlerts(alerts)
-			externalNotifierExist = true
-		}
-
-		if !localNotifierExist && !externalNotifierExist {
+		err := sch.notify(key, alerts, logger)
+		if errors.Is(err, errNoNotifier) {
 			logger.Error("no external or internal notifier - alerts not delivered!", "count", len(alerts.PostableAlerts))
+			sch.saveDeadLetterAlerts(key, alerts, logger)
+		} else if err != nil {
+			logger.Error("alerts not delivered to all notifiers", "count", len(alerts.PostableAlerts), "err", err)
 		}
 	}
 
 	clearState := func() {
 		states := sch.stateManager.GetStatesForRuleUID(key.OrgID, key.UID)
-		expiredAlerts := FromAlertsStateToStoppedAlert(states, sch.appURL, sch.clock)
+		expiredAlerts := FromAlertsStateToStoppedAlert(states, sch.appURL, sch.clock, sch.resolvedAlertsPolicy(key))
 		sch.stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+		expiredAlerts = sch.dropOldResolvedAlerts(key.OrgID, expiredAlerts, logger)
+		notify(expiredAlerts, logger)
+	}
+
+	pauseState := func() {
+		expiredAlerts := sch.pauseStates(key)
+		expiredAlerts = sch.dropOldResolvedAlerts(key.OrgID, expiredAlerts, logger)
 		notify(expiredAlerts, logger)
 	}
 
@@ -528,16 +2360,34 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 		return q.Result, nil
 	}
 
+	// resultsErr is the failure of the last evaluation whose results are all errors, for the circuit breaker.
+	var resultsErr error
 	evaluate := func(ctx context.Context, r *models.AlertRule, attempt int64, e *evaluation) error {
 		logger := logger.New("version", r.Version, "attempt", attempt, "now", e.scheduledAt)
+		if w, ok := sch.skipEvaluation(r, e.scheduledAt); ok {
+			logger.Debug("evaluation skipped, the rule is in a maintenance window", "window_uid", w.UID)
+			evalSuppressed.Inc()
+			return nil
+		}
+		release, err := sch.acquireDatasources(ctx, r)
+		if err != nil {
+			return err
+		}
 		start := sch.clock.Now()
 
-		condition := models.Condition{
-			Condition: r.Condition,
-			OrgID:     r.OrgID,
-			Data:      r.Data,
+		var results eval.Results
+		if r.IsRecording() {
+			err = sch.record(ctx, r, e.scheduledAt)
+		} else {
+			condition := models.Condition{
+				Condition: r.Condition,
+				OrgID:     r.OrgID,
+				Data:      r.Data,
+				Timeout:   r.EvaluationTimeout,
+			}
+			results, err = sch.evaluator.ConditionEval(&condition, e.scheduledAt, sch.expressionService)
 		}
-		results, err := sch.evaluator.ConditionEval(&condition, e.scheduledAt, sch.expressionService)
+		release()
 		dur := sch.clock.Now().Sub(start)
 		evalTotal.Inc()
 		evalDuration.Observe(dur.Seconds())
@@ -547,7 +2397,12 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 			logger.Error("failed to evaluate alert rule", "duration", dur, "err", err)
 			return err
 		}
+		if r.IsRecording() {
+			logger.Debug("recording rule evaluated", "duration", dur)
+			return nil
+		}
 		logger.Debug("alert rule evaluated", "results", results, "duration", dur)
+		resultsErr = resultsError(results)
 
 		processedStates := sch.stateManager.ProcessEvalResults(ctx, r, results)
 		sch.saveAlertStates(ctx, processedStates)
@@ -571,6 +2426,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 
 	evalRunning := false
 	var currentRule *models.AlertRule
+	var circuit ruleCircuit
 	defer sch.stopApplied(key)
 	for {
 		select {
@@ -606,6 +2462,13 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 					sch.evalApplied(key, ctx.scheduledAt)
 				}()
 
+				if circuit.skip(ctx.scheduledAt) {
+					logger.Debug("evaluation skipped, the rule failed to evaluate too many times in a row", "until", circuit.skipUntil)
+					evalSkipped.Inc()
+					return
+				}
+
+				resultsErr = nil
 				err := retryIfError(func(attempt int64) error {
 					// fetch latest alert rule version
 					if currentRule == nil || currentRule.Version < ctx.version {
@@ -620,16 +2483,559 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 				})
 				if err != nil {
 					logger.Error("evaluation failed after all retries", "err", err)
+				} else {
+					err = resultsErr
+				}
+
+				var alert *amv2.PostableAlert
+				if err != nil {
+					interval := sch.baseInterval
+					if currentRule != nil {
+						interval = time.Duration(currentRule.IntervalSeconds) * time.Second
+					}
+					alert = sch.evaluationFailed(key, &circuit, interval, ctx.scheduledAt, err)
+					if alert != nil {
+						logger.Warn("rule failed to evaluate too many times in a row, it is backed off", "failures", circuit.failures, "until", circuit.skipUntil)
+					}
+				} else {
+					alert = sch.evaluationSucceeded(key, &circuit, ctx.scheduledAt)
+				}
+				if alert != nil {
+					notify(definitions.PostableAlerts{PostableAlerts: []amv2.PostableAlert{*alert}}, logger)
 				}
 			}()
 		case <-grafanaCtx.Done():
-			clearState()
+			if sch.takeHandedOff(key) {
+				sch.stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+			} else if sch.takePausing(key) {
+				pauseState()
+			} else {
+				clearState()
+			}
 			logger.Debug("stopping alert rule routine")
 			return nil
 		}
 	}
 }
 
+// record evaluates the queries and expressions of the recording rule and writes the samples of its result to
+// the target datasource.
+func (sch *schedule) record(ctx context.Context, r *models.AlertRule, now time.Time) error {
+	if sch.recordingWriter == nil {
+		return errNoRecordingWriter
+	}
+	resp, err := sch.evaluator.QueriesAndExpressionsEval(r.OrgID, r.Data, now, sch.expressionService)
+	if err != nil {
+		return err
+	}
+	samples, err := recordedSamples(r, resp, now)
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+	return sch.recordingWriter.Write(ctx, r.OrgID, r.Record.TargetDatasourceUID, samples)
+}
+
+// recordedSamples returns the samples of the result of the recording rule, one per number of the query or
+// expression it records. The labels of the rule take precedence over the ones of the numbers, and numbers
+// without value are not recorded.
+func recordedSamples(r *models.AlertRule, resp *backend.QueryDataResponse, now time.Time) ([]writer.Sample, error) {
+	res, ok := resp.Responses[r.Record.From]
+	if !ok {
+		return nil, fmt.Errorf("no result for %s", r.Record.From)
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	samples := make([]writer.Sample, 0, len(res.Frames))
+	for _, frame := range res.Frames {
+		if len(frame.Fields) != 1 || frame.Fields[0].Type() != data.FieldTypeNullableFloat64 || frame.Fields[0].Len() != 1 {
+			return nil, fmt.Errorf("the result of %s must be made of numbers, reduce it with an expression", r.Record.From)
+		}
+		v := frame.At(0, 0).(*float64) // type checked above
+		if v == nil {
+			continue
+		}
+		labels := make(data.Labels, len(frame.Fields[0].Labels)+len(r.Labels)+1)
+		for name, value := range frame.Fields[0].Labels {
+			labels[name] = value
+		}
+		for name, value := range r.Labels {
+			labels[name] = value
+		}
+		labels["__name__"] = r.Record.Metric
+		samples = append(samples, writer.Sample{Labels: labels, Value: *v, Timestamp: now})
+	}
+	return samples, nil
+}
+
+// splitByExternalLabelMatcher splits the alerts into the ones that can be forwarded to external Alertmanager(s)
+// and the ones that must be handled internally, according to the external label matcher of the organization.
+func (sch *schedule) splitByExternalLabelMatcher(orgID int64, alerts definitions.PostableAlerts) (external definitions.PostableAlerts, internal definitions.PostableAlerts) {
+	m, ok := sch.externalLabelMatchers[orgID]
+	if !ok {
+		if sch.defaultExternalLabelMatcher == nil {
+			return alerts, definitions.PostableAlerts{}
+		}
+		m = *sch.defaultExternalLabelMatcher
+	}
+
+	label := m.Label
+	if label == "" {
+		label = defaultExternalMatcherLabel
+	}
+
+	values := make(map[string]struct{}, len(m.Values))
+	for _, v := range m.Values {
+		values[v] = struct{}{}
+	}
+
+	for _, a := range alerts.PostableAlerts {
+		if v, ok := a.Labels[label]; ok {
+			if _, ok := values[v]; ok {
+				external.PostableAlerts = append(external.PostableAlerts, a)
+				continue
+			}
+		}
+		internal.PostableAlerts = append(internal.PostableAlerts, a)
+	}
+
+	return external, internal
+}
+
+// applyRequiredLabels applies the policy of the organization to the alerts missing required labels. It returns
+// the alerts to send to external Alertmanager(s), the ones to handle internally instead, and the number of
+// alerts dropped.
+func (sch *schedule) applyRequiredLabels(orgID int64, alerts definitions.PostableAlerts) (external definitions.PostableAlerts, internal definitions.PostableAlerts, dropped int) {
+	required, ok := sch.requiredLabels[orgID]
+	if !ok {
+		required = sch.defaultRequiredLabels
+	}
+	if required.Policy == MissingLabelsSend {
+		return alerts, definitions.PostableAlerts{}, 0
+	}
+
+	for _, a := range alerts.PostableAlerts {
+		var missing []string
+		for name := range required.Labels {
+			if _, ok := a.Labels[name]; !ok {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) == 0 {
+			external.PostableAlerts = append(external.PostableAlerts, a)
+			continue
+		}
+
+		switch required.Policy {
+		case MissingLabelsDrop:
+			dropped++
+		case MissingLabelsInternal:
+			internal.PostableAlerts = append(internal.PostableAlerts, a)
+		case MissingLabelsDefault:
+			// The labels are shared with the alerts sent to the local notifier, copy them.
+			labels := make(amv2.LabelSet, len(a.Labels)+len(missing))
+			for k, v := range a.Labels {
+				labels[k] = v
+			}
+			for _, name := range missing {
+				labels[name] = required.Labels[name]
+			}
+			a.Labels = labels
+			external.PostableAlerts = append(external.PostableAlerts, a)
+		}
+	}
+
+	return external, internal, dropped
+}
+
+// AdminConfigurationChanged syncs the admin configuration as soon as possible instead of waiting for the
+// next poll. Changes notified while a sync is pending are applied by that sync.
+func (sch *schedule) AdminConfigurationChanged() {
+	select {
+	case sch.adminConfigChanged <- struct{}{}:
+	default:
+	}
+}
+
+// sendAlertsToFor returns the Alertmanagers choice for the alerts of the rule. Rules of the admin configuration
+// of the organization that are sent externally use external Alertmanager(s) only, the others use the choice
+// of the organization.
+func (sch *schedule) sendAlertsToFor(key models.AlertRuleKey) models.AlertmanagersChoice {
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+	return sch.routingModeFor(key)
+}
+
+// routingModeFor is sendAlertsToFor for callers holding adminConfigMtx.
+func (sch *schedule) routingModeFor(key models.AlertRuleKey) models.AlertmanagersChoice {
+	if _, ok := sch.externalRules[key.OrgID][key.UID]; ok {
+		return models.ExternalAlertmanagers
+	}
+	return sch.sendAlertsTo[key.OrgID]
+}
+
+// handledExternally returns true if alerts of the organization with this Alertmanagers choice are sent to
+// external Alertmanager(s) only and some of them have been discovered, unless they fall back to the local notifier.
+func (sch *schedule) handledExternally(orgID int64, sendAlertsTo models.AlertmanagersChoice) bool {
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+	return sendAlertsTo == models.ExternalAlertmanagers && sch.hasExternalTargets(orgID) && !sch.fallsBackToLocal(orgID, sendAlertsTo)
+}
+
+// hasExternalTargets returns true if the sender of the organization discovered external Alertmanager(s) or sends
+// alerts directly to PagerDuty, webhooks, SNS or SQS. It must be called while holding adminConfigMtx.
+func (sch *schedule) hasExternalTargets(orgID int64) bool {
+	s, ok := sch.senders[orgID]
+	return ok && (len(s.Alertmanagers()) > 0 || s.SendsDirectly())
+}
+
+// fallsBackToLocal returns true if alerts of the organization with this Alertmanagers choice are sent to
+// external Alertmanager(s) only but they are unhealthy, and must be sent to the local notifier as well.
+func (sch *schedule) fallsBackToLocal(orgID int64, sendAlertsTo models.AlertmanagersChoice) bool {
+	return sch.localFallback && sendAlertsTo == models.ExternalAlertmanagers && sch.IsUnhealthy(orgID)
+}
+
+// externalRouting is what is done with the alerts of a rule that can be sent to external Alertmanager(s).
+type externalRouting int
+
+const (
+	// externalRoutingNoSender is used when the organization has no sender.
+	externalRoutingNoSender externalRouting = iota
+	// externalRoutingInternal is used when the organization handles its alerts internally only.
+	externalRoutingInternal
+	// externalRoutingPaused is used when the external delivery is paused for all organizations.
+	externalRoutingPaused
+	// externalRoutingOrgPaused is used when the external delivery of the organization is paused.
+	externalRoutingOrgPaused
+	// externalRoutingNotDispatched is used when another scheduler dispatches the alerts of the organization.
+	externalRoutingNotDispatched
+	// externalRoutingSend is used when the alerts are sent to the sender of the organization.
+	externalRoutingSend
+)
+
+// alertRouting is where the alerts of a rule are delivered, as decided by routeAlerts.
+type alertRouting struct {
+	sendAlertsTo models.AlertmanagersChoice
+	// paused and orgPaused are true if the external delivery is paused for all the organizations or for this
+	// one, its alerts are all sent to the local notifier.
+	paused    bool
+	orgPaused bool
+	// handledExternally is true if only the alerts that must be handled internally are sent to the local
+	// notifier, fallsBack if all of them are because the external Alertmanager(s) are unhealthy.
+	handledExternally bool
+	fallsBack         bool
+	external          externalRouting
+}
+
+// routeAlerts decides where the alerts of the rule are delivered. It is shared by notify and ExplainRouting so
+// that the explanations follow the alerts actually delivered.
+func (sch *schedule) routeAlerts(key models.AlertRuleKey) alertRouting {
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+
+	r := alertRouting{sendAlertsTo: sch.routingModeFor(key)}
+	if r.sendAlertsTo != models.InternalAlertmanager {
+		r.paused = sch.ExternalDeliveryPaused()
+		r.orgPaused, _ = sch.ExternalDeliveryPausedFor(key.OrgID)
+	}
+	r.fallsBack = sch.fallsBackToLocal(key.OrgID, r.sendAlertsTo)
+	r.handledExternally = !r.paused && !r.orgPaused && r.sendAlertsTo == models.ExternalAlertmanagers && sch.hasExternalTargets(key.OrgID) && !r.fallsBack
+	r.fallsBack = r.fallsBack && !r.paused && !r.orgPaused
+
+	_, ok := sch.senders[key.OrgID]
+	switch {
+	case !ok:
+		r.external = externalRoutingNoSender
+	case r.sendAlertsTo == models.InternalAlertmanager:
+		r.external = externalRoutingInternal
+	case sch.ExternalDeliveryPaused():
+		r.external = externalRoutingPaused
+	case r.orgPaused:
+		r.external = externalRoutingOrgPaused
+	case !sch.dispatches(key.OrgID):
+		r.external = externalRoutingNotDispatched
+	default:
+		r.external = externalRoutingSend
+	}
+	return r
+}
+
+// RoutingExplanation describes where an alert would be delivered, and why.
+type RoutingExplanation struct {
+	// Local is true if the alert would be handled by the internal Alertmanager of the organization.
+	Local bool
+	// External are the discovered external Alertmanager(s) the alert would be sent to.
+	External []string
+	// Reasons explain the decisions, in the order they were taken.
+	Reasons []string
+}
+
+// ExplainRouting returns where an alert with the given labels would be delivered for the organization,
+// following the same decisions as the alerts produced by the evaluation of its rules. The rule is the one of
+// the rule UID label, if any. Nothing is sent.
+func (sch *schedule) ExplainRouting(orgID int64, labels map[string]string) RoutingExplanation {
+	var exp RoutingExplanation
+	key := models.AlertRuleKey{OrgID: orgID, UID: labels[models.RuleUIDLabel]}
+	routing := sch.routeAlerts(key)
+
+	alerts := definitions.PostableAlerts{PostableAlerts: []amv2.PostableAlert{{Alert: amv2.Alert{Labels: labels}}}}
+	externalAlerts, _ := sch.splitByExternalLabelMatcher(orgID, alerts)
+	matched := len(externalAlerts.PostableAlerts) > 0
+	if !matched {
+		exp.Reasons = append(exp.Reasons, "the alert does not match the external label matcher of the organization")
+	}
+	externalAlerts, _, dropped := sch.applyRequiredLabels(orgID, externalAlerts)
+	forwardable := len(externalAlerts.PostableAlerts) > 0
+	if dropped > 0 {
+		exp.Reasons = append(exp.Reasons, "the alert misses required labels and is dropped")
+	} else if matched && !forwardable {
+		exp.Reasons = append(exp.Reasons, "the alert misses required labels and is handled internally")
+	}
+
+	switch {
+	case routing.paused:
+		exp.Local = true
+		exp.Reasons = append(exp.Reasons, "the external delivery is paused, the alert is handled internally")
+	case routing.orgPaused:
+		exp.Local = true
+		exp.Reasons = append(exp.Reasons, "the external delivery of the organization is paused, the alert is handled internally")
+	case routing.fallsBack:
+		exp.Local = true
+		exp.Reasons = append(exp.Reasons, "the external Alertmanager(s) are unhealthy, the alert falls back to the internal Alertmanager")
+	case !routing.handledExternally:
+		exp.Local = true
+		exp.Reasons = append(exp.Reasons, "the organization handles alerts internally or has no discovered external Alertmanager")
+	case !forwardable && dropped == 0:
+		exp.Local = true
+		exp.Reasons = append(exp.Reasons, "the alert is handled internally")
+	default:
+		exp.Reasons = append(exp.Reasons, "the organization sends alerts to external Alertmanager(s) only")
+	}
+
+	if exp.Local {
+		if _, err := sch.multiOrgNotifier.AlertmanagerFor(orgID); err != nil {
+			exp.Local = false
+			exp.Reasons = append(exp.Reasons, fmt.Sprintf("the internal Alertmanager is not available: %s", err))
+		}
+	}
+
+	switch routing.external {
+	case externalRoutingNoSender:
+		exp.Reasons = append(exp.Reasons, "the organization has no external Alertmanager configured")
+	case externalRoutingInternal:
+		exp.Reasons = append(exp.Reasons, "the organization does not send alerts to external Alertmanager(s)")
+	case externalRoutingPaused:
+		exp.Reasons = append(exp.Reasons, "the external delivery is paused, alerts are not sent to external Alertmanager(s)")
+	case externalRoutingOrgPaused:
+		exp.Reasons = append(exp.Reasons, "the external delivery of the organization is paused, alerts are not sent to external Alertmanager(s)")
+	case externalRoutingNotDispatched:
+		exp.Reasons = append(exp.Reasons, "another Grafana instance dispatches the alerts of the organization to external Alertmanager(s)")
+	case externalRoutingSend:
+		if !forwardable {
+			break
+		}
+		sch.adminConfigMtx.RLock()
+		mt, ok := sch.muteTimingsFor(key)
+		sch.adminConfigMtx.RUnlock()
+		if ok && mt.contains(sch.clock.Now()) {
+			exp.Reasons = append(exp.Reasons, "the rule is in a mute timing, its firing alerts are not sent to external Alertmanager(s)")
+			break
+		}
+		for _, u := range sch.AlertmanagersFor(orgID) {
+			exp.External = append(exp.External, u.String())
+		}
+		exp.Reasons = append(exp.Reasons, fmt.Sprintf("the alert is sent to the %d discovered external Alertmanager(s)", len(exp.External)))
+	}
+
+	return exp
+}
+
+// notify sends the alerts of a rule to the local notifier and/or the external Alertmanager(s) of its organization.
+// It returns errNoNotifier if neither of them is available, and errNoLocalNotifier if the local notifier is
+// missing and the scheduler is configured to fail in that case.
+func (sch *schedule) notify(key models.AlertRuleKey, alerts definitions.PostableAlerts, logger log.Logger) (err error) {
+	if len(alerts.PostableAlerts) == 0 {
+		logger.Debug("no alerts to put in the notifier or to send to external Alertmanager(s)")
+		return nil
+	}
+
+	var routingMode models.AlertmanagersChoice
+	var localDelivered, externalDelivered bool
+	if sch.routingDecisionLogs {
+		defer func() {
+			sch.logRoutingDecision(key, alerts, routingMode, localDelivered, externalDelivered, err)
+		}()
+	}
+	var localDispatched, externalDispatched []amv2.PostableAlert
+	if sch.dispatchSink != nil {
+		defer func() {
+			if len(localDispatched) == 0 && len(externalDispatched) == 0 {
+				return
+			}
+			sch.dispatchSink(DispatchEvent{
+				OrgID:     key.OrgID,
+				RuleUID:   key.UID,
+				Timestamp: sch.clock.Now(),
+				Local:     localDispatched,
+				External:  externalDispatched,
+			})
+		}()
+	}
+
+	sch.recordDeliveryAttempt(key.OrgID)
+
+	// The quota applies before the alerts are enriched, which can be costly for rules with many series.
+	alerts = sch.applyAlertQuota(key, alerts, logger)
+	if sch.enrichers != nil {
+		sch.enrichers.enrich(context.Background(), key, alerts.PostableAlerts, logger)
+	}
+	alerts = sch.inhibitAlerts(key, alerts, logger)
+	alerts = sch.suppressAlerts(key, alerts, logger)
+
+	// Alerts that must not be forwarded to external Alertmanager(s) are handled internally only.
+	externalAlerts, internalAlerts := sch.splitByExternalLabelMatcher(key.OrgID, alerts)
+	externalAlerts, missingLabelsAlerts, dropped := sch.applyRequiredLabels(key.OrgID, externalAlerts)
+	internalAlerts.PostableAlerts = append(internalAlerts.PostableAlerts, missingLabelsAlerts.PostableAlerts...)
+	if dropped > 0 {
+		logger.Debug("alerts missing required labels are not sent to external Alertmanager(s)", "count", dropped)
+		sch.metrics.MissingLabelsAlertsDropped.WithLabelValues(fmt.Sprint(key.OrgID)).Add(float64(dropped))
+	}
+
+	// Send alerts to local notifier if they need to be handled internally
+	// or if no external AMs have been discovered yet.
+	routing := sch.routeAlerts(key)
+	sendAlertsTo := routing.sendAlertsTo
+	localAlerts := alerts
+	if routing.paused {
+		logger.Debug("external delivery is paused, alerts are only sent to the local notifier")
+	} else if routing.orgPaused {
+		logger.Debug("external delivery of the organization is paused, alerts are only sent to the local notifier")
+	} else if routing.handledExternally {
+		localAlerts = internalAlerts
+	} else if routing.fallsBack {
+		logger.Warn("external alertmanagers are unhealthy, falling back to local notifier", "count", len(externalAlerts.PostableAlerts))
+		sch.metrics.LocalFallbackAlerts.WithLabelValues(fmt.Sprint(key.OrgID)).Add(float64(len(externalAlerts.PostableAlerts)))
+	}
+
+	var localNotifierExist, externalNotifierExist, localNotifierMissing bool
+	if len(localAlerts.PostableAlerts) == 0 {
+		logger.Debug("no alerts to put in the notifier")
+	} else {
+		logger.Debug("sending alerts to local notifier", "count", len(localAlerts.PostableAlerts), "alerts", localAlerts.PostableAlerts)
+		n, err := sch.multiOrgNotifier.AlertmanagerFor(key.OrgID)
+		if err == nil {
+			localNotifierExist = true
+			if err := n.PutAlerts(localAlerts); err != nil {
+				logger.Error("failed to put alerts in the local notifier", "count", len(localAlerts.PostableAlerts), "err", err)
+			} else {
+				localDelivered = true
+				localDispatched = localAlerts.PostableAlerts
+				sch.recordDelivery(key.OrgID)
+			}
+		} else {
+			if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+				localNotifierMissing = sendAlertsTo == models.AllAlertmanagers
+				if localNotifierMissing && sch.missingLocalNotifier != MissingLocalNotifierIgnore {
+					logger.Warn("local notifier was not found, alerts are only sent to external Alertmanager(s)")
+				} else {
+					logger.Debug("local notifier was not found")
+				}
+			} else {
+				logger.Error("local notifier is not available", "err", err)
+			}
+		}
+	}
+
+	// Send alerts to external Alertmanager(s) if we have a sender for this organization
+	// and alerts are not being handled just internally.
+	sch.adminConfigMtx.RLock()
+	defer sch.adminConfigMtx.RUnlock()
+	routingMode = sendAlertsTo
+	// The sender can have been stopped since the alerts were routed.
+	s, ok := sch.senders[key.OrgID]
+	if routing.external == externalRoutingPaused {
+		// The alerts are sent to the local notifier instead, they are not delivered if there is none.
+		logger.Debug("external delivery is paused, alerts are not sent to external notifier", "count", len(externalAlerts.PostableAlerts))
+	} else if routing.external == externalRoutingNotDispatched {
+		logger.Debug("another scheduler dispatches the alerts of the organization, alerts are not sent to external notifier", "count", len(externalAlerts.PostableAlerts))
+		externalNotifierExist = true
+	} else if ok && routing.external == externalRoutingSend {
+		externalAlerts = sch.muteExternalAlerts(key, externalAlerts, logger)
+		externalAlerts = sch.groupExternalAlerts(key, externalAlerts, logger)
+		externalAlerts = sch.dedupExternalAlerts(key, externalAlerts, logger)
+		externalAlerts = sch.rateLimitExternalAlerts(key, externalAlerts, logger)
+		sch.recordExternalAlerts(key, externalAlerts)
+		logger.Debug("sending alerts to external notifier", "count", len(externalAlerts.PostableAlerts), "alerts", externalAlerts.PostableAlerts)
+		s.SendAlerts(externalAlerts)
+		sch.captureSend(key.OrgID, externalAlerts)
+		externalNotifierExist = true
+		externalDelivered = len(externalAlerts.PostableAlerts) > 0
+		externalDispatched = externalAlerts.PostableAlerts
+	}
+
+	if !localNotifierExist && !externalNotifierExist {
+		sch.metrics.AlertsNotDelivered.WithLabelValues(fmt.Sprint(key.OrgID)).Add(float64(len(alerts.PostableAlerts)))
+		return errNoNotifier
+	}
+
+	if localNotifierMissing && sch.missingLocalNotifier == MissingLocalNotifierError {
+		return errNoLocalNotifier
+	}
+
+	return nil
+}
+
+// labelsToModel converts the labels of an alert to the labels of the Prometheus model.
+func labelsToModel(ls amv2.LabelSet) prometheusModel.LabelSet {
+	res := make(prometheusModel.LabelSet, len(ls))
+	for k, v := range ls {
+		res[prometheusModel.LabelName(k)] = prometheusModel.LabelValue(v)
+	}
+	return res
+}
+
+// logRoutingDecision logs how a batch of alerts of a rule was routed to the notifiers. The keys of the entry
+// are the same for every batch so that it can be parsed reliably.
+func (sch *schedule) logRoutingDecision(key models.AlertRuleKey, alerts definitions.PostableAlerts, routingMode models.AlertmanagersChoice, localDelivered, externalDelivered bool, err error) {
+	now := sch.clock.Now()
+	resolved := 0
+	for _, a := range alerts.PostableAlerts {
+		if !time.Time(a.EndsAt).IsZero() && !time.Time(a.EndsAt).After(now) {
+			resolved++
+		}
+	}
+
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+
+	sch.log.Info("routing decision",
+		"org", key.OrgID,
+		"rule_uid", key.UID,
+		"total", len(alerts.PostableAlerts),
+		"firing", len(alerts.PostableAlerts)-resolved,
+		"resolved", resolved,
+		"routing_mode", routingMode.String(),
+		"local_delivered", localDelivered,
+		"external_delivered", externalDelivered,
+		"error", errMsg)
+}
+
+// Replay sends the alerts through the same routing as the alerts produced by the evaluation of the rule,
+// as if the rule had produced them. It returns an error if the alerts could not be delivered.
+func (sch *schedule) Replay(key models.AlertRuleKey, alerts definitions.PostableAlerts) error {
+	if _, ok := sch.disabledOrgs[key.OrgID]; ok {
+		return fmt.Errorf("organization %d is disabled", key.OrgID)
+	}
+
+	return sch.notify(key, alerts, sch.log.New("uid", key.UID, "org", key.OrgID, "replay", true))
+}
+
 func (sch *schedule) saveAlertStates(ctx context.Context, states []*state.State) {
 	sch.log.Debug("saving alert states", "count", len(states))
 	for _, s := range states {
@@ -8,6 +8,8 @@ import (
 	models "github.com/grafana/grafana/pkg/services/ngalert/models"
 	mock "github.com/stretchr/testify/mock"
 
+	sender "github.com/grafana/grafana/pkg/services/ngalert/sender"
+
 	time "time"
 
 	url "net/url"
@@ -18,6 +20,11 @@ type FakeScheduleService struct {
 	mock.Mock
 }
 
+// AdminConfigurationChanged provides a mock function with given fields:
+func (_m *FakeScheduleService) AdminConfigurationChanged() {
+	_m.Called()
+}
+
 // AlertmanagersFor provides a mock function with given fields: orgID
 func (_m *FakeScheduleService) AlertmanagersFor(orgID int64) []*url.URL {
 	ret := _m.Called(orgID)
@@ -34,11 +41,48 @@ func (_m *FakeScheduleService) AlertmanagersFor(orgID int64) []*url.URL {
 	return r0
 }
 
+// DeadLetterAlerts provides a mock function with given fields: ctx, orgID
+func (_m *FakeScheduleService) DeadLetterAlerts(ctx context.Context, orgID int64) ([]*models.DeadLetterAlert, error) {
+	ret := _m.Called(ctx, orgID)
+
+	var r0 []*models.DeadLetterAlert
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*models.DeadLetterAlert); ok {
+		r0 = rf(ctx, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.DeadLetterAlert)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteAlertRule provides a mock function with given fields: key
 func (_m *FakeScheduleService) DeleteAlertRule(key models.AlertRuleKey) {
 	_m.Called(key)
 }
 
+// DeliveryHealth provides a mock function with given fields:
+func (_m *FakeScheduleService) DeliveryHealth() DeliveryHealth {
+	ret := _m.Called()
+
+	var r0 DeliveryHealth
+	if rf, ok := ret.Get(0).(func() DeliveryHealth); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(DeliveryHealth)
+	}
+
+	return r0
+}
+
 // DroppedAlertmanagersFor provides a mock function with given fields: orgID
 func (_m *FakeScheduleService) DroppedAlertmanagersFor(orgID int64) []*url.URL {
 	ret := _m.Called(orgID)
@@ -55,6 +99,57 @@ func (_m *FakeScheduleService) DroppedAlertmanagersFor(orgID int64) []*url.URL {
 	return r0
 }
 
+// ExternalDeliveryPausedFor provides a mock function with given fields: orgID
+func (_m *FakeScheduleService) ExternalDeliveryPausedFor(orgID int64) (bool, time.Time) {
+	ret := _m.Called(orgID)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(int64) bool); ok {
+		r0 = rf(orgID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 time.Time
+	if rf, ok := ret.Get(1).(func(int64) time.Time); ok {
+		r1 = rf(orgID)
+	} else {
+		r1 = ret.Get(1).(time.Time)
+	}
+
+	return r0, r1
+}
+
+// InconsistenciesFor provides a mock function with given fields: orgID
+func (_m *FakeScheduleService) InconsistenciesFor(orgID int64) []string {
+	ret := _m.Called(orgID)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(int64) []string); ok {
+		r0 = rf(orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// IsUnhealthy provides a mock function with given fields: orgID
+func (_m *FakeScheduleService) IsUnhealthy(orgID int64) bool {
+	ret := _m.Called(orgID)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(int64) bool); ok {
+		r0 = rf(orgID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // Pause provides a mock function with given fields:
 func (_m *FakeScheduleService) Pause() error {
 	ret := _m.Called()
@@ -69,6 +164,72 @@ func (_m *FakeScheduleService) Pause() error {
 	return r0
 }
 
+// PauseExternalDeliveryFor provides a mock function with given fields: orgID, duration
+func (_m *FakeScheduleService) PauseExternalDeliveryFor(orgID int64, duration time.Duration) {
+	_m.Called(orgID, duration)
+}
+
+// PendingRoutingModeChangeFor provides a mock function with given fields: orgID
+func (_m *FakeScheduleService) PendingRoutingModeChangeFor(orgID int64) (PendingRoutingModeChange, bool) {
+	ret := _m.Called(orgID)
+
+	var r0 PendingRoutingModeChange
+	if rf, ok := ret.Get(0).(func(int64) PendingRoutingModeChange); ok {
+		r0 = rf(orgID)
+	} else {
+		r0 = ret.Get(0).(PendingRoutingModeChange)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(int64) bool); ok {
+		r1 = rf(orgID)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// PurgeDeadLetterAlerts provides a mock function with given fields: ctx, orgID
+func (_m *FakeScheduleService) PurgeDeadLetterAlerts(ctx context.Context, orgID int64) error {
+	ret := _m.Called(ctx, orgID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, orgID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RedispatchDeadLetterAlerts provides a mock function with given fields: ctx, orgID
+func (_m *FakeScheduleService) RedispatchDeadLetterAlerts(ctx context.Context, orgID int64) (int, error) {
+	ret := _m.Called(ctx, orgID)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, int64) int); ok {
+		r0 = rf(ctx, orgID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResumeExternalDeliveryFor provides a mock function with given fields: orgID
+func (_m *FakeScheduleService) ResumeExternalDeliveryFor(orgID int64) {
+	_m.Called(orgID)
+}
+
 // Run provides a mock function with given fields: _a0
 func (_m *FakeScheduleService) Run(_a0 context.Context) error {
 	ret := _m.Called(_a0)
@@ -83,6 +244,38 @@ func (_m *FakeScheduleService) Run(_a0 context.Context) error {
 	return r0
 }
 
+// SendTestAlert provides a mock function with given fields: ctx, cfg
+func (_m *FakeScheduleService) SendTestAlert(ctx context.Context, cfg *models.AdminConfiguration) []sender.AMTestResult {
+	ret := _m.Called(ctx, cfg)
+
+	var r0 []sender.AMTestResult
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AdminConfiguration) []sender.AMTestResult); ok {
+		r0 = rf(ctx, cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]sender.AMTestResult)
+		}
+	}
+
+	return r0
+}
+
+// SenderStatuses provides a mock function with given fields:
+func (_m *FakeScheduleService) SenderStatuses() []SenderStatus {
+	ret := _m.Called()
+
+	var r0 []SenderStatus
+	if rf, ok := ret.Get(0).(func() []SenderStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]SenderStatus)
+		}
+	}
+
+	return r0
+}
+
 // Unpause provides a mock function with given fields:
 func (_m *FakeScheduleService) Unpause() error {
 	ret := _m.Called()
@@ -0,0 +1,69 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	prometheusModel "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/sender"
+)
+
+const (
+	// startupNotificationAlertName is the name of the alert sent when a sender starts.
+	startupNotificationAlertName = "GrafanaSenderStarted"
+	// startupNotificationTimeout is how long we wait for a new sender to discover its Alertmanager(s)
+	// before giving up on sending the startup notification, checking every startupNotificationInterval.
+	startupNotificationTimeout  = time.Minute
+	startupNotificationInterval = 100 * time.Millisecond
+	// startupNotificationDuration is how long after it is sent the startup notification is resolved.
+	startupNotificationDuration = 5 * time.Minute
+)
+
+// startupNotifications sends an alert to the external Alertmanager(s) when a sender starts, if
+// startupNotification is set, with the labels of startupNotificationLabels.
+type startupNotifications struct {
+	startupNotification       bool
+	startupNotificationLabels map[string]string
+}
+
+// sendStartupNotification sends the startup notification alert of the organization to its external
+// Alertmanager(s). Alerts sent before any Alertmanager is discovered are dropped, so it waits for the
+// sender to discover them, up to startupNotificationTimeout.
+func (sch *schedule) sendStartupNotification(orgID int64, s *sender.Sender) {
+	ticker := sch.clock.Ticker(startupNotificationInterval)
+	defer ticker.Stop()
+	timeout := sch.clock.After(startupNotificationTimeout)
+	for len(s.Alertmanagers()) == 0 {
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			sch.log.Warn("no alertmanager discovered, the startup notification is not sent", "org", orgID)
+			return
+		}
+	}
+
+	labels := amv2.LabelSet{
+		prometheusModel.AlertNameLabel: startupNotificationAlertName,
+		"org_id":                       fmt.Sprint(orgID),
+	}
+	for k, v := range sch.startupNotificationLabels {
+		labels[k] = v
+	}
+	now := sch.clock.Now()
+	alerts := definitions.PostableAlerts{PostableAlerts: []amv2.PostableAlert{{
+		Alert: amv2.Alert{Labels: labels},
+		Annotations: amv2.LabelSet{
+			"description": "Grafana started forwarding the alerts of the organization to this Alertmanager.",
+		},
+		StartsAt: strfmt.DateTime(now),
+		EndsAt:   strfmt.DateTime(now.Add(startupNotificationDuration)),
+	}}}
+
+	sch.log.Debug("sending startup notification", "org", orgID)
+	s.SendAlerts(alerts)
+	sch.captureSend(orgID, alerts)
+}
@@ -0,0 +1,233 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/prometheus/prometheus/notifier"
+)
+
+// awsQueueCapacity is the number of batches of alerts waiting to be published to SNS or SQS, the batches sent
+// while it is full are dropped.
+const awsQueueCapacity = 100
+
+// awsTarget is the SNS topic or SQS queue alerts are published to, through the client of its service.
+type awsTarget struct {
+	// target is the ARN of the topic or the URL of the queue. fifo is true if it is a FIFO topic or queue,
+	// whose messages need a group and a deduplication ID.
+	target string
+	fifo   bool
+	sns    snsiface.SNSAPI
+	sqs    sqsiface.SQSAPI
+}
+
+// buildAWS returns the SNS topic or SQS queue of the configuration, nil if it has none. Requests are retried
+// like the ones to the other targets, with the backoff of the SDK starting at retryBackoff.
+func buildAWS(cfg *ngmodels.AdminConfiguration, decrypt DecryptFn, retries int, retryBackoff time.Duration) (*awsTarget, error) {
+	if cfg.AWS == nil {
+		return nil, nil
+	}
+	c := cfg.AWS
+	service := sqs.ServiceName
+	if c.TopicARN != "" {
+		service = sns.ServiceName
+	}
+
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", service, c.Region)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AWS endpoint: %w", err)
+	}
+	httpClient, err := httpClientFor(cfg, endpoint, u)
+	if err != nil {
+		return nil, err
+	}
+
+	awsCfg := aws.NewConfig().WithRegion(c.Region).WithHTTPClient(httpClient)
+	awsCfg = request.WithRetryer(awsCfg, client.DefaultRetryer{NumMaxRetries: retries, MinRetryDelay: retryBackoff})
+	if len(c.SecureSettings) > 0 {
+		if decrypt == nil {
+			return nil, errors.New("the sender cannot decrypt the AWS credentials")
+		}
+		accessKey := decrypt(context.Background(), c.SecureSettings, ngmodels.AWSAccessKeyKey, "")
+		secretKey := decrypt(context.Background(), c.SecureSettings, ngmodels.AWSSecretKeyKey, "")
+		if accessKey == "" || secretKey == "" {
+			return nil, errors.New("the AWS credentials must have both an access key and a secret key")
+		}
+		awsCfg = awsCfg.WithCredentials(credentials.NewStaticCredentials(accessKey, secretKey, ""))
+	}
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the AWS session: %w", err)
+	}
+	if c.AssumeRoleARN != "" {
+		creds := stscreds.NewCredentials(sess, c.AssumeRoleARN, func(p *stscreds.AssumeRoleProvider) {
+			if c.ExternalID != "" {
+				p.ExternalID = aws.String(c.ExternalID)
+			}
+		})
+		sess = sess.Copy(&aws.Config{Credentials: creds})
+	}
+
+	// The endpoint is the one of the topic or queue only, the role is assumed through the endpoint of STS.
+	var serviceCfg *aws.Config
+	if c.Endpoint != "" {
+		serviceCfg = aws.NewConfig().WithEndpoint(c.Endpoint)
+	}
+	target := &awsTarget{target: c.Target(), fifo: strings.HasSuffix(c.Target(), ".fifo")}
+	if c.TopicARN != "" {
+		target.sns = sns.New(sess, serviceCfg)
+	} else {
+		target.sqs = sqs.New(sess, serviceCfg)
+	}
+	return target, nil
+}
+
+// sendToAWS queues the alerts to be published to SNS or SQS, if the sender publishes alerts there.
+func (s *Sender) sendToAWS(as []*notifier.Alert) {
+	s.awsMtx.RLock()
+	t := s.aws
+	s.awsMtx.RUnlock()
+	if t == nil {
+		return
+	}
+
+	select {
+	case s.awsQueue <- as:
+	default:
+		s.logger.Warn("AWS queue is full, alerts are dropped", "alert_count", len(as))
+	}
+}
+
+// runAWS publishes the alerts queued to SNS or SQS, until the sender is stopped.
+func (s *Sender) runAWS() {
+	for {
+		select {
+		case <-s.sdCtx.Done():
+			return
+		case as := <-s.awsQueue:
+			s.awsMtx.RLock()
+			t := s.aws
+			s.awsMtx.RUnlock()
+			if t != nil {
+				s.postToAWS(s.sdCtx, t, as)
+			}
+		}
+	}
+}
+
+// postToAWS publishes a message per alert, relabeled, to SNS or SQS and keeps track of the outcome. The result is
+// the one of the first message that failed, if any.
+func (s *Sender) postToAWS(ctx context.Context, t *awsTarget, as []*notifier.Alert) {
+	as, ids := s.relabelNotifierAlerts(t.target, as)
+	if len(as) == 0 {
+		return
+	}
+	atomic.AddInt64(&s.inflight, 1)
+	defer atomic.AddInt64(&s.inflight, -1)
+
+	res := SendResult{Alertmanager: t.target, Direct: true, Alerts: len(as), AlertLabels: make([]map[string]string, 0, len(as)), AlertIDs: ids}
+	if raw, err := json.Marshal(as); err == nil {
+		res.BatchID = batchID(raw)
+	}
+	for _, a := range as {
+		res.AlertLabels = append(res.AlertLabels, a.Labels.Map())
+	}
+	start := time.Now()
+	for _, a := range as {
+		attempts, statusCode, err := t.publish(ctx, a)
+		if attempts > res.Attempts {
+			res.Attempts = attempts
+		}
+		if res.Err == nil {
+			res.StatusCode, res.Err = statusCode, err
+		}
+	}
+	res.Duration = time.Since(start)
+	if res.Err != nil {
+		s.logger.Warn("failed to publish alerts to AWS", "target", t.target, "alert_count", len(as), "err", res.Err)
+	}
+	s.recordResult(ctx, res, nil)
+}
+
+// publish publishes the alert as a JSON message, like the alerts sent to an Alertmanager, with its status and
+// alert name as message attributes so that subscriptions can filter them. It returns the number of attempts
+// and the HTTP status code of the last response, 0 if none was received.
+func (t *awsTarget) publish(ctx context.Context, a *notifier.Alert) (int, int, error) {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return 0, 0, err
+	}
+	status := "firing"
+	if a.Resolved() {
+		status = "resolved"
+	}
+	attrs := map[string]string{"status": status}
+	if name := a.Labels.Get("alertname"); name != "" {
+		attrs["alertname"] = name
+	}
+	// FIFO topics and queues keep the messages of an alert in order, and do not deliver the same status of an
+	// alert twice since it started.
+	groupID := fmt.Sprintf("%016x", a.Labels.Hash())
+	dedupID := fmt.Sprintf("%s-%s-%d", groupID, status, a.StartsAt.Unix())
+
+	var req *request.Request
+	capture := func(r *request.Request) { req = r }
+	if t.sns != nil {
+		in := &sns.PublishInput{
+			TopicArn:          aws.String(t.target),
+			Message:           aws.String(string(body)),
+			MessageAttributes: make(map[string]*sns.MessageAttributeValue, len(attrs)),
+		}
+		for k, v := range attrs {
+			in.MessageAttributes[k] = &sns.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+		}
+		if t.fifo {
+			in.MessageGroupId, in.MessageDeduplicationId = aws.String(groupID), aws.String(dedupID)
+		}
+		_, err = t.sns.PublishWithContext(ctx, in, capture)
+	} else {
+		in := &sqs.SendMessageInput{
+			QueueUrl:          aws.String(t.target),
+			MessageBody:       aws.String(string(body)),
+			MessageAttributes: make(map[string]*sqs.MessageAttributeValue, len(attrs)),
+		}
+		for k, v := range attrs {
+			in.MessageAttributes[k] = &sqs.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+		}
+		if t.fifo {
+			in.MessageGroupId, in.MessageDeduplicationId = aws.String(groupID), aws.String(dedupID)
+		}
+		_, err = t.sqs.SendMessageWithContext(ctx, in, capture)
+	}
+
+	attempts, statusCode := 0, 0
+	if req != nil {
+		attempts = req.RetryCount + 1
+		if req.HTTPResponse != nil {
+			statusCode = req.HTTPResponse.StatusCode
+		}
+	}
+	return attempts, statusCode, err
+}
@@ -0,0 +1,108 @@
+package sender
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+func TestAWS(t *testing.T) {
+	var mtx sync.Mutex
+	var messages []url.Values
+	fakeSQS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("Action") != "SendMessage" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mtx.Lock()
+		messages = append(messages, r.Form)
+		id := len(messages)
+		mtx.Unlock()
+		// The SDK verifies the checksum of the body of the messages sent.
+		sum := md5.Sum([]byte(r.Form.Get("MessageBody")))
+		_, _ = fmt.Fprintf(w, "<SendMessageResponse><SendMessageResult><MD5OfMessageBody>%x</MD5OfMessageBody>"+
+			"<MessageId>%d</MessageId></SendMessageResult></SendMessageResponse>", sum, id)
+	}))
+	defer fakeSQS.Close()
+
+	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+	secureSettings, err := secretsService.EncryptJsonData(context.Background(), map[string]string{
+		ngmodels.AWSAccessKeyKey: "access-key",
+		ngmodels.AWSSecretKeyKey: "secret-key",
+	}, secrets.WithoutScope())
+	require.NoError(t, err)
+
+	queueURL := "https://sqs.eu-west-1.amazonaws.com/123456789012/alerts"
+	s, err := New(nil)
+	require.NoError(t, err)
+	s.SetDecryptFn(secretsService.GetDecryptedValue)
+	runSenderWithTargets(t, s, &ngmodels.AdminConfiguration{
+		OrgID: 1,
+		AWS: &ngmodels.AWSConfig{
+			Region:         "eu-west-1",
+			QueueURL:       queueURL,
+			Endpoint:       fakeSQS.URL,
+			SecureSettings: secureSettings,
+		},
+	})
+
+	s.SendAlerts(apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{
+		{
+			Alert:       models.Alert{Labels: models.LabelSet{"alertname": "firing"}},
+			Annotations: models.LabelSet{"summary": "something is firing"},
+		},
+		{
+			Alert:  models.Alert{Labels: models.LabelSet{"alertname": "resolved"}},
+			EndsAt: strfmt.DateTime(time.Now().Add(-time.Minute)),
+		},
+	}})
+	require.Eventually(t, func() bool {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return len(messages) == 2
+	}, 10*time.Second, 100*time.Millisecond)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	statuses := map[string]string{}
+	for _, m := range messages {
+		require.Equal(t, queueURL, m.Get("QueueUrl"))
+		var body struct {
+			Labels      map[string]string `json:"labels"`
+			Annotations map[string]string `json:"annotations"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(m.Get("MessageBody")), &body))
+		attrs := map[string]string{}
+		for i := 1; m.Get(fmt.Sprintf("MessageAttribute.%d.Name", i)) != ""; i++ {
+			attrs[m.Get(fmt.Sprintf("MessageAttribute.%d.Name", i))] = m.Get(fmt.Sprintf("MessageAttribute.%d.Value.StringValue", i))
+		}
+		require.Equal(t, body.Labels["alertname"], attrs["alertname"])
+		statuses[attrs["alertname"]] = attrs["status"]
+		if body.Labels["alertname"] == "firing" {
+			require.Equal(t, "something is firing", body.Annotations["summary"])
+		}
+	}
+	require.Equal(t, map[string]string{"firing": "firing", "resolved": "resolved"}, statuses)
+
+	require.Eventually(t, func() bool {
+		res, ok := s.LastSendResults()[queueURL]
+		return ok && res.Err == nil && res.Alerts == 2 && res.StatusCode == http.StatusOK && res.Direct
+	}, 10*time.Second, 100*time.Millisecond)
+}
@@ -0,0 +1,181 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery"
+	"github.com/prometheus/prometheus/discovery/dns"
+	"github.com/prometheus/prometheus/discovery/refresh"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+	"gopkg.in/yaml.v2"
+)
+
+// Alertmanager URLs whose scheme is prefixed with one of the mechanisms below and a "+" are not sent alerts to
+// directly: the Alertmanager(s) are discovered and refreshed periodically, the same way Prometheus discovers them.
+//
+//	dnssrv+https://_alertmanager._tcp.example.com/prefix: the targets of the SRV records of the name.
+//	dns+https://alertmanager.example.com:9093/prefix: the A records of the name, with the given port.
+//	file+https:///etc/grafana/alertmanagers/*.json: the targets of the Prometheus file_sd files, in JSON or
+//	  YAML, matching the path. Their path prefix is set with the path_prefix parameter.
+//
+// The refresh parameter sets how often DNS names are resolved and files are read, e.g. refresh=1m. The
+// Alertmanager(s) of a Kubernetes service are discovered with the SRV records of its headless service, e.g.
+// dnssrv+http://_web._tcp.alertmanager-operated.monitoring.svc.cluster.local.
+const (
+	dnsSRVDiscovery = "dnssrv"
+	dnsDiscovery    = "dns"
+	fileDiscovery   = "file"
+
+	defaultFileRefreshInterval = 5 * time.Minute
+)
+
+// isDiscovered returns true if the Alertmanager(s) of the URL are discovered.
+func isDiscovered(u *url.URL) bool {
+	return strings.Contains(u.Scheme, "+")
+}
+
+// serviceDiscovery returns how the Alertmanager(s) of a URL are discovered, along with their scheme and path prefix.
+// Alertmanager(s) that are not discovered have a static configuration with the host of the URL.
+func serviceDiscovery(u *url.URL) (sdConfigs discovery.Configs, scheme string, pathPrefix string, err error) {
+	if !isDiscovered(u) {
+		return discovery.Configs{
+			discovery.StaticConfig{
+				{
+					Targets: []model.LabelSet{{model.AddressLabel: model.LabelValue(u.Host)}},
+				},
+			},
+		}, u.Scheme, u.Path, nil
+	}
+
+	mechanism := u.Scheme[:strings.Index(u.Scheme, "+")]
+	scheme = u.Scheme[len(mechanism)+1:]
+	if scheme != "http" && scheme != "https" {
+		return nil, "", "", fmt.Errorf("discovered Alertmanagers must use http or https, not %q", scheme)
+	}
+	query := u.Query()
+	var refresh model.Duration
+	if r := query.Get("refresh"); r != "" {
+		if refresh, err = model.ParseDuration(r); err != nil {
+			return nil, "", "", fmt.Errorf("invalid refresh interval: %w", err)
+		}
+	}
+
+	switch mechanism {
+	case dnsSRVDiscovery, dnsDiscovery:
+		sdConfig := dns.DefaultSDConfig
+		sdConfig.Names = []string{u.Hostname()}
+		if refresh != 0 {
+			sdConfig.RefreshInterval = refresh
+		}
+		if mechanism == dnsDiscovery {
+			sdConfig.Type = "A"
+			if sdConfig.Port, err = strconv.Atoi(u.Port()); err != nil {
+				return nil, "", "", errors.New("discovering Alertmanagers from A records requires a port")
+			}
+		}
+		sdConfigs = discovery.Configs{&sdConfig}
+		pathPrefix = u.Path
+	case fileDiscovery:
+		if u.Host != "" || u.Path == "" {
+			return nil, "", "", errors.New("discovering Alertmanagers from files requires a path without host")
+		}
+		if _, err := filepath.Match(u.Path, ""); err != nil {
+			return nil, "", "", fmt.Errorf("invalid file pattern: %w", err)
+		}
+		sdConfig := &fileSDConfig{pattern: u.Path, refreshInterval: defaultFileRefreshInterval}
+		if refresh != 0 {
+			sdConfig.refreshInterval = time.Duration(refresh)
+		}
+		sdConfigs = discovery.Configs{sdConfig}
+		pathPrefix = query.Get("path_prefix")
+	default:
+		return nil, "", "", fmt.Errorf("unknown Alertmanager discovery %q", mechanism)
+	}
+	return sdConfigs, scheme, pathPrefix, nil
+}
+
+// fileSDConfig discovers Alertmanager(s) from the Prometheus file_sd files matching a pattern. Unlike the
+// file discovery of Prometheus, the files are only read periodically, changes are not watched.
+type fileSDConfig struct {
+	pattern         string
+	refreshInterval time.Duration
+}
+
+// Name implements the discovery.Config interface.
+func (*fileSDConfig) Name() string { return fileDiscovery }
+
+// NewDiscoverer implements the discovery.Config interface.
+func (c *fileSDConfig) NewDiscoverer(opts discovery.DiscovererOptions) (discovery.Discoverer, error) {
+	d := &fileSD{pattern: c.pattern, sources: map[string]struct{}{}}
+	return refresh.NewDiscovery(opts.Logger, fileDiscovery, c.refreshInterval, d.refresh), nil
+}
+
+type fileSD struct {
+	pattern string
+	// sources are the target groups of the previous refresh, the ones gone must be sent empty to be removed.
+	sources map[string]struct{}
+}
+
+func (d *fileSD) refresh(_ context.Context) ([]*targetgroup.Group, error) {
+	files, err := filepath.Glob(d.pattern)
+	if err != nil {
+		return nil, err
+	}
+	var res []*targetgroup.Group
+	for _, f := range files {
+		groups, err := readTargetGroups(f)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, groups...)
+	}
+
+	sources := make(map[string]struct{}, len(res))
+	for _, group := range res {
+		sources[group.Source] = struct{}{}
+	}
+	for source := range d.sources {
+		if _, ok := sources[source]; !ok {
+			res = append(res, &targetgroup.Group{Source: source})
+		}
+	}
+	d.sources = sources
+	return res, nil
+}
+
+// readTargetGroups reads the target groups of a file, in YAML if its extension is .yml or .yaml, else in JSON.
+func readTargetGroups(filename string) ([]*targetgroup.Group, error) {
+	content, err := ioutil.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*targetgroup.Group
+	switch filepath.Ext(filename) {
+	case ".yml", ".yaml":
+		err = yaml.UnmarshalStrict(content, &groups)
+	default:
+		err = json.Unmarshal(content, &groups)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the Alertmanagers of %s: %w", filename, err)
+	}
+
+	for i, group := range groups {
+		if group == nil {
+			return nil, fmt.Errorf("nil target group in %s", filename)
+		}
+		group.Source = fmt.Sprintf("%s:%d", filename, i)
+	}
+	return groups, nil
+}
@@ -0,0 +1,58 @@
+package sender
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestDiscoveredAlertmanagers(t *testing.T) {
+	am := newFakeAlertmanager(t)
+	amURL, err := url.Parse(am.URL)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	targets := fmt.Sprintf(`[{"targets": [%q]}]`, amURL.Host)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "alertmanagers.json"), []byte(targets), 0600))
+
+	s, err := New(nil)
+	require.NoError(t, err)
+	s.Run()
+	t.Cleanup(s.Stop)
+	require.NoError(t, s.ApplyConfig(&ngmodels.AdminConfiguration{OrgID: 1, Alertmanagers: []string{
+		"file+http://" + filepath.Join(dir, "*.json") + "?refresh=1s",
+		"file+ftp://" + filepath.Join(dir, "*.json"),
+		"dns+http://alertmanager.example.com",
+		"consul+http://alertmanager",
+	}}))
+
+	invalid := s.InvalidAlertmanagers()
+	require.Len(t, invalid, 3)
+	require.EqualError(t, invalid["file+ftp://"+filepath.Join(dir, "*.json")], `discovered Alertmanagers must use http or https, not "ftp"`)
+	require.EqualError(t, invalid["dns+http://alertmanager.example.com"], "discovering Alertmanagers from A records requires a port")
+	require.EqualError(t, invalid["consul+http://alertmanager"], `unknown Alertmanager discovery "consul"`)
+
+	require.Eventually(t, func() bool {
+		ams := s.Alertmanagers()
+		return len(ams) == 1 && ams[0].Host == amURL.Host
+	}, 10*time.Second, 100*time.Millisecond)
+
+	s.SendAlerts(postableAlerts("discovered"))
+	require.Eventually(t, func() bool {
+		return len(am.alerts()) == 1
+	}, 10*time.Second, 100*time.Millisecond)
+	require.Equal(t, []string{"discovered"}, am.alerts())
+
+	// The Alertmanager(s) are discovered again when the files change.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "alertmanagers.json"), []byte(`[]`), 0600))
+	require.Eventually(t, func() bool {
+		return len(s.Alertmanagers()) == 0
+	}, 10*time.Second, 100*time.Millisecond)
+}
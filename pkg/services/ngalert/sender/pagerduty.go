@@ -0,0 +1,226 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+
+	"github.com/prometheus/prometheus/notifier"
+)
+
+const (
+	// pagerDutyQueueCapacity is the number of batches of alerts waiting to be sent to PagerDuty, the batches
+	// sent while it is full are dropped.
+	pagerDutyQueueCapacity = 100
+	// pagerDutyMaxSummary is the longest summary of an event accepted by the PagerDuty Events API v2.
+	pagerDutyMaxSummary = 1024
+
+	pagerDutyEventTrigger = "trigger"
+	pagerDutyEventResolve = "resolve"
+)
+
+// pagerDutyTarget is the PagerDuty service alerts are sent to.
+type pagerDutyTarget struct {
+	url        string
+	routingKey string
+	severity   string
+	client     *http.Client
+}
+
+// pagerDutyEvent is an event of the PagerDuty Events API v2.
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Client      string            `json:"client,omitempty"`
+	ClientURL   string            `json:"client_url,omitempty"`
+	Links       []pagerDutyLink   `json:"links,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// buildPagerDuty returns the PagerDuty service of the configuration, nil if it has none.
+func buildPagerDuty(cfg *ngmodels.AdminConfiguration, decrypt DecryptFn) (*pagerDutyTarget, error) {
+	if cfg.PagerDuty == nil {
+		return nil, nil
+	}
+	if decrypt == nil {
+		return nil, errors.New("the sender cannot decrypt the routing key of PagerDuty")
+	}
+
+	routingKey := decrypt(context.Background(), cfg.PagerDuty.SecureSettings, ngmodels.PagerDutyRoutingKeyKey, "")
+	if routingKey == "" {
+		return nil, errors.New("the PagerDuty configuration has no routing key")
+	}
+	pdURL := cfg.PagerDuty.URL
+	if pdURL == "" {
+		pdURL = ngmodels.PagerDutyDefaultURL
+	}
+	u, err := url.Parse(pdURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PagerDuty URL: %w", err)
+	}
+	severity := cfg.PagerDuty.Severity
+	if severity == "" {
+		severity = "critical"
+	}
+
+	client, err := httpClientFor(cfg, pdURL, u)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pagerDutyTarget{
+		url:        pdURL,
+		routingKey: routingKey,
+		severity:   severity,
+		client:     client,
+	}, nil
+}
+
+// sendToPagerDuty queues the alerts to be sent to PagerDuty, if the sender sends alerts there.
+func (s *Sender) sendToPagerDuty(as []*notifier.Alert) {
+	s.pagerDutyMtx.RLock()
+	pd := s.pagerDuty
+	s.pagerDutyMtx.RUnlock()
+	if pd == nil {
+		return
+	}
+
+	select {
+	case s.pagerDutyQueue <- as:
+	default:
+		s.logger.Warn("PagerDuty queue is full, alerts are dropped", "alert_count", len(as))
+	}
+}
+
+// runPagerDuty sends the alerts queued to PagerDuty, until the sender is stopped.
+func (s *Sender) runPagerDuty() {
+	for {
+		select {
+		case <-s.sdCtx.Done():
+			return
+		case as := <-s.pagerDutyQueue:
+			s.pagerDutyMtx.RLock()
+			pd := s.pagerDuty
+			s.pagerDutyMtx.RUnlock()
+			if pd != nil {
+				s.postToPagerDuty(s.sdCtx, pd, as)
+			}
+		}
+	}
+}
+
+// postToPagerDuty sends an event per alert, relabeled, to PagerDuty and keeps track of the outcome. The result is the one
+// of the first event that failed, if any.
+func (s *Sender) postToPagerDuty(ctx context.Context, pd *pagerDutyTarget, as []*notifier.Alert) {
+	as, ids := s.relabelNotifierAlerts(pd.url, as)
+	if len(as) == 0 {
+		return
+	}
+	atomic.AddInt64(&s.inflight, 1)
+	defer atomic.AddInt64(&s.inflight, -1)
+
+	res := SendResult{Alertmanager: pd.url, Direct: true, Alerts: len(as), AlertLabels: make([]map[string]string, 0, len(as)), AlertIDs: ids}
+	if raw, err := json.Marshal(as); err == nil {
+		res.BatchID = batchID(raw)
+	}
+	for _, a := range as {
+		res.AlertLabels = append(res.AlertLabels, a.Labels.Map())
+	}
+	start := time.Now()
+	for _, a := range as {
+		attempts, statusCode := 0, 0
+		body, err := json.Marshal(pd.event(a))
+		if err == nil {
+			attempts, statusCode, err = s.post(ctx, pd.client, pd.url, "application/json", body)
+		}
+		if attempts > res.Attempts {
+			res.Attempts = attempts
+		}
+		if res.Err == nil {
+			res.StatusCode, res.Err = statusCode, err
+		}
+	}
+	res.Duration = time.Since(start)
+	if res.Err != nil {
+		s.logger.Warn("failed to send alerts to PagerDuty", "url", pd.url, "alert_count", len(as), "err", res.Err)
+	}
+	s.recordResult(ctx, res, nil)
+}
+
+// event returns the event of the alert, a trigger if it is firing and a resolve if it is resolved. Alerts are
+// deduplicated by labels, so that the resolve of an alert resolves the incident of its trigger.
+func (pd *pagerDutyTarget) event(a *notifier.Alert) pagerDutyEvent {
+	event := pagerDutyEvent{
+		RoutingKey:  pd.routingKey,
+		EventAction: pagerDutyEventTrigger,
+		DedupKey:    fmt.Sprintf("%016x", a.Labels.Hash()),
+	}
+	if a.Resolved() {
+		event.EventAction = pagerDutyEventResolve
+		return event
+	}
+
+	severity := a.Labels.Get("severity")
+	if !ngmodels.IsPagerDutySeverity(severity) {
+		severity = pd.severity
+	}
+	source := a.Labels.Get("instance")
+	if source == "" {
+		source = "Grafana"
+	}
+	summary := a.Annotations.Get("summary")
+	if summary == "" {
+		summary = a.Labels.Get("alertname")
+	}
+	if summary == "" {
+		summary = a.Labels.String()
+	}
+	if len(summary) > pagerDutyMaxSummary {
+		summary = summary[:pagerDutyMaxSummary-3] + "..."
+	}
+
+	details := make(map[string]string, len(a.Labels)+len(a.Annotations))
+	for _, l := range a.Labels {
+		details[l.Name] = l.Value
+	}
+	for _, an := range a.Annotations {
+		details[an.Name] = an.Value
+	}
+
+	event.Payload = &pagerDutyPayload{
+		Summary:       summary,
+		Source:        source,
+		Severity:      severity,
+		CustomDetails: details,
+	}
+	if !a.StartsAt.IsZero() {
+		event.Payload.Timestamp = a.StartsAt.UTC().Format(time.RFC3339)
+	}
+	event.Client = "Grafana"
+	if a.GeneratorURL != "" {
+		event.ClientURL = a.GeneratorURL
+		event.Links = []pagerDutyLink{{Href: a.GeneratorURL, Text: "Alert rule"}}
+	}
+	return event
+}
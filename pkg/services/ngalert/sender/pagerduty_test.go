@@ -0,0 +1,93 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+)
+
+func TestPagerDuty(t *testing.T) {
+	var mtx sync.Mutex
+	var events []map[string]interface{}
+	fakePagerDuty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mtx.Lock()
+		events = append(events, event)
+		mtx.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer fakePagerDuty.Close()
+
+	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+	secureSettings, err := secretsService.EncryptJsonData(context.Background(), map[string]string{
+		ngmodels.PagerDutyRoutingKeyKey: "routing-key",
+	}, secrets.WithoutScope())
+	require.NoError(t, err)
+
+	s, err := New(nil)
+	require.NoError(t, err)
+	s.SetDecryptFn(secretsService.GetDecryptedValue)
+	runSenderWithTargets(t, s, &ngmodels.AdminConfiguration{
+		OrgID:     1,
+		PagerDuty: &ngmodels.PagerDutyConfig{URL: fakePagerDuty.URL, Severity: "error", SecureSettings: secureSettings},
+	})
+
+	s.SendAlerts(apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{
+		{
+			Alert:       models.Alert{Labels: models.LabelSet{"alertname": "firing", "severity": "warning"}},
+			Annotations: models.LabelSet{"summary": "something is firing"},
+		},
+		{
+			Alert:  models.Alert{Labels: models.LabelSet{"alertname": "default severity"}},
+			EndsAt: strfmt.DateTime(time.Now().Add(time.Hour)),
+		},
+		{
+			Alert:  models.Alert{Labels: models.LabelSet{"alertname": "resolved"}},
+			EndsAt: strfmt.DateTime(time.Now().Add(-time.Minute)),
+		},
+	}})
+	require.Eventually(t, func() bool {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return len(events) == 3
+	}, 10*time.Second, 100*time.Millisecond)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	for _, event := range events {
+		require.Equal(t, "routing-key", event["routing_key"])
+		require.NotEmpty(t, event["dedup_key"])
+	}
+	require.Equal(t, "trigger", events[0]["event_action"])
+	payload := events[0]["payload"].(map[string]interface{})
+	require.Equal(t, "something is firing", payload["summary"])
+	require.Equal(t, "warning", payload["severity"])
+	require.Equal(t, "firing", payload["custom_details"].(map[string]interface{})["alertname"])
+	require.Equal(t, "trigger", events[1]["event_action"])
+	require.Equal(t, "error", events[1]["payload"].(map[string]interface{})["severity"])
+	require.Equal(t, "resolve", events[2]["event_action"])
+	require.Nil(t, events[2]["payload"])
+
+	require.Eventually(t, func() bool {
+		res, ok := s.LastSendResults()[fakePagerDuty.URL]
+		return ok && res.Err == nil && res.Alerts == 3 && res.Direct
+	}, 10*time.Second, 100*time.Millisecond)
+}
@@ -0,0 +1,152 @@
+package sender
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/prometheus/notifier"
+)
+
+// priorityInterval is how often the alerts of the priority queues are handed to the notifier managers whose
+// queue has room for them.
+const priorityInterval = 50 * time.Millisecond
+
+// Priority is the priority of the alerts sent to the Alertmanager(s).
+type Priority string
+
+const (
+	PriorityHigh Priority = "high"
+	PriorityLow  Priority = "low"
+)
+
+// PriorityStats is a snapshot of the alerts of a priority handled by the sender since it started.
+type PriorityStats struct {
+	// Queued is the number of alerts of the priority waiting to be handed to the notifier.
+	Queued int
+	// DroppedTotal is the number of alerts of the priority dropped because their queue was full.
+	DroppedTotal int
+}
+
+// priorityQueues are the alerts of a shard waiting to be handed to its notifier manager, by priority.
+type priorityQueues struct {
+	high []*notifier.Alert
+	low  []*notifier.Alert
+}
+
+// SetPriority sends the alerts with one of the given values of the label before the other alerts. The alerts
+// wait in a high and a low priority queue per shard, each holding as many alerts as the queue of the notifier,
+// and are handed to the notifier a batch at a time, high priority alerts first. An empty label, the default,
+// hands all the alerts to the notifier right away, as do senders sending the alerts in order. It must be called
+// after SetQueue, and before ApplyConfig and Run.
+func (s *Sender) SetPriority(label string, high []string) {
+	if label == "" || len(high) == 0 || s.ordered {
+		return
+	}
+	s.priorityLabel = label
+	s.highPriority = make(map[string]struct{}, len(high))
+	for _, v := range high {
+		s.highPriority[v] = struct{}{}
+	}
+	s.priorityQueues = make([]priorityQueues, len(s.managers))
+	s.priorityDropped = map[Priority]int64{}
+}
+
+// priorityOf returns the priority of the alert.
+func (s *Sender) priorityOf(a *notifier.Alert) Priority {
+	if _, ok := s.highPriority[a.Labels.Get(s.priorityLabel)]; ok {
+		return PriorityHigh
+	}
+	return PriorityLow
+}
+
+// enqueueByPriority adds the alerts to the priority queues of their shard, discarding the oldest alerts of a
+// queue that is full, and hands what the notifier managers have room for to them.
+func (s *Sender) enqueueByPriority(as []*notifier.Alert) {
+	s.priorityMtx.Lock()
+	for _, a := range as {
+		q := &s.priorityQueues[s.shardOf(a)]
+		p := s.priorityOf(a)
+		queue := &q.low
+		if p == PriorityHigh {
+			queue = &q.high
+		}
+		if len(*queue) >= s.queueCapacity {
+			*queue = (*queue)[1:]
+			s.priorityDropped[p]++
+		}
+		*queue = append(*queue, a)
+	}
+	s.priorityMtx.Unlock()
+	s.dispatchByPriority()
+}
+
+// runPriority hands the alerts of the priority queues to the notifier managers every priority interval, until
+// the sender is stopped.
+func (s *Sender) runPriority() {
+	ticker := time.NewTicker(priorityInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.sdCtx.Done():
+			return
+		case <-ticker.C:
+			s.dispatchByPriority()
+		}
+	}
+}
+
+// dispatchByPriority hands to every notifier manager with fewer than a batch of alerts queued the alerts to fill
+// it up to a batch, the high priority alerts of its shard first. Keeping no more than a batch in the managers
+// means a high priority alert waits for at most the batch being sent and the one queued.
+func (s *Sender) dispatchByPriority() {
+	s.dispatchMtx.Lock()
+	defer s.dispatchMtx.Unlock()
+	for i, m := range s.managers {
+		room := s.batchSize - s.managerQueued(i)
+		if room <= 0 {
+			continue
+		}
+		s.priorityMtx.Lock()
+		q := &s.priorityQueues[i]
+		batch := takeAlerts(&q.high, room)
+		batch = append(batch, takeAlerts(&q.low, room-len(batch))...)
+		s.priorityMtx.Unlock()
+		if len(batch) > 0 {
+			atomic.AddInt64(&s.enqueued, int64(len(batch)))
+			m.Send(batch...)
+		}
+	}
+}
+
+// takeAlerts removes up to n alerts from the front of the queue and returns them.
+func takeAlerts(queue *[]*notifier.Alert, n int) []*notifier.Alert {
+	if n <= 0 || len(*queue) == 0 {
+		return nil
+	}
+	if n > len(*queue) {
+		n = len(*queue)
+	}
+	taken := append([]*notifier.Alert(nil), (*queue)[:n]...)
+	*queue = (*queue)[n:]
+	return taken
+}
+
+// priorityStats returns the stats of the priority queues, nil if the alerts have no priority.
+func (s *Sender) priorityStats() map[Priority]PriorityStats {
+	if s.priorityLabel == "" {
+		return nil
+	}
+	s.priorityMtx.Lock()
+	defer s.priorityMtx.Unlock()
+	stats := map[Priority]PriorityStats{
+		PriorityHigh: {DroppedTotal: int(s.priorityDropped[PriorityHigh])},
+		PriorityLow:  {DroppedTotal: int(s.priorityDropped[PriorityLow])},
+	}
+	for _, q := range s.priorityQueues {
+		high, low := stats[PriorityHigh], stats[PriorityLow]
+		high.Queued += len(q.high)
+		low.Queued += len(q.low)
+		stats[PriorityHigh], stats[PriorityLow] = high, low
+	}
+	return stats
+}
@@ -0,0 +1,92 @@
+package sender
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/notifier"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestPriority(t *testing.T) {
+	alert := func(name, severity string) *notifier.Alert {
+		return &notifier.Alert{Labels: labels.FromStrings("alertname", name, "severity", severity)}
+	}
+
+	t.Run("without a priority label the alerts are handed to the notifier", func(t *testing.T) {
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetPriority("", []string{"critical"})
+		s.enqueue([]*notifier.Alert{alert("low", "warning"), alert("high", "critical")})
+
+		stats := s.QueueStats()
+		require.Nil(t, stats.Priorities)
+		require.Equal(t, 2, stats.Queued)
+	})
+
+	t.Run("alerts wait in the queue of their priority", func(t *testing.T) {
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetQueue(0, 2, 0)
+		s.SetPriority("severity", []string{"critical"})
+
+		// The sender is not running, the notifier holds a batch and the other alerts wait by priority.
+		s.enqueue([]*notifier.Alert{alert("low1", "warning"), alert("low2", "warning"), alert("high1", "critical")})
+		s.enqueue([]*notifier.Alert{alert("high2", "critical")})
+		stats := s.QueueStats()
+		require.Equal(t, 4, stats.Queued)
+		require.Equal(t, map[Priority]PriorityStats{
+			PriorityHigh: {Queued: 1},
+			PriorityLow:  {Queued: 1},
+		}, stats.Priorities)
+	})
+
+	t.Run("high priority alerts are sent first", func(t *testing.T) {
+		fakeAM := store.NewFakeExternalAlertmanager(t)
+		defer fakeAM.Close()
+
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetQueue(0, 2, 0)
+		s.SetPriority("severity", []string{"critical"})
+		s.Run()
+		defer s.Stop()
+		require.NoError(t, s.ApplyConfig(&ngmodels.AdminConfiguration{OrgID: 1, Alertmanagers: []string{fakeAM.URL()}}))
+		require.Eventually(t, func() bool {
+			return len(s.Alertmanagers()) == 1
+		}, 10*time.Second, 200*time.Millisecond)
+
+		s.enqueue([]*notifier.Alert{
+			alert("low1", "warning"), alert("low2", "warning"), alert("high1", "critical"), alert("high2", "critical"),
+		})
+		require.Eventually(t, func() bool {
+			return fakeAM.AlertsCount() == 4
+		}, 10*time.Second, 200*time.Millisecond)
+
+		names := make([]string, 0, 4)
+		for _, a := range fakeAM.Alerts() {
+			names = append(names, a.Labels["alertname"])
+		}
+		require.Equal(t, []string{"high1", "high2", "low1", "low2"}, names)
+		require.Zero(t, s.QueueStats().Queued)
+	})
+
+	t.Run("alerts are dropped when their priority queue is full", func(t *testing.T) {
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetQueue(1, 1, 0)
+		s.SetPriority("severity", []string{"critical"})
+
+		s.enqueue([]*notifier.Alert{alert("high1", "critical")})
+		s.enqueue([]*notifier.Alert{alert("high2", "critical"), alert("high3", "critical")})
+		stats := s.QueueStats()
+		require.Equal(t, PriorityStats{Queued: 1, DroppedTotal: 1}, stats.Priorities[PriorityHigh])
+		require.Equal(t, PriorityStats{}, stats.Priorities[PriorityLow])
+		require.Equal(t, 2, stats.Queued)
+		require.Equal(t, 1, stats.DroppedTotal)
+	})
+}
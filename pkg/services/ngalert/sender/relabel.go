@@ -0,0 +1,246 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/prometheus/notifier"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/relabel"
+)
+
+// relabeling are the relabeling rules of the labels and of the annotations of the alerts sent to a target.
+type relabeling struct {
+	labels      []*relabel.Config
+	annotations []*relabel.Config
+}
+
+// relabelings are the relabeling rules of a configuration.
+type relabelings struct {
+	// all are the rules of all the targets, nil if there are none.
+	all *relabeling
+	// alertmanagers are the rules of particular Alertmanagers, by base URL, and targets the ones of the webhooks
+	// and PagerDuty, by URL.
+	alertmanagers map[string]*relabeling
+	targets       map[string]*relabeling
+}
+
+// buildRelabelings returns the relabeling rules of the configuration.
+func buildRelabelings(cfg *ngmodels.AdminConfiguration) (relabelings, error) {
+	res := relabelings{alertmanagers: map[string]*relabeling{}, targets: map[string]*relabeling{}}
+	var err error
+	if cfg.Relabel != nil {
+		if res.all, err = buildRelabeling(*cfg.Relabel); err != nil {
+			return res, err
+		}
+	}
+
+	alertmanagers := make(map[string]struct{}, len(cfg.Alertmanagers))
+	for _, am := range cfg.Alertmanagers {
+		alertmanagers[am] = struct{}{}
+	}
+	for target, rules := range cfg.TargetRelabels {
+		r, err := buildRelabeling(rules)
+		if err != nil {
+			return res, err
+		}
+		if _, ok := alertmanagers[target]; !ok {
+			res.targets[target] = r
+			continue
+		}
+		u, err := url.Parse(target)
+		if err != nil {
+			return res, err
+		}
+		res.alertmanagers[baseURL(u)] = r
+	}
+	return res, nil
+}
+
+func buildRelabeling(rules ngmodels.AlertRelabelConfigs) (*relabeling, error) {
+	lbls, err := ngmodels.PrometheusRelabelConfigs(rules.Labels)
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := ngmodels.PrometheusRelabelConfigs(rules.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	return &relabeling{labels: lbls, annotations: annotations}, nil
+}
+
+// relabelingsFor returns the relabeling rules of the alerts sent to the URL, the ones of all the targets first. The
+// requests to an Alertmanager are matched by the longest base URL they start with, the other targets by URL.
+func (s *Sender) relabelingsFor(targetURL string, alertmanager bool) []*relabeling {
+	s.relabelMtx.RLock()
+	defer s.relabelMtx.RUnlock()
+	var res []*relabeling
+	if s.relabelings.all != nil {
+		res = append(res, s.relabelings.all)
+	}
+	if !alertmanager {
+		if r, ok := s.relabelings.targets[targetURL]; ok {
+			res = append(res, r)
+		}
+		return res
+	}
+	var match string
+	for k := range s.relabelings.alertmanagers {
+		if (targetURL == k || strings.HasPrefix(targetURL, k+"/")) && len(k) > len(match) {
+			match = k
+		}
+	}
+	if match != "" {
+		res = append(res, s.relabelings.alertmanagers[match])
+	}
+	return res
+}
+
+// relabelAlert returns the labels and the annotations of an alert relabeled, false if the alert is dropped.
+func relabelAlert(rs []*relabeling, lbls, annotations labels.Labels) (labels.Labels, labels.Labels, bool) {
+	for _, r := range rs {
+		if len(r.labels) > 0 {
+			if lbls = relabel.Process(lbls, r.labels...); lbls == nil {
+				return nil, nil, false
+			}
+		}
+		if len(r.annotations) > 0 {
+			if annotations = relabel.Process(annotations, r.annotations...); annotations == nil {
+				return nil, nil, false
+			}
+		}
+	}
+	return lbls, annotations, true
+}
+
+// relabelRequest returns a copy of the request to an Alertmanager with its alerts relabeled, the request itself if
+// there are no relabeling rules and nil if all the alerts are dropped.
+func (s *Sender) relabelRequest(req *http.Request) (*http.Request, error) {
+	rs := s.relabelingsFor(req.URL.String(), true)
+	if len(rs) == 0 {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("the alerts of the request cannot be relabeled")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = body.Close() }()
+	raw, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// The alerts of both the v1 and v2 APIs have their labels and annotations in objects, the other fields
+	// are sent as they are.
+	var alerts []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &alerts); err != nil {
+		return nil, err
+	}
+	relabeled := make([]map[string]json.RawMessage, 0, len(alerts))
+	ids := alertIDs{}
+	for _, a := range alerts {
+		var lbls, annotations map[string]string
+		if err := unmarshalField(a, "labels", &lbls); err != nil {
+			return nil, err
+		}
+		if err := unmarshalField(a, "annotations", &annotations); err != nil {
+			return nil, err
+		}
+		l, an, keep := relabelAlert(rs, labels.FromMap(lbls), labels.FromMap(annotations))
+		if !keep {
+			continue
+		}
+		if a["labels"], err = json.Marshal(l.Map()); err != nil {
+			return nil, err
+		}
+		if _, ok := a["annotations"]; ok || len(an) > 0 {
+			if a["annotations"], err = json.Marshal(an.Map()); err != nil {
+				return nil, err
+			}
+		}
+		relabeled = append(relabeled, a)
+		id := alertID(l.Map())
+		ids[id] = append(ids[id], alertID(lbls))
+	}
+	if len(relabeled) == 0 {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(relabeled)
+	if err != nil {
+		return nil, err
+	}
+	// The alerts are reported with their IDs before being relabeled.
+	ctx := context.WithValue(req.Context(), alertIDsKey{}, ids)
+	r, err := http.NewRequestWithContext(ctx, req.Method, req.URL.String(), bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	r.Header = req.Header.Clone()
+	return r, nil
+}
+
+func unmarshalField(obj map[string]json.RawMessage, field string, v interface{}) error {
+	raw, ok := obj[field]
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// relabelPostableAlerts returns copies of the alerts relabeled for the target, without the alerts dropped, and
+// the IDs of the alerts kept.
+func (s *Sender) relabelPostableAlerts(targetURL string, alerts []models.PostableAlert) ([]models.PostableAlert, []string) {
+	rs := s.relabelingsFor(targetURL, false)
+	res := make([]models.PostableAlert, 0, len(alerts))
+	ids := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		id := AlertID(a)
+		if len(rs) > 0 {
+			l, an, keep := relabelAlert(rs, labels.FromMap(a.Labels), labels.FromMap(a.Annotations))
+			if !keep {
+				continue
+			}
+			a.Labels = l.Map()
+			a.Annotations = an.Map()
+		}
+		res = append(res, a)
+		ids = append(ids, id)
+	}
+	return res, ids
+}
+
+// relabelNotifierAlerts returns copies of the alerts relabeled for the target, without the alerts dropped, and
+// the IDs of the alerts kept.
+func (s *Sender) relabelNotifierAlerts(targetURL string, as []*notifier.Alert) ([]*notifier.Alert, []string) {
+	rs := s.relabelingsFor(targetURL, false)
+	res := make([]*notifier.Alert, 0, len(as))
+	ids := make([]string, 0, len(as))
+	for _, a := range as {
+		id := alertID(a.Labels.Map())
+		if len(rs) > 0 {
+			l, an, keep := relabelAlert(rs, a.Labels, a.Annotations)
+			if !keep {
+				continue
+			}
+			relabeled := *a
+			relabeled.Labels, relabeled.Annotations = l, an
+			a = &relabeled
+		}
+		res = append(res, a)
+		ids = append(ids, id)
+	}
+	return res, ids
+}
@@ -0,0 +1,77 @@
+package sender
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRelabel(t *testing.T) {
+	var mtx sync.Mutex
+	received := map[string][]models.PostableAlert{}
+	fakeTarget := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alerts []models.PostableAlert
+		if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		received[r.URL.Path] = append(received[r.URL.Path], alerts...)
+	}))
+	defer fakeTarget.Close()
+
+	// The internal labels and the annotations with personal data are stripped from the alerts sent to all the
+	// targets, and the Alertmanager does not get the alerts of severity info.
+	cfg := &ngmodels.AdminConfiguration{
+		OrgID:         1,
+		Alertmanagers: []string{fakeTarget.URL},
+		Webhooks:      []ngmodels.AlertWebhookConfig{{URL: fakeTarget.URL + "/hook"}},
+		Relabel: &ngmodels.AlertRelabelConfigs{
+			Labels:      []ngmodels.RelabelConfig{{Action: "labeldrop", Regex: "folder_id|datasource_uid"}},
+			Annotations: []ngmodels.RelabelConfig{{Action: "labeldrop", Regex: "owner_email"}},
+		},
+		TargetRelabels: map[string]ngmodels.AlertRelabelConfigs{
+			fakeTarget.URL: {Labels: []ngmodels.RelabelConfig{{SourceLabels: []string{"severity"}, Regex: "info", Action: "drop"}}},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+	s, err := New(nil)
+	require.NoError(t, err)
+	s.Run()
+	t.Cleanup(s.Stop)
+	require.NoError(t, s.ApplyConfig(cfg))
+	require.Eventually(t, func() bool {
+		return len(s.Alertmanagers()) == 1
+	}, 10*time.Second, 100*time.Millisecond)
+
+	s.SendAlerts(apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{
+		{
+			Annotations: models.LabelSet{"summary": "disk full", "owner_email": "jane@example.com"},
+			Alert:       models.Alert{Labels: models.LabelSet{"alertname": "alert1", "severity": "critical", "folder_id": "3"}},
+		},
+		{Alert: models.Alert{Labels: models.LabelSet{"alertname": "alert2", "severity": "info", "datasource_uid": "abc"}}},
+	}})
+	require.Eventually(t, func() bool {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return len(received["/api/v2/alerts"]) == 1 && len(received["/hook"]) == 2
+	}, 10*time.Second, 100*time.Millisecond)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	sent := received["/api/v2/alerts"][0]
+	require.Equal(t, models.LabelSet{"alertname": "alert1", "severity": "critical"}, sent.Labels)
+	require.Equal(t, models.LabelSet{"summary": "disk full"}, sent.Annotations)
+	require.Equal(t, models.LabelSet{"alertname": "alert1", "severity": "critical"}, received["/hook"][0].Labels)
+	require.Equal(t, models.LabelSet{"alertname": "alert2", "severity": "info"}, received["/hook"][1].Labels)
+}
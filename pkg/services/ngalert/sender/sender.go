@@ -1,10 +1,24 @@
 package sender
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"net/url"
+	"path"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -12,12 +26,14 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 
+	"github.com/golang/snappy"
 	"github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/client_golang/prometheus"
 	common_config "github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/discovery"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
 	"github.com/prometheus/prometheus/notifier"
 	"github.com/prometheus/prometheus/pkg/labels"
 )
@@ -25,6 +41,18 @@ import (
 const (
 	defaultMaxQueueCapacity = 10000
 	defaultTimeout          = 10 * time.Second
+
+	// maxBatchSize is the most alerts the notifier sends in a request.
+	maxBatchSize = 64
+
+	// backlogInterval is how often the backlog is checked for alerts to send again, backlogBackoff the
+	// backoff before they are sent again for the first time and maxBacklogBackoff the longest one.
+	backlogInterval   = 250 * time.Millisecond
+	backlogBackoff    = time.Second
+	maxBacklogBackoff = time.Minute
+
+	// drainInterval is how often the queue is checked while draining the sender.
+	drainInterval = 50 * time.Millisecond
 )
 
 // Sender is responsible for dispatching alert notifications to an external Alertmanager service.
@@ -32,53 +60,581 @@ type Sender struct {
 	logger log.Logger
 	wg     sync.WaitGroup
 
-	manager *notifier.Manager
+	// managers are the notifier managers the alerts are sharded to, each of them sending a batch of alerts
+	// at a time. registries hold their metrics.
+	managers   []*notifier.Manager
+	registries []*prometheus.Registry
+	// enqueued is the total number of alerts handed to the notifier managers.
+	enqueued int64
+	// inflight is the number of requests to the Alertmanager(s) in progress.
+	inflight int64
+
+	// queueCapacity is the number of alerts each manager queues and batchSize the most alerts sent in a request.
+	queueCapacity int
+	batchSize     int
+	// buffered holds the alerts waiting for flushInterval to be handed to the managers, 0 hands them right away.
+	flushInterval time.Duration
+	bufferMtx     sync.Mutex
+	buffered      []*notifier.Alert
 
+	// priorityLabel is the label whose values in highPriority make alerts high priority, empty if alerts have no
+	// priority. priorityQueues hold, per shard, the alerts waiting to be handed to the managers by priority, and
+	// priorityDropped the number of alerts dropped because their queue was full.
+	priorityLabel   string
+	highPriority    map[string]struct{}
+	priorityMtx     sync.Mutex
+	priorityQueues  []priorityQueues
+	priorityDropped map[Priority]int64
+	dispatchMtx     sync.Mutex
+
+	// drainTimeout is how long Stop waits for the alerts queued to be sent, 0 stops right away.
+	// droppedAtStop is the number of alerts still queued when the sender stopped.
+	drainTimeout  time.Duration
+	droppedAtStop int64
+
+	sdCtx     context.Context
 	sdCancel  context.CancelFunc
 	sdManager *discovery.Manager
+
+	// invalid holds the Alertmanager(s) of the current configuration that could not be applied,
+	// amConfigs the ones that were applied.
+	invalidMtx sync.RWMutex
+	invalid    map[string]error
+	amConfigs  []*config.AlertmanagerConfig
+
+	resultsMtx  sync.RWMutex
+	results     map[string]SendResult
+	lastFlush   time.Time
+	lastSuccess time.Time
+
+	// onSendResult is called with the outcome of every attempt to send alerts to an Alertmanager.
+	onSendResult func(SendResult)
+
+	// compress enables gzip compression of the alerts sent, compressions are the compressions of
+	// particular Alertmanager(s), by URL of the Alertmanager without user information, which take
+	// precedence. uncompressed holds the Alertmanager(s) that do not accept compressed alerts.
+	compress        int32
+	compressionsMtx sync.RWMutex
+	compressions    map[string]string
+	uncompressedMtx sync.RWMutex
+	uncompressed    map[string]struct{}
+
+	// retries is the number of times a send failing with a retryable error is retried, waiting
+	// retryBackoff before the first retry and twice as long before each subsequent one.
+	retries      int
+	retryBackoff time.Duration
+
+	// backlog holds the alerts that could not be sent because an Alertmanager was unreachable, to send them
+	// again until they are older than backlogTTL. It holds at most backlogSize batches, 0 disables it.
+	backlogMtx    sync.Mutex
+	backlog       []*backlogEntry
+	backlogSize   int
+	backlogTTL    time.Duration
+	backlogCtx    context.Context
+	backlogCancel context.CancelFunc
+
+	// clients are the HTTP clients of the notifier, by URL of the Alertmanager, that the alerts of the backlog
+	// are sent with. They are reset when a configuration is applied, as the notifier then creates new ones.
+	clientsMtx sync.RWMutex
+	clients    map[string]*http.Client
+
+	// ordered sends the alerts in the order they are sent to the sender, one batch at a time. orderMtx serializes
+	// the sends and the backlog.
+	ordered  bool
+	orderMtx sync.Mutex
+
+	// decrypt decrypts the secure settings of the credentials of the Alertmanager(s). headers are the
+	// headers of the credentials, by URL of the Alertmanager without user information.
+	decrypt    DecryptFn
+	headersMtx sync.RWMutex
+	headers    map[string]map[string]string
+
+	// timeouts are the timeouts of the requests to the Alertmanager(s), by URL of the Alertmanager without user
+	// information, and orgDefaultTimeout the one of the others. They are used to send the backlog again.
+	timeoutsMtx       sync.RWMutex
+	timeouts          map[string]time.Duration
+	orgDefaultTimeout time.Duration
+
+	// pagerDuty is the PagerDuty service alerts are also sent to, nil if none. pagerDutyQueue holds the batches
+	// of alerts waiting to be sent to it.
+	pagerDutyMtx   sync.RWMutex
+	pagerDuty      *pagerDutyTarget
+	pagerDutyQueue chan []*notifier.Alert
+
+	// webhooks are the HTTP endpoints alerts are also posted to. webhookQueue holds the batches of alerts
+	// waiting to be posted to them.
+	webhooksMtx  sync.RWMutex
+	webhooks     []*webhookTarget
+	webhookQueue chan []models.PostableAlert
+
+	// aws is the SNS topic or SQS queue alerts are also published to, nil if none. awsQueue holds the batches
+	// of alerts waiting to be published to it.
+	awsMtx   sync.RWMutex
+	aws      *awsTarget
+	awsQueue chan []*notifier.Alert
+
+	// silences are the silences of the internal Alertmanager, synced with the Alertmanager(s) according to
+	// silenceSync. nil does not sync them.
+	silencesMtx sync.RWMutex
+	silences    SilenceStore
+	silenceSync ngmodels.SilenceSyncMode
+
+	// relabelings are the relabeling rules of the alerts sent to the Alertmanager(s), the PagerDuty service,
+	// the webhooks and SNS or SQS.
+	relabelMtx  sync.RWMutex
+	relabelings relabelings
+}
+
+// DecryptFn returns the decrypted value of a secure setting, or fallback if it is not set.
+type DecryptFn func(ctx context.Context, sjd map[string][]byte, key string, fallback string) string
+
+// backlogEntry is a batch of alerts that could not be sent to an Alertmanager.
+type backlogEntry struct {
+	url      string
+	header   http.Header
+	body     []byte
+	ids      alertIDs
+	failedAt time.Time
+	next     time.Time
+	backoff  time.Duration
+}
+
+// QueueStats is a snapshot of the alerts handled by the sender since it started.
+type QueueStats struct {
+	// Queued is the number of alerts waiting to be sent.
+	Queued int
+	// SentTotal is the number of alerts taken off the queue and sent to the Alertmanager(s), including the batch in flight.
+	SentTotal int
+	// DroppedTotal is the number of alerts dropped because the queue was full or no Alertmanager accepted them.
+	DroppedTotal int
+	// Backlogged is the number of batches of alerts waiting to be sent again to an unreachable Alertmanager.
+	Backlogged int
+	// LastFlush is the last time a batch of alerts was sent to an Alertmanager, zero if none was sent yet.
+	LastFlush time.Time
+	// LastSuccess is the last time an Alertmanager accepted a batch of alerts, zero if none did yet.
+	LastSuccess time.Time
+	// Priorities are the stats of the alerts by priority, nil if the alerts have no priority. Their alerts
+	// queued and dropped are included in Queued and DroppedTotal.
+	Priorities map[Priority]PriorityStats
+}
+
+// AMTestResult is the outcome of testing the connectivity to an Alertmanager.
+type AMTestResult struct {
+	// URL is the URL of the Alertmanager endpoint requested.
+	URL string
+	// Reachable is true if the Alertmanager answered with a successful status code.
+	Reachable bool
+	Latency   time.Duration
+	// StatusCode is the HTTP status code returned by the Alertmanager, 0 if no response was received.
+	StatusCode int
+	Err        error
+}
+
+// SendResult is the outcome of the most recent attempt to send a batch of alerts to an Alertmanager.
+type SendResult struct {
+	// StatusCode is the HTTP status code returned by the Alertmanager, 0 if no response was received.
+	StatusCode int
+	Timestamp  time.Time
+	Err        error
+	// Attempts is the number of times the alerts were sent, more than one if the sends were retried.
+	Attempts int
+	// Alertmanager is the URL the alerts were sent to. Direct is true if it is not an Alertmanager but a target
+	// the alerts are sent to directly: PagerDuty, a webhook, or an SNS topic or SQS queue.
+	Alertmanager string
+	Direct       bool
+	// Alerts is the number of alerts sent, Duration how long it took including the retries.
+	Alerts   int
+	Duration time.Duration
+	// BatchID identifies the batch of alerts sent, it is derived from the alerts: the same batch sent to
+	// several targets, or sent again from the backlog, has the same ID. AlertLabels are the labels of the
+	// alerts of the batch, nil if they could not be read, and AlertIDs their IDs, see AlertID.
+	BatchID     string
+	AlertLabels []map[string]string
+	AlertIDs    []string
+	// PayloadBytes is the size of the alerts sent, SentBytes the size of the body sent last once encoded with
+	// Encoding: gzip, snappy or identity if it was not compressed. They are 0 if the body is unknown.
+	PayloadBytes int64
+	SentBytes    int64
+	Encoding     string
 }
 
 func New(_ *metrics.Scheduler) (*Sender, error) {
 	l := log.New("sender")
 	sdCtx, sdCancel := context.WithCancel(context.Background())
+	backlogCtx, backlogCancel := context.WithCancel(context.Background())
 	s := &Sender{
-		logger:   l,
-		sdCancel: sdCancel,
+		logger:         l,
+		queueCapacity:  defaultMaxQueueCapacity,
+		batchSize:      maxBatchSize,
+		sdCtx:          sdCtx,
+		sdCancel:       sdCancel,
+		results:        map[string]SendResult{},
+		uncompressed:   map[string]struct{}{},
+		backlogCtx:     backlogCtx,
+		backlogCancel:  backlogCancel,
+		pagerDutyQueue: make(chan []*notifier.Alert, pagerDutyQueueCapacity),
+		webhookQueue:   make(chan []models.PostableAlert, webhookQueueCapacity),
+		awsQueue:       make(chan []*notifier.Alert, awsQueueCapacity),
 	}
 
-	s.manager = notifier.NewManager(
+	s.addManager()
+	s.sdManager = discovery.NewManager(sdCtx, s.logger)
+
+	return s, nil
+}
+
+// addManager adds a notifier manager, with its own queue, to the sender.
+func (s *Sender) addManager() {
+	registry := prometheus.NewRegistry()
+	s.registries = append(s.registries, registry)
+	s.managers = append(s.managers, notifier.NewManager(
 		// Injecting a new registry here means these metrics are not exported.
 		// Once we fix the individual Alertmanager metrics we should fix this scenario too.
-		&notifier.Options{QueueCapacity: defaultMaxQueueCapacity, Registerer: prometheus.NewRegistry()},
+		&notifier.Options{QueueCapacity: s.queueCapacity, Registerer: registry, Do: s.do},
 		s.logger,
-	)
+	))
+}
 
-	s.sdManager = discovery.NewManager(sdCtx, s.logger)
+// SetConcurrency sets the number of batches of alerts sent at the same time, 1 by default. Alerts are
+// sharded by labels so that the updates of an alert are sent in order, each shard has its own queue.
+// Every batch is sent to all the Alertmanager(s) in parallel. It must be called before ApplyConfig and Run.
+func (s *Sender) SetConcurrency(concurrency int) {
+	if s.ordered {
+		return
+	}
+	for len(s.managers) < concurrency {
+		s.addManager()
+	}
+}
 
-	return s, nil
+// SetQueue sets the number of alerts each queue holds, 10000 by default, the most alerts sent in a request, 64
+// by default and at most, and how long alerts are buffered before being queued so that they are sent in fewer
+// requests, 0 by default. Alerts are queued without waiting for the flush interval once there is a full batch
+// for every queue. Zero values keep the defaults. It must be called after SetConcurrency, and before ApplyConfig
+// and Run.
+func (s *Sender) SetQueue(capacity, batchSize int, flushInterval time.Duration) {
+	if capacity > 0 && capacity != s.queueCapacity {
+		s.queueCapacity = capacity
+		managers := s.managers
+		s.managers, s.registries = nil, nil
+		for _, m := range managers {
+			m.Stop()
+			s.addManager()
+		}
+	}
+	if batchSize > 0 && batchSize < maxBatchSize {
+		s.batchSize = batchSize
+	}
+	if flushInterval > 0 {
+		s.flushInterval = flushInterval
+	}
 }
 
-// ApplyConfig syncs a configuration with the sender.
+// ApplyConfig syncs a configuration with the sender. Alertmanager(s) with an invalid URL are skipped
+// and reported by InvalidAlertmanagers, the configuration is rejected only if it has no valid target at all.
+// Alerts are also sent to the PagerDuty service, the webhooks and the SNS topic or SQS queue of the configuration,
+// if any.
 func (s *Sender) ApplyConfig(cfg *ngmodels.AdminConfiguration) error {
-	notifierCfg, err := buildNotifierConfig(cfg)
+	if len(cfg.Credentials) > 0 && s.decrypt == nil {
+		return errors.New("the sender cannot decrypt the credentials of the Alertmanager(s)")
+	}
+
+	notifierCfg, invalid := buildNotifierConfig(cfg, s.decrypt)
+	pagerDuty, err := buildPagerDuty(cfg, s.decrypt)
 	if err != nil {
 		return err
 	}
-
-	if err := s.manager.ApplyConfig(notifierCfg); err != nil {
+	webhooks, err := buildWebhooks(cfg)
+	if err != nil {
+		return err
+	}
+	topicOrQueue, err := buildAWS(cfg, s.decrypt, s.retries, s.retryBackoff)
+	if err != nil {
+		return err
+	}
+	if len(invalid) > 0 && len(notifierCfg.AlertingConfig.AlertmanagerConfigs) == 0 &&
+		pagerDuty == nil && len(webhooks) == 0 && topicOrQueue == nil {
+		return invalidAlertmanagersError(invalid)
+	}
+	relabelings, err := buildRelabelings(cfg)
+	if err != nil {
 		return err
 	}
 
+	for _, m := range s.managers {
+		if err := m.ApplyConfig(notifierCfg); err != nil {
+			return err
+		}
+	}
+
 	sdCfgs := make(map[string]discovery.Configs)
 	for k, v := range notifierCfg.AlertingConfig.AlertmanagerConfigs.ToMap() {
 		sdCfgs[k] = v.ServiceDiscoveryConfigs
 	}
 
-	return s.sdManager.ApplyConfig(sdCfgs)
+	if err := s.sdManager.ApplyConfig(sdCfgs); err != nil {
+		return err
+	}
+
+	s.invalidMtx.Lock()
+	s.invalid = invalid
+	s.amConfigs = notifierCfg.AlertingConfig.AlertmanagerConfigs
+	s.invalidMtx.Unlock()
+
+	s.clientsMtx.Lock()
+	s.clients = map[string]*http.Client{}
+	s.clientsMtx.Unlock()
+
+	headers := buildHeaders(cfg, s.decrypt)
+	s.headersMtx.Lock()
+	s.headers = headers
+	s.headersMtx.Unlock()
+
+	timeouts := make(map[string]time.Duration, len(cfg.Timeouts))
+	for amURL := range cfg.Timeouts {
+		if u, err := url.Parse(amURL); err == nil {
+			timeouts[baseURL(u)] = time.Duration(timeoutFor(cfg, amURL))
+		}
+	}
+	s.timeoutsMtx.Lock()
+	s.timeouts = timeouts
+	s.orgDefaultTimeout = time.Duration(timeoutFor(cfg, ""))
+	s.timeoutsMtx.Unlock()
+
+	compressions := make(map[string]string, len(cfg.Compressions))
+	for amURL, compression := range cfg.Compressions {
+		if u, err := url.Parse(amURL); err == nil {
+			compressions[baseURL(u)] = compression
+		}
+	}
+	s.compressionsMtx.Lock()
+	s.compressions = compressions
+	s.compressionsMtx.Unlock()
+
+	s.pagerDutyMtx.Lock()
+	s.pagerDuty = pagerDuty
+	s.pagerDutyMtx.Unlock()
+
+	s.webhooksMtx.Lock()
+	s.webhooks = webhooks
+	s.webhooksMtx.Unlock()
+
+	s.awsMtx.Lock()
+	s.aws = topicOrQueue
+	s.awsMtx.Unlock()
+
+	s.silencesMtx.Lock()
+	s.silenceSync = cfg.SilenceSync
+	s.silencesMtx.Unlock()
+
+	s.relabelMtx.Lock()
+	s.relabelings = relabelings
+	s.relabelMtx.Unlock()
+
+	return nil
+}
+
+// timeoutOf returns the timeout of the requests to the Alertmanager the request URL belongs to.
+func (s *Sender) timeoutOf(u *url.URL) time.Duration {
+	s.timeoutsMtx.RLock()
+	defer s.timeoutsMtx.RUnlock()
+	var match string
+	for k := range s.timeouts {
+		if (u.String() == k || strings.HasPrefix(u.String(), k+"/")) && len(k) > len(match) {
+			match = k
+		}
+	}
+	if match != "" {
+		return s.timeouts[match]
+	}
+	if s.orgDefaultTimeout > 0 {
+		return s.orgDefaultTimeout
+	}
+	return defaultTimeout
+}
+
+// buildHeaders returns, per base URL of the Alertmanager(s), their static headers and the headers of their
+// credentials. Discovered Alertmanager(s) are not sent the headers, as their URLs are not known in advance.
+func buildHeaders(cfg *ngmodels.AdminConfiguration, decrypt DecryptFn) map[string]map[string]string {
+	headers := map[string]map[string]string{}
+	for amURL, static := range cfg.Headers {
+		u, err := url.Parse(amURL)
+		if err != nil || len(static) == 0 {
+			continue
+		}
+		key := baseURL(u)
+		if headers[key] == nil {
+			headers[key] = map[string]string{}
+		}
+		for k, v := range static {
+			headers[key][k] = v
+		}
+	}
+	for amURL, creds := range cfg.Credentials {
+		u, err := url.Parse(amURL)
+		if err != nil {
+			continue
+		}
+		for k := range creds.SecureSettings {
+			if !strings.HasPrefix(k, ngmodels.HeaderKeyPrefix) {
+				continue
+			}
+			key := baseURL(u)
+			if headers[key] == nil {
+				headers[key] = map[string]string{}
+			}
+			headers[key][strings.TrimPrefix(k, ngmodels.HeaderKeyPrefix)] = decrypt(context.Background(), creds.SecureSettings, k, "")
+		}
+	}
+	return headers
+}
+
+// SetDecryptFn sets the function used to decrypt the credentials of the Alertmanager(s). Configurations
+// with credentials cannot be applied without it. It must be called before ApplyConfig.
+func (s *Sender) SetDecryptFn(fn DecryptFn) {
+	s.decrypt = fn
+}
+
+// headersFor returns the headers of the Alertmanager the request URL belongs to.
+func (s *Sender) headersFor(u *url.URL) map[string]string {
+	s.headersMtx.RLock()
+	defer s.headersMtx.RUnlock()
+	return matchHeaders(s.headers, u)
+}
+
+// matchHeaders returns the headers of the longest base URL the request URL starts with.
+func matchHeaders(headers map[string]map[string]string, u *url.URL) map[string]string {
+	var match string
+	for k := range headers {
+		if (u.String() == k || strings.HasPrefix(u.String(), k+"/")) && len(k) > len(match) {
+			match = k
+		}
+	}
+	return headers[match]
+}
+
+// baseURL returns the URL of the Alertmanager without user information and trailing slash, the requests to
+// the Alertmanager start with it.
+func baseURL(u *url.URL) string {
+	return strings.TrimSuffix((&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String(), "/")
+}
+
+// compressionOf returns the compression of the alerts sent to the Alertmanager the request URL belongs to:
+// its own compression if it has one, gzip if compression is enabled and none otherwise.
+func (s *Sender) compressionOf(u *url.URL) string {
+	s.compressionsMtx.RLock()
+	defer s.compressionsMtx.RUnlock()
+	var match string
+	for k := range s.compressions {
+		if (u.String() == k || strings.HasPrefix(u.String(), k+"/")) && len(k) > len(match) {
+			match = k
+		}
+	}
+	if match != "" {
+		return s.compressions[match]
+	}
+	if atomic.LoadInt32(&s.compress) != 0 {
+		return ngmodels.CompressionGzip
+	}
+	return ngmodels.CompressionNone
+}
+
+// OnSendResult registers a function called with the outcome of every attempt to send alerts to
+// an Alertmanager. It must be called before Run.
+func (s *Sender) OnSendResult(fn func(SendResult)) {
+	s.onSendResult = fn
+}
+
+// SetCompression enables or disables the gzip compression of the alerts sent to the Alertmanager(s) without
+// a compression of their own. Compression is best-effort: Alertmanager(s) that reject compressed alerts are
+// sent them uncompressed.
+func (s *Sender) SetCompression(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&s.compress, v)
+}
+
+// SetRetries sets the number of times a send failing with a network error or a timeout, a 5xx or a 429 status
+// code is retried, and the backoff before the first retry, doubled before each subsequent one. Every attempt
+// has the timeout of the Alertmanager. Other failures are not retried. It must be called before Run.
+func (s *Sender) SetRetries(retries int, backoff time.Duration) {
+	s.retries = retries
+	s.retryBackoff = backoff
+}
+
+// SetOrdered sends the alerts to every Alertmanager in the order they are sent to the sender, for Alertmanager(s)
+// that require it, at the cost of throughput: the alerts share a single queue whatever the concurrency and
+// priorities, a single batch is sent at a time, and a batch failing with a retryable error is sent again from the
+// backlog before any newer batch, which waits behind it in the backlog. A batch is only sent out of order if it is
+// discarded from the backlog, because the backlog is full or the batch is older than the TTL. It must be called
+// before SetConcurrency, SetQueue and SetPriority.
+func (s *Sender) SetOrdered(ordered bool) {
+	s.ordered = ordered
+}
+
+// SetBacklog keeps up to size batches of alerts that could not be sent because an Alertmanager was
+// unreachable, and sends them again with an exponential backoff until they are older than ttl. The oldest
+// batch is discarded when the backlog is full. A size of 0 disables it. The alerts sent again can be received
+// after newer updates of the same alerts, unless the sender sends the alerts in order, see SetOrdered. It must be
+// called before Run.
+func (s *Sender) SetBacklog(size int, ttl time.Duration) {
+	s.backlogSize = size
+	s.backlogTTL = ttl
 }
 
 func (s *Sender) Run() {
-	s.wg.Add(2)
+	if s.backlogSize > 0 {
+		s.wg.Add(1)
+		go func() {
+			s.runBacklog()
+			s.wg.Done()
+		}()
+	}
+
+	if s.flushInterval > 0 {
+		s.wg.Add(1)
+		go func() {
+			s.runFlush()
+			s.wg.Done()
+		}()
+	}
+
+	if s.priorityLabel != "" {
+		s.wg.Add(1)
+		go func() {
+			s.runPriority()
+			s.wg.Done()
+		}()
+	}
+
+	s.silencesMtx.RLock()
+	syncSilences := s.silences != nil
+	s.silencesMtx.RUnlock()
+	if syncSilences {
+		s.wg.Add(1)
+		go func() {
+			s.runSilenceSync()
+			s.wg.Done()
+		}()
+	}
+
+	s.wg.Add(3)
+	go func() {
+		s.runPagerDuty()
+		s.wg.Done()
+	}()
+	go func() {
+		s.runWebhooks()
+		s.wg.Done()
+	}()
+	go func() {
+		s.runAWS()
+		s.wg.Done()
+	}()
+
+	s.wg.Add(1 + len(s.managers))
 
 	go func() {
 		if err := s.sdManager.Run(); err != nil {
@@ -87,67 +643,1148 @@ func (s *Sender) Run() {
 		s.wg.Done()
 	}()
 
+	if len(s.managers) == 1 {
+		go func() {
+			s.managers[0].Run(s.sdManager.SyncCh())
+			s.wg.Done()
+		}()
+		return
+	}
+
+	// Every manager needs the discovered Alertmanager(s).
+	syncChs := make([]chan map[string][]*targetgroup.Group, 0, len(s.managers))
+	for _, m := range s.managers {
+		syncCh := make(chan map[string][]*targetgroup.Group)
+		syncChs = append(syncChs, syncCh)
+		go func(m *notifier.Manager) {
+			m.Run(syncCh)
+			s.wg.Done()
+		}(m)
+	}
+	s.wg.Add(1)
 	go func() {
-		s.manager.Run(s.sdManager.SyncCh())
-		s.wg.Done()
+		defer s.wg.Done()
+		for {
+			select {
+			case <-s.sdCtx.Done():
+				return
+			case tsets := <-s.sdManager.SyncCh():
+				for _, syncCh := range syncChs {
+					select {
+					case <-s.sdCtx.Done():
+						return
+					case syncCh <- tsets:
+					}
+				}
+			}
+		}
 	}()
 }
 
-// SendAlerts sends a set of alerts to the configured Alertmanager(s).
+// SendAlerts sends a set of alerts to the configured Alertmanager(s), and PagerDuty, webhooks and SNS or SQS if
+// configured.
 func (s *Sender) SendAlerts(alerts apimodels.PostableAlerts) {
 	if len(alerts.PostableAlerts) == 0 {
 		s.logger.Debug("no alerts to send to external Alertmanager(s)")
 		return
 	}
 	as := make([]*notifier.Alert, 0, len(alerts.PostableAlerts))
+	// The notifier manager sets the labels of the alerts it is sent, the targets without Alertmanager have
+	// their own alerts.
+	direct := make([]*notifier.Alert, 0, len(alerts.PostableAlerts))
 	for _, a := range alerts.PostableAlerts {
-		na := alertToNotifierAlert(a)
-		as = append(as, na)
+		as = append(as, alertToNotifierAlert(a))
+		direct = append(direct, alertToNotifierAlert(a))
+	}
+	s.sendToPagerDuty(direct)
+	s.sendToWebhooks(alerts.PostableAlerts)
+	s.sendToAWS(direct)
+
+	s.logger.Debug("sending alerts to the external Alertmanager(s)", "am_count", len(s.Alertmanagers()), "alert_count", len(as))
+	if s.flushInterval == 0 {
+		s.enqueue(as)
+		return
+	}
+
+	s.bufferMtx.Lock()
+	s.buffered = append(s.buffered, as...)
+	full := len(s.buffered) >= s.batchSize*len(s.managers)
+	s.bufferMtx.Unlock()
+	if full {
+		s.flush()
 	}
+}
+
+// runFlush queues the alerts buffered every flush interval, until the sender is stopped.
+func (s *Sender) runFlush() {
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.sdCtx.Done():
+			return
+		case <-ticker.C:
+			s.flush()
+		}
+	}
+}
+
+// flush queues the alerts buffered.
+func (s *Sender) flush() {
+	s.bufferMtx.Lock()
+	as := s.buffered
+	s.buffered = nil
+	s.bufferMtx.Unlock()
+	if len(as) > 0 {
+		s.enqueue(as)
+	}
+}
 
-	s.logger.Debug("sending alerts to the external Alertmanager(s)", "am_count", len(s.manager.Alertmanagers()), "alert_count", len(as))
-	s.manager.Send(as...)
+// enqueue hands the alerts to the managers, sharded by labels, or adds them to the priority queues if the alerts
+// have a priority.
+func (s *Sender) enqueue(as []*notifier.Alert) {
+	if s.priorityLabel != "" {
+		s.enqueueByPriority(as)
+		return
+	}
+	atomic.AddInt64(&s.enqueued, int64(len(as)))
+	if len(s.managers) == 1 {
+		s.managers[0].Send(as...)
+		return
+	}
+
+	shards := make([][]*notifier.Alert, len(s.managers))
+	for _, a := range as {
+		i := s.shardOf(a)
+		shards[i] = append(shards[i], a)
+	}
+	for i, shard := range shards {
+		if len(shard) > 0 {
+			s.managers[i].Send(shard...)
+		}
+	}
 }
 
-// Stop shuts down the sender.
+// shardOf returns the index of the manager the alert is sent by.
+func (s *Sender) shardOf(a *notifier.Alert) int {
+	if len(s.managers) == 1 {
+		return 0
+	}
+	return int(a.Labels.Hash() % uint64(len(s.managers)))
+}
+
+// Stop shuts down the sender. If a drain timeout is set, it first waits for the alerts queued to be sent,
+// for up to the drain timeout. The alerts still queued then are dropped, see DroppedAtStop.
 func (s *Sender) Stop() {
+	if s.drainTimeout > 0 {
+		s.flush()
+		s.drain()
+	}
+	atomic.StoreInt64(&s.droppedAtStop, int64(s.QueueStats().Queued))
 	s.sdCancel()
-	s.manager.Stop()
+	for _, m := range s.managers {
+		m.Stop()
+	}
+	s.backlogCancel()
 	s.wg.Wait()
 }
 
+// SetDrainTimeout sets how long Stop waits for the alerts queued to be sent before shutting down the sender.
+func (s *Sender) SetDrainTimeout(timeout time.Duration) {
+	s.drainTimeout = timeout
+}
+
+// DroppedAtStop returns the number of alerts that were still queued, and were dropped, when the sender stopped.
+func (s *Sender) DroppedAtStop() int {
+	return int(atomic.LoadInt64(&s.droppedAtStop))
+}
+
+// drain waits for the queue to be empty and the requests in progress to be done, for up to the drain timeout.
+// The queue must be seen empty twice in a row, as the batch taken off the queue is not sent right away.
+func (s *Sender) drain() {
+	idle := func() bool {
+		return s.QueueStats().Queued == 0 && atomic.LoadInt64(&s.inflight) == 0
+	}
+	if idle() {
+		return
+	}
+
+	s.logger.Info("draining the alerts queued before stopping", "queued", s.QueueStats().Queued)
+	timeout := time.NewTimer(s.drainTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+	wasIdle := false
+	for {
+		select {
+		case <-timeout.C:
+			s.logger.Warn("timed out draining the alerts queued", "queued", s.QueueStats().Queued)
+			return
+		case <-ticker.C:
+			isIdle := idle()
+			if isIdle && wasIdle {
+				s.logger.Info("drained the alerts queued")
+				return
+			}
+			wasIdle = isIdle
+		}
+	}
+}
+
 // Alertmanagers returns a list of the discovered Alertmanager(s).
 func (s *Sender) Alertmanagers() []*url.URL {
-	return s.manager.Alertmanagers()
+	return s.managers[0].Alertmanagers()
+}
+
+// SendsDirectly returns true if alerts are also sent directly to PagerDuty, webhooks, SNS or SQS, without
+// Alertmanager.
+func (s *Sender) SendsDirectly() bool {
+	s.pagerDutyMtx.RLock()
+	pd := s.pagerDuty
+	s.pagerDutyMtx.RUnlock()
+	s.awsMtx.RLock()
+	t := s.aws
+	s.awsMtx.RUnlock()
+	s.webhooksMtx.RLock()
+	defer s.webhooksMtx.RUnlock()
+	return pd != nil || t != nil || len(s.webhooks) > 0
 }
 
 // DroppedAlertmanagers returns a list of Alertmanager(s) we no longer send alerts to.
 func (s *Sender) DroppedAlertmanagers() []*url.URL {
-	return s.manager.DroppedAlertmanagers()
+	return s.managers[0].DroppedAlertmanagers()
+}
+
+// TestAlertmanagers requests the health endpoint of every Alertmanager of the current configuration, with
+// the same HTTP client configuration as the alerts sent, and returns whether each of them is reachable.
+// No alert is sent.
+func (s *Sender) TestAlertmanagers(ctx context.Context) []AMTestResult {
+	s.invalidMtx.RLock()
+	amConfigs := s.amConfigs
+	s.invalidMtx.RUnlock()
+
+	var results []AMTestResult
+	for _, amConfig := range amConfigs {
+		for _, u := range targetURLs(amConfig, "/-/healthy") {
+			results = append(results, testAlertmanager(ctx, amConfig, http.MethodGet, u, s.headersFor(u), nil))
+		}
+	}
+	return results
+}
+
+// SendTestAlerts sends alerts to every Alertmanager of a configuration that is not applied to any sender,
+// with the HTTP client configuration the sender would use, and returns whether each of them accepted the
+// alerts. Alertmanager(s) with an invalid URL are reported as such. Alerts are sent only once, without
+// retries nor backlog.
+func SendTestAlerts(ctx context.Context, cfg *ngmodels.AdminConfiguration, decrypt DecryptFn, alerts apimodels.PostableAlerts) []AMTestResult {
+	results := make([]AMTestResult, 0, len(cfg.Alertmanagers))
+	failAll := func(err error) []AMTestResult {
+		for _, amURL := range cfg.Alertmanagers {
+			results = append(results, AMTestResult{URL: amURL, Err: err})
+		}
+		return results
+	}
+	if len(cfg.Credentials) > 0 && decrypt == nil {
+		return failAll(errors.New("the sender cannot decrypt the credentials of the Alertmanager(s)"))
+	}
+	// The alerts of the v1 and v2 APIs have the same JSON encoding.
+	body, err := json.Marshal(alerts.PostableAlerts)
+	if err != nil {
+		return failAll(err)
+	}
+
+	notifierCfg, invalid := buildNotifierConfig(cfg, decrypt)
+	headers := buildHeaders(cfg, decrypt)
+	for amURL, err := range invalid {
+		results = append(results, AMTestResult{URL: amURL, Err: err})
+	}
+	for _, amURL := range cfg.Alertmanagers {
+		if u, err := url.Parse(amURL); err == nil && isDiscovered(u) && invalid[amURL] == nil {
+			results = append(results, AMTestResult{URL: amURL, Err: errors.New("discovered Alertmanagers are not tested before the configuration is saved")})
+		}
+	}
+	for _, amConfig := range notifierCfg.AlertingConfig.AlertmanagerConfigs {
+		for _, u := range targetURLs(amConfig, path.Join("/api", string(amConfig.APIVersion), "alerts")) {
+			results = append(results, testAlertmanager(ctx, amConfig, http.MethodPost, u, matchHeaders(headers, u), body))
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].URL < results[j].URL
+	})
+	return results
+}
+
+// targetURLs returns the URLs of the endpoint with the given path of the static Alertmanager(s) of a configuration.
+func targetURLs(amConfig *config.AlertmanagerConfig, endpoint string) []*url.URL {
+	var res []*url.URL
+	for _, sdConfig := range amConfig.ServiceDiscoveryConfigs {
+		staticConfig, ok := sdConfig.(discovery.StaticConfig)
+		if !ok {
+			continue
+		}
+		for _, group := range staticConfig {
+			for _, target := range group.Targets {
+				res = append(res, &url.URL{
+					Scheme: amConfig.Scheme,
+					Host:   string(target[model.AddressLabel]),
+					Path:   path.Join("/", amConfig.PathPrefix, endpoint),
+				})
+			}
+		}
+	}
+	return res
+}
+
+func testAlertmanager(ctx context.Context, amConfig *config.AlertmanagerConfig, method string, u *url.URL, headers map[string]string, body []byte) AMTestResult {
+	res := AMTestResult{URL: u.String()}
+	client, err := common_config.NewClientFromConfig(amConfig.HTTPClientConfig, "alertmanager")
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(amConfig.Timeout))
+	defer cancel()
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	res.Latency = time.Since(start)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	res.StatusCode = resp.StatusCode
+	if resp.StatusCode/100 != 2 {
+		res.Err = fmt.Errorf("bad response status %s", resp.Status)
+		return res
+	}
+	res.Reachable = true
+	return res
+}
+
+// InvalidAlertmanagers returns the Alertmanager(s) of the current configuration that could not be applied and why.
+func (s *Sender) InvalidAlertmanagers() map[string]error {
+	s.invalidMtx.RLock()
+	defer s.invalidMtx.RUnlock()
+	res := make(map[string]error, len(s.invalid))
+	for u, err := range s.invalid {
+		res[u] = err
+	}
+
+	return res
+}
+
+// LastSendResults returns the result of the most recent send to each of the discovered Alertmanager(s), to
+// PagerDuty by URL of its Events API, to the webhooks and to SNS or SQS by ARN of the topic or URL of the queue.
+// Results of Alertmanager(s) that are no longer discovered are discarded.
+func (s *Sender) LastSendResults() map[string]SendResult {
+	ams := s.Alertmanagers()
+	s.pagerDutyMtx.RLock()
+	pd := s.pagerDuty
+	s.pagerDutyMtx.RUnlock()
+	s.webhooksMtx.RLock()
+	webhooks := s.webhooks
+	s.webhooksMtx.RUnlock()
+	s.awsMtx.RLock()
+	t := s.aws
+	s.awsMtx.RUnlock()
+
+	s.resultsMtx.Lock()
+	defer s.resultsMtx.Unlock()
+	active := make(map[string]struct{}, len(ams)+1)
+	for _, am := range ams {
+		active[am.String()] = struct{}{}
+	}
+	if pd != nil {
+		active[pd.url] = struct{}{}
+	}
+	for _, wh := range webhooks {
+		active[wh.redacted] = struct{}{}
+	}
+	if t != nil {
+		active[t.target] = struct{}{}
+	}
+
+	res := make(map[string]SendResult, len(s.results))
+	for u, r := range s.results {
+		if _, ok := active[u]; !ok {
+			delete(s.results, u)
+			continue
+		}
+		res[u] = r
+	}
+
+	return res
+}
+
+// QueueStats returns the number of alerts queued, sent and dropped by the sender.
+func (s *Sender) QueueStats() QueueStats {
+	var queued, dropped float64
+	for i := range s.registries {
+		q, d := s.managerStats(i)
+		queued += q
+		dropped += d
+	}
+
+	s.resultsMtx.RLock()
+	lastFlush, lastSuccess := s.lastFlush, s.lastSuccess
+	s.resultsMtx.RUnlock()
+
+	s.backlogMtx.Lock()
+	backlogged := len(s.backlog)
+	s.backlogMtx.Unlock()
+
+	s.bufferMtx.Lock()
+	buffered := len(s.buffered)
+	s.bufferMtx.Unlock()
+
+	stats := QueueStats{
+		Queued:       int(queued) + buffered,
+		SentTotal:    int(atomic.LoadInt64(&s.enqueued) - int64(queued) - int64(dropped)),
+		DroppedTotal: int(dropped),
+		LastFlush:    lastFlush,
+		LastSuccess:  lastSuccess,
+		Backlogged:   backlogged,
+		Priorities:   s.priorityStats(),
+	}
+	for _, p := range stats.Priorities {
+		stats.Queued += p.Queued
+		stats.DroppedTotal += p.DroppedTotal
+	}
+	return stats
+}
+
+// managerStats returns the number of alerts queued and dropped by a manager.
+func (s *Sender) managerStats(i int) (queued float64, dropped float64) {
+	mfs, err := s.registries[i].Gather()
+	if err != nil {
+		s.logger.Warn("failed to gather the notifier metrics", "err", err)
+	}
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			switch mf.GetName() {
+			case "prometheus_notifications_queue_length":
+				queued += m.GetGauge().GetValue()
+			case "prometheus_notifications_dropped_total":
+				dropped += m.GetCounter().GetValue()
+			}
+		}
+	}
+	return queued, dropped
+}
+
+// managerQueued returns the number of alerts queued by a manager.
+func (s *Sender) managerQueued(i int) int {
+	queued, _ := s.managerStats(i)
+	return int(queued)
+}
+
+// do sends the alerts of the request to the Alertmanager, relabeled, in requests of at most batchSize alerts.
+// It returns the response of the first request that failed, if any. The deadline of the notifier is ignored,
+// every attempt to send the alerts has its own.
+func (s *Sender) do(_ context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
+	relabeled, err := s.relabelRequest(req)
+	if err != nil {
+		s.logger.Warn("failed to relabel the alerts sent", "alertmanager", req.URL.Redacted(), "err", err)
+		return nil, err
+	}
+	if relabeled == nil {
+		// All the alerts were dropped, there is nothing to send.
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+	}
+	req = relabeled
+	if s.batchSize >= maxBatchSize || req.GetBody == nil {
+		return s.doBatch(client, req)
+	}
+	req = req.WithContext(context.WithValue(req.Context(), splitBatchesKey{}, &splitBatches{}))
+	reqs, err := splitRequest(req, s.batchSize)
+	if err != nil {
+		s.logger.Warn("failed to split the alerts sent in batches", "alertmanager", req.URL.String(), "err", err)
+		return s.doBatch(client, req)
+	}
+
+	var res *http.Response
+	var resErr error
+	for i, r := range reqs {
+		resp, err := s.doBatch(client, r)
+		// Keep the response of the first request that failed, or of the last one if none did.
+		if i == 0 || (resErr == nil && res.StatusCode/100 == 2) {
+			discardResponse(res)
+			res, resErr = resp, err
+			continue
+		}
+		discardResponse(resp)
+	}
+	return res, resErr
+}
+
+// splitBatchesKey is the key of the splitBatches of a request split in batches in its context.
+type splitBatchesKey struct{}
+
+// splitBatches is the first result of the batches of a request that failed, if any, guarded by resultsMtx.
+type splitBatches struct {
+	failed *SendResult
+}
+
+// discardResponse reads and closes the body of the response, if any.
+func discardResponse(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+// splitRequest returns copies of the request with at most batchSize of its alerts each.
+func splitRequest(req *http.Request, batchSize int) ([]*http.Request, error) {
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = body.Close() }()
+	var alerts []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&alerts); err != nil {
+		return nil, err
+	}
+	if len(alerts) <= batchSize {
+		return []*http.Request{req}, nil
+	}
+
+	reqs := make([]*http.Request, 0, (len(alerts)+batchSize-1)/batchSize)
+	for i := 0; i < len(alerts); i += batchSize {
+		end := i + batchSize
+		if end > len(alerts) {
+			end = len(alerts)
+		}
+		b, err := json.Marshal(alerts[i:end])
+		if err != nil {
+			return nil, err
+		}
+		r, err := http.NewRequestWithContext(req.Context(), req.Method, req.URL.String(), bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		r.Header = req.Header.Clone()
+		reqs = append(reqs, r)
+	}
+	return reqs, nil
+}
+
+// doBatch sends the request to the Alertmanager and keeps track of the outcome.
+func (s *Sender) doBatch(client *http.Client, req *http.Request) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if s.backlogSize > 0 {
+		s.setClient(req.URL.String(), client)
+	}
+	atomic.AddInt64(&s.inflight, 1)
+	defer atomic.AddInt64(&s.inflight, -1)
+
+	if s.ordered {
+		s.orderMtx.Lock()
+		defer s.orderMtx.Unlock()
+		if s.backlogSize > 0 && req.GetBody != nil && !s.flushBacklogInOrder(req.URL.String()) {
+			return s.deferBatch(req)
+		}
+	}
+
+	var (
+		resp     *http.Response
+		sent     sentBody
+		err      error
+		attempts int
+	)
+	// The notifier sends the alerts with a single deadline, the timeout of the Alertmanager, which retries would
+	// exceed. Every attempt has the timeout instead, until the sender is stopped.
+	timeout := s.timeoutOf(req.URL)
+	start := time.Now()
+	backoff := s.retryBackoff
+	for {
+		attempts++
+		attemptCtx, cancel := context.WithTimeout(s.sdCtx, timeout)
+		resp, sent, err = s.send(attemptCtx, client, req)
+		if resp != nil {
+			resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		} else {
+			cancel()
+		}
+		if attempts > s.retries || req.GetBody == nil || !retryable(resp, err) {
+			break
+		}
+
+		discardResponse(resp)
+		s.logger.Debug("retrying to send alerts", "alertmanager", req.URL.String(), "attempt", attempts, "err", err)
+		select {
+		case <-s.sdCtx.Done():
+		case <-time.After(backoff):
+		}
+		if s.sdCtx.Err() != nil {
+			resp, err = nil, s.sdCtx.Err()
+			break
+		}
+		backoff *= 2
+
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			resp, err = nil, bodyErr
+			break
+		}
+		req.Body = body
+	}
+
+	if s.backlogSize > 0 && req.GetBody != nil && retryable(resp, err) {
+		s.addToBacklog(req)
+	}
+
+	batchID, alertLabels, ids := sentAlerts(req)
+	s.recordResult(req.Context(), SendResult{
+		Err:          err,
+		Attempts:     attempts,
+		Alertmanager: req.URL.String(),
+		Alerts:       len(alertLabels),
+		Duration:     time.Since(start),
+		BatchID:      batchID,
+		AlertLabels:  alertLabels,
+		AlertIDs:     ids,
+		PayloadBytes: req.ContentLength,
+		SentBytes:    sent.size,
+		Encoding:     sent.encoding,
+	}, resp)
+
+	return resp, err
+}
+
+// sentAlerts returns the ID of the batch of alerts in the body of the request, the labels of the alerts and
+// their IDs, no ID and no labels if the body cannot be read.
+func sentAlerts(req *http.Request) (string, []map[string]string, []string) {
+	if req.GetBody == nil {
+		return "", nil, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", nil, nil
+	}
+	defer func() { _ = body.Close() }()
+	raw, err := ioutil.ReadAll(body)
+	if err != nil {
+		return "", nil, nil
+	}
+	// The alerts of both the v1 and v2 APIs have their labels in a labels object.
+	var alerts []struct {
+		Labels map[string]string `json:"labels"`
+	}
+	if err := json.Unmarshal(raw, &alerts); err != nil {
+		return "", nil, nil
+	}
+	relabeled, _ := req.Context().Value(alertIDsKey{}).(alertIDs)
+	alertLabels := make([]map[string]string, 0, len(alerts))
+	ids := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		alertLabels = append(alertLabels, a.Labels)
+		id := alertID(a.Labels)
+		if original, ok := relabeled[id]; ok {
+			ids = append(ids, original...)
+		} else {
+			ids = append(ids, id)
+		}
+	}
+	return batchID(raw), alertLabels, ids
+}
+
+// AlertID returns the ID the alert is reported with by the AlertIDs of the results of its sends, whatever the
+// relabeling of its labels by the sender.
+func AlertID(alert models.PostableAlert) string {
+	return alertID(alertToNotifierAlert(alert).Labels.Map())
+}
+
+// alertID returns the ID of the alert with the labels.
+func alertID(lbls map[string]string) string {
+	return fmt.Sprintf("%016x", labels.FromMap(lbls).Hash())
+}
+
+// alertIDsKey is the key of the alertIDs of a relabeled request in its context.
+type alertIDsKey struct{}
+
+// alertIDs are the IDs of the alerts of a relabeled request, by ID of the alerts once relabeled. Alerts
+// relabeled the same have several IDs.
+type alertIDs map[string][]string
+
+// batchID returns the ID of the batch of alerts of the body.
+func batchID(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:16])
+}
+
+// recordResult keeps track of the outcome of sending alerts to the Alertmanager. The last result of an
+// Alertmanager is the first failure of the batches of a request split in batches, if any, so that the
+// batches sent successfully after it do not hide it.
+func (s *Sender) recordResult(ctx context.Context, res SendResult, resp *http.Response) {
+	res.Timestamp = time.Now()
+	if resp != nil {
+		res.StatusCode = resp.StatusCode
+		// Any HTTP status 2xx is OK.
+		if resp.StatusCode/100 != 2 {
+			res.Err = fmt.Errorf("bad response status %s", resp.Status)
+		}
+	}
+
+	s.resultsMtx.Lock()
+	last := res
+	if split, ok := ctx.Value(splitBatchesKey{}).(*splitBatches); ok {
+		if split.failed == nil && res.Err != nil {
+			split.failed = &res
+		} else if split.failed != nil {
+			last = *split.failed
+		}
+	}
+	s.results[res.Alertmanager] = last
+	s.lastFlush = res.Timestamp
+	if res.Err == nil {
+		s.lastSuccess = res.Timestamp
+	}
+	s.resultsMtx.Unlock()
+
+	if s.onSendResult != nil {
+		s.onSendResult(res)
+	}
+}
+
+// addToBacklog keeps the alerts of the request to send them again later, discarding the oldest batch
+// if the backlog is full.
+func (s *Sender) addToBacklog(req *http.Request) {
+	body, err := req.GetBody()
+	if err != nil {
+		s.logger.Warn("failed to add alerts to the backlog", "alertmanager", req.URL.String(), "err", err)
+		return
+	}
+	defer func() { _ = body.Close() }()
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		s.logger.Warn("failed to add alerts to the backlog", "alertmanager", req.URL.String(), "err", err)
+		return
+	}
+
+	now := time.Now()
+	s.backlogMtx.Lock()
+	defer s.backlogMtx.Unlock()
+	if len(s.backlog) >= s.backlogSize {
+		s.logger.Warn("backlog is full, discarding the oldest alerts", "alertmanager", s.backlog[0].url)
+		s.backlog = s.backlog[1:]
+	}
+	ids, _ := req.Context().Value(alertIDsKey{}).(alertIDs)
+	s.backlog = append(s.backlog, &backlogEntry{
+		url:      req.URL.String(),
+		header:   req.Header.Clone(),
+		body:     b,
+		ids:      ids,
+		failedAt: now,
+		next:     now.Add(backlogBackoff),
+		backoff:  backlogBackoff,
+	})
+}
+
+// runBacklog sends the alerts of the backlog again when their backoff is over, until the sender is stopped.
+func (s *Sender) runBacklog() {
+	ticker := time.NewTicker(backlogInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.backlogCtx.Done():
+			return
+		case <-ticker.C:
+			s.flushBacklog()
+		}
+	}
+}
+
+// flushBacklog sends the alerts of the backlog whose backoff is over, and discards the ones sent, rejected
+// or older than the TTL.
+func (s *Sender) flushBacklog() {
+	if s.ordered {
+		s.orderMtx.Lock()
+		s.flushBacklogInOrder("")
+		s.orderMtx.Unlock()
+		return
+	}
+
+	now := time.Now()
+	var due []*backlogEntry
+	s.backlogMtx.Lock()
+	kept := s.backlog[:0]
+	for _, e := range s.backlog {
+		switch {
+		case s.expired(e, now):
+		case !e.next.After(now):
+			due = append(due, e)
+		default:
+			kept = append(kept, e)
+		}
+	}
+	s.backlog = kept
+	s.backlogMtx.Unlock()
+
+	for _, e := range due {
+		if s.resend(e) {
+			continue
+		}
+		s.backlogMtx.Lock()
+		if len(s.backlog) < s.backlogSize {
+			s.backlog = append(s.backlog, e)
+		} else {
+			s.logger.Warn("backlog is full, discarding the alerts", "alertmanager", e.url)
+		}
+		s.backlogMtx.Unlock()
+	}
 }
 
-func buildNotifierConfig(cfg *ngmodels.AdminConfiguration) (*config.Config, error) {
+// flushBacklogInOrder sends the alerts of the backlog of every Alertmanager in the order they were added to it,
+// and stops at the first batch of an Alertmanager that is not sent, so that its newer batches are not sent before
+// it. If amURL is not empty, only the batches of that Alertmanager are sent, whatever their backoff, and it
+// returns true if none of them is left. It must be called with the order lock held.
+func (s *Sender) flushBacklogInOrder(amURL string) bool {
+	now := time.Now()
+	s.backlogMtx.Lock()
+	backlog := append([]*backlogEntry(nil), s.backlog...)
+	s.backlogMtx.Unlock()
+
+	blocked := map[string]bool{}
+	kept := make([]*backlogEntry, 0, len(backlog))
+	for _, e := range backlog {
+		switch {
+		case s.expired(e, now):
+		case blocked[e.url] || (amURL != "" && e.url != amURL):
+			kept = append(kept, e)
+		case amURL == "" && e.next.After(now):
+			blocked[e.url] = true
+			kept = append(kept, e)
+		case !s.resend(e):
+			blocked[e.url] = true
+			kept = append(kept, e)
+		}
+	}
+
+	// The backlog is only added to with the order lock held.
+	s.backlogMtx.Lock()
+	s.backlog = kept
+	s.backlogMtx.Unlock()
+	return !blocked[amURL]
+}
+
+// deferBatch adds the alerts of the request to the backlog, behind the alerts that could not be sent to the
+// Alertmanager yet, and records that they were not sent.
+func (s *Sender) deferBatch(req *http.Request) (*http.Response, error) {
+	s.addToBacklog(req)
+	err := errors.New("older alerts could not be sent to the Alertmanager yet, the alerts were added to the backlog")
+	batchID, alertLabels, ids := sentAlerts(req)
+	s.recordResult(req.Context(), SendResult{
+		Err:          err,
+		Alertmanager: req.URL.String(),
+		Alerts:       len(alertLabels),
+		BatchID:      batchID,
+		AlertLabels:  alertLabels,
+		AlertIDs:     ids,
+		PayloadBytes: req.ContentLength,
+	}, nil)
+	return nil, err
+}
+
+// expired returns true, and logs it, if the alerts of the backlog entry are older than the TTL.
+func (s *Sender) expired(e *backlogEntry, now time.Time) bool {
+	if s.backlogTTL <= 0 || now.Sub(e.failedAt) <= s.backlogTTL {
+		return false
+	}
+	s.logger.Warn("alerts were not sent before the backlog TTL, discarding them", "alertmanager", e.url, "failed_at", e.failedAt)
+	return true
+}
+
+// resend sends the alerts of the backlog entry again. It returns true if they were sent or rejected, false if
+// they must be sent again once the backoff of the entry, which it doubles, is over.
+func (s *Sender) resend(e *backlogEntry) bool {
+	timeout := defaultTimeout
+	if u, err := url.Parse(e.url); err == nil {
+		timeout = s.timeoutOf(u)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if e.ids != nil {
+		ctx = context.WithValue(ctx, alertIDsKey{}, e.ids)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(e.body))
+	if err != nil {
+		s.logger.Warn("failed to send alerts of the backlog", "alertmanager", e.url, "err", err)
+		return true
+	}
+	req.Header = e.header.Clone()
+	client, ok := s.clientOf(e.url)
+	if !ok {
+		s.logger.Debug("alertmanager of the backlog has no client yet, sending its alerts later", "alertmanager", e.url)
+		e.next = time.Now().Add(e.backoff)
+		return false
+	}
+	start := time.Now()
+	resp, sent, err := s.send(ctx, client, req)
+	retry := retryable(resp, err)
+	batchID, alertLabels, ids := sentAlerts(req)
+	s.recordResult(ctx, SendResult{
+		Err:          err,
+		Attempts:     1,
+		Alertmanager: e.url,
+		Alerts:       len(alertLabels),
+		Duration:     time.Since(start),
+		BatchID:      batchID,
+		AlertLabels:  alertLabels,
+		AlertIDs:     ids,
+		PayloadBytes: req.ContentLength,
+		SentBytes:    sent.size,
+		Encoding:     sent.encoding,
+	}, resp)
+	discardResponse(resp)
+
+	if err == nil && resp.StatusCode/100 == 2 {
+		s.logger.Debug("sent alerts of the backlog", "alertmanager", e.url)
+		return true
+	}
+	if !retry {
+		s.logger.Warn("alerts of the backlog were rejected, discarding them", "alertmanager", e.url, "err", err)
+		return true
+	}
+
+	e.backoff *= 2
+	if e.backoff > maxBacklogBackoff {
+		e.backoff = maxBacklogBackoff
+	}
+	e.next = time.Now().Add(e.backoff)
+	return false
+}
+
+// clientOf returns the HTTP client the notifier sends the alerts to the Alertmanager with, the client of its
+// configuration for a static Alertmanager the notifier did not send alerts to since the configuration was applied.
+// It returns false for the other Alertmanager(s), whose client is known once the notifier sends alerts to them.
+func (s *Sender) clientOf(amURL string) (*http.Client, bool) {
+	s.clientsMtx.RLock()
+	client, ok := s.clients[amURL]
+	s.clientsMtx.RUnlock()
+	if ok {
+		return client, true
+	}
+
+	s.invalidMtx.RLock()
+	amConfigs := s.amConfigs
+	s.invalidMtx.RUnlock()
+	for _, amConfig := range amConfigs {
+		for _, u := range targetURLs(amConfig, path.Join("/api", string(amConfig.APIVersion), "alerts")) {
+			if u.String() != amURL {
+				continue
+			}
+			client, err := common_config.NewClientFromConfig(amConfig.HTTPClientConfig, "alertmanager")
+			if err != nil {
+				s.logger.Warn("failed to create the client of the alertmanager", "alertmanager", amURL, "err", err)
+				return nil, false
+			}
+			s.setClient(amURL, client)
+			return client, true
+		}
+	}
+	return nil, false
+}
+
+// setClient records the HTTP client the notifier sends the alerts to the Alertmanager with.
+func (s *Sender) setClient(amURL string, client *http.Client) {
+	s.clientsMtx.Lock()
+	defer s.clientsMtx.Unlock()
+	if s.clients == nil {
+		s.clients = map[string]*http.Client{}
+	}
+	s.clients[amURL] = client
+}
+
+// sentBody is the body of a request as it was sent: its size and its encoding, identity if it was not
+// compressed.
+type sentBody struct {
+	size     int64
+	encoding string
+}
+
+// send sends the request to the Alertmanager, compressing its body with the compression of the Alertmanager.
+// If the Alertmanager rejects the compressed body but accepts the uncompressed one, it is not compressed anymore.
+func (s *Sender) send(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, sentBody, error) {
+	for k, v := range s.headersFor(req.URL) {
+		req.Header.Set(k, v)
+	}
+
+	amURL := req.URL.String()
+	plain := sentBody{size: req.ContentLength, encoding: encodingIdentity}
+	s.uncompressedMtx.RLock()
+	_, uncompressed := s.uncompressed[amURL]
+	s.uncompressedMtx.RUnlock()
+	compression := s.compressionOf(req.URL)
+	if compression == ngmodels.CompressionNone || uncompressed || req.GetBody == nil {
+		resp, err := client.Do(req.WithContext(ctx))
+		return resp, plain, err
+	}
+
+	compressed, err := compressRequest(req, compression)
+	if err != nil {
+		return nil, plain, err
+	}
+	resp, err := client.Do(compressed.WithContext(ctx))
+	if err != nil || (resp.StatusCode != http.StatusBadRequest && resp.StatusCode != http.StatusUnsupportedMediaType) {
+		return resp, sentBody{size: compressed.ContentLength, encoding: compression}, err
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, plain, err
+	}
+	req.Body = body
+	resp, err = client.Do(req.WithContext(ctx))
+	if err == nil && resp.StatusCode/100 == 2 {
+		s.logger.Warn("alertmanager does not accept compressed alerts, sending them uncompressed", "alertmanager", amURL, "compression", compression)
+		s.uncompressedMtx.Lock()
+		s.uncompressed[amURL] = struct{}{}
+		s.uncompressedMtx.Unlock()
+	}
+	return resp, plain, err
+}
+
+// retryable returns true if the send failed with a network error, including a timeout, a server error or because
+// of rate limiting. Sends canceled because the sender is stopped are not retried.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// cancelOnClose cancels the context of a request once the body of its response is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// encodingIdentity is the encoding of the bodies sent uncompressed.
+const encodingIdentity = "identity"
+
+// compressRequest returns a copy of the request with a body compressed with gzip, or with the block format of
+// snappy like the remote write protocol.
+func compressRequest(req *http.Request, compression string) (*http.Request, error) {
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = body.Close() }()
+
+	var buf bytes.Buffer
+	switch compression {
+	case ngmodels.CompressionGzip:
+		gz := gzip.NewWriter(&buf)
+		if _, err := io.Copy(gz, body); err != nil {
+			return nil, err
+		}
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+	case ngmodels.CompressionSnappy:
+		raw, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(snappy.Encode(nil, raw))
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compression)
+	}
+
+	compressed := req.Clone(req.Context())
+	compressed.Body = ioutil.NopCloser(&buf)
+	compressed.ContentLength = int64(buf.Len())
+	compressed.GetBody = nil
+	compressed.Header.Set("Content-Encoding", compression)
+	return compressed, nil
+}
+
+// buildNotifierConfig builds the notifier configuration for the valid Alertmanager(s) of the configuration.
+// It returns the invalid ones along with the reason they are invalid.
+// invalidAlertmanagersError returns an error listing the Alertmanager(s) with an invalid URL and why, by URL.
+func invalidAlertmanagersError(invalid map[string]error) error {
+	urls := make([]string, 0, len(invalid))
+	for amURL := range invalid {
+		urls = append(urls, amURL)
+	}
+	sort.Strings(urls)
+	msgs := make([]string, 0, len(urls))
+	for _, amURL := range urls {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", amURL, invalid[amURL]))
+	}
+	return fmt.Errorf("no valid Alertmanager: %s", strings.Join(msgs, "; "))
+}
+
+func buildNotifierConfig(cfg *ngmodels.AdminConfiguration, decrypt DecryptFn) (*config.Config, map[string]error) {
 	amConfigs := make([]*config.AlertmanagerConfig, 0, len(cfg.Alertmanagers))
+	invalid := map[string]error{}
 	for _, amURL := range cfg.Alertmanagers {
 		u, err := url.Parse(amURL)
 		if err != nil {
-			return nil, err
+			invalid[amURL] = err
+			continue
 		}
 
-		sdConfig := discovery.Configs{
-			discovery.StaticConfig{
-				{
-					Targets: []model.LabelSet{{model.AddressLabel: model.LabelValue(u.Host)}},
-				},
-			},
+		sdConfigs, scheme, pathPrefix, err := serviceDiscovery(u)
+		if err != nil {
+			invalid[amURL] = err
+			continue
 		}
 
 		amConfig := &config.AlertmanagerConfig{
-			APIVersion:              config.AlertmanagerAPIVersionV2,
-			Scheme:                  u.Scheme,
-			PathPrefix:              u.Path,
-			Timeout:                 model.Duration(defaultTimeout),
-			ServiceDiscoveryConfigs: sdConfig,
+			APIVersion:              apiVersion(cfg, amURL),
+			Scheme:                  scheme,
+			PathPrefix:              pathPrefix,
+			Timeout:                 timeoutFor(cfg, amURL),
+			ServiceDiscoveryConfigs: sdConfigs,
+		}
+
+		if proxyURL := proxyFor(cfg, amURL, u); proxyURL != "" {
+			pu, err := url.Parse(proxyURL)
+			if err != nil {
+				invalid[amURL] = fmt.Errorf("invalid proxy: %w", err)
+				continue
+			}
+			amConfig.HTTPClientConfig.ProxyURL = common_config.URL{URL: pu}
+		}
+
+		if tlsCfg, ok := cfg.TLSConfigs[amURL]; ok {
+			amConfig.HTTPClientConfig.TLSConfig = common_config.TLSConfig{
+				CAFile:             tlsCfg.CAFile,
+				CertFile:           tlsCfg.CertFile,
+				KeyFile:            tlsCfg.KeyFile,
+				InsecureSkipVerify: tlsCfg.InsecureSkipVerify,
+			}
 		}
 
 		// Check the URL for basic authentication information first
@@ -160,6 +1797,19 @@ func buildNotifierConfig(cfg *ngmodels.AdminConfiguration) (*config.Config, erro
 				amConfig.HTTPClientConfig.BasicAuth.Password = common_config.Secret(password)
 			}
 		}
+
+		if creds, ok := cfg.Credentials[amURL]; ok {
+			amConfig.HTTPClientConfig.BasicAuth = nil
+			if creds.BasicAuthUser != "" {
+				amConfig.HTTPClientConfig.BasicAuth = &common_config.BasicAuth{
+					Username: creds.BasicAuthUser,
+					Password: common_config.Secret(decrypt(context.Background(), creds.SecureSettings, ngmodels.BasicAuthPasswordKey, "")),
+				}
+			}
+			if token := decrypt(context.Background(), creds.SecureSettings, ngmodels.BearerTokenKey, ""); token != "" {
+				amConfig.HTTPClientConfig.BearerToken = common_config.Secret(token)
+			}
+		}
 		amConfigs = append(amConfigs, amConfig)
 	}
 
@@ -169,7 +1819,68 @@ func buildNotifierConfig(cfg *ngmodels.AdminConfiguration) (*config.Config, erro
 		},
 	}
 
-	return notifierConfig, nil
+	return notifierConfig, invalid
+}
+
+// apiVersion returns the version of the API of the Alertmanager alerts are sent to, v2 unless configured
+// otherwise. The notifier encodes the alerts for the version of the API.
+func apiVersion(cfg *ngmodels.AdminConfiguration, amURL string) config.AlertmanagerAPIVersion {
+	if cfg.APIVersions[amURL] == ngmodels.AlertmanagerAPIVersionV1 {
+		return config.AlertmanagerAPIVersionV1
+	}
+	return config.AlertmanagerAPIVersionV2
+}
+
+// timeoutFor returns the timeout of the requests to the Alertmanager, the one of the organization if it has none
+// and the default timeout if neither is set. Invalid timeouts are ignored, they are rejected when saved.
+func timeoutFor(cfg *ngmodels.AdminConfiguration, amURL string) model.Duration {
+	for _, timeout := range []string{cfg.Timeouts[amURL], cfg.Timeout} {
+		if d, err := time.ParseDuration(timeout); err == nil && d > 0 {
+			return model.Duration(d)
+		}
+	}
+	return model.Duration(defaultTimeout)
+}
+
+// proxyFor returns the proxy used to send alerts to the Alertmanager, empty if none.
+func proxyFor(cfg *ngmodels.AdminConfiguration, amURL string, u *url.URL) string {
+	if proxyURL, ok := cfg.ProxyURLs[amURL]; ok {
+		return proxyURL
+	}
+	if cfg.ProxyURL == "" || matchesNoProxy(u.Hostname(), cfg.NoProxy) {
+		return ""
+	}
+	return cfg.ProxyURL
+}
+
+// matchesNoProxy returns true if the host must be reached without proxy. Hosts match "*", an IP address or CIDR
+// containing them, and a domain name they are or are a subdomain of, with or without a leading dot.
+func matchesNoProxy(host string, noProxy []string) bool {
+	ip := net.ParseIP(host)
+	for _, np := range noProxy {
+		np = strings.ToLower(strings.TrimSpace(np))
+		if np == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(np); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if npIP := net.ParseIP(np); npIP != nil {
+			if npIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		domain := strings.TrimPrefix(np, ".")
+		h := strings.ToLower(host)
+		if h == domain || strings.HasSuffix(h, "."+domain) {
+			return true
+		}
+	}
+	return false
 }
 
 func alertToNotifierAlert(alert models.PostableAlert) *notifier.Alert {
@@ -198,3 +1909,57 @@ func alertToNotifierAlert(alert models.PostableAlert) *notifier.Alert {
 func removeSpaces(labelName string) string {
 	return strings.Join(strings.Fields(labelName), "")
 }
+
+// httpClientFor returns the client of the requests to a target of the configuration that is not an Alertmanager,
+// with the timeout of the organization and its proxy.
+func httpClientFor(cfg *ngmodels.AdminConfiguration, targetURL string, u *url.URL) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL := proxyFor(cfg, targetURL, u); proxyURL != "" {
+		pu, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(pu)
+	}
+	return &http.Client{Transport: transport, Timeout: time.Duration(timeoutFor(cfg, ""))}, nil
+}
+
+// post sends the body to a target that is not an Alertmanager, retrying it like the alerts sent to the
+// Alertmanager(s). It returns the number of attempts and the HTTP status code of the last response, 0 if none
+// was received.
+func (s *Sender) post(ctx context.Context, client *http.Client, targetURL, contentType string, body []byte) (int, int, error) {
+	var (
+		resp     *http.Response
+		err      error
+		attempts int
+	)
+	backoff := s.retryBackoff
+	for {
+		attempts++
+		var req *http.Request
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+		if err != nil {
+			return attempts, 0, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		resp, err = client.Do(req)
+		if attempts > s.retries || !retryable(resp, err) {
+			break
+		}
+		discardResponse(resp)
+		select {
+		case <-ctx.Done():
+			return attempts, 0, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	if err != nil {
+		return attempts, 0, err
+	}
+	defer discardResponse(resp)
+	if resp.StatusCode/100 != 2 {
+		return attempts, resp.StatusCode, fmt.Errorf("bad response status %s", resp.Status)
+	}
+	return attempts, resp.StatusCode, nil
+}
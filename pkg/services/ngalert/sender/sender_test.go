@@ -0,0 +1,478 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// fakeAlertmanager records the names of the alerts it accepts, after failing the given number of requests with
+// a 503 status code, and not answering the given number of requests before they time out. If a password is set,
+// requests without it are rejected with a 401 status code.
+type fakeAlertmanager struct {
+	*httptest.Server
+	mtx      sync.Mutex
+	failures int
+	hangs    int
+	password string
+	received []string
+}
+
+func newFakeAlertmanager(t *testing.T) *fakeAlertmanager {
+	t.Helper()
+	am := &fakeAlertmanager{}
+	am.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alerts []struct {
+			Labels map[string]string `json:"labels"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		am.mtx.Lock()
+		if am.hangs > 0 {
+			am.hangs--
+			am.mtx.Unlock()
+			<-r.Context().Done()
+			return
+		}
+		defer am.mtx.Unlock()
+		if _, password, _ := r.BasicAuth(); password != am.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if am.failures > 0 {
+			am.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		for _, a := range alerts {
+			am.received = append(am.received, a.Labels["alertname"])
+		}
+	}))
+	t.Cleanup(am.Close)
+	return am
+}
+
+func (am *fakeAlertmanager) fail(n int) {
+	am.mtx.Lock()
+	defer am.mtx.Unlock()
+	am.failures = n
+}
+
+func (am *fakeAlertmanager) hang(n int) {
+	am.mtx.Lock()
+	defer am.mtx.Unlock()
+	am.hangs = n
+}
+
+func (am *fakeAlertmanager) requirePassword(password string) {
+	am.mtx.Lock()
+	defer am.mtx.Unlock()
+	am.password = password
+}
+
+func (am *fakeAlertmanager) alerts() []string {
+	am.mtx.Lock()
+	defer am.mtx.Unlock()
+	return append([]string(nil), am.received...)
+}
+
+// runSender starts the sender with the Alertmanager and waits for it to be discovered.
+func runSender(t *testing.T, s *Sender, am *fakeAlertmanager) {
+	t.Helper()
+	runSenderWithConfig(t, s, am, &ngmodels.AdminConfiguration{OrgID: 1, Alertmanagers: []string{am.URL}})
+}
+
+// runSenderWithConfig starts the sender with the configuration and waits for the Alertmanager to be discovered.
+func runSenderWithConfig(t *testing.T, s *Sender, am *fakeAlertmanager, cfg *ngmodels.AdminConfiguration) {
+	t.Helper()
+	s.Run()
+	t.Cleanup(s.Stop)
+	require.NoError(t, s.ApplyConfig(cfg))
+	require.Eventually(t, func() bool {
+		return len(s.Alertmanagers()) == 1
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+// runSenderWithTargets starts the sender with the configuration of targets sent to without Alertmanager.
+func runSenderWithTargets(t *testing.T, s *Sender, cfg *ngmodels.AdminConfiguration) {
+	t.Helper()
+	require.NoError(t, cfg.Validate())
+	s.Run()
+	t.Cleanup(s.Stop)
+	require.NoError(t, s.ApplyConfig(cfg))
+	require.True(t, s.SendsDirectly())
+}
+
+func TestApplyConfigInvalidAlertmanagers(t *testing.T) {
+	invalidURLs := []string{"dns+http://alertmanager.example.com", "consul+http://alertmanager"}
+
+	t.Run("a configuration without any valid target is rejected with the errors of all the Alertmanager(s)", func(t *testing.T) {
+		s, err := New(nil)
+		require.NoError(t, err)
+		err = s.ApplyConfig(&ngmodels.AdminConfiguration{OrgID: 1, Alertmanagers: invalidURLs})
+		require.EqualError(t, err, `no valid Alertmanager: consul+http://alertmanager: unknown Alertmanager discovery "consul"; `+
+			"dns+http://alertmanager.example.com: discovering Alertmanagers from A records requires a port")
+	})
+
+	t.Run("alerts are sent to the direct targets of a configuration without any valid Alertmanager", func(t *testing.T) {
+		var mtx sync.Mutex
+		requests := 0
+		fakeWebhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mtx.Lock()
+			requests++
+			mtx.Unlock()
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer fakeWebhook.Close()
+
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.Run()
+		t.Cleanup(s.Stop)
+		require.NoError(t, s.ApplyConfig(&ngmodels.AdminConfiguration{
+			OrgID:         1,
+			Alertmanagers: invalidURLs,
+			Webhooks:      []ngmodels.AlertWebhookConfig{{URL: fakeWebhook.URL}},
+		}))
+		require.Len(t, s.InvalidAlertmanagers(), 2)
+
+		s.SendAlerts(postableAlerts("alert1"))
+		require.Eventually(t, func() bool {
+			mtx.Lock()
+			defer mtx.Unlock()
+			return requests == 1
+		}, 10*time.Second, 100*time.Millisecond)
+	})
+}
+
+func postableAlerts(names ...string) apimodels.PostableAlerts {
+	alerts := apimodels.PostableAlerts{}
+	for _, name := range names {
+		alerts.PostableAlerts = append(alerts.PostableAlerts, models.PostableAlert{
+			Alert: models.Alert{Labels: models.LabelSet{"alertname": name}},
+		})
+	}
+	return alerts
+}
+
+func TestOrderedDelivery(t *testing.T) {
+	t.Run("a single queue is used whatever the concurrency and priorities", func(t *testing.T) {
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetOrdered(true)
+		s.SetConcurrency(4)
+		s.SetPriority("severity", []string{"critical"})
+		require.Len(t, s.managers, 1)
+		require.Empty(t, s.priorityLabel)
+	})
+
+	t.Run("newer alerts wait for the alerts of the backlog", func(t *testing.T) {
+		am := newFakeAlertmanager(t)
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetOrdered(true)
+		s.SetBacklog(10, time.Hour)
+		runSender(t, s, am)
+
+		// The first batch fails, and fails again when it is sent before the second batch, which waits behind it.
+		am.fail(2)
+		s.SendAlerts(postableAlerts("first"))
+		require.Eventually(t, func() bool {
+			return s.QueueStats().Backlogged == 1
+		}, 10*time.Second, 50*time.Millisecond)
+		s.SendAlerts(postableAlerts("second"))
+		require.Eventually(t, func() bool {
+			return s.QueueStats().Backlogged == 2
+		}, 10*time.Second, 50*time.Millisecond)
+
+		require.Eventually(t, func() bool {
+			return s.QueueStats().Backlogged == 0
+		}, 10*time.Second, 100*time.Millisecond)
+		require.Equal(t, []string{"first", "second"}, am.alerts())
+	})
+
+	t.Run("alerts of the backlog are sent before newer alerts", func(t *testing.T) {
+		am := newFakeAlertmanager(t)
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetOrdered(true)
+		s.SetBacklog(10, time.Hour)
+		runSender(t, s, am)
+
+		am.fail(1)
+		s.SendAlerts(postableAlerts("first"))
+		require.Eventually(t, func() bool {
+			return s.QueueStats().Backlogged == 1
+		}, 10*time.Second, 50*time.Millisecond)
+		s.SendAlerts(postableAlerts("second"))
+		require.Eventually(t, func() bool {
+			return len(am.alerts()) == 2
+		}, 10*time.Second, 50*time.Millisecond)
+		require.Equal(t, []string{"first", "second"}, am.alerts())
+		require.Zero(t, s.QueueStats().Backlogged)
+	})
+}
+
+func TestRetries(t *testing.T) {
+	t.Run("server errors are retried and client errors fail fast", func(t *testing.T) {
+		flaky := newFakeAlertmanager(t)
+		flaky.fail(2)
+		var badRequestHits int32
+		badRequest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&badRequestHits, 1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		t.Cleanup(badRequest.Close)
+
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetRetries(3, 10*time.Millisecond)
+		s.Run()
+		t.Cleanup(s.Stop)
+		require.NoError(t, s.ApplyConfig(&ngmodels.AdminConfiguration{OrgID: 1, Alertmanagers: []string{flaky.URL, badRequest.URL}}))
+		require.Eventually(t, func() bool {
+			return len(s.Alertmanagers()) == 2
+		}, 10*time.Second, 100*time.Millisecond)
+
+		s.SendAlerts(postableAlerts("retried"))
+		require.Eventually(t, func() bool {
+			return len(s.LastSendResults()) == 2
+		}, 10*time.Second, 50*time.Millisecond)
+		for u, res := range s.LastSendResults() {
+			if strings.HasPrefix(u, flaky.URL) {
+				require.NoError(t, res.Err)
+				require.Equal(t, 3, res.Attempts)
+			} else {
+				require.Error(t, res.Err)
+				require.Equal(t, http.StatusBadRequest, res.StatusCode)
+				require.Equal(t, 1, res.Attempts)
+			}
+		}
+		require.Equal(t, []string{"retried"}, flaky.alerts())
+		require.Equal(t, int32(1), atomic.LoadInt32(&badRequestHits))
+	})
+
+	t.Run("every attempt has the timeout of the Alertmanager", func(t *testing.T) {
+		am := newFakeAlertmanager(t)
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetRetries(2, 10*time.Millisecond)
+		runSenderWithConfig(t, s, am, &ngmodels.AdminConfiguration{OrgID: 1, Alertmanagers: []string{am.URL}, Timeout: "200ms"})
+
+		// The first attempt times out, the retry must not be cut short by the deadline of the first one.
+		am.hang(1)
+		s.SendAlerts(postableAlerts("test"))
+		require.Eventually(t, func() bool {
+			return len(am.alerts()) == 1
+		}, 10*time.Second, 50*time.Millisecond)
+
+		res := s.LastSendResults()
+		require.Len(t, res, 1)
+		for _, r := range res {
+			require.NoError(t, r.Err)
+			require.Equal(t, 2, r.Attempts)
+		}
+	})
+}
+
+func TestBacklog(t *testing.T) {
+	t.Run("alerts are kept while the Alertmanager is unavailable", func(t *testing.T) {
+		am := newFakeAlertmanager(t)
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetBacklog(10, time.Hour)
+		runSender(t, s, am)
+
+		am.fail(math.MaxInt32)
+		s.SendAlerts(postableAlerts("test"))
+		require.Eventually(t, func() bool {
+			return s.QueueStats().Backlogged == 1
+		}, 10*time.Second, 50*time.Millisecond)
+		require.Empty(t, am.alerts())
+
+		// And sent once it is available again.
+		am.fail(0)
+		require.Eventually(t, func() bool {
+			return s.QueueStats().Backlogged == 0
+		}, 10*time.Second, 100*time.Millisecond)
+		require.Equal(t, []string{"test"}, am.alerts())
+	})
+
+	t.Run("alerts that timed out are sent again from the backlog", func(t *testing.T) {
+		am := newFakeAlertmanager(t)
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetBacklog(10, time.Hour)
+		runSenderWithConfig(t, s, am, &ngmodels.AdminConfiguration{OrgID: 1, Alertmanagers: []string{am.URL}, Timeout: "200ms"})
+
+		am.hang(1)
+		s.SendAlerts(postableAlerts("test"))
+		require.Eventually(t, func() bool {
+			return s.QueueStats().Backlogged == 1
+		}, 10*time.Second, 50*time.Millisecond)
+		require.Empty(t, am.alerts())
+
+		require.Eventually(t, func() bool {
+			return s.QueueStats().Backlogged == 0
+		}, 10*time.Second, 100*time.Millisecond)
+		require.Equal(t, []string{"test"}, am.alerts())
+	})
+
+	t.Run("alerts of the backlog are sent with the client of the current configuration", func(t *testing.T) {
+		am := newFakeAlertmanager(t)
+		am.requirePassword("old")
+		u, err := url.Parse(am.URL)
+		require.NoError(t, err)
+		withPassword := func(password string) *ngmodels.AdminConfiguration {
+			u.User = url.UserPassword("grafana", password)
+			return &ngmodels.AdminConfiguration{OrgID: 1, Alertmanagers: []string{u.String()}, Timeout: "200ms"}
+		}
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetBacklog(10, time.Hour)
+		runSenderWithConfig(t, s, am, withPassword("old"))
+
+		am.hang(1)
+		s.SendAlerts(postableAlerts("test"))
+		require.Eventually(t, func() bool {
+			return s.QueueStats().Backlogged == 1
+		}, 10*time.Second, 50*time.Millisecond)
+
+		// The password changes while the alerts are in the backlog.
+		am.requirePassword("new")
+		require.NoError(t, s.ApplyConfig(withPassword("new")))
+		require.Eventually(t, func() bool {
+			return s.QueueStats().Backlogged == 0
+		}, 10*time.Second, 100*time.Millisecond)
+		require.Equal(t, []string{"test"}, am.alerts())
+	})
+}
+
+func TestAlertIDs(t *testing.T) {
+	// run sends the alert with a label dropped by the relabeling, and returns the IDs the sender reports it with
+	// once the Alertmanager accepted it.
+	run := func(t *testing.T, s *Sender, am *fakeAlertmanager, alert models.PostableAlert) []string {
+		var (
+			mtx sync.Mutex
+			ids []string
+		)
+		s.OnSendResult(func(res SendResult) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			if res.Err == nil {
+				ids = append(ids, res.AlertIDs...)
+			}
+		})
+		runSenderWithConfig(t, s, am, &ngmodels.AdminConfiguration{
+			OrgID:         1,
+			Alertmanagers: []string{am.URL},
+			Timeout:       "200ms",
+			Relabel:       &ngmodels.AlertRelabelConfigs{Labels: []ngmodels.RelabelConfig{{Action: "labeldrop", Regex: "pod"}}},
+		})
+		s.SendAlerts(apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{alert}})
+		require.Eventually(t, func() bool {
+			mtx.Lock()
+			defer mtx.Unlock()
+			return len(ids) > 0
+		}, 10*time.Second, 50*time.Millisecond)
+		mtx.Lock()
+		defer mtx.Unlock()
+		return ids
+	}
+	alert := models.PostableAlert{Alert: models.Alert{Labels: models.LabelSet{"alertname": "test", "pod": "pod-1"}}}
+	relabeled := models.PostableAlert{Alert: models.Alert{Labels: models.LabelSet{"alertname": "test"}}}
+	require.NotEqual(t, AlertID(alert), AlertID(relabeled))
+
+	t.Run("relabeled alerts are reported with the ID of the alerts sent", func(t *testing.T) {
+		am := newFakeAlertmanager(t)
+		s, err := New(nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{AlertID(alert)}, run(t, s, am, alert))
+	})
+
+	t.Run("relabeled alerts sent from the backlog keep their ID", func(t *testing.T) {
+		am := newFakeAlertmanager(t)
+		am.hang(1)
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetBacklog(10, time.Hour)
+		require.Equal(t, []string{AlertID(alert)}, run(t, s, am, alert))
+		require.Zero(t, s.QueueStats().Backlogged)
+	})
+}
+
+func TestSplitBatches(t *testing.T) {
+	t.Run("a batch sent successfully does not hide the failure of an earlier batch", func(t *testing.T) {
+		am := newFakeAlertmanager(t)
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetQueue(0, 1, 0)
+		var (
+			mtx     sync.Mutex
+			results []SendResult
+		)
+		s.OnSendResult(func(res SendResult) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			results = append(results, res)
+		})
+		runSender(t, s, am)
+
+		// The alerts are sent in a single request, split in a batch per alert.
+		amURL := s.Alertmanagers()[0].String()
+		am.fail(1)
+		resp, err := s.do(context.Background(), nil, newAlertsRequest(t, amURL, postableAlerts("first", "second")))
+		require.NoError(t, err)
+		discardResponse(resp)
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		require.Equal(t, []string{"second"}, am.alerts())
+
+		mtx.Lock()
+		require.Len(t, results, 2)
+		require.Error(t, results[0].Err)
+		require.NoError(t, results[1].Err)
+		mtx.Unlock()
+		res := s.LastSendResults()
+		require.Len(t, res, 1)
+		for _, r := range res {
+			require.Error(t, r.Err)
+			require.Equal(t, http.StatusServiceUnavailable, r.StatusCode)
+		}
+
+		// The next request is not affected by the failure.
+		resp, err = s.do(context.Background(), nil, newAlertsRequest(t, amURL, postableAlerts("third")))
+		require.NoError(t, err)
+		discardResponse(resp)
+		for _, r := range s.LastSendResults() {
+			require.NoError(t, r.Err)
+		}
+	})
+}
+
+// newAlertsRequest returns the request the notifier sends the alerts to the Alertmanager with.
+func newAlertsRequest(t *testing.T, amURL string, alerts apimodels.PostableAlerts) *http.Request {
+	t.Helper()
+	b, err := json.Marshal(alerts.PostableAlerts)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, amURL, bytes.NewReader(b))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
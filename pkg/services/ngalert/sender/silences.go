@@ -0,0 +1,403 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	common_config "github.com/prometheus/common/config"
+	"github.com/prometheus/prometheus/config"
+)
+
+// silenceSyncInterval is how often the silences are synced with the external Alertmanager(s).
+const silenceSyncInterval = time.Minute
+
+// grafanaSilenceMarker and alertmanagerSilenceMarker, followed by the ID of the silence mirrored, mark the
+// comment of the mirrors of the silences of the internal Alertmanager and of the external Alertmanager(s). They
+// find the mirror of a silence, and keep a mirror from being mirrored back.
+const (
+	grafanaSilenceMarker      = "grafana-silence-id="
+	alertmanagerSilenceMarker = "alertmanager-silence-id="
+)
+
+// SilenceStore is the internal Alertmanager of an organization, whose silences are synced with its external
+// Alertmanager(s).
+type SilenceStore interface {
+	ListSilences(filter []string) (apimodels.GettableSilences, error)
+	CreateSilence(ps *apimodels.PostableSilence) (string, error)
+	DeleteSilence(silenceID string) error
+}
+
+// SetSilenceStore sets the internal Alertmanager whose silences are synced with the external Alertmanager(s),
+// according to the silence sync of the configuration. It must be set before Run.
+func (s *Sender) SetSilenceStore(store SilenceStore) {
+	s.silencesMtx.Lock()
+	defer s.silencesMtx.Unlock()
+	s.silences = store
+}
+
+func (s *Sender) runSilenceSync() {
+	ticker := time.NewTicker(silenceSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.sdCtx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SyncSilences(s.sdCtx); err != nil {
+				s.logger.Error("failed to sync the silences with the external Alertmanager(s)", "err", err)
+			}
+		}
+	}
+}
+
+// SyncSilences mirrors the active silences of the internal Alertmanager to the static Alertmanager(s) of the
+// configuration that expose the v2 API, and expires the mirrors of the silences that are no longer active. If
+// the sync is bidirectional, the active silences of the Alertmanager(s) are also mirrored to the internal
+// Alertmanager.
+//
+// The silence mirrored from always wins: a mirror changed on its own is overwritten, and a mirror expired on its
+// own is created again, while the silence it mirrors is active. Mirrors are never mirrored back.
+func (s *Sender) SyncSilences(ctx context.Context) error {
+	s.silencesMtx.RLock()
+	store, mode := s.silences, s.silenceSync
+	s.silencesMtx.RUnlock()
+	if store == nil || mode == ngmodels.SilenceSyncDisabled {
+		return nil
+	}
+
+	internal, err := store.ListSilences(nil)
+	if err != nil {
+		return fmt.Errorf("failed to list the silences of the internal Alertmanager: %w", err)
+	}
+
+	s.invalidMtx.RLock()
+	amConfigs := s.amConfigs
+	s.invalidMtx.RUnlock()
+
+	var errs []string
+	// external are the active silences of the Alertmanager(s) to mirror to the internal Alertmanager, by ID.
+	// The replicas of an Alertmanager cluster have the same silences.
+	external := map[string]*models.GettableSilence{}
+	for _, amConfig := range amConfigs {
+		if amConfig.APIVersion != config.AlertmanagerAPIVersionV2 {
+			continue
+		}
+		client, err := common_config.NewClientFromConfig(amConfig.HTTPClientConfig, "alertmanager")
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, u := range targetURLs(amConfig, "/api/v2") {
+			api := &silenceAPI{client: client, url: u, headers: s.headersFor(u), timeout: time.Duration(amConfig.Timeout)}
+			silences, err := api.list(ctx)
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			if err := s.pushSilences(ctx, api, internal, silences); err != nil {
+				errs = append(errs, err.Error())
+			}
+			for _, sil := range silences {
+				if _, mirror := markedID(sil, grafanaSilenceMarker); isActive(sil) && !mirror {
+					external[*sil.ID] = sil
+				}
+			}
+		}
+	}
+
+	if mode == ngmodels.SilenceSyncBidirectional {
+		// The mirrors of the silences of an Alertmanager that could not be listed are kept.
+		if err := s.pullSilences(store, internal, external, len(errs) == 0); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// pushSilences mirrors the active silences of the internal Alertmanager to an external Alertmanager, given its
+// silences, and expires the mirrors of the silences that are no longer active.
+func (s *Sender) pushSilences(ctx context.Context, api *silenceAPI, internal, external apimodels.GettableSilences) error {
+	mirrors := map[string][]*models.GettableSilence{}
+	for _, sil := range external {
+		if id, ok := markedID(sil, grafanaSilenceMarker); ok && isActive(sil) {
+			mirrors[id] = append(mirrors[id], sil)
+		}
+	}
+
+	var errs []string
+	active := map[string]struct{}{}
+	for _, sil := range internal {
+		if _, mirror := markedID(sil, alertmanagerSilenceMarker); !isActive(sil) || mirror {
+			continue
+		}
+		active[*sil.ID] = struct{}{}
+		desired := mirrorOf(sil, grafanaSilenceMarker)
+		existing := mirrors[*sil.ID]
+		if len(existing) == 0 {
+			if _, err := api.post(ctx, desired); err != nil {
+				errs = append(errs, err.Error())
+			}
+			continue
+		}
+		// A silence can be mirrored more than once if it was mirrored to several replicas of an Alertmanager
+		// cluster before they gossiped.
+		for _, dup := range existing[1:] {
+			if err := api.expire(ctx, *dup.ID); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if !sameSilence(desired, existing[0]) {
+			s.logger.Info("updating the mirror of a silence in an external Alertmanager", "alertmanager", api.url.Redacted(), "silence", *sil.ID, "mirror", *existing[0].ID)
+			updateOf(desired, existing[0])
+			if _, err := api.post(ctx, desired); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	for id, existing := range mirrors {
+		if _, ok := active[id]; ok {
+			continue
+		}
+		for _, sil := range existing {
+			if err := api.expire(ctx, *sil.ID); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// pullSilences mirrors the active silences of the external Alertmanager(s) to the internal Alertmanager. If all
+// of them were listed, complete, the mirrors of the silences that are no longer active are expired.
+func (s *Sender) pullSilences(store SilenceStore, internal apimodels.GettableSilences, external map[string]*models.GettableSilence, complete bool) error {
+	mirrors := map[string][]*models.GettableSilence{}
+	for _, sil := range internal {
+		if id, ok := markedID(sil, alertmanagerSilenceMarker); ok && isActive(sil) {
+			mirrors[id] = append(mirrors[id], sil)
+		}
+	}
+
+	var errs []string
+	for id, sil := range external {
+		desired := mirrorOf(sil, alertmanagerSilenceMarker)
+		existing := mirrors[id]
+		if len(existing) == 0 {
+			if _, err := store.CreateSilence(desired); err != nil {
+				errs = append(errs, fmt.Sprintf("failed to mirror silence %s to the internal Alertmanager: %s", id, err))
+			}
+			continue
+		}
+		for _, dup := range existing[1:] {
+			if err := store.DeleteSilence(*dup.ID); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if !sameSilence(desired, existing[0]) {
+			s.logger.Info("updating the mirror of a silence of an external Alertmanager", "silence", id, "mirror", *existing[0].ID)
+			updateOf(desired, existing[0])
+			if _, err := store.CreateSilence(desired); err != nil {
+				errs = append(errs, fmt.Sprintf("failed to update the mirror of silence %s in the internal Alertmanager: %s", id, err))
+			}
+		}
+	}
+
+	if complete {
+		for id, existing := range mirrors {
+			if _, ok := external[id]; ok {
+				continue
+			}
+			for _, sil := range existing {
+				if err := store.DeleteSilence(*sil.ID); err != nil {
+					errs = append(errs, err.Error())
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// isActive returns true if the silence is active or pending.
+func isActive(sil *models.GettableSilence) bool {
+	return sil.Status != nil && sil.Status.State != nil && *sil.Status.State != models.SilenceStatusStateExpired
+}
+
+// markedID returns the ID of the silence the silence is a mirror of, if its comment ends with the marker.
+func markedID(sil *models.GettableSilence, marker string) (string, bool) {
+	if sil.Comment == nil {
+		return "", false
+	}
+	c := *sil.Comment
+	i := strings.LastIndex(c, "["+marker)
+	if i < 0 || !strings.HasSuffix(c, "]") {
+		return "", false
+	}
+	return c[i+len(marker)+1 : len(c)-1], true
+}
+
+// mirrorOf returns the mirror of a silence, whose comment ends with the marker and the ID of the silence.
+func mirrorOf(sil *models.GettableSilence, marker string) *models.PostableSilence {
+	comment := fmt.Sprintf("[%s%s]", marker, *sil.ID)
+	if sil.Comment != nil && *sil.Comment != "" {
+		comment = *sil.Comment + " " + comment
+	}
+	return &models.PostableSilence{
+		Silence: models.Silence{
+			Comment:   &comment,
+			CreatedBy: sil.CreatedBy,
+			StartsAt:  sil.StartsAt,
+			EndsAt:    sil.EndsAt,
+			Matchers:  sil.Matchers,
+		},
+	}
+}
+
+// updateOf makes a mirror the update of the existing one. The start of an active silence cannot be changed, an
+// Alertmanager starts a silence when it is created if its start is in the past.
+func updateOf(mirror *models.PostableSilence, existing *models.GettableSilence) {
+	mirror.ID = *existing.ID
+	if mirror.StartsAt != nil && time.Time(*mirror.StartsAt).Before(time.Now()) {
+		mirror.StartsAt = existing.StartsAt
+	}
+}
+
+// sameSilence returns true if a mirror has the matchers, end, author and comment it should have. The start is not
+// compared as it is moved to the creation of the mirror if it is in the past, and the end is compared to the
+// millisecond as the API returns it.
+func sameSilence(mirror *models.PostableSilence, existing *models.GettableSilence) bool {
+	str := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	if str(mirror.Comment) != str(existing.Comment) || str(mirror.CreatedBy) != str(existing.CreatedBy) {
+		return false
+	}
+	if (mirror.EndsAt == nil) != (existing.EndsAt == nil) ||
+		(mirror.EndsAt != nil && !time.Time(*mirror.EndsAt).Truncate(time.Millisecond).Equal(time.Time(*existing.EndsAt).Truncate(time.Millisecond))) {
+		return false
+	}
+	return matchersKey(mirror.Matchers) == matchersKey(existing.Matchers)
+}
+
+// matchersKey returns a key of the matchers that is the same for the same matchers in any order.
+func matchersKey(matchers models.Matchers) string {
+	keys := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		if m == nil || m.Name == nil || m.Value == nil {
+			continue
+		}
+		op := "="
+		if m.IsEqual != nil && !*m.IsEqual {
+			op = "!="
+		}
+		if m.IsRegex != nil && *m.IsRegex {
+			op += "~"
+		}
+		keys = append(keys, fmt.Sprintf("%q%s%q", *m.Name, op, *m.Value))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// silenceAPI is the silences API v2 of an external Alertmanager.
+type silenceAPI struct {
+	client *http.Client
+	// url is the URL of the v2 API.
+	url     *url.URL
+	headers map[string]string
+	timeout time.Duration
+}
+
+func (a *silenceAPI) list(ctx context.Context) (apimodels.GettableSilences, error) {
+	var silences apimodels.GettableSilences
+	if err := a.do(ctx, http.MethodGet, "silences", nil, &silences); err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+func (a *silenceAPI) post(ctx context.Context, sil *models.PostableSilence) (string, error) {
+	var res struct {
+		SilenceID string `json:"silenceID"`
+	}
+	if err := a.do(ctx, http.MethodPost, "silences", sil, &res); err != nil {
+		return "", err
+	}
+	return res.SilenceID, nil
+}
+
+func (a *silenceAPI) expire(ctx context.Context, id string) error {
+	return a.do(ctx, http.MethodDelete, path.Join("silence", url.PathEscape(id)), nil, nil)
+}
+
+func (a *silenceAPI) do(ctx context.Context, method, endpoint string, body, result interface{}) error {
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+	u := *a.url
+	u.Path = path.Join(u.Path, endpoint)
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range a.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, u.Redacted(), err)
+	}
+	defer discardResponse(resp)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s %s: bad response status %s", method, u.Redacted(), resp.Status)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("%s %s: %w", method, u.Redacted(), err)
+	}
+	return nil
+}
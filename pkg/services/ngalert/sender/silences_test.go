@@ -0,0 +1,201 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+// fakeSilences are the silences of a fake Alertmanager, either the internal one or an external one.
+type fakeSilences struct {
+	mtx      sync.Mutex
+	silences map[string]*models.GettableSilence
+	nextID   int
+}
+
+func (f *fakeSilences) ListSilences(_ []string) (apimodels.GettableSilences, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	res := make(apimodels.GettableSilences, 0, len(f.silences))
+	for _, sil := range f.silences {
+		s := *sil
+		res = append(res, &s)
+	}
+	return res, nil
+}
+
+func (f *fakeSilences) CreateSilence(ps *apimodels.PostableSilence) (string, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	id := ps.ID
+	if id == "" {
+		f.nextID++
+		id = fmt.Sprintf("silence-%d", f.nextID)
+	} else if _, ok := f.silences[id]; !ok {
+		return "", notifier.ErrSilenceNotFound
+	}
+	state := models.SilenceStatusStateActive
+	updatedAt := strfmt.DateTime(time.Now())
+	f.silences[id] = &models.GettableSilence{ID: &id, Status: &models.SilenceStatus{State: &state}, UpdatedAt: &updatedAt, Silence: ps.Silence}
+	return id, nil
+}
+
+func (f *fakeSilences) DeleteSilence(id string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	sil, ok := f.silences[id]
+	if !ok {
+		return notifier.ErrSilenceNotFound
+	}
+	state := models.SilenceStatusStateExpired
+	sil.Status = &models.SilenceStatus{State: &state}
+	return nil
+}
+
+// active returns the active silences, by comment.
+func (f *fakeSilences) active() map[string]*models.GettableSilence {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	res := map[string]*models.GettableSilence{}
+	for _, sil := range f.silences {
+		if *sil.Status.State == models.SilenceStatusStateActive {
+			res[*sil.Comment] = sil
+		}
+	}
+	return res
+}
+
+func TestSilenceSync(t *testing.T) {
+	newSilence := func(comment string) *apimodels.PostableSilence {
+		name, value, isRegex, createdBy := "alertname", "test", false, "grafana"
+		startsAt, endsAt := strfmt.DateTime(time.Now()), strfmt.DateTime(time.Now().Add(time.Hour))
+		return &apimodels.PostableSilence{Silence: models.Silence{
+			Comment:   &comment,
+			CreatedBy: &createdBy,
+			StartsAt:  &startsAt,
+			EndsAt:    &endsAt,
+			Matchers:  models.Matchers{{Name: &name, Value: &value, IsRegex: &isRegex}},
+		}}
+	}
+
+	setup := func(t *testing.T, mode ngmodels.SilenceSyncMode) (*Sender, *fakeSilences, *fakeSilences) {
+		t.Helper()
+		internal := &fakeSilences{silences: map[string]*models.GettableSilence{}}
+		external := &fakeSilences{silences: map[string]*models.GettableSilence{}}
+		fakeAM := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/api/v2/silences":
+				silences, _ := external.ListSilences(nil)
+				_ = json.NewEncoder(w).Encode(silences)
+			case r.Method == http.MethodPost && r.URL.Path == "/api/v2/silences":
+				var ps apimodels.PostableSilence
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&ps))
+				id, err := external.CreateSilence(&ps)
+				if err != nil {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]string{"silenceID": id})
+			case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/v2/silence/"):
+				if err := external.DeleteSilence(strings.TrimPrefix(r.URL.Path, "/api/v2/silence/")); err != nil {
+					w.WriteHeader(http.StatusNotFound)
+				}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		t.Cleanup(fakeAM.Close)
+
+		s, err := New(nil)
+		require.NoError(t, err)
+		s.SetSilenceStore(internal)
+		require.NoError(t, s.ApplyConfig(&ngmodels.AdminConfiguration{Alertmanagers: []string{fakeAM.URL}, SilenceSync: mode}))
+		return s, internal, external
+	}
+	ctx := context.Background()
+
+	t.Run("silences of Grafana are mirrored to the external Alertmanager", func(t *testing.T) {
+		s, internal, external := setup(t, ngmodels.SilenceSyncPush)
+		id, err := internal.CreateSilence(newSilence("maintenance"))
+		require.NoError(t, err)
+
+		require.NoError(t, s.SyncSilences(ctx))
+		mirrorComment := "maintenance [grafana-silence-id=" + id + "]"
+		require.Contains(t, external.active(), mirrorComment)
+		require.Len(t, external.silences, 1)
+
+		updatedAt := external.active()[mirrorComment].UpdatedAt
+		require.NoError(t, s.SyncSilences(ctx))
+		require.Len(t, external.silences, 1, "the mirror is not created again")
+		require.Equal(t, updatedAt, external.active()[mirrorComment].UpdatedAt, "the mirror is not updated")
+
+		// A mirror changed in the external Alertmanager is overwritten.
+		mirror := external.active()[mirrorComment]
+		changed := newSilence(mirrorComment)
+		changed.ID = *mirror.ID
+		changedEndsAt := strfmt.DateTime(time.Now().Add(time.Minute))
+		changed.EndsAt = &changedEndsAt
+		_, err = external.CreateSilence(changed)
+		require.NoError(t, err)
+		require.NoError(t, s.SyncSilences(ctx))
+		internalSilence := internal.active()["maintenance"]
+		require.WithinDuration(t, time.Time(*internalSilence.EndsAt), time.Time(*external.active()[mirrorComment].EndsAt), time.Millisecond)
+
+		// A mirror expired in the external Alertmanager is created again.
+		require.NoError(t, external.DeleteSilence(*mirror.ID))
+		require.NoError(t, s.SyncSilences(ctx))
+		require.Contains(t, external.active(), mirrorComment)
+
+		require.NoError(t, internal.DeleteSilence(id))
+		require.NoError(t, s.SyncSilences(ctx))
+		require.Empty(t, external.active())
+	})
+
+	t.Run("silences of the external Alertmanager are mirrored to Grafana if bidirectional", func(t *testing.T) {
+		s, internal, external := setup(t, ngmodels.SilenceSyncBidirectional)
+		id, err := external.CreateSilence(newSilence("deploy"))
+		require.NoError(t, err)
+
+		require.NoError(t, s.SyncSilences(ctx))
+		require.Contains(t, internal.active(), "deploy [alertmanager-silence-id="+id+"]")
+		require.NoError(t, s.SyncSilences(ctx))
+		require.Len(t, internal.silences, 1)
+		require.Len(t, external.silences, 1, "mirrors are not mirrored back")
+
+		require.NoError(t, external.DeleteSilence(id))
+		require.NoError(t, s.SyncSilences(ctx))
+		require.Empty(t, internal.active())
+	})
+
+	t.Run("silences of the external Alertmanager are not mirrored to Grafana if push", func(t *testing.T) {
+		s, internal, external := setup(t, ngmodels.SilenceSyncPush)
+		_, err := external.CreateSilence(newSilence("deploy"))
+		require.NoError(t, err)
+
+		require.NoError(t, s.SyncSilences(ctx))
+		require.Empty(t, internal.silences)
+	})
+
+	t.Run("silences are not synced by default", func(t *testing.T) {
+		s, internal, external := setup(t, ngmodels.SilenceSyncDisabled)
+		_, err := internal.CreateSilence(newSilence("maintenance"))
+		require.NoError(t, err)
+
+		require.NoError(t, s.SyncSilences(ctx))
+		require.Empty(t, external.silences)
+	})
+}
@@ -0,0 +1,136 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+)
+
+// webhookQueueCapacity is the number of batches of alerts waiting to be posted to the webhooks, the batches
+// sent while it is full are dropped.
+const webhookQueueCapacity = 100
+
+// webhookTarget is an HTTP endpoint alerts are posted to.
+type webhookTarget struct {
+	url         string
+	redacted    string
+	tmpl        *template.Template
+	contentType string
+	client      *http.Client
+}
+
+// webhookData is the data the template of the body posted to a webhook is executed with.
+type webhookData struct {
+	Alerts []models.PostableAlert
+}
+
+// buildWebhooks returns the webhooks of the configuration.
+func buildWebhooks(cfg *ngmodels.AdminConfiguration) ([]*webhookTarget, error) {
+	webhooks := make([]*webhookTarget, 0, len(cfg.Webhooks))
+	for _, wh := range cfg.Webhooks {
+		u, err := url.Parse(wh.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook URL: %w", err)
+		}
+		target := &webhookTarget{url: wh.URL, redacted: u.Redacted(), contentType: wh.ContentType}
+		if target.contentType == "" {
+			target.contentType = "application/json"
+		}
+		if wh.Template != "" {
+			if target.tmpl, err = ngmodels.ParseWebhookTemplate(wh.Template); err != nil {
+				return nil, fmt.Errorf("invalid template of webhook %s: %w", target.redacted, err)
+			}
+		}
+		if target.client, err = httpClientFor(cfg, wh.URL, u); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, target)
+	}
+	return webhooks, nil
+}
+
+// sendToWebhooks queues the alerts to be posted to the webhooks, if the sender posts alerts to any.
+func (s *Sender) sendToWebhooks(alerts []models.PostableAlert) {
+	s.webhooksMtx.RLock()
+	n := len(s.webhooks)
+	s.webhooksMtx.RUnlock()
+	if n == 0 {
+		return
+	}
+
+	select {
+	case s.webhookQueue <- alerts:
+	default:
+		s.logger.Warn("webhook queue is full, alerts are dropped", "alert_count", len(alerts))
+	}
+}
+
+// runWebhooks posts the alerts queued to the webhooks, until the sender is stopped. The webhooks are posted
+// to one after the other.
+func (s *Sender) runWebhooks() {
+	for {
+		select {
+		case <-s.sdCtx.Done():
+			return
+		case alerts := <-s.webhookQueue:
+			s.webhooksMtx.RLock()
+			webhooks := s.webhooks
+			s.webhooksMtx.RUnlock()
+			for _, wh := range webhooks {
+				s.postToWebhook(s.sdCtx, wh, alerts)
+			}
+		}
+	}
+}
+
+// postToWebhook posts the alerts to the webhook, relabeled, and keeps track of the outcome.
+func (s *Sender) postToWebhook(ctx context.Context, wh *webhookTarget, alerts []models.PostableAlert) {
+	alerts, ids := s.relabelPostableAlerts(wh.url, alerts)
+	if len(alerts) == 0 {
+		return
+	}
+	atomic.AddInt64(&s.inflight, 1)
+	defer atomic.AddInt64(&s.inflight, -1)
+
+	res := SendResult{Alertmanager: wh.redacted, Direct: true, Alerts: len(alerts), AlertLabels: make([]map[string]string, 0, len(alerts)), AlertIDs: ids}
+	if raw, err := json.Marshal(alerts); err == nil {
+		res.BatchID = batchID(raw)
+	}
+	for _, a := range alerts {
+		res.AlertLabels = append(res.AlertLabels, a.Labels)
+	}
+	start := time.Now()
+	body, err := wh.body(alerts)
+	if err == nil {
+		res.Attempts, res.StatusCode, err = s.post(ctx, wh.client, wh.url, wh.contentType, body)
+	}
+	res.Err = err
+	res.Duration = time.Since(start)
+	if err != nil {
+		s.logger.Warn("failed to post alerts to the webhook", "url", wh.redacted, "alert_count", len(alerts), "err", err)
+	}
+	s.recordResult(ctx, res, nil)
+}
+
+// body returns the body posted to the webhook, the template executed with the alerts or their JSON array if
+// the webhook has no template.
+func (wh *webhookTarget) body(alerts []models.PostableAlert) ([]byte, error) {
+	if wh.tmpl == nil {
+		return json.Marshal(alerts)
+	}
+	var buf bytes.Buffer
+	if err := wh.tmpl.Execute(&buf, webhookData{Alerts: alerts}); err != nil {
+		return nil, fmt.Errorf("failed to execute the template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
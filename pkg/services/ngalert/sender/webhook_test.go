@@ -0,0 +1,64 @@
+package sender
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestWebhooks(t *testing.T) {
+	type request struct {
+		contentType string
+		body        string
+	}
+	var mtx sync.Mutex
+	requests := map[string][]request{}
+	fakeWebhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mtx.Lock()
+		requests[r.URL.Path] = append(requests[r.URL.Path], request{contentType: r.Header.Get("Content-Type"), body: string(b)})
+		mtx.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fakeWebhook.Close()
+
+	s, err := New(nil)
+	require.NoError(t, err)
+	runSenderWithTargets(t, s, &ngmodels.AdminConfiguration{
+		OrgID: 1,
+		Webhooks: []ngmodels.AlertWebhookConfig{
+			{URL: fakeWebhook.URL + "/raw"},
+			{URL: fakeWebhook.URL + "/templated", Template: `{{ range .Alerts }}{{ .Labels.alertname }};{{ end }}`, ContentType: "text/plain"},
+		},
+	})
+
+	s.SendAlerts(postableAlerts("alert1", "alert2"))
+	require.Eventually(t, func() bool {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return len(requests["/raw"]) == 1 && len(requests["/templated"]) == 1
+	}, 10*time.Second, 100*time.Millisecond)
+
+	mtx.Lock()
+	require.Equal(t, "application/json", requests["/raw"][0].contentType)
+	var posted []models.PostableAlert
+	require.NoError(t, json.Unmarshal([]byte(requests["/raw"][0].body), &posted))
+	require.Len(t, posted, 2)
+	require.Equal(t, "alert1", posted[0].Labels["alertname"])
+	require.Equal(t, request{contentType: "text/plain", body: "alert1;alert2;"}, requests["/templated"][0])
+	mtx.Unlock()
+
+	require.Eventually(t, func() bool {
+		res := s.LastSendResults()
+		return len(res) == 2 && res[fakeWebhook.URL+"/raw"].Direct && res[fakeWebhook.URL+"/templated"].Direct
+	}, 10*time.Second, 100*time.Millisecond)
+}
@@ -22,6 +22,9 @@ type cache struct {
 	log         log.Logger
 	metrics     *metrics.State
 	externalURL *url.URL
+	// fingerprintKeys makes the states keyed by the fingerprint of their labels instead of their JSON
+	// representation.
+	fingerprintKeys bool
 }
 
 func newCache(logger log.Logger, metrics *metrics.State, externalURL *url.URL) *cache {
@@ -46,8 +49,7 @@ func (c *cache) getOrCreate(ctx context.Context, alertRule *ngModels.AlertRule,
 	lbs := mergeLabels(ruleLabels, result.Instance)
 	attachRuleLabels(lbs, alertRule)
 
-	il := ngModels.InstanceLabels(lbs)
-	id, err := il.StringKey()
+	id, err := c.stateID(ngModels.InstanceLabels(lbs))
 	if err != nil {
 		c.log.Error("error getting cacheId for entry", "err", err.Error())
 	}
@@ -93,10 +95,21 @@ func (c *cache) getOrCreate(ctx context.Context, alertRule *ngModels.AlertRule,
 	return newState
 }
 
+// stateID returns the key of the state of an alert instance in the cache.
+func (c *cache) stateID(labels ngModels.InstanceLabels) (string, error) {
+	if c.fingerprintKeys {
+		return labels.Fingerprint(), nil
+	}
+	return labels.StringKey()
+}
+
 func attachRuleLabels(m map[string]string, alertRule *ngModels.AlertRule) {
 	m[ngModels.RuleUIDLabel] = alertRule.UID
 	m[ngModels.NamespaceUIDLabel] = alertRule.NamespaceUID
 	m[prometheusModel.AlertNameLabel] = alertRule.Title
+	if alertRule.NotificationSettings != nil {
+		m[ngModels.ReceiverLabel] = alertRule.NotificationSettings.Receiver
+	}
 }
 
 func (c *cache) expandRuleLabelsAndAnnotations(ctx context.Context, alertRule *ngModels.AlertRule, labels map[string]string, alertInstance eval.Result) (map[string]string, map[string]string) {
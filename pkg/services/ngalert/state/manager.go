@@ -42,13 +42,17 @@ type Manager struct {
 
 	ruleStore        store.RuleStore
 	instanceStore    store.InstanceStore
+	historyStore     store.StateHistoryStore
 	sqlStore         sqlstore.Store
 	dashboardService dashboards.DashboardService
 	imageService     image.ImageService
+
+	// onTransition, if set, receives every state transition of the alert instances.
+	onTransition func(ngModels.AlertStateTransition)
 }
 
 func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL,
-	ruleStore store.RuleStore, instanceStore store.InstanceStore, sqlStore sqlstore.Store,
+	ruleStore store.RuleStore, instanceStore store.InstanceStore, historyStore store.StateHistoryStore, sqlStore sqlstore.Store,
 	dashboardService dashboards.DashboardService, imageService image.ImageService) *Manager {
 	manager := &Manager{
 		cache:            newCache(logger, metrics, externalURL),
@@ -58,6 +62,7 @@ func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL,
 		metrics:          metrics,
 		ruleStore:        ruleStore,
 		instanceStore:    instanceStore,
+		historyStore:     historyStore,
 		sqlStore:         sqlStore,
 		dashboardService: dashboardService,
 		imageService:     imageService,
@@ -66,6 +71,19 @@ func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL,
 	return manager
 }
 
+// OnTransition sets the function that receives every state transition of the alert instances, whether the state
+// history is kept or not. It must not block, and must be set before the evaluation of the rules starts.
+func (st *Manager) OnTransition(fn func(ngModels.AlertStateTransition)) {
+	st.onTransition = fn
+}
+
+// UseFingerprintKeys makes the states of the alert instances keyed by the fingerprint of their labels, which does
+// not depend on the order of the labels, instead of their JSON representation. It must be called before the cache
+// is warmed, which keys the states saved in the database with it so that they are not duplicated.
+func (st *Manager) UseFingerprintKeys() {
+	st.cache.fingerprintKeys = true
+}
+
 func (st *Manager) Close() {
 	st.quit <- struct{}{}
 }
@@ -108,26 +126,7 @@ func (st *Manager) Warm(ctx context.Context) {
 				st.log.Error("rule not found for instance, ignoring", "rule", entry.RuleUID)
 				continue
 			}
-
-			lbs := map[string]string(entry.Labels)
-			cacheId, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("error getting cacheId for entry", "msg", err.Error())
-			}
-			stateForEntry := &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheId:              cacheId,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-			}
-			states = append(states, stateForEntry)
+			states = append(states, st.stateFromInstance(entry, ruleForEntry))
 		}
 	}
 
@@ -136,6 +135,49 @@ func (st *Manager) Warm(ctx context.Context) {
 	}
 }
 
+// WarmRule replaces the states of the rule in the cache with the ones saved in the database, e.g. when the
+// rule was evaluated by another scheduler until now.
+func (st *Manager) WarmRule(ctx context.Context, orgID int64, alertRuleUID string) {
+	ruleCmd := ngModels.GetAlertRuleByUIDQuery{OrgID: orgID, UID: alertRuleUID}
+	if err := st.ruleStore.GetAlertRuleByUID(ctx, &ruleCmd); err != nil {
+		st.log.Error("unable to fetch rule", "alertRuleUID", alertRuleUID, "msg", err.Error())
+		return
+	}
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: orgID,
+		RuleUID:   alertRuleUID,
+	}
+	if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
+		st.log.Error("unable to fetch previous state", "alertRuleUID", alertRuleUID, "msg", err.Error())
+		return
+	}
+
+	st.RemoveByRuleUID(orgID, alertRuleUID)
+	for _, entry := range cmd.Result {
+		st.set(st.stateFromInstance(entry, ruleCmd.Result))
+	}
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, alertRule *ngModels.AlertRule) *State {
+	cacheId, err := st.cache.stateID(entry.Labels)
+	if err != nil {
+		st.log.Error("error getting cacheId for entry", "msg", err.Error())
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheId:              cacheId,
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          alertRule.Annotations,
+	}
+}
+
 func (st *Manager) getOrCreate(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result) *State {
 	return st.cache.getOrCreate(ctx, alertRule, result)
 }
@@ -205,45 +247,11 @@ func (st *Manager) maybeTakeScreenshot(
 // Set the current state based on evaluation results
 func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result) *State {
 	currentState := st.getOrCreate(ctx, alertRule, result)
-
-	currentState.LastEvaluationTime = result.EvaluatedAt
-	currentState.EvaluationDuration = result.EvaluationDuration
-	currentState.Results = append(currentState.Results, Evaluation{
-		EvaluationTime:  result.EvaluatedAt,
-		EvaluationState: result.State,
-		Values:          NewEvaluationValues(result.Values),
-		Condition:       alertRule.Condition,
-	})
-	currentState.LastEvaluationString = result.EvaluationString
-	currentState.TrimResults(alertRule)
 	oldState := currentState.State
 	oldReason := currentState.StateReason
 
 	st.log.Debug("setting alert state", "uid", alertRule.UID)
-	switch result.State {
-	case eval.Normal:
-		currentState.resultNormal(alertRule, result)
-	case eval.Alerting:
-		currentState.resultAlerting(alertRule, result)
-	case eval.Error:
-		currentState.resultError(alertRule, result)
-	case eval.NoData:
-		currentState.resultNoData(alertRule, result)
-	case eval.Pending: // we do not emit results with this state
-	}
-
-	// Set reason iff: result is different than state, reason is not Alerting or Normal
-	currentState.StateReason = ""
-
-	if currentState.State != result.State &&
-		result.State != eval.Normal &&
-		result.State != eval.Alerting {
-		currentState.StateReason = result.State.String()
-	}
-
-	// Set Resolved property so the scheduler knows to send a postable alert
-	// to Alertmanager.
-	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
+	currentState.setNextState(alertRule, result)
 
 	err := st.maybeTakeScreenshot(ctx, alertRule, currentState, oldState)
 	if err != nil {
@@ -324,6 +332,8 @@ func (i InstanceStateAndReason) String() string {
 func (st *Manager) annotateState(ctx context.Context, alertRule *ngModels.AlertRule, labels data.Labels, evaluatedAt time.Time, currentData, previousData InstanceStateAndReason) {
 	st.log.Debug("alert state changed creating annotation", "alertRuleUID", alertRule.UID, "newState", currentData.String(), "oldState", previousData.String())
 
+	st.recordTransition(ctx, alertRule, labels, evaluatedAt, currentData, previousData)
+
 	labels = removePrivateLabels(labels)
 	annotationText := fmt.Sprintf("%s {%s} - %s", alertRule.Title, labels.String(), currentData.String())
 
@@ -368,6 +378,34 @@ func (st *Manager) annotateState(ctx context.Context, alertRule *ngModels.AlertR
 	}
 }
 
+// recordTransition saves the state transition of the alert instance to the state history, if it is kept, and
+// passes it to the function set with OnTransition.
+func (st *Manager) recordTransition(ctx context.Context, alertRule *ngModels.AlertRule, labels data.Labels, evaluatedAt time.Time, currentData, previousData InstanceStateAndReason) {
+	if st.historyStore == nil && st.onTransition == nil {
+		return
+	}
+	transition := &ngModels.AlertStateTransition{
+		OrgID:          alertRule.OrgID,
+		RuleUID:        alertRule.UID,
+		RuleVersion:    alertRule.Version,
+		Labels:         ngModels.InstanceLabels(labels),
+		PreviousState:  ngModels.InstanceStateType(previousData.State.String()),
+		PreviousReason: previousData.Reason,
+		State:          ngModels.InstanceStateType(currentData.State.String()),
+		Reason:         currentData.Reason,
+		TransitionedAt: evaluatedAt,
+	}
+	if st.onTransition != nil {
+		st.onTransition(*transition)
+	}
+	if st.historyStore == nil {
+		return
+	}
+	if err := st.historyStore.SaveAlertStateTransitions(ctx, []*ngModels.AlertStateTransition{transition}); err != nil {
+		st.log.Error("error saving alert state transition", "alertRuleUID", alertRule.UID, "error", err.Error())
+	}
+}
+
 func (st *Manager) staleResultsHandler(ctx context.Context, alertRule *ngModels.AlertRule, states map[string]*State) {
 	allStates := st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)
 	for _, s := range allStates {
@@ -37,7 +37,7 @@ func TestDashboardAnnotations(t *testing.T) {
 	_, dbstore := tests.SetupTestEnv(t, 1)
 
 	sqlStore := mockstore.NewSQLStoreMock()
-	st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, sqlStore, &dashboards.FakeDashboardService{}, &image.NoopImageService{})
+	st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, dbstore, sqlStore, &dashboards.FakeDashboardService{}, &image.NoopImageService{})
 
 	fakeAnnoRepo := store.NewFakeAnnotationsRepo()
 	annotations.SetRepository(fakeAnnoRepo)
@@ -76,6 +76,41 @@ func TestDashboardAnnotations(t *testing.T) {
 	}, time.Second, 100*time.Millisecond, "unexpected annotations")
 }
 
+func TestStateHistory(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2022-01-01")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	st := state.NewManager(log.New("test_state_history"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, dbstore, mockstore.NewSQLStoreMock(), &dashboards.FakeDashboardService{}, &image.NoopImageService{})
+	annotations.SetRepository(store.NewFakeAnnotationsRepo())
+
+	rule := tests.CreateTestAlertRule(t, ctx, dbstore, 600, 1)
+	st.Warm(ctx)
+	for i, s := range []eval.State{eval.Alerting, eval.Alerting, eval.Error, eval.Normal} {
+		_ = st.ProcessEvalResults(ctx, rule, eval.Results{{
+			Instance:    data.Labels{"instance_label": "test"},
+			State:       s,
+			EvaluatedAt: evaluationTime.Add(time.Duration(i) * time.Minute),
+		}})
+	}
+
+	// Only the changes of state, or of reason, are kept.
+	expected := []string{"Alerting[Error] -> Normal[]", "Alerting[] -> Alerting[Error]", "Normal[] -> Alerting[]"}
+	require.Eventuallyf(t, func() bool {
+		query := models.GetAlertStateHistoryQuery{OrgID: rule.OrgID, RuleUID: rule.UID}
+		if err := dbstore.GetAlertStateHistory(ctx, &query); err != nil {
+			return false
+		}
+		actual := make([]string, 0, len(query.Result))
+		for _, tr := range query.Result {
+			actual = append(actual, fmt.Sprintf("%s[%s] -> %s[%s]", tr.PreviousState, tr.PreviousReason, tr.State, tr.Reason))
+		}
+		return assert.ObjectsAreEqual(expected, actual)
+	}, time.Second, 100*time.Millisecond, "unexpected state history")
+}
+
 func TestProcessEvalResults(t *testing.T) {
 	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
 	if err != nil {
@@ -1779,7 +1814,7 @@ func TestProcessEvalResults(t *testing.T) {
 
 	for _, tc := range testCases {
 		ss := mockstore.NewSQLStoreMock()
-		st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, &store.FakeInstanceStore{}, ss, &dashboards.FakeDashboardService{}, &image.NotAvailableImageService{})
+		st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, &store.FakeInstanceStore{}, nil, ss, &dashboards.FakeDashboardService{}, &image.NotAvailableImageService{})
 		t.Run(tc.desc, func(t *testing.T) {
 			fakeAnnoRepo := store.NewFakeAnnotationsRepo()
 			annotations.SetRepository(fakeAnnoRepo)
@@ -1900,7 +1935,7 @@ func TestStaleResultsHandler(t *testing.T) {
 	for _, tc := range testCases {
 		ctx := context.Background()
 		sqlStore := mockstore.NewSQLStoreMock()
-		st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, sqlStore, &dashboards.FakeDashboardService{}, &image.NoopImageService{})
+		st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, dbstore, sqlStore, &dashboards.FakeDashboardService{}, &image.NoopImageService{})
 		st.Warm(ctx)
 		existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
 
@@ -1920,3 +1955,50 @@ func TestStaleResultsHandler(t *testing.T) {
 		assert.Equal(t, tc.finalStateCount, len(existingStatesForRule))
 	}
 }
+
+func TestFingerprintKeys(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	const mainOrgID int64 = 1
+	rule := tests.CreateTestAlertRule(t, ctx, dbstore, 600, mainOrgID)
+
+	labels := models.InstanceLabels{
+		"__alert_rule_namespace_uid__": "namespace",
+		"__alert_rule_uid__":           rule.UID,
+		"alertname":                    rule.Title,
+		"test1":                        "testValue1",
+	}
+	// The instance was saved while the states were keyed by the JSON representation of their labels.
+	require.NoError(t, dbstore.SaveAlertInstance(ctx, &models.SaveAlertInstanceCommand{
+		RuleOrgID:         rule.OrgID,
+		RuleUID:           rule.UID,
+		Labels:            labels,
+		State:             models.InstanceStateFiring,
+		LastEvalTime:      evaluationTime,
+		CurrentStateSince: evaluationTime.Add(-time.Minute),
+		CurrentStateEnd:   evaluationTime.Add(time.Minute),
+	}))
+
+	st := state.NewManager(log.New("test_fingerprint_keys"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, dbstore, mockstore.NewSQLStoreMock(), &dashboards.FakeDashboardService{}, &image.NoopImageService{})
+	st.UseFingerprintKeys()
+	st.Warm(ctx)
+
+	states := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+	require.Len(t, states, 1)
+	require.Equal(t, labels.Fingerprint(), states[0].CacheId)
+
+	// The firing instance is still the same once evaluated again.
+	st.ProcessEvalResults(ctx, rule, eval.Results{{
+		Instance:    data.Labels{"test1": "testValue1"},
+		State:       eval.Alerting,
+		EvaluatedAt: evaluationTime.Add(10 * time.Second),
+	}})
+	states = st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+	require.Len(t, states, 1)
+	require.Equal(t, eval.Alerting, states[0].State)
+	require.Equal(t, evaluationTime.Add(-time.Minute), states[0].StartsAt)
+}
@@ -0,0 +1,53 @@
+package state
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// Replayer sets the states of the alert instances of a rule from its evaluation results the same way the Manager
+// does, without persisting, annotating or taking screenshots of them. It is used to replay the evaluations of a
+// rule over a past time range, the results must be processed in the order of their evaluation.
+type Replayer struct {
+	cache *cache
+	log   log.Logger
+}
+
+func NewReplayer(logger log.Logger, externalURL *url.URL) *Replayer {
+	return &Replayer{
+		cache: newCache(logger, nil, externalURL),
+		log:   logger,
+	}
+}
+
+// ProcessEvalResults sets the next states of the alert instances of the rule and returns them. The states of the
+// instances missing from the results for two intervals of the rule are dropped.
+func (r *Replayer) ProcessEvalResults(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results) []*State {
+	var evaluatedAt time.Time
+	states := make([]*State, 0, len(results))
+	processedResults := make(map[string]struct{}, len(results))
+	for _, result := range results {
+		s := r.cache.getOrCreate(ctx, alertRule, result)
+		s.setNextState(alertRule, result)
+		r.cache.set(s)
+		states = append(states, s)
+		processedResults[s.CacheId] = struct{}{}
+		if result.EvaluatedAt.After(evaluatedAt) {
+			evaluatedAt = result.EvaluatedAt
+		}
+	}
+
+	stale := evaluatedAt.Add(-2 * time.Duration(alertRule.IntervalSeconds) * time.Second)
+	for _, s := range r.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID) {
+		if _, ok := processedResults[s.CacheId]; !ok && s.LastEvaluationTime.Before(stale) {
+			r.log.Debug("removing stale state entry", "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID, "cacheID", s.CacheId)
+			r.cache.deleteEntry(s.OrgID, s.AlertRuleUID, s.CacheId)
+		}
+	}
+	return states
+}
@@ -0,0 +1,80 @@
+package state_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestReplayer(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
+	require.NoError(t, err)
+
+	rule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "test_title",
+		UID:             "test_alert_rule_uid",
+		NamespaceUID:    "test_namespace_uid",
+		IntervalSeconds: 10,
+		For:             20 * time.Second,
+	}
+	result := func(instance string, s eval.State, at time.Time) eval.Result {
+		return eval.Result{Instance: data.Labels{"instance": instance}, State: s, EvaluatedAt: at}
+	}
+
+	r := state.NewReplayer(log.New("test_replayer"), nil)
+	ctx := context.Background()
+
+	expected := []eval.State{eval.Pending, eval.Pending, eval.Alerting}
+	for i, s := range expected {
+		at := evaluationTime.Add(time.Duration(i) * 10 * time.Second)
+		states := r.ProcessEvalResults(ctx, rule, eval.Results{result("a", eval.Alerting, at), result("b", eval.Normal, at)})
+		require.Len(t, states, 2)
+		require.Equal(t, s, states[0].State)
+		require.Equal(t, eval.Normal, states[1].State)
+	}
+
+	t.Run("resolved alert", func(t *testing.T) {
+		at := evaluationTime.Add(30 * time.Second)
+		states := r.ProcessEvalResults(ctx, rule, eval.Results{result("a", eval.Normal, at)})
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Normal, states[0].State)
+		require.True(t, states[0].Resolved)
+	})
+
+	t.Run("stale instance is dropped", func(t *testing.T) {
+		// the instance b was last evaluated at +20s, it is stale after two intervals
+		at := evaluationTime.Add(50 * time.Second)
+		states := r.ProcessEvalResults(ctx, rule, eval.Results{result("a", eval.Normal, at)})
+		require.Len(t, states, 1)
+
+		at = evaluationTime.Add(60 * time.Second)
+		states = r.ProcessEvalResults(ctx, rule, eval.Results{result("b", eval.Alerting, at)})
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Pending, states[0].State)
+		require.Equal(t, at, states[0].StartsAt)
+	})
+}
+
+func TestReplayer_NotificationSettings(t *testing.T) {
+	rule := &models.AlertRule{
+		OrgID:                1,
+		Title:                "test_title",
+		UID:                  "test_alert_rule_uid",
+		IntervalSeconds:      10,
+		NotificationSettings: &models.NotificationSettings{Receiver: "test-receiver"},
+	}
+
+	r := state.NewReplayer(log.New("test_replayer"), nil)
+	states := r.ProcessEvalResults(context.Background(), rule, eval.Results{{Instance: data.Labels{"instance": "a"}, State: eval.Alerting, EvaluatedAt: time.Now()}})
+	require.Len(t, states, 1)
+	require.Equal(t, "test-receiver", states[0].Labels[models.ReceiverLabel])
+}
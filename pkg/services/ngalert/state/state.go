@@ -160,6 +160,46 @@ func (a *State) resultNoData(alertRule *models.AlertRule, result eval.Result) {
 	}
 }
 
+// setNextState sets the state that follows the result of an evaluation of the alert rule.
+func (a *State) setNextState(alertRule *models.AlertRule, result eval.Result) {
+	a.LastEvaluationTime = result.EvaluatedAt
+	a.EvaluationDuration = result.EvaluationDuration
+	a.Results = append(a.Results, Evaluation{
+		EvaluationTime:  result.EvaluatedAt,
+		EvaluationState: result.State,
+		Values:          NewEvaluationValues(result.Values),
+		Condition:       alertRule.Condition,
+	})
+	a.LastEvaluationString = result.EvaluationString
+	a.TrimResults(alertRule)
+	oldState := a.State
+
+	switch result.State {
+	case eval.Normal:
+		a.resultNormal(alertRule, result)
+	case eval.Alerting:
+		a.resultAlerting(alertRule, result)
+	case eval.Error:
+		a.resultError(alertRule, result)
+	case eval.NoData:
+		a.resultNoData(alertRule, result)
+	case eval.Pending: // we do not emit results with this state
+	}
+
+	// Set reason iff: result is different than state, reason is not Alerting or Normal
+	a.StateReason = ""
+
+	if a.State != result.State &&
+		result.State != eval.Normal &&
+		result.State != eval.Alerting {
+		a.StateReason = result.State.String()
+	}
+
+	// Set Resolved property so the scheduler knows to send a postable alert
+	// to Alertmanager.
+	a.Resolved = oldState == eval.Alerting && a.State == eval.Normal
+}
+
 func (a *State) NeedsSending(resendDelay time.Duration) bool {
 	if a.State == eval.Pending || a.State == eval.Normal && !a.Resolved {
 		return false
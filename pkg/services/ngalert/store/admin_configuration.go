@@ -15,13 +15,25 @@ var (
 
 type UpdateAdminConfigurationCmd struct {
 	AdminConfiguration *ngmodels.AdminConfiguration
+	// UserID and Login are the user changing the configuration, recorded with the change.
+	UserID int64
+	Login  string
+}
+
+type DeleteAdminConfigurationCmd struct {
+	OrgID int64
+	// UserID and Login are the user deleting the configuration, recorded with the change.
+	UserID int64
+	Login  string
 }
 
 type AdminConfigurationStore interface {
 	GetAdminConfiguration(orgID int64) (*ngmodels.AdminConfiguration, error)
 	GetAdminConfigurations() ([]*ngmodels.AdminConfiguration, error)
-	DeleteAdminConfiguration(orgID int64) error
+	DeleteAdminConfiguration(DeleteAdminConfigurationCmd) error
 	UpdateAdminConfiguration(UpdateAdminConfigurationCmd) error
+	// GetAdminConfigurationChanges returns the changes of the admin configuration of the organization, oldest first.
+	GetAdminConfigurationChanges(ctx context.Context, orgID int64) ([]*ngmodels.AdminConfigurationChange, error)
 }
 
 func (st *DBstore) GetAdminConfiguration(orgID int64) (*ngmodels.AdminConfiguration, error) {
@@ -63,30 +75,111 @@ func (st DBstore) GetAdminConfigurations() ([]*ngmodels.AdminConfiguration, erro
 	return cfg, nil
 }
 
-func (st DBstore) DeleteAdminConfiguration(orgID int64) error {
-	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		_, err := sess.Exec("DELETE FROM ngalert_configuration WHERE org_id = ?", orgID)
+func (st DBstore) DeleteAdminConfiguration(cmd DeleteAdminConfigurationCmd) error {
+	var change *ngmodels.AdminConfigurationChange
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		old := &ngmodels.AdminConfiguration{}
+		has, err := sess.Table("ngalert_configuration").Where("org_id = ?", cmd.OrgID).Get(old)
 		if err != nil {
 			return err
 		}
+		if !has {
+			return nil
+		}
 
-		return nil
+		_, err = sess.Exec("DELETE FROM ngalert_configuration WHERE org_id = ?", cmd.OrgID)
+		if err != nil {
+			return err
+		}
+
+		change = newAdminConfigurationChange(cmd.OrgID, cmd.UserID, cmd.Login, ngmodels.AdminConfigurationChangeDelete, old, nil)
+		return insertAdminConfigurationChange(sess, change)
 	})
+	if err == nil && change != nil {
+		st.logAdminConfigurationChange(change)
+	}
+	return err
 }
 
 func (st DBstore) UpdateAdminConfiguration(cmd UpdateAdminConfigurationCmd) error {
-	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		has, err := sess.Table("ngalert_configuration").Where("org_id = ?", cmd.AdminConfiguration.OrgID).Exist()
+	var change *ngmodels.AdminConfigurationChange
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		old := &ngmodels.AdminConfiguration{}
+		has, err := sess.Table("ngalert_configuration").Where("org_id = ?", cmd.AdminConfiguration.OrgID).Get(old)
 		if err != nil {
 			return err
 		}
 
 		if !has {
-			_, err := sess.Table("ngalert_configuration").Insert(cmd.AdminConfiguration)
+			old = nil
+			_, err = sess.Table("ngalert_configuration").Insert(cmd.AdminConfiguration)
+		} else {
+			_, err = sess.Table("ngalert_configuration").AllCols().Update(cmd.AdminConfiguration)
+		}
+		if err != nil {
 			return err
 		}
 
-		_, err = sess.Table("ngalert_configuration").AllCols().Update(cmd.AdminConfiguration)
-		return err
+		change = newAdminConfigurationChange(cmd.AdminConfiguration.OrgID, cmd.UserID, cmd.Login, ngmodels.AdminConfigurationChangeUpdate, old, cmd.AdminConfiguration)
+		return insertAdminConfigurationChange(sess, change)
 	})
+	if err == nil {
+		st.logAdminConfigurationChange(change)
+	}
+	return err
+}
+
+func (st DBstore) GetAdminConfigurationChanges(ctx context.Context, orgID int64) ([]*ngmodels.AdminConfigurationChange, error) {
+	var changes []*ngmodels.AdminConfigurationChange
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id = ?", orgID).Asc("id").Find(&changes)
+	}); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// newAdminConfigurationChange returns the change from the old to the updated admin configuration, either of them nil
+// if there was no configuration before the change or if it was deleted.
+func newAdminConfigurationChange(orgID, userID int64, login, action string, old, updated *ngmodels.AdminConfiguration) *ngmodels.AdminConfigurationChange {
+	change := &ngmodels.AdminConfigurationChange{
+		OrgID:     orgID,
+		UserID:    userID,
+		Login:     login,
+		Action:    action,
+		CreatedAt: TimeNow().UTC(),
+	}
+	if old != nil {
+		change.OldAlertmanagers = old.Alertmanagers
+		change.OldSendAlertsTo = old.SendAlertsTo.String()
+	}
+	if updated != nil {
+		change.NewAlertmanagers = updated.Alertmanagers
+		change.NewSendAlertsTo = updated.SendAlertsTo.String()
+	}
+	return change
+}
+
+func insertAdminConfigurationChange(sess *sqlstore.DBSession, change *ngmodels.AdminConfigurationChange) error {
+	if _, err := sess.Insert(change); err != nil {
+		return fmt.Errorf("failed to insert admin configuration change: %w", err)
+	}
+	return nil
+}
+
+// logAdminConfigurationChange logs a change of the admin configuration with a fixed set of keys, so that the
+// changes can be found in the logs as well.
+func (st DBstore) logAdminConfigurationChange(change *ngmodels.AdminConfigurationChange) {
+	if st.Logger == nil {
+		return
+	}
+	st.Logger.Info("admin configuration changed",
+		"org", change.OrgID,
+		"userId", change.UserID,
+		"login", change.Login,
+		"action", change.Action,
+		"oldAlertmanagers", change.OldAlertmanagers,
+		"newAlertmanagers", change.NewAlertmanagers,
+		"oldSendAlertsTo", change.OldSendAlertsTo,
+		"newSendAlertsTo", change.NewSendAlertsTo)
 }
@@ -0,0 +1,63 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationAdminConfigurationChanges(t *testing.T) {
+	mockTimeNow()
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	require.NoError(t, dbstore.UpdateAdminConfiguration(store.UpdateAdminConfigurationCmd{
+		AdminConfiguration: &models.AdminConfiguration{OrgID: 1, Alertmanagers: []string{"http://am1:9093"}, SendAlertsTo: models.AllAlertmanagers},
+		UserID:             10,
+		Login:              "admin",
+	}))
+	require.NoError(t, dbstore.UpdateAdminConfiguration(store.UpdateAdminConfigurationCmd{
+		AdminConfiguration: &models.AdminConfiguration{OrgID: 1, Alertmanagers: []string{"http://am2:9093"}, SendAlertsTo: models.ExternalAlertmanagers},
+		UserID:             11,
+		Login:              "editor",
+	}))
+	require.NoError(t, dbstore.DeleteAdminConfiguration(store.DeleteAdminConfigurationCmd{OrgID: 1, UserID: 10, Login: "admin"}))
+	// Deleting a configuration that does not exist is not a change.
+	require.NoError(t, dbstore.DeleteAdminConfiguration(store.DeleteAdminConfigurationCmd{OrgID: 1, UserID: 10, Login: "admin"}))
+	require.NoError(t, dbstore.UpdateAdminConfiguration(store.UpdateAdminConfigurationCmd{
+		AdminConfiguration: &models.AdminConfiguration{OrgID: 2, Alertmanagers: []string{"http://am3:9093"}},
+	}))
+
+	changes, err := dbstore.GetAdminConfigurationChanges(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	for _, c := range changes {
+		c.ID = 0
+		c.CreatedAt = c.CreatedAt.UTC()
+	}
+	require.Equal(t, []*models.AdminConfigurationChange{
+		{
+			OrgID: 1, UserID: 10, Login: "admin", Action: models.AdminConfigurationChangeUpdate,
+			NewAlertmanagers: []string{"http://am1:9093"}, NewSendAlertsTo: "all", CreatedAt: time.Unix(0, 0).UTC(),
+		},
+		{
+			OrgID: 1, UserID: 11, Login: "editor", Action: models.AdminConfigurationChangeUpdate,
+			OldAlertmanagers: []string{"http://am1:9093"}, OldSendAlertsTo: "all",
+			NewAlertmanagers: []string{"http://am2:9093"}, NewSendAlertsTo: "external", CreatedAt: time.Unix(1, 0).UTC(),
+		},
+		{
+			OrgID: 1, UserID: 10, Login: "admin", Action: models.AdminConfigurationChangeDelete,
+			OldAlertmanagers: []string{"http://am2:9093"}, OldSendAlertsTo: "external", CreatedAt: time.Unix(2, 0).UTC(),
+		},
+	}, changes)
+
+	changes, err = dbstore.GetAdminConfigurationChanges(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+}
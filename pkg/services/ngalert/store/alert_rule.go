@@ -31,6 +31,8 @@ type UpdateRuleGroupCmd struct {
 type UpdateRule struct {
 	Existing *ngmodels.AlertRule
 	New      ngmodels.AlertRule
+	// RestoredFrom is the version of the rule New restores, 0 if it does not restore one.
+	RestoredFrom int64
 }
 
 var (
@@ -56,6 +58,8 @@ type RuleStore interface {
 	// and return the map of uuid to id.
 	InsertAlertRules(ctx context.Context, rule []ngmodels.AlertRule) (map[string]int64, error)
 	UpdateAlertRules(ctx context.Context, rule []UpdateRule) error
+	// GetAlertRuleVersions returns the versions of the alert rule, most recent first.
+	GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) ([]*ngmodels.AlertRuleVersion, error)
 }
 
 func getAlertRuleByUID(sess *sqlstore.DBSession, alertRuleUID string, orgID int64) (*ngmodels.AlertRule, error) {
@@ -160,22 +164,28 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 			}
 			newRules = append(newRules, r)
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleUID:          r.UID,
-				RuleOrgID:        r.OrgID,
-				RuleNamespaceUID: r.NamespaceUID,
-				RuleGroup:        r.RuleGroup,
-				ParentVersion:    0,
-				Version:          r.Version,
-				Created:          r.Updated,
-				Condition:        r.Condition,
-				Title:            r.Title,
-				Data:             r.Data,
-				IntervalSeconds:  r.IntervalSeconds,
-				NoDataState:      r.NoDataState,
-				ExecErrState:     r.ExecErrState,
-				For:              r.For,
-				Annotations:      r.Annotations,
-				Labels:           r.Labels,
+				RuleUID:              r.UID,
+				RuleOrgID:            r.OrgID,
+				RuleNamespaceUID:     r.NamespaceUID,
+				RuleGroup:            r.RuleGroup,
+				ParentVersion:        0,
+				Version:              r.Version,
+				Created:              r.Updated,
+				Condition:            r.Condition,
+				Title:                r.Title,
+				Data:                 r.Data,
+				IntervalSeconds:      r.IntervalSeconds,
+				NoDataState:          r.NoDataState,
+				ExecErrState:         r.ExecErrState,
+				For:                  r.For,
+				Annotations:          r.Annotations,
+				Labels:               r.Labels,
+				Record:               r.Record,
+				NotificationSettings: r.NotificationSettings,
+				IsPaused:             r.IsPaused,
+				AlignEvaluation:      r.AlignEvaluation,
+				EvaluationTimeout:    r.EvaluationTimeout,
+				Dependencies:         r.Dependencies,
 			})
 		}
 		if len(newRules) > 0 {
@@ -224,22 +234,29 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []UpdateRule) erro
 			}
 			parentVersion = r.Existing.Version
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
+				RuleOrgID:            r.New.OrgID,
+				RuleUID:              r.New.UID,
+				RuleNamespaceUID:     r.New.NamespaceUID,
+				RuleGroup:            r.New.RuleGroup,
+				ParentVersion:        parentVersion,
+				RestoredFrom:         r.RestoredFrom,
+				Version:              r.New.Version,
+				Created:              r.New.Updated,
+				Condition:            r.New.Condition,
+				Title:                r.New.Title,
+				Data:                 r.New.Data,
+				IntervalSeconds:      r.New.IntervalSeconds,
+				NoDataState:          r.New.NoDataState,
+				ExecErrState:         r.New.ExecErrState,
+				For:                  r.New.For,
+				Annotations:          r.New.Annotations,
+				Labels:               r.New.Labels,
+				Record:               r.New.Record,
+				NotificationSettings: r.New.NotificationSettings,
+				IsPaused:             r.New.IsPaused,
+				AlignEvaluation:      r.New.AlignEvaluation,
+				EvaluationTimeout:    r.New.EvaluationTimeout,
+				Dependencies:         r.New.Dependencies,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -251,6 +268,17 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []UpdateRule) erro
 	})
 }
 
+// GetAlertRuleVersions returns the versions of the alert rule, most recent first.
+func (st DBstore) GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) ([]*ngmodels.AlertRuleVersion, error) {
+	var versions []*ngmodels.AlertRuleVersion
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("rule_org_id = ? AND rule_uid = ?", orgID, ruleUID).Desc("version").Find(&versions)
+	}); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
 // GetOrgAlertRules is a handler for retrieving alert rules of specific organisation.
 func (st DBstore) ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
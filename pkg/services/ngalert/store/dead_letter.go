@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+type DeadLetterStore interface {
+	// SaveDeadLetterAlerts saves alerts that could not be delivered, replacing the previous versions of the alerts.
+	SaveDeadLetterAlerts(ctx context.Context, alerts []*models.DeadLetterAlert) error
+
+	// GetDeadLetterAlerts returns the alerts of the organization that could not be delivered, oldest first.
+	GetDeadLetterAlerts(ctx context.Context, orgID int64) ([]*models.DeadLetterAlert, error)
+
+	// DeleteDeadLetterAlerts deletes the alerts of the organization with the IDs, or all of them if there is no ID.
+	DeleteDeadLetterAlerts(ctx context.Context, orgID int64, ids ...int64) error
+}
+
+func (st DBstore) SaveDeadLetterAlerts(ctx context.Context, alerts []*models.DeadLetterAlert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for _, a := range alerts {
+			if a.CreatedAt.IsZero() {
+				a.CreatedAt = TimeNow().UTC()
+			}
+			if _, err := sess.Where("org_id = ? AND rule_uid = ? AND fingerprint = ?", a.OrgID, a.RuleUID, a.Fingerprint).Delete(&models.DeadLetterAlert{}); err != nil {
+				return fmt.Errorf("failed to delete previous dead letter alert: %w", err)
+			}
+			if _, err := sess.Insert(a); err != nil {
+				return fmt.Errorf("failed to insert dead letter alert: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetDeadLetterAlerts(ctx context.Context, orgID int64) ([]*models.DeadLetterAlert, error) {
+	var alerts []*models.DeadLetterAlert
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id = ?", orgID).Asc("id").Find(&alerts)
+	}); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (st DBstore) DeleteDeadLetterAlerts(ctx context.Context, orgID int64, ids ...int64) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Where("org_id = ?", orgID)
+		if len(ids) > 0 {
+			q = q.In("id", ids)
+		}
+		if _, err := q.Delete(&models.DeadLetterAlert{}); err != nil {
+			return fmt.Errorf("failed to delete dead letter alerts: %w", err)
+		}
+		return nil
+	})
+}
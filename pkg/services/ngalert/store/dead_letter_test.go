@@ -0,0 +1,67 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationDeadLetterAlerts(t *testing.T) {
+	mockTimeNow()
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	require.NoError(t, dbstore.SaveDeadLetterAlerts(ctx, []*models.DeadLetterAlert{
+		{OrgID: 1, RuleUID: "a", Fingerprint: "1", Alert: `{"labels":{"alertname":"a1"}}`},
+		{OrgID: 1, RuleUID: "a", Fingerprint: "2", Alert: `{"labels":{"alertname":"a2"}}`},
+		{OrgID: 1, RuleUID: "b", Fingerprint: "1", Alert: `{"labels":{"alertname":"b1"}}`},
+		{OrgID: 2, RuleUID: "a", Fingerprint: "1", Alert: `{"labels":{"alertname":"a1"}}`},
+	}))
+
+	alertsOf := func(orgID int64) []string {
+		alerts, err := dbstore.GetDeadLetterAlerts(ctx, orgID)
+		require.NoError(t, err)
+		res := make([]string, 0, len(alerts))
+		for _, a := range alerts {
+			res = append(res, a.RuleUID+a.Fingerprint+":"+a.Alert)
+		}
+		return res
+	}
+	require.Equal(t, []string{
+		`a1:{"labels":{"alertname":"a1"}}`,
+		`a2:{"labels":{"alertname":"a2"}}`,
+		`b1:{"labels":{"alertname":"b1"}}`,
+	}, alertsOf(1))
+
+	t.Run("only the latest version of an alert is kept", func(t *testing.T) {
+		require.NoError(t, dbstore.SaveDeadLetterAlerts(ctx, []*models.DeadLetterAlert{
+			{OrgID: 1, RuleUID: "a", Fingerprint: "1", Alert: `{"labels":{"alertname":"a1"},"annotations":{"updated":"true"}}`},
+		}))
+		require.Equal(t, []string{
+			`a2:{"labels":{"alertname":"a2"}}`,
+			`b1:{"labels":{"alertname":"b1"}}`,
+			`a1:{"labels":{"alertname":"a1"},"annotations":{"updated":"true"}}`,
+		}, alertsOf(1))
+	})
+
+	t.Run("alerts are deleted by ID", func(t *testing.T) {
+		alerts, err := dbstore.GetDeadLetterAlerts(ctx, 1)
+		require.NoError(t, err)
+		// The IDs of another organization are ignored.
+		others, err := dbstore.GetDeadLetterAlerts(ctx, 2)
+		require.NoError(t, err)
+		require.NoError(t, dbstore.DeleteDeadLetterAlerts(ctx, 1, alerts[0].ID, others[0].ID))
+		require.Len(t, alertsOf(1), 2)
+		require.Len(t, alertsOf(2), 1)
+	})
+
+	t.Run("all the alerts of an organization are deleted without ID", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteDeadLetterAlerts(ctx, 1))
+		require.Empty(t, alertsOf(1))
+		require.Len(t, alertsOf(2), 1)
+	})
+}
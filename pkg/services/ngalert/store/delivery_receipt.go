@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// insertDeliveryReceiptSQL inserts a receipt, the labels are serialized by hand as for alert instances.
+const insertDeliveryReceiptSQL = "INSERT INTO alert_delivery_receipt (org_id, batch_id, target, rule_uid, labels, labels_hash, status_code, error, attempts, sent_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+type DeliveryReceiptStore interface {
+	// SaveDeliveryReceipts saves the outcomes of sending alerts to external targets.
+	SaveDeliveryReceipts(ctx context.Context, receipts []*models.DeliveryReceipt) error
+
+	// GetDeliveryReceipts returns the delivery receipts matching the query, most recent first.
+	GetDeliveryReceipts(ctx context.Context, query *models.GetDeliveryReceiptsQuery) error
+
+	// DeleteDeliveryReceipts removes the delivery receipts of all organizations sent before the time, and
+	// returns how many were removed.
+	DeleteDeliveryReceipts(ctx context.Context, before time.Time) (int64, error)
+}
+
+func (st DBstore) SaveDeliveryReceipts(ctx context.Context, receipts []*models.DeliveryReceipt) error {
+	if len(receipts) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for _, r := range receipts {
+			labelTupleJSON, labelsHash, err := r.Labels.StringAndHash()
+			if err != nil {
+				return err
+			}
+			r.LabelsHash = labelsHash
+
+			if _, err := sess.Exec(insertDeliveryReceiptSQL, r.OrgID, r.BatchID, r.Target, r.RuleUID, labelTupleJSON, r.LabelsHash, r.StatusCode, r.Error, r.Attempts, r.SentAt.Unix()); err != nil {
+				return fmt.Errorf("failed to insert delivery receipt: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetDeliveryReceipts(ctx context.Context, query *models.GetDeliveryReceiptsQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Where("org_id = ?", query.OrgID)
+		if query.RuleUID != "" {
+			q = q.And("rule_uid = ?", query.RuleUID)
+		}
+		if query.Labels != nil {
+			_, hash, err := query.Labels.StringAndHash()
+			if err != nil {
+				return err
+			}
+			q = q.And("labels_hash = ?", hash)
+		}
+		if query.BatchID != "" {
+			q = q.And("batch_id = ?", query.BatchID)
+		}
+		if !query.From.IsZero() {
+			q = q.And("sent_at >= ?", query.From.Unix())
+		}
+		if !query.To.IsZero() {
+			q = q.And("sent_at < ?", query.To.Unix())
+		}
+		q = q.Desc("sent_at", "id")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+
+		receipts := make([]*models.DeliveryReceipt, 0)
+		if err := q.Find(&receipts); err != nil {
+			return err
+		}
+		query.Result = receipts
+		return nil
+	})
+}
+
+func (st DBstore) DeleteDeliveryReceipts(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_delivery_receipt WHERE sent_at < ?", before.Unix())
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
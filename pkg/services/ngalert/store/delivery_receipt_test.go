@@ -0,0 +1,87 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationDeliveryReceipts(t *testing.T) {
+	mockTimeNow()
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	start := time.Unix(1000, 0).UTC()
+	receipt := func(orgID int64, batchID, ruleUID, instance string, minutes int, err string) *models.DeliveryReceipt {
+		return &models.DeliveryReceipt{
+			OrgID:      orgID,
+			BatchID:    batchID,
+			Target:     "http://alertmanager:9093",
+			RuleUID:    ruleUID,
+			Labels:     models.InstanceLabels{"alertname": ruleUID, "instance": instance},
+			StatusCode: 200,
+			Error:      err,
+			Attempts:   1,
+			SentAt:     start.Add(time.Duration(minutes) * time.Minute),
+		}
+	}
+	require.NoError(t, dbstore.SaveDeliveryReceipts(ctx, []*models.DeliveryReceipt{
+		receipt(1, "x", "a", "1", 0, "unavailable"),
+		receipt(1, "x", "a", "1", 1, ""),
+		receipt(1, "y", "a", "2", 2, ""),
+		receipt(1, "y", "b", "1", 3, ""),
+		receipt(2, "z", "a", "1", 4, ""),
+	}))
+
+	receiptsOf := func(query models.GetDeliveryReceiptsQuery) []string {
+		require.NoError(t, dbstore.GetDeliveryReceipts(ctx, &query))
+		res := make([]string, 0, len(query.Result))
+		for _, r := range query.Result {
+			res = append(res, r.BatchID+":"+r.RuleUID+r.Labels["instance"])
+		}
+		return res
+	}
+
+	t.Run("receipts of an organization are returned most recent first", func(t *testing.T) {
+		require.Equal(t, []string{"y:b1", "y:a2", "x:a1", "x:a1"}, receiptsOf(models.GetDeliveryReceiptsQuery{OrgID: 1}))
+
+		query := models.GetDeliveryReceiptsQuery{OrgID: 1, From: start, To: start.Add(time.Minute)}
+		require.NoError(t, dbstore.GetDeliveryReceipts(ctx, &query))
+		require.Len(t, query.Result, 1)
+		require.Equal(t, "http://alertmanager:9093", query.Result[0].Target)
+		require.Equal(t, "unavailable", query.Result[0].Error)
+		require.False(t, query.Result[0].Delivered())
+		require.True(t, start.Equal(query.Result[0].SentAt))
+	})
+
+	t.Run("receipts are filtered by rule", func(t *testing.T) {
+		require.Equal(t, []string{"y:a2", "x:a1", "x:a1"}, receiptsOf(models.GetDeliveryReceiptsQuery{OrgID: 1, RuleUID: "a"}))
+	})
+
+	t.Run("receipts are filtered by labels", func(t *testing.T) {
+		require.Equal(t, []string{"x:a1", "x:a1"}, receiptsOf(models.GetDeliveryReceiptsQuery{
+			OrgID:  1,
+			Labels: models.InstanceLabels{"alertname": "a", "instance": "1"},
+		}))
+	})
+
+	t.Run("receipts are filtered by batch", func(t *testing.T) {
+		require.Equal(t, []string{"y:b1", "y:a2"}, receiptsOf(models.GetDeliveryReceiptsQuery{OrgID: 1, BatchID: "y"}))
+	})
+
+	t.Run("the most recent receipts are returned up to the limit", func(t *testing.T) {
+		require.Equal(t, []string{"y:b1", "y:a2"}, receiptsOf(models.GetDeliveryReceiptsQuery{OrgID: 1, Limit: 2}))
+	})
+	t.Run("receipts sent before a time are deleted", func(t *testing.T) {
+		deleted, err := dbstore.DeleteDeliveryReceipts(ctx, start.Add(2*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+		require.Equal(t, []string{"y:b1", "y:a2"}, receiptsOf(models.GetDeliveryReceiptsQuery{OrgID: 1}))
+		require.Equal(t, []string{"z:a1"}, receiptsOf(models.GetDeliveryReceiptsQuery{OrgID: 2}))
+	})
+}
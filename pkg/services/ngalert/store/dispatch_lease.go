@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+type DispatchLeaseStore interface {
+	// AcquireDispatchLease acquires or renews the lease of the organization for the holder until now plus the
+	// TTL. It returns false if another holder has a lease that did not expire.
+	AcquireDispatchLease(ctx context.Context, orgID int64, holder string, now time.Time, ttl time.Duration) (bool, error)
+
+	// ReleaseDispatchLeases releases the leases of the holder, when it stops.
+	ReleaseDispatchLeases(ctx context.Context, holder string) error
+}
+
+func (st DBstore) AcquireDispatchLease(ctx context.Context, orgID int64, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	acquired := false
+	err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		lease := models.DispatchLease{}
+		has, err := sess.Where("org_id = ?", orgID).Get(&lease)
+		if err != nil {
+			return err
+		}
+		expiresAt := now.Add(ttl).Unix()
+		if !has {
+			_, err := sess.Insert(&models.DispatchLease{OrgID: orgID, Holder: holder, ExpiresAt: expiresAt})
+			acquired = err == nil
+			return err
+		}
+		if lease.Holder != holder && lease.ExpiresAt >= now.Unix() {
+			return nil
+		}
+
+		res, err := sess.Exec("UPDATE alert_dispatch_lease SET holder = ?, expires_at = ?, version = ? WHERE id = ? AND version = ?",
+			holder, expiresAt, lease.Version+1, lease.ID, lease.Version)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		acquired = affected == 1
+		return err
+	})
+	if err != nil && st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+		// Another holder created the lease first.
+		return false, nil
+	}
+	return acquired, err
+}
+
+func (st DBstore) ReleaseDispatchLeases(ctx context.Context, holder string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Delete(&models.DispatchLease{Holder: holder})
+		return err
+	})
+}
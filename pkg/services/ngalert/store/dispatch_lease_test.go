@@ -0,0 +1,59 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationDispatchLeases(t *testing.T) {
+	mockTimeNow()
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Unix(100000, 0)
+	acquired, err := dbstore.AcquireDispatchLease(ctx, 1, "a", now, 30*time.Second)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	acquired, err = dbstore.AcquireDispatchLease(ctx, 2, "b", now, 30*time.Second)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	t.Run("a lease that did not expire is not acquired by another holder", func(t *testing.T) {
+		acquired, err := dbstore.AcquireDispatchLease(ctx, 1, "b", now.Add(10*time.Second), 30*time.Second)
+		require.NoError(t, err)
+		require.False(t, acquired)
+	})
+
+	t.Run("the holder renews its lease", func(t *testing.T) {
+		acquired, err := dbstore.AcquireDispatchLease(ctx, 1, "a", now.Add(20*time.Second), 30*time.Second)
+		require.NoError(t, err)
+		require.True(t, acquired)
+		acquired, err = dbstore.AcquireDispatchLease(ctx, 1, "b", now.Add(40*time.Second), 30*time.Second)
+		require.NoError(t, err)
+		require.False(t, acquired)
+	})
+
+	t.Run("an expired lease is acquired by another holder", func(t *testing.T) {
+		acquired, err := dbstore.AcquireDispatchLease(ctx, 1, "b", now.Add(time.Minute), 30*time.Second)
+		require.NoError(t, err)
+		require.True(t, acquired)
+		acquired, err = dbstore.AcquireDispatchLease(ctx, 1, "a", now.Add(time.Minute), 30*time.Second)
+		require.NoError(t, err)
+		require.False(t, acquired)
+	})
+
+	t.Run("the leases of a holder that stops are released", func(t *testing.T) {
+		require.NoError(t, dbstore.ReleaseDispatchLeases(ctx, "b"))
+		acquired, err := dbstore.AcquireDispatchLease(ctx, 2, "a", now.Add(time.Minute), 30*time.Second)
+		require.NoError(t, err)
+		require.True(t, acquired)
+		acquired, err = dbstore.AcquireDispatchLease(ctx, 1, "a", now.Add(time.Minute), 30*time.Second)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+}
@@ -0,0 +1,176 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type MaintenanceWindowStore interface {
+	// GetMaintenanceWindows returns the maintenance windows of the organization, by start time.
+	GetMaintenanceWindows(ctx context.Context, orgID int64) ([]*models.MaintenanceWindow, error)
+
+	// GetMaintenanceWindow returns the maintenance window of the organization with the UID, or
+	// models.ErrMaintenanceWindowNotFound.
+	GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error)
+
+	// GetActiveMaintenanceWindows returns the maintenance windows of all the organizations active at the time.
+	GetActiveMaintenanceWindows(ctx context.Context, at time.Time) ([]*models.MaintenanceWindow, error)
+
+	// SaveMaintenanceWindow creates the maintenance window, with a new UID if it has none, or replaces the one of
+	// the organization with its UID.
+	SaveMaintenanceWindow(ctx context.Context, w *models.MaintenanceWindow) error
+
+	// DeleteMaintenanceWindow deletes the maintenance window of the organization with the UID, and its
+	// suppressions, or returns models.ErrMaintenanceWindowNotFound.
+	DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string) error
+
+	// SaveMaintenanceSuppressions records the suppression of alerts by maintenance windows, updating the
+	// suppressions of the same alerts by the same windows.
+	SaveMaintenanceSuppressions(ctx context.Context, suppressions []*models.MaintenanceSuppression) error
+
+	// GetMaintenanceSuppressions returns the alerts of the organization suppressed by the maintenance window,
+	// most recently suppressed first.
+	GetMaintenanceSuppressions(ctx context.Context, orgID int64, windowUID string) ([]*models.MaintenanceSuppression, error)
+}
+
+func (st DBstore) GetMaintenanceWindows(ctx context.Context, orgID int64) ([]*models.MaintenanceWindow, error) {
+	var windows []*models.MaintenanceWindow
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id = ?", orgID).Asc("starts_at", "id").Find(&windows)
+	}); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+func (st DBstore) GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error) {
+	var w models.MaintenanceWindow
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&w)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrMaintenanceWindowNotFound
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (st DBstore) GetActiveMaintenanceWindows(ctx context.Context, at time.Time) ([]*models.MaintenanceWindow, error) {
+	var windows []*models.MaintenanceWindow
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var candidates []*models.MaintenanceWindow
+		// The windows that ended are filtered out by the database, the ones that did not start yet here, as the
+		// representation of the times differs between databases.
+		if err := sess.Where("ends_at > ?", at.UTC()).Asc("starts_at", "id").Find(&candidates); err != nil {
+			return err
+		}
+		for _, w := range candidates {
+			if w.ActiveAt(at) {
+				windows = append(windows, w)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+func (st DBstore) SaveMaintenanceWindow(ctx context.Context, w *models.MaintenanceWindow) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		w.Updated = TimeNow().UTC()
+		w.StartsAt = w.StartsAt.UTC()
+		w.EndsAt = w.EndsAt.UTC()
+		if w.UID == "" {
+			w.UID = util.GenerateShortUID()
+		} else {
+			var existing models.MaintenanceWindow
+			has, err := sess.Where("org_id = ? AND uid = ?", w.OrgID, w.UID).Get(&existing)
+			if err != nil {
+				return err
+			}
+			if has {
+				w.ID = existing.ID
+				if _, err := sess.ID(w.ID).AllCols().Update(w); err != nil {
+					return fmt.Errorf("failed to update maintenance window: %w", err)
+				}
+				return nil
+			}
+		}
+		w.ID = 0
+		if _, err := sess.Insert(w); err != nil {
+			return fmt.Errorf("failed to insert maintenance window: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		deleted, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&models.MaintenanceWindow{})
+		if err != nil {
+			return fmt.Errorf("failed to delete maintenance window: %w", err)
+		}
+		if deleted == 0 {
+			return models.ErrMaintenanceWindowNotFound
+		}
+		if _, err := sess.Where("org_id = ? AND window_uid = ?", orgID, uid).Delete(&models.MaintenanceSuppression{}); err != nil {
+			return fmt.Errorf("failed to delete maintenance suppressions: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) SaveMaintenanceSuppressions(ctx context.Context, suppressions []*models.MaintenanceSuppression) error {
+	if len(suppressions) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for _, s := range suppressions {
+			if s.LastSuppressedAt.IsZero() {
+				s.LastSuppressedAt = TimeNow()
+			}
+			s.LastSuppressedAt = s.LastSuppressedAt.UTC()
+			var existing models.MaintenanceSuppression
+			has, err := sess.Where("org_id = ? AND window_uid = ? AND rule_uid = ? AND fingerprint = ?", s.OrgID, s.WindowUID, s.RuleUID, s.Fingerprint).Get(&existing)
+			if err != nil {
+				return err
+			}
+			if has {
+				s.ID = existing.ID
+				s.FirstSuppressedAt = existing.FirstSuppressedAt
+				s.Count = existing.Count + 1
+				if _, err := sess.ID(s.ID).AllCols().Update(s); err != nil {
+					return fmt.Errorf("failed to update maintenance suppression: %w", err)
+				}
+				continue
+			}
+			s.FirstSuppressedAt = s.LastSuppressedAt
+			s.Count = 1
+			if _, err := sess.Insert(s); err != nil {
+				return fmt.Errorf("failed to insert maintenance suppression: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetMaintenanceSuppressions(ctx context.Context, orgID int64, windowUID string) ([]*models.MaintenanceSuppression, error) {
+	var suppressions []*models.MaintenanceSuppression
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id = ? AND window_uid = ?", orgID, windowUID).Desc("last_suppressed_at").Desc("id").Find(&suppressions)
+	}); err != nil {
+		return nil, err
+	}
+	return suppressions, nil
+}
@@ -0,0 +1,98 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationMaintenanceWindows(t *testing.T) {
+	mockTimeNow()
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+	past := &models.MaintenanceWindow{OrgID: 1, Title: "past", StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)}
+	active := &models.MaintenanceWindow{OrgID: 1, Title: "active", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), Matchers: []string{`severity="critical"`}, SkipEvaluation: true}
+	future := &models.MaintenanceWindow{OrgID: 1, Title: "future", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)}
+	other := &models.MaintenanceWindow{OrgID: 2, Title: "other", StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Minute)}
+	for _, w := range []*models.MaintenanceWindow{future, active, past, other} {
+		require.NoError(t, dbstore.SaveMaintenanceWindow(ctx, w))
+		require.NotEmpty(t, w.UID)
+	}
+
+	titles := func(windows []*models.MaintenanceWindow) []string {
+		res := make([]string, 0, len(windows))
+		for _, w := range windows {
+			res = append(res, w.Title)
+		}
+		return res
+	}
+
+	windows, err := dbstore.GetMaintenanceWindows(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"past", "active", "future"}, titles(windows))
+
+	w, err := dbstore.GetMaintenanceWindow(ctx, 1, active.UID)
+	require.NoError(t, err)
+	require.Equal(t, []string{`severity="critical"`}, w.Matchers)
+	require.True(t, w.SkipEvaluation)
+	require.True(t, w.StartsAt.Equal(active.StartsAt))
+
+	_, err = dbstore.GetMaintenanceWindow(ctx, 2, active.UID)
+	require.ErrorIs(t, err, models.ErrMaintenanceWindowNotFound)
+
+	t.Run("only the windows active at the time are returned", func(t *testing.T) {
+		windows, err := dbstore.GetActiveMaintenanceWindows(ctx, now)
+		require.NoError(t, err)
+		require.Equal(t, []string{"active", "other"}, titles(windows))
+
+		windows, err = dbstore.GetActiveMaintenanceWindows(ctx, now.Add(90*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []string{"future"}, titles(windows))
+	})
+
+	t.Run("a window is replaced by its UID", func(t *testing.T) {
+		updated := &models.MaintenanceWindow{OrgID: 1, UID: future.UID, Title: "future updated", StartsAt: now.Add(3 * time.Hour), EndsAt: now.Add(4 * time.Hour)}
+		require.NoError(t, dbstore.SaveMaintenanceWindow(ctx, updated))
+		require.Equal(t, future.ID, updated.ID)
+		windows, err := dbstore.GetMaintenanceWindows(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"past", "active", "future updated"}, titles(windows))
+	})
+
+	t.Run("suppressions of the same alert are merged", func(t *testing.T) {
+		require.NoError(t, dbstore.SaveMaintenanceSuppressions(ctx, []*models.MaintenanceSuppression{
+			{OrgID: 1, WindowUID: active.UID, RuleUID: "a", Fingerprint: "1", Labels: map[string]string{"alertname": "a1"}, LastSuppressedAt: now},
+			{OrgID: 1, WindowUID: active.UID, RuleUID: "a", Fingerprint: "2", Labels: map[string]string{"alertname": "a2"}, LastSuppressedAt: now},
+		}))
+		require.NoError(t, dbstore.SaveMaintenanceSuppressions(ctx, []*models.MaintenanceSuppression{
+			{OrgID: 1, WindowUID: active.UID, RuleUID: "a", Fingerprint: "1", Labels: map[string]string{"alertname": "a1"}, LastSuppressedAt: now.Add(time.Minute)},
+		}))
+
+		suppressions, err := dbstore.GetMaintenanceSuppressions(ctx, 1, active.UID)
+		require.NoError(t, err)
+		require.Len(t, suppressions, 2)
+		require.Equal(t, "1", suppressions[0].Fingerprint)
+		require.Equal(t, map[string]string{"alertname": "a1"}, suppressions[0].Labels)
+		require.EqualValues(t, 2, suppressions[0].Count)
+		require.True(t, suppressions[0].FirstSuppressedAt.Equal(now))
+		require.True(t, suppressions[0].LastSuppressedAt.Equal(now.Add(time.Minute)))
+		require.EqualValues(t, 1, suppressions[1].Count)
+	})
+
+	t.Run("deleting a window deletes its suppressions", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteMaintenanceWindow(ctx, 1, active.UID))
+		_, err := dbstore.GetMaintenanceWindow(ctx, 1, active.UID)
+		require.ErrorIs(t, err, models.ErrMaintenanceWindowNotFound)
+		suppressions, err := dbstore.GetMaintenanceSuppressions(ctx, 1, active.UID)
+		require.NoError(t, err)
+		require.Empty(t, suppressions)
+		require.ErrorIs(t, dbstore.DeleteMaintenanceWindow(ctx, 1, active.UID), models.ErrMaintenanceWindowNotFound)
+	})
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// schedulerMemberRetention is how long the members that stopped without leaving are kept.
+const schedulerMemberRetention = 24 * time.Hour
+
+type SchedulerMemberStore interface {
+	// HeartbeatSchedulerMember records that the member is alive at the time.
+	HeartbeatSchedulerMember(ctx context.Context, memberID string, at time.Time) error
+
+	// GetSchedulerMembers returns the IDs of the members alive since the time, sorted.
+	GetSchedulerMembers(ctx context.Context, since time.Time) ([]string, error)
+
+	// DeleteSchedulerMember removes the member, when it stops.
+	DeleteSchedulerMember(ctx context.Context, memberID string) error
+}
+
+func (st DBstore) HeartbeatSchedulerMember(ctx context.Context, memberID string, at time.Time) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		upsertSQL := st.SQLStore.Dialect.UpsertSQL(
+			"alert_scheduler_member",
+			[]string{"member_id"},
+			[]string{"member_id", "heartbeat"})
+		if _, err := sess.SQL(upsertSQL, memberID, at.Unix()).Query(); err != nil {
+			return err
+		}
+
+		_, err := sess.Exec("DELETE FROM alert_scheduler_member WHERE heartbeat < ?", at.Add(-schedulerMemberRetention).Unix())
+		return err
+	})
+}
+
+func (st DBstore) GetSchedulerMembers(ctx context.Context, since time.Time) ([]string, error) {
+	var members []string
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Table(&models.SchedulerMember{}).Cols("member_id").Where("heartbeat >= ?", since.Unix()).Asc("member_id")
+		return q.Find(&members)
+	})
+	return members, err
+}
+
+func (st DBstore) DeleteSchedulerMember(ctx context.Context, memberID string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Delete(&models.SchedulerMember{MemberID: memberID})
+		return err
+	})
+}
@@ -0,0 +1,47 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationSchedulerMembers(t *testing.T) {
+	mockTimeNow()
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Unix(100000, 0)
+	require.NoError(t, dbstore.HeartbeatSchedulerMember(ctx, "b", now.Add(-time.Minute)))
+	require.NoError(t, dbstore.HeartbeatSchedulerMember(ctx, "a", now))
+	require.NoError(t, dbstore.HeartbeatSchedulerMember(ctx, "c", now.Add(-time.Hour)))
+
+	members, err := dbstore.GetSchedulerMembers(ctx, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, members)
+
+	t.Run("a heartbeat updates the member", func(t *testing.T) {
+		require.NoError(t, dbstore.HeartbeatSchedulerMember(ctx, "c", now))
+		members, err := dbstore.GetSchedulerMembers(ctx, now.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b", "c"}, members)
+	})
+
+	t.Run("a member leaving is deleted", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteSchedulerMember(ctx, "b"))
+		members, err := dbstore.GetSchedulerMembers(ctx, now.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "c"}, members)
+	})
+
+	t.Run("members without heartbeat for a day are deleted", func(t *testing.T) {
+		require.NoError(t, dbstore.HeartbeatSchedulerMember(ctx, "a", now.Add(25*time.Hour)))
+		members, err := dbstore.GetSchedulerMembers(ctx, time.Time{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a"}, members)
+	})
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// insertAlertStateTransitionSQL inserts a transition, the labels are serialized by hand as for alert instances.
+const insertAlertStateTransitionSQL = "INSERT INTO alert_state_history (org_id, rule_uid, labels, labels_hash, rule_version, previous_state, previous_reason, state, reason, transitioned_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+type StateHistoryStore interface {
+	// SaveAlertStateTransitions saves state transitions of alert instances.
+	SaveAlertStateTransitions(ctx context.Context, transitions []*models.AlertStateTransition) error
+
+	// GetAlertStateHistory returns the state transitions of the alert instances matching the query, most
+	// recent first.
+	GetAlertStateHistory(ctx context.Context, query *models.GetAlertStateHistoryQuery) error
+}
+
+func (st DBstore) SaveAlertStateTransitions(ctx context.Context, transitions []*models.AlertStateTransition) error {
+	if len(transitions) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for _, t := range transitions {
+			labelTupleJSON, labelsHash, err := t.Labels.StringAndHash()
+			if err != nil {
+				return err
+			}
+			t.LabelsHash = labelsHash
+
+			if _, err := sess.Exec(insertAlertStateTransitionSQL, t.OrgID, t.RuleUID, labelTupleJSON, t.LabelsHash, t.RuleVersion, t.PreviousState, t.PreviousReason, t.State, t.Reason, t.TransitionedAt.Unix()); err != nil {
+				return fmt.Errorf("failed to insert alert state transition: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetAlertStateHistory(ctx context.Context, query *models.GetAlertStateHistoryQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Where("org_id = ?", query.OrgID)
+		if query.RuleUID != "" {
+			q = q.And("rule_uid = ?", query.RuleUID)
+		}
+		if len(query.NamespaceUIDs) > 0 {
+			args := make([]interface{}, 0, len(query.NamespaceUIDs)+1)
+			in := make([]string, 0, len(query.NamespaceUIDs))
+			args = append(args, query.OrgID)
+			for _, namespaceUID := range query.NamespaceUIDs {
+				args = append(args, namespaceUID)
+				in = append(in, "?")
+			}
+			q = q.And(fmt.Sprintf("rule_uid IN (SELECT uid FROM alert_rule WHERE org_id = ? AND namespace_uid IN (%s))", strings.Join(in, ",")), args...)
+		}
+		if query.Labels != nil {
+			_, hash, err := query.Labels.StringAndHash()
+			if err != nil {
+				return err
+			}
+			q = q.And("labels_hash = ?", hash)
+		}
+		if !query.From.IsZero() {
+			q = q.And("transitioned_at >= ?", query.From.Unix())
+		}
+		if !query.To.IsZero() {
+			q = q.And("transitioned_at < ?", query.To.Unix())
+		}
+		q = q.Desc("transitioned_at", "id")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+
+		transitions := make([]*models.AlertStateTransition, 0)
+		if err := q.Find(&transitions); err != nil {
+			return err
+		}
+		query.Result = transitions
+		return nil
+	})
+}
@@ -0,0 +1,93 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationAlertStateHistory(t *testing.T) {
+	mockTimeNow()
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	start := time.Unix(1000, 0).UTC()
+	transition := func(orgID int64, ruleUID, instance string, minutes int, state models.InstanceStateType) *models.AlertStateTransition {
+		return &models.AlertStateTransition{
+			OrgID:          orgID,
+			RuleUID:        ruleUID,
+			Labels:         models.InstanceLabels{"alertname": ruleUID, "instance": instance},
+			PreviousState:  models.InstanceStateNormal,
+			State:          state,
+			TransitionedAt: start.Add(time.Duration(minutes) * time.Minute),
+			RuleVersion:    int64(orgID),
+		}
+	}
+	require.NoError(t, dbstore.SaveAlertStateTransitions(ctx, []*models.AlertStateTransition{
+		transition(1, "a", "1", 0, models.InstanceStatePending),
+		transition(1, "a", "1", 1, models.InstanceStateFiring),
+		transition(1, "a", "2", 2, models.InstanceStateFiring),
+		transition(1, "b", "1", 3, models.InstanceStateError),
+		transition(2, "a", "1", 4, models.InstanceStateFiring),
+	}))
+
+	historyOf := func(query models.GetAlertStateHistoryQuery) []string {
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, &query))
+		res := make([]string, 0, len(query.Result))
+		for _, tr := range query.Result {
+			res = append(res, tr.RuleUID+tr.Labels["instance"]+":"+string(tr.State))
+		}
+		return res
+	}
+
+	t.Run("transitions of an organization are returned most recent first", func(t *testing.T) {
+		require.Equal(t, []string{"b1:Error", "a2:Alerting", "a1:Alerting", "a1:Pending"}, historyOf(models.GetAlertStateHistoryQuery{OrgID: 1}))
+
+		query := models.GetAlertStateHistoryQuery{OrgID: 2}
+		require.NoError(t, dbstore.GetAlertStateHistory(ctx, &query))
+		require.Len(t, query.Result, 1)
+		require.Equal(t, models.InstanceLabels{"alertname": "a", "instance": "1"}, query.Result[0].Labels)
+		require.Equal(t, models.InstanceStateNormal, query.Result[0].PreviousState)
+		require.True(t, start.Add(4*time.Minute).Equal(query.Result[0].TransitionedAt))
+		require.EqualValues(t, 2, query.Result[0].RuleVersion)
+	})
+
+	t.Run("transitions are filtered by rule", func(t *testing.T) {
+		require.Equal(t, []string{"a2:Alerting", "a1:Alerting", "a1:Pending"}, historyOf(models.GetAlertStateHistoryQuery{OrgID: 1, RuleUID: "a"}))
+	})
+
+	t.Run("transitions are filtered by folder", func(t *testing.T) {
+		rule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, 1)
+		require.NoError(t, dbstore.SaveAlertStateTransitions(ctx, []*models.AlertStateTransition{
+			{OrgID: 1, RuleUID: rule.UID, Labels: models.InstanceLabels{"instance": "1"}, State: models.InstanceStateFiring, TransitionedAt: start},
+		}))
+
+		require.Equal(t, []string{rule.UID + "1:Alerting"}, historyOf(models.GetAlertStateHistoryQuery{OrgID: 1, NamespaceUIDs: []string{rule.NamespaceUID}}))
+		require.Empty(t, historyOf(models.GetAlertStateHistoryQuery{OrgID: 1, NamespaceUIDs: []string{"other"}}))
+		require.Empty(t, historyOf(models.GetAlertStateHistoryQuery{OrgID: 2, NamespaceUIDs: []string{rule.NamespaceUID}}))
+	})
+
+	t.Run("transitions are filtered by labels", func(t *testing.T) {
+		require.Equal(t, []string{"a1:Alerting", "a1:Pending"}, historyOf(models.GetAlertStateHistoryQuery{
+			OrgID:  1,
+			Labels: models.InstanceLabels{"alertname": "a", "instance": "1"},
+		}))
+	})
+
+	t.Run("transitions are filtered by time range", func(t *testing.T) {
+		require.Equal(t, []string{"a2:Alerting", "a1:Alerting"}, historyOf(models.GetAlertStateHistoryQuery{
+			OrgID: 1,
+			From:  start.Add(time.Minute),
+			To:    start.Add(3 * time.Minute),
+		}))
+	})
+
+	t.Run("the most recent transitions are returned up to the limit", func(t *testing.T) {
+		require.Equal(t, []string{"b1:Error", "a2:Alerting"}, historyOf(models.GetAlertStateHistoryQuery{OrgID: 1, Limit: 2}))
+	})
+}
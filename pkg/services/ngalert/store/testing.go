@@ -8,8 +8,10 @@ import (
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/util"
@@ -43,6 +45,8 @@ type FakeRuleStore struct {
 	Hook        func(cmd interface{}) error // use Hook if you need to intercept some query and return an error
 	RecordedOps []interface{}
 	Folders     map[int64][]*models2.Folder
+	// OrgID -> RuleUID -> Versions, most recent first
+	RuleVersions map[int64]map[string][]*models.AlertRuleVersion
 }
 
 type GenericRecordedQuery struct {
@@ -198,6 +202,9 @@ func (f *FakeRuleStore) GetAlertRulesForScheduling(_ context.Context, q *models.
 				OrgID:           rule.OrgID,
 				IntervalSeconds: rule.IntervalSeconds,
 				Version:         rule.Version,
+				IsPaused:        rule.IsPaused,
+				AlignEvaluation: rule.AlignEvaluation,
+				Dependencies:    rule.Dependencies,
 			})
 		}
 	}
@@ -315,6 +322,16 @@ func (f *FakeRuleStore) UpdateAlertRules(_ context.Context, q []UpdateRule) erro
 	return nil
 }
 
+func (f *FakeRuleStore) GetAlertRuleVersions(_ context.Context, orgID int64, ruleUID string) ([]*models.AlertRuleVersion, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.RecordedOps = append(f.RecordedOps, GenericRecordedQuery{
+		Name:   "GetAlertRuleVersions",
+		Params: []interface{}{orgID, ruleUID},
+	})
+	return f.RuleVersions[orgID][ruleUID], nil
+}
+
 func (f *FakeRuleStore) InsertAlertRules(_ context.Context, q []models.AlertRule) (map[string]int64, error) {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
@@ -389,6 +406,10 @@ func NewFakeAdminConfigStore(t *testing.T) *FakeAdminConfigStore {
 type FakeAdminConfigStore struct {
 	mtx     sync.Mutex
 	Configs map[int64]*models.AdminConfiguration
+	// Extra are returned by GetAdminConfigurations after the configurations in Configs, e.g. to
+	// return more than one configuration for an organization.
+	Extra   []*models.AdminConfiguration
+	Changes []*models.AdminConfigurationChange
 }
 
 func (f *FakeAdminConfigStore) GetAdminConfiguration(orgID int64) (*models.AdminConfiguration, error) {
@@ -404,24 +425,247 @@ func (f *FakeAdminConfigStore) GetAdminConfigurations() ([]*models.AdminConfigur
 	for _, ac := range f.Configs {
 		acs = append(acs, ac)
 	}
+	acs = append(acs, f.Extra...)
 
 	return acs, nil
 }
 
-func (f *FakeAdminConfigStore) DeleteAdminConfiguration(orgID int64) error {
+func (f *FakeAdminConfigStore) DeleteAdminConfiguration(cmd DeleteAdminConfigurationCmd) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
-	delete(f.Configs, orgID)
+	if old, ok := f.Configs[cmd.OrgID]; ok {
+		f.Changes = append(f.Changes, newAdminConfigurationChange(cmd.OrgID, cmd.UserID, cmd.Login, models.AdminConfigurationChangeDelete, old, nil))
+	}
+	delete(f.Configs, cmd.OrgID)
 	return nil
 }
 func (f *FakeAdminConfigStore) UpdateAdminConfiguration(cmd UpdateAdminConfigurationCmd) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
+	f.Changes = append(f.Changes, newAdminConfigurationChange(cmd.AdminConfiguration.OrgID, cmd.UserID, cmd.Login, models.AdminConfigurationChangeUpdate, f.Configs[cmd.AdminConfiguration.OrgID], cmd.AdminConfiguration))
 	f.Configs[cmd.AdminConfiguration.OrgID] = cmd.AdminConfiguration
 
 	return nil
 }
 
+func (f *FakeAdminConfigStore) GetAdminConfigurationChanges(_ context.Context, orgID int64) ([]*models.AdminConfigurationChange, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var changes []*models.AdminConfigurationChange
+	for _, c := range f.Changes {
+		if c.OrgID == orgID {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+func NewFakeDeadLetterStore(t *testing.T) *FakeDeadLetterStore {
+	t.Helper()
+	return &FakeDeadLetterStore{}
+}
+
+type FakeDeadLetterStore struct {
+	mtx    sync.Mutex
+	nextID int64
+	Alerts []*models.DeadLetterAlert
+}
+
+func (f *FakeDeadLetterStore) SaveDeadLetterAlerts(_ context.Context, alerts []*models.DeadLetterAlert) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, a := range alerts {
+		kept := f.Alerts[:0]
+		for _, prev := range f.Alerts {
+			if prev.OrgID != a.OrgID || prev.RuleUID != a.RuleUID || prev.Fingerprint != a.Fingerprint {
+				kept = append(kept, prev)
+			}
+		}
+		f.nextID++
+		a.ID = f.nextID
+		f.Alerts = append(kept, a)
+	}
+	return nil
+}
+
+func (f *FakeDeadLetterStore) GetDeadLetterAlerts(_ context.Context, orgID int64) ([]*models.DeadLetterAlert, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var res []*models.DeadLetterAlert
+	for _, a := range f.Alerts {
+		if a.OrgID == orgID {
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
+
+func (f *FakeDeadLetterStore) DeleteDeadLetterAlerts(_ context.Context, orgID int64, ids ...int64) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	deleted := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		deleted[id] = struct{}{}
+	}
+	kept := f.Alerts[:0]
+	for _, a := range f.Alerts {
+		if _, ok := deleted[a.ID]; a.OrgID == orgID && (len(ids) == 0 || ok) {
+			continue
+		}
+		kept = append(kept, a)
+	}
+	f.Alerts = kept
+	return nil
+}
+
+func NewFakeDeliveryReceiptStore(t *testing.T) *FakeDeliveryReceiptStore {
+	t.Helper()
+	return &FakeDeliveryReceiptStore{}
+}
+
+type FakeDeliveryReceiptStore struct {
+	mtx      sync.Mutex
+	Receipts []*models.DeliveryReceipt
+	// Err is returned by SaveDeliveryReceipts, if set.
+	Err error
+}
+
+func (f *FakeDeliveryReceiptStore) SaveDeliveryReceipts(_ context.Context, receipts []*models.DeliveryReceipt) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.Receipts = append(f.Receipts, receipts...)
+	return nil
+}
+
+func (f *FakeDeliveryReceiptStore) GetDeliveryReceipts(_ context.Context, query *models.GetDeliveryReceiptsQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	query.Result = nil
+	for _, r := range f.Receipts {
+		if query.Limit > 0 && len(query.Result) == query.Limit {
+			break
+		}
+		if r.OrgID == query.OrgID {
+			query.Result = append(query.Result, r)
+		}
+	}
+	return nil
+}
+
+func (f *FakeDeliveryReceiptStore) DeleteDeliveryReceipts(_ context.Context, before time.Time) (int64, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	kept := f.Receipts[:0]
+	for _, r := range f.Receipts {
+		if !r.SentAt.Before(before) {
+			kept = append(kept, r)
+		}
+	}
+	deleted := int64(len(f.Receipts) - len(kept))
+	f.Receipts = kept
+	return deleted, nil
+}
+
+func NewFakeMaintenanceWindowStore(t *testing.T) *FakeMaintenanceWindowStore {
+	t.Helper()
+	return &FakeMaintenanceWindowStore{}
+}
+
+type FakeMaintenanceWindowStore struct {
+	mtx          sync.Mutex
+	nextID       int64
+	Windows      []*models.MaintenanceWindow
+	Suppressions []*models.MaintenanceSuppression
+}
+
+func (f *FakeMaintenanceWindowStore) GetMaintenanceWindows(_ context.Context, orgID int64) ([]*models.MaintenanceWindow, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var res []*models.MaintenanceWindow
+	for _, w := range f.Windows {
+		if w.OrgID == orgID {
+			res = append(res, w)
+		}
+	}
+	return res, nil
+}
+
+func (f *FakeMaintenanceWindowStore) GetMaintenanceWindow(_ context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, w := range f.Windows {
+		if w.OrgID == orgID && w.UID == uid {
+			return w, nil
+		}
+	}
+	return nil, models.ErrMaintenanceWindowNotFound
+}
+
+func (f *FakeMaintenanceWindowStore) GetActiveMaintenanceWindows(_ context.Context, at time.Time) ([]*models.MaintenanceWindow, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var res []*models.MaintenanceWindow
+	for _, w := range f.Windows {
+		if w.ActiveAt(at) {
+			res = append(res, w)
+		}
+	}
+	return res, nil
+}
+
+func (f *FakeMaintenanceWindowStore) SaveMaintenanceWindow(_ context.Context, w *models.MaintenanceWindow) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if w.UID == "" {
+		w.UID = util.GenerateShortUID()
+	}
+	for i, existing := range f.Windows {
+		if existing.OrgID == w.OrgID && existing.UID == w.UID {
+			w.ID = existing.ID
+			f.Windows[i] = w
+			return nil
+		}
+	}
+	f.nextID++
+	w.ID = f.nextID
+	f.Windows = append(f.Windows, w)
+	return nil
+}
+
+func (f *FakeMaintenanceWindowStore) DeleteMaintenanceWindow(_ context.Context, orgID int64, uid string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for i, w := range f.Windows {
+		if w.OrgID == orgID && w.UID == uid {
+			f.Windows = append(f.Windows[:i], f.Windows[i+1:]...)
+			return nil
+		}
+	}
+	return models.ErrMaintenanceWindowNotFound
+}
+
+func (f *FakeMaintenanceWindowStore) SaveMaintenanceSuppressions(_ context.Context, suppressions []*models.MaintenanceSuppression) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.Suppressions = append(f.Suppressions, suppressions...)
+	return nil
+}
+
+func (f *FakeMaintenanceWindowStore) GetMaintenanceSuppressions(_ context.Context, orgID int64, windowUID string) ([]*models.MaintenanceSuppression, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var res []*models.MaintenanceSuppression
+	for _, s := range f.Suppressions {
+		if s.OrgID == orgID && s.WindowUID == windowUID {
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
 type FakeExternalAlertmanager struct {
 	t      *testing.T
 	mtx    sync.Mutex
@@ -538,3 +782,71 @@ func (repo *FakeAnnotationsRepo) FindTags(_ context.Context, query *annotations.
 	}
 	return result, nil
 }
+
+func NewFakeSchedulerMemberStore(t *testing.T) *FakeSchedulerMemberStore {
+	t.Helper()
+	return &FakeSchedulerMemberStore{Heartbeats: map[string]time.Time{}}
+}
+
+type FakeSchedulerMemberStore struct {
+	mtx        sync.Mutex
+	Heartbeats map[string]time.Time
+}
+
+func (f *FakeSchedulerMemberStore) HeartbeatSchedulerMember(_ context.Context, memberID string, at time.Time) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.Heartbeats[memberID] = at
+	return nil
+}
+
+func (f *FakeSchedulerMemberStore) GetSchedulerMembers(_ context.Context, since time.Time) ([]string, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var members []string
+	for m, at := range f.Heartbeats {
+		if !at.Before(since) {
+			members = append(members, m)
+		}
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+func (f *FakeSchedulerMemberStore) DeleteSchedulerMember(_ context.Context, memberID string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	delete(f.Heartbeats, memberID)
+	return nil
+}
+
+func NewFakeDispatchLeaseStore(t *testing.T) *FakeDispatchLeaseStore {
+	t.Helper()
+	return &FakeDispatchLeaseStore{Leases: map[int64]models.DispatchLease{}}
+}
+
+type FakeDispatchLeaseStore struct {
+	mtx    sync.Mutex
+	Leases map[int64]models.DispatchLease
+}
+
+func (f *FakeDispatchLeaseStore) AcquireDispatchLease(_ context.Context, orgID int64, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if l, ok := f.Leases[orgID]; ok && l.Holder != holder && l.ExpiresAt >= now.Unix() {
+		return false, nil
+	}
+	f.Leases[orgID] = models.DispatchLease{OrgID: orgID, Holder: holder, ExpiresAt: now.Add(ttl).Unix()}
+	return true, nil
+}
+
+func (f *FakeDispatchLeaseStore) ReleaseDispatchLeases(_ context.Context, holder string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for orgID, l := range f.Leases {
+		if l.Holder == holder {
+			delete(f.Leases, orgID)
+		}
+	}
+	return nil
+}
@@ -61,7 +61,7 @@ func SetupTestEnv(t *testing.T, baseInterval time.Duration) (*ngalert.AlertNG, *
 
 	ng, err := ngalert.ProvideService(
 		cfg, nil, routing.NewRouteRegister(), sqlStore, nil, nil, nil, nil,
-		secretsService, nil, m, folderService, ac, &dashboards.FakeDashboardService{}, nil,
+		secretsService, nil, m, folderService, ac, &dashboards.FakeDashboardService{}, nil, nil,
 	)
 	require.NoError(t, err)
 	return ng, &store.DBstore{
@@ -0,0 +1,34 @@
+package writer
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeWriter keeps the samples written, by data source UID.
+type FakeWriter struct {
+	mtx     sync.Mutex
+	Samples map[string][]Sample
+	Err     error
+}
+
+func NewFakeWriter() *FakeWriter {
+	return &FakeWriter{Samples: make(map[string][]Sample)}
+}
+
+func (w *FakeWriter) Write(_ context.Context, _ int64, datasourceUID string, samples []Sample) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.Err != nil {
+		return w.Err
+	}
+	w.Samples[datasourceUID] = append(w.Samples[datasourceUID], samples...)
+	return nil
+}
+
+// Written returns the samples written to the data source.
+func (w *FakeWriter) Written(datasourceUID string) []Sample {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return append([]Sample(nil), w.Samples[datasourceUID]...)
+}
@@ -0,0 +1,130 @@
+// Package writer writes the samples of the recording rules to their target data sources.
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// ErrUnsupportedDatasource is returned when samples are written to a data source of a type recording rules cannot
+// write to.
+var ErrUnsupportedDatasource = errors.New("recording rules cannot write to the data source")
+
+// writeTimeout is the timeout of the requests writing samples.
+const writeTimeout = 30 * time.Second
+
+// Sample is a sample of a metric written by a recording rule, the name of the metric is the __name__ label.
+type Sample struct {
+	Labels    data.Labels
+	Value     float64
+	Timestamp time.Time
+}
+
+// Writer writes samples to data sources.
+type Writer interface {
+	Write(ctx context.Context, orgID int64, datasourceUID string, samples []Sample) error
+}
+
+// DatasourceWriter writes samples to Prometheus data sources, through the remote write API of their server. The
+// path of the API is the AlertingRemoteWritePath of the data source.
+type DatasourceWriter struct {
+	dsCache        datasources.CacheService
+	secretsService secrets.Service
+	client         *http.Client
+	log            log.Logger
+}
+
+func NewDatasourceWriter(dsCache datasources.CacheService, secretsService secrets.Service, logger log.Logger) *DatasourceWriter {
+	return &DatasourceWriter{
+		dsCache:        dsCache,
+		secretsService: secretsService,
+		client:         &http.Client{Timeout: writeTimeout},
+		log:            logger,
+	}
+}
+
+// Write writes the samples to the data source of the organization.
+func (w *DatasourceWriter) Write(ctx context.Context, orgID int64, datasourceUID string, samples []Sample) error {
+	ds, err := w.dsCache.GetDatasourceByUID(ctx, datasourceUID, &models.SignedInUser{
+		OrgId:   orgID,
+		OrgRole: models.ROLE_ADMIN,
+	}, false)
+	if err != nil {
+		return fmt.Errorf("failed to get the data source %s: %w", datasourceUID, err)
+	}
+	if ds.Type != models.DS_PROMETHEUS {
+		return fmt.Errorf("%w: %s is of type %s", ErrUnsupportedDatasource, datasourceUID, ds.Type)
+	}
+
+	body, err := remotewrite.TimeSeriesToBytes(timeSeries(samples))
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(ds.Url, "/") + ds.AlertingRemoteWritePath()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.Header.Set("User-Agent", "Grafana")
+	if ds.BasicAuth {
+		decrypted, err := w.secretsService.DecryptJsonData(ctx, ds.SecureJsonData)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt the basic auth password of the data source %s: %w", datasourceUID, err)
+		}
+		req.SetBasicAuth(ds.BasicAuthUser, decrypted["basicAuthPassword"])
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.log.Warn("failed to close the response body", "err", err)
+		}
+	}()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("the data source %s responded with status %d: %s", datasourceUID, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// timeSeries returns the time series of the samples, one per sample, with their labels sorted by name as
+// required by the remote write API.
+func timeSeries(samples []Sample) []prompb.TimeSeries {
+	series := make([]prompb.TimeSeries, 0, len(samples))
+	for _, s := range samples {
+		labels := make([]prompb.Label, 0, len(s.Labels))
+		for name, value := range s.Labels {
+			labels = append(labels, prompb.Label{Name: name, Value: value})
+		}
+		sort.Slice(labels, func(i, j int) bool {
+			return labels[i].Name < labels[j].Name
+		})
+		series = append(series, prompb.TimeSeries{
+			Labels:  labels,
+			Samples: []prompb.Sample{{Value: s.Value, Timestamp: s.Timestamp.UnixNano() / int64(time.Millisecond)}},
+		})
+	}
+	return series
+}
@@ -0,0 +1,88 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	dsfakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	secretsfakes "github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+func TestDatasourceWriter(t *testing.T) {
+	var (
+		received *prompb.WriteRequest
+		path     string
+		user     string
+		password string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		user, password, _ = r.BasicAuth()
+		compressed, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		b, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		received = &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(b, received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	secretsService := secretsfakes.NewFakeSecretsService()
+	secureJSONData, err := secretsService.EncryptJsonData(context.Background(), map[string]string{"basicAuthPassword": "secret"}, nil)
+	require.NoError(t, err)
+
+	dsCache := &dsfakes.FakeCacheService{DataSources: []*models.DataSource{
+		{Uid: "prom", Type: models.DS_PROMETHEUS, Url: server.URL + "/"},
+		{
+			Uid: "mimir", Type: models.DS_PROMETHEUS, Url: server.URL + "/prometheus",
+			JsonData:  simplejson.NewFromAny(map[string]interface{}{"alertingRemoteWritePath": "/api/v1/push"}),
+			BasicAuth: true, BasicAuthUser: "user", SecureJsonData: secureJSONData,
+		},
+		{Uid: "loki", Type: models.DS_LOKI, Url: server.URL},
+	}}
+	w := NewDatasourceWriter(dsCache, secretsService, log.New("test"))
+
+	now := time.Unix(1000, 0)
+	samples := []Sample{
+		{Labels: data.Labels{"__name__": "job:up:sum", "job": "a"}, Value: 1, Timestamp: now},
+		{Labels: data.Labels{"__name__": "job:up:sum", "job": "b"}, Value: 2, Timestamp: now},
+	}
+
+	t.Run("writes the samples with the remote write API", func(t *testing.T) {
+		require.NoError(t, w.Write(context.Background(), 1, "prom", samples))
+		require.Equal(t, "/api/v1/write", path)
+		require.Empty(t, user)
+		require.Len(t, received.Timeseries, 2)
+		require.Equal(t, []prompb.Label{{Name: "__name__", Value: "job:up:sum"}, {Name: "job", Value: "a"}}, received.Timeseries[0].Labels)
+		require.Equal(t, []prompb.Sample{{Value: 1, Timestamp: 1000000}}, received.Timeseries[0].Samples)
+	})
+
+	t.Run("writes to the path of the data source with basic auth", func(t *testing.T) {
+		require.NoError(t, w.Write(context.Background(), 1, "mimir", samples))
+		require.Equal(t, "/prometheus/api/v1/push", path)
+		require.Equal(t, "user", user)
+		require.Equal(t, "secret", password)
+	})
+
+	t.Run("does not write to other data sources", func(t *testing.T) {
+		err := w.Write(context.Background(), 1, "loki", samples)
+		require.True(t, errors.Is(err, ErrUnsupportedDatasource))
+
+		require.Error(t, w.Write(context.Background(), 1, "unknown", samples))
+	})
+}
@@ -27,6 +27,20 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 	AddProvisioningMigrations(mg)
 
 	AddAlertImageMigrations(mg)
+
+	AddAlertDeadLetterMigrations(mg)
+
+	AddAdminConfigChangeMigrations(mg)
+
+	AddAlertStateHistoryMigrations(mg)
+
+	AddSchedulerMemberMigrations(mg)
+
+	AddDeliveryReceiptMigrations(mg)
+
+	AddDispatchLeaseMigrations(mg)
+
+	AddMaintenanceWindowMigrations(mg)
 }
 
 // AddAlertDefinitionMigrations should not be modified.
@@ -241,6 +255,24 @@ func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 			Cols: []string{"org_id", "dashboard_uid", "panel_id"},
 		},
 	))
+
+	// add record column, the definition of recording rules
+	mg.AddMigration("add column record to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "record", Type: migrator.DB_Text, Nullable: true}))
+
+	// add notification_settings column, the contact point the alerts of the rule are routed to
+	mg.AddMigration("add column notification_settings to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "notification_settings", Type: migrator.DB_Text, Nullable: true}))
+
+	// add is_paused column, paused rules are not evaluated
+	mg.AddMigration("add column is_paused to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+
+	// add align_evaluation column, aligned rules are evaluated at multiples of their interval
+	mg.AddMigration("add column align_evaluation to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "align_evaluation", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+
+	// add evaluation_timeout column, the evaluation timeout of the rule overriding the one of the configuration
+	mg.AddMigration("add column evaluation_timeout to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "evaluation_timeout", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+
+	// add dependencies column, the rules inhibiting the firing alerts of the rule while they fire
+	mg.AddMigration("add column dependencies to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "dependencies", Type: migrator.DB_Text, Nullable: true}))
 }
 
 func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -284,6 +316,24 @@ func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// add record column
+	mg.AddMigration("add column record to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "record", Type: migrator.DB_Text, Nullable: true}))
+
+	// add notification_settings column
+	mg.AddMigration("add column notification_settings to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "notification_settings", Type: migrator.DB_Text, Nullable: true}))
+
+	// add is_paused column
+	mg.AddMigration("add column is_paused to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+
+	// add align_evaluation column
+	mg.AddMigration("add column align_evaluation to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "align_evaluation", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+
+	// add evaluation_timeout column
+	mg.AddMigration("add column evaluation_timeout to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "evaluation_timeout", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+
+	// add dependencies column
+	mg.AddMigration("add column dependencies to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "dependencies", Type: migrator.DB_Text, Nullable: true}))
 }
 
 func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
@@ -340,6 +390,76 @@ func AddAlertAdminConfigMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("add column send_alerts_to in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
 		Name: "send_alerts_to", Type: migrator.DB_SmallInt, Nullable: false, Default: "0",
 	}))
+	mg.AddMigration("add column disabled in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "disabled", Type: migrator.DB_Bool, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add column external_rule_uids in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "external_rule_uids", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column tls_configs in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "tls_configs", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column credentials in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "credentials", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column proxy_url in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "proxy_url", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column no_proxy in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "no_proxy", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column proxy_urls in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "proxy_urls", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column api_versions in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "api_versions", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column headers in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "headers", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column timeout in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "timeout", Type: migrator.DB_NVarchar, Length: 20, Nullable: true,
+	}))
+	mg.AddMigration("add column timeouts in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "timeouts", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column pager_duty in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "pager_duty", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column webhooks in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "webhooks", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column silence_sync in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "silence_sync", Type: migrator.DB_NVarchar, Length: 20, Nullable: true,
+	}))
+	mg.AddMigration("add column relabel in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "relabel", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column target_relabels in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "target_relabels", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column aws in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "aws", Type: migrator.DB_Text, Nullable: true,
+	}))
+
+	mg.AddMigration("add column compressions in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "compressions", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column resolved_alerts in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "resolved_alerts", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column rule_resolved_alerts in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "rule_resolved_alerts", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column mute_timings in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "mute_timings", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column rule_mute_timings in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "rule_mute_timings", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column grouping in ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "grouping", Type: migrator.DB_Text, Nullable: true,
+	}))
 }
 
 func AddProvisioningMigrations(mg *migrator.Migrator) {
@@ -379,3 +499,183 @@ func AddAlertImageMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("create alert_image table", migrator.NewAddTableMigration(imageTable))
 	mg.AddMigration("add unique index on token to alert_image table", migrator.NewAddIndexMigration(imageTable, imageTable.Indices[0]))
 }
+
+func AddAlertDeadLetterMigrations(mg *migrator.Migrator) {
+	deadLetterTable := migrator.Table{
+		Name: "alert_dead_letter",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "fingerprint", Type: migrator.DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "alert", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "created_at", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid", "fingerprint"}, Type: migrator.UniqueIndex},
+		},
+	}
+	mg.AddMigration("create alert_dead_letter table", migrator.NewAddTableMigration(deadLetterTable))
+	mg.AddMigration("add unique index on org_id, rule_uid and fingerprint to alert_dead_letter table", migrator.NewAddIndexMigration(deadLetterTable, deadLetterTable.Indices[0]))
+}
+
+func AddAdminConfigChangeMigrations(mg *migrator.Migrator) {
+	changeTable := migrator.Table{
+		Name: "ngalert_configuration_change",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "login", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "action", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "old_alertmanagers", Type: migrator.DB_Text, Nullable: true},
+			{Name: "new_alertmanagers", Type: migrator.DB_Text, Nullable: true},
+			{Name: "old_send_alerts_to", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "new_send_alerts_to", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "created_at", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id"}},
+		},
+	}
+	mg.AddMigration("create ngalert_configuration_change table", migrator.NewAddTableMigration(changeTable))
+	mg.AddMigration("add index on org_id to ngalert_configuration_change table", migrator.NewAddIndexMigration(changeTable, changeTable.Indices[0]))
+}
+
+func AddAlertStateHistoryMigrations(mg *migrator.Migrator) {
+	historyTable := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "previous_reason", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "reason", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "transitioned_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "transitioned_at"}},
+			{Cols: []string{"org_id", "rule_uid", "transitioned_at"}},
+			{Cols: []string{"org_id", "labels_hash", "transitioned_at"}},
+		},
+	}
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(historyTable))
+	mg.AddMigration("add index on org_id and transitioned_at to alert_state_history table", migrator.NewAddIndexMigration(historyTable, historyTable.Indices[0]))
+	mg.AddMigration("add index on org_id, rule_uid and transitioned_at to alert_state_history table", migrator.NewAddIndexMigration(historyTable, historyTable.Indices[1]))
+	mg.AddMigration("add index on org_id, labels_hash and transitioned_at to alert_state_history table", migrator.NewAddIndexMigration(historyTable, historyTable.Indices[2]))
+	mg.AddMigration("add rule_version column to alert_state_history table", migrator.NewAddColumnMigration(historyTable, &migrator.Column{Name: "rule_version", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+}
+
+func AddSchedulerMemberMigrations(mg *migrator.Migrator) {
+	memberTable := migrator.Table{
+		Name: "alert_scheduler_member",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "member_id", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "heartbeat", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"member_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+	mg.AddMigration("create alert_scheduler_member table", migrator.NewAddTableMigration(memberTable))
+	mg.AddMigration("add unique index on member_id to alert_scheduler_member table", migrator.NewAddIndexMigration(memberTable, memberTable.Indices[0]))
+}
+
+func AddDeliveryReceiptMigrations(mg *migrator.Migrator) {
+	receiptTable := migrator.Table{
+		Name: "alert_delivery_receipt",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "batch_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "target", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "status_code", Type: migrator.DB_Int, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: false},
+			{Name: "attempts", Type: migrator.DB_Int, Nullable: false},
+			{Name: "sent_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "sent_at"}},
+			{Cols: []string{"org_id", "rule_uid", "sent_at"}},
+			{Cols: []string{"org_id", "labels_hash", "sent_at"}},
+			{Cols: []string{"org_id", "batch_id"}},
+		},
+	}
+	mg.AddMigration("create alert_delivery_receipt table", migrator.NewAddTableMigration(receiptTable))
+	mg.AddMigration("add index on org_id and sent_at to alert_delivery_receipt table", migrator.NewAddIndexMigration(receiptTable, receiptTable.Indices[0]))
+	mg.AddMigration("add index on org_id, rule_uid and sent_at to alert_delivery_receipt table", migrator.NewAddIndexMigration(receiptTable, receiptTable.Indices[1]))
+	mg.AddMigration("add index on org_id, labels_hash and sent_at to alert_delivery_receipt table", migrator.NewAddIndexMigration(receiptTable, receiptTable.Indices[2]))
+	mg.AddMigration("add index on org_id and batch_id to alert_delivery_receipt table", migrator.NewAddIndexMigration(receiptTable, receiptTable.Indices[3]))
+}
+
+func AddDispatchLeaseMigrations(mg *migrator.Migrator) {
+	leaseTable := migrator.Table{
+		Name: "alert_dispatch_lease",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "holder", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "expires_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"holder"}},
+		},
+	}
+	mg.AddMigration("create alert_dispatch_lease table", migrator.NewAddTableMigration(leaseTable))
+	mg.AddMigration("add unique index on org_id to alert_dispatch_lease table", migrator.NewAddIndexMigration(leaseTable, leaseTable.Indices[0]))
+	mg.AddMigration("add index on holder to alert_dispatch_lease table", migrator.NewAddIndexMigration(leaseTable, leaseTable.Indices[1]))
+}
+
+func AddMaintenanceWindowMigrations(mg *migrator.Migrator) {
+	windowTable := migrator.Table{
+		Name: "alert_maintenance_window",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "title", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "starts_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "ends_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: true},
+			{Name: "skip_evaluation", Type: migrator.DB_Bool, Nullable: false, Default: "0"},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"ends_at"}},
+		},
+	}
+	mg.AddMigration("create alert_maintenance_window table", migrator.NewAddTableMigration(windowTable))
+	mg.AddMigration("add unique index on org_id and uid to alert_maintenance_window table", migrator.NewAddIndexMigration(windowTable, windowTable.Indices[0]))
+	mg.AddMigration("add index on ends_at to alert_maintenance_window table", migrator.NewAddIndexMigration(windowTable, windowTable.Indices[1]))
+
+	suppressionTable := migrator.Table{
+		Name: "alert_maintenance_suppression",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "window_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "fingerprint", Type: migrator.DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "first_suppressed_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "last_suppressed_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "count", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "window_uid", "rule_uid", "fingerprint"}, Type: migrator.UniqueIndex},
+		},
+	}
+	mg.AddMigration("create alert_maintenance_suppression table", migrator.NewAddTableMigration(suppressionTable))
+	mg.AddMigration("add unique index on org_id, window_uid, rule_uid and fingerprint to alert_maintenance_suppression table", migrator.NewAddIndexMigration(suppressionTable, suppressionTable.Indices[0]))
+}
@@ -3,6 +3,7 @@ package setting
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -47,7 +48,17 @@ const (
 	schedulerDefaultAdminConfigPollInterval = 60 * time.Second
 	schedulereDefaultExecuteAlerts          = true
 	schedulerDefaultMaxAttempts             = 3
+	schedulerDefaultCircuitBreakerThreshold = 0
+	schedulerDefaultCircuitBreakerBackoff   = time.Hour
+	schedulerDefaultEvaluationJitter        = false
+	schedulerDefaultMaxFiringAlertsPerOrg   = 0
+	schedulerDefaultMaxFiringAlertsPerRule  = 0
+	stateDefaultStableAlertFingerprints     = false
 	schedulerDefaultLegacyMinInterval       = 1
+	schedulerDefaultEvaluationSharding      = false
+	schedulerDefaultShardingHeartbeat       = 10 * time.Second
+	schedulerDefaultDispatchElection        = false
+	schedulerDefaultDispatchLeaseInterval   = 10 * time.Second
 	screenshotsDefaultEnabled               = false
 	screenshotsDefaultMaxConcurrent         = 5
 	screenshotsDefaultUploadImageStorage    = false
@@ -68,9 +79,57 @@ type UnifiedAlertingSettings struct {
 	HAPeerTimeout                  time.Duration
 	HAGossipInterval               time.Duration
 	HAPushPullInterval             time.Duration
+	HAEvaluationSharding           bool
+	HAShardingHeartbeatInterval    time.Duration
+	HADispatchLeaderElection       bool
+	HADispatchLeaseInterval        time.Duration
+	HeartbeatURL                   string
+	HeartbeatAPIKey                string
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
+	EvaluationJitter               bool
+	CircuitBreakerThreshold        int
+	CircuitBreakerMaxBackoff       time.Duration
+	MaxFiringAlertsPerOrg          int
+	MaxFiringAlertsPerRule         int
+	StableAlertFingerprints        bool
+	MaxResolvedAlertAge            time.Duration
+	ExternalLabelMatcherLabel      string
+	ExternalLabelMatcherValues     []string
+	StrictAdminConfig              bool
+	ExternalPriorityLabel          string
+	ExternalHighPriorityValues     []string
+	OrderedDeliveryOrgs            map[int64]struct{}
+	AdminConfigAuditLog            bool
+	ExternalUnhealthyThreshold     int
+	ExternalHealthyThreshold       int
+	StartupNotification            bool
+	StartupNotificationLabels      map[string]string
+	RoutingModeStabilization       time.Duration
+	CompressedSendsOrgs            map[int64]struct{}
+	FingerprintIgnoredLabels       []string
+	FingerprintTrimLabels          bool
+	FingerprintLowercaseLabels     []string
+	ExternalLocalFallback          bool
+	MissingLocalNotifier           string
+	MaxFallbackDuration            time.Duration
+	ExternalRequiredLabels         map[string]string
+	ExternalMissingLabelsPolicy    string
+	RoutingDecisionLogs            bool
+	DuplicateAdminConfigs          string
+	ExternalSendBacklogSize        int
+	ExternalSendBacklogTTL         time.Duration
+	ExternalSenderDrainTimeout     time.Duration
+	ExternalResendInterval         time.Duration
+	ExternalSenderConcurrency      int
+	ExternalRateLimit              float64
+	ExternalRateLimitBurst         int
+	ExternalQueueCapacity          int
+	ExternalMaxBatchSize           int
+	ExternalFlushInterval          time.Duration
+	DeliveryReceiptsTTL            time.Duration
+	DeliveryReceiptsBufferSize     int
 	ExecuteAlerts                  bool
 	DefaultConfiguration           string
 	Enabled                        *bool // determines whether unified alerting is enabled. If it is nil then user did not define it and therefore its value will be determined during migration. Services should not use it directly.
@@ -182,6 +241,23 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	if err != nil {
 		return err
 	}
+	uaCfg.HAEvaluationSharding = ua.Key("ha_evaluation_sharding").MustBool(schedulerDefaultEvaluationSharding)
+	uaCfg.HAShardingHeartbeatInterval, err = gtime.ParseDuration(valueAsString(ua, "ha_sharding_heartbeat_interval", schedulerDefaultShardingHeartbeat.String()))
+	if err != nil {
+		return err
+	}
+	uaCfg.HADispatchLeaderElection = ua.Key("ha_dispatch_leader_election").MustBool(schedulerDefaultDispatchElection)
+	uaCfg.HADispatchLeaseInterval, err = gtime.ParseDuration(valueAsString(ua, "ha_dispatch_lease_interval", schedulerDefaultDispatchLeaseInterval.String()))
+	if err != nil {
+		return err
+	}
+	uaCfg.HeartbeatURL = ua.Key("heartbeat_url").MustString("")
+	if uaCfg.HeartbeatURL != "" {
+		if u, err := url.Parse(uaCfg.HeartbeatURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("value of setting 'heartbeat_url' should be an http or https URL")
+		}
+	}
+	uaCfg.HeartbeatAPIKey = ua.Key("heartbeat_api_key").MustString("")
 	uaCfg.HAListenAddr = ua.Key("ha_listen_address").MustString(alertmanagerDefaultClusterAddr)
 	uaCfg.HAAdvertiseAddr = ua.Key("ha_advertise_address").MustString("")
 	peers := ua.Key("ha_peers").MustString("")
@@ -229,6 +305,174 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.MaxAttempts = uaMaxAttempts
 
+	uaCfg.EvaluationJitter = ua.Key("evaluation_jitter").MustBool(schedulerDefaultEvaluationJitter)
+
+	uaCfg.CircuitBreakerThreshold = ua.Key("evaluation_circuit_breaker_threshold").MustInt(schedulerDefaultCircuitBreakerThreshold)
+	if uaCfg.CircuitBreakerThreshold < 0 {
+		return fmt.Errorf("value of setting 'evaluation_circuit_breaker_threshold' should be 0 or greater")
+	}
+	uaCfg.CircuitBreakerMaxBackoff, err = gtime.ParseDuration(valueAsString(ua, "evaluation_circuit_breaker_max_backoff", schedulerDefaultCircuitBreakerBackoff.String()))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.MaxFiringAlertsPerOrg = ua.Key("max_firing_alerts_per_org").MustInt(schedulerDefaultMaxFiringAlertsPerOrg)
+	if uaCfg.MaxFiringAlertsPerOrg < 0 {
+		return fmt.Errorf("value of setting 'max_firing_alerts_per_org' should be 0 or greater")
+	}
+	uaCfg.MaxFiringAlertsPerRule = ua.Key("max_firing_alerts_per_rule").MustInt(schedulerDefaultMaxFiringAlertsPerRule)
+	if uaCfg.MaxFiringAlertsPerRule < 0 {
+		return fmt.Errorf("value of setting 'max_firing_alerts_per_rule' should be 0 or greater")
+	}
+	uaCfg.StableAlertFingerprints = ua.Key("stable_alert_fingerprints").MustBool(stateDefaultStableAlertFingerprints)
+
+	uaCfg.MaxResolvedAlertAge, err = gtime.ParseDuration(valueAsString(ua, "max_resolved_alert_age", "0s"))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.ExternalLabelMatcherLabel = valueAsString(ua, "external_label_matcher_label", "severity")
+	uaCfg.ExternalLabelMatcherValues = util.SplitString(valueAsString(ua, "external_label_matcher_values", ""))
+
+	uaCfg.StrictAdminConfig = ua.Key("strict_admin_config").MustBool(false)
+
+	uaCfg.ExternalPriorityLabel = valueAsString(ua, "external_priority_label", "")
+	uaCfg.ExternalHighPriorityValues = util.SplitString(valueAsString(ua, "external_high_priority_values", ""))
+
+	uaCfg.OrderedDeliveryOrgs, err = readOrgIDs(ua, "ordered_delivery_orgs")
+	if err != nil {
+		return err
+	}
+
+	uaCfg.AdminConfigAuditLog = ua.Key("admin_config_audit_log").MustBool(false)
+
+	uaCfg.ExternalUnhealthyThreshold = ua.Key("external_alertmanagers_unhealthy_threshold").MustInt(3)
+	uaCfg.ExternalHealthyThreshold = ua.Key("external_alertmanagers_healthy_threshold").MustInt(3)
+
+	uaCfg.StartupNotification = ua.Key("startup_notification").MustBool(false)
+	uaCfg.StartupNotificationLabels = map[string]string{}
+	for _, label := range strings.Split(valueAsString(ua, "startup_notification_labels", ""), ",") {
+		if strings.TrimSpace(label) == "" {
+			continue
+		}
+		kv := strings.SplitN(label, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return fmt.Errorf("invalid startup notification label %q, it must be name=value", label)
+		}
+		uaCfg.StartupNotificationLabels[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	uaCfg.RoutingModeStabilization, err = gtime.ParseDuration(valueAsString(ua, "routing_mode_stabilization", "0s"))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.CompressedSendsOrgs, err = readOrgIDs(ua, "compressed_sends_orgs")
+	if err != nil {
+		return err
+	}
+
+	uaCfg.FingerprintIgnoredLabels = util.SplitString(valueAsString(ua, "fingerprint_ignored_labels", ""))
+	uaCfg.FingerprintTrimLabels = ua.Key("fingerprint_trim_labels").MustBool(false)
+	uaCfg.FingerprintLowercaseLabels = util.SplitString(valueAsString(ua, "fingerprint_lowercase_labels", ""))
+
+	uaCfg.ExternalLocalFallback = ua.Key("external_alertmanagers_local_fallback").MustBool(false)
+
+	uaCfg.MissingLocalNotifier, err = readOneOf(ua, "missing_local_notifier", "ignore", "warn", "error")
+	if err != nil {
+		return err
+	}
+
+	uaCfg.MaxFallbackDuration, err = gtime.ParseDuration(valueAsString(ua, "max_fallback_duration", "0s"))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.ExternalRequiredLabels = map[string]string{}
+	for _, label := range strings.Split(valueAsString(ua, "external_required_labels", ""), ",") {
+		kv := strings.SplitN(label, "=", 2)
+		name := strings.TrimSpace(kv[0])
+		if name == "" {
+			if len(kv) == 2 {
+				return fmt.Errorf("invalid required label %q, it must be name or name=default", label)
+			}
+			continue
+		}
+		uaCfg.ExternalRequiredLabels[name] = ""
+		if len(kv) == 2 {
+			uaCfg.ExternalRequiredLabels[name] = strings.TrimSpace(kv[1])
+		}
+	}
+	uaCfg.ExternalMissingLabelsPolicy, err = readOneOf(ua, "external_missing_labels_policy", "send", "drop", "internal", "default")
+	if err != nil {
+		return err
+	}
+
+	uaCfg.RoutingDecisionLogs = ua.Key("routing_decision_logs").MustBool(false)
+
+	uaCfg.DuplicateAdminConfigs, err = readOneOf(ua, "duplicate_admin_configs", "latest", "skip")
+	if err != nil {
+		return err
+	}
+
+	uaCfg.ExternalSendBacklogSize = ua.Key("external_send_backlog_size").MustInt(0)
+	if uaCfg.ExternalSendBacklogSize < 0 {
+		return fmt.Errorf("value of setting 'external_send_backlog_size' should be 0 or greater")
+	}
+	uaCfg.ExternalSendBacklogTTL, err = gtime.ParseDuration(valueAsString(ua, "external_send_backlog_ttl", "0s"))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.ExternalSenderDrainTimeout, err = gtime.ParseDuration(valueAsString(ua, "external_sender_drain_timeout", "0s"))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.ExternalResendInterval, err = gtime.ParseDuration(valueAsString(ua, "external_resend_interval", "0s"))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.ExternalSenderConcurrency = ua.Key("external_sender_concurrency").MustInt(1)
+	if uaCfg.ExternalSenderConcurrency < 1 {
+		return fmt.Errorf("value of setting 'external_sender_concurrency' should be 1 or greater")
+	}
+
+	uaCfg.ExternalRateLimit = ua.Key("external_rate_limit").MustFloat64(0)
+	if uaCfg.ExternalRateLimit < 0 {
+		return fmt.Errorf("value of setting 'external_rate_limit' should be 0 or greater")
+	}
+	uaCfg.ExternalRateLimitBurst = ua.Key("external_rate_limit_burst").MustInt(0)
+	if uaCfg.ExternalRateLimitBurst < 0 {
+		return fmt.Errorf("value of setting 'external_rate_limit_burst' should be 0 or greater")
+	}
+
+	uaCfg.ExternalQueueCapacity = ua.Key("external_queue_capacity").MustInt(10000)
+	if uaCfg.ExternalQueueCapacity < 1 {
+		return fmt.Errorf("value of setting 'external_queue_capacity' should be 1 or greater")
+	}
+	uaCfg.ExternalMaxBatchSize = ua.Key("external_max_batch_size").MustInt(64)
+	if uaCfg.ExternalMaxBatchSize < 1 || uaCfg.ExternalMaxBatchSize > 64 {
+		return fmt.Errorf("value of setting 'external_max_batch_size' should be between 1 and 64")
+	}
+	uaCfg.ExternalFlushInterval, err = gtime.ParseDuration(valueAsString(ua, "external_flush_interval", "0s"))
+	if err != nil {
+		return err
+	}
+
+	uaCfg.DeliveryReceiptsTTL, err = gtime.ParseDuration(valueAsString(ua, "delivery_receipts_ttl", "168h"))
+	if err != nil {
+		return err
+	}
+	if uaCfg.DeliveryReceiptsTTL < 0 {
+		return fmt.Errorf("value of setting 'delivery_receipts_ttl' should be 0 or greater")
+	}
+	uaCfg.DeliveryReceiptsBufferSize = ua.Key("delivery_receipts_buffer_size").MustInt(10000)
+	if uaCfg.DeliveryReceiptsBufferSize <= 0 {
+		return fmt.Errorf("value of setting 'delivery_receipts_buffer_size' should be greater than 0")
+	}
+
 	uaCfg.BaseInterval = SchedulerBaseInterval
 
 	uaMinInterval, err := gtime.ParseDuration(valueAsString(ua, "min_interval", uaCfg.BaseInterval.String()))
@@ -269,6 +513,30 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	return nil
 }
 
+// readOrgIDs returns the organization IDs of a comma-separated list.
+func readOrgIDs(section *ini.Section, keyName string) (map[int64]struct{}, error) {
+	orgIDs := make(map[int64]struct{})
+	for _, org := range util.SplitString(valueAsString(section, keyName, "")) {
+		orgID, err := strconv.ParseInt(org, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		orgIDs[orgID] = struct{}{}
+	}
+	return orgIDs, nil
+}
+
+// readOneOf returns the value of the key, which must be one of the given values, the first one being the default.
+func readOneOf(section *ini.Section, keyName string, values ...string) (string, error) {
+	v := valueAsString(section, keyName, values[0])
+	for _, allowed := range values {
+		if v == allowed {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("invalid value %q of setting '%s', it must be one of %s", v, keyName, strings.Join(values, ", "))
+}
+
 func GetAlertmanagerDefaultConfiguration() string {
 	return alertmanagerDefaultConfiguration
 }
@@ -266,3 +266,39 @@ func TestMinInterval(t *testing.T) {
 		})
 	}
 }
+
+func TestInvalidDeliverySettings(t *testing.T) {
+	testCases := []struct {
+		key   string
+		value string
+		err   string
+	}{
+		{key: "ordered_delivery_orgs", value: "1,one", err: "invalid syntax"},
+		{key: "startup_notification_labels", value: "severity=info,team", err: "invalid startup notification label"},
+		{key: "compressed_sends_orgs", value: "2;3", err: "invalid syntax"},
+		{key: "missing_local_notifier", value: "fail", err: "it must be one of ignore, warn, error"},
+		{key: "external_required_labels", value: "team,=default", err: "invalid required label"},
+		{key: "external_missing_labels_policy", value: "ignore", err: "it must be one of send, drop, internal, default"},
+		{key: "duplicate_admin_configs", value: "first", err: "it must be one of latest, skip"},
+		{key: "external_send_backlog_size", value: "-1", err: "should be 0 or greater"},
+		{key: "external_sender_concurrency", value: "0", err: "should be 1 or greater"},
+		{key: "external_rate_limit", value: "-1", err: "should be 0 or greater"},
+		{key: "external_queue_capacity", value: "0", err: "should be 1 or greater"},
+		{key: "external_max_batch_size", value: "65", err: "should be between 1 and 64"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			f := ini.Empty()
+			section, err := f.NewSection("unified_alerting")
+			require.NoError(t, err)
+			_, err = section.NewKey(tc.key, tc.value)
+			require.NoError(t, err)
+
+			cfg := NewCfg()
+			cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+			err = cfg.ReadUnifiedAlertingSettings(f)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}